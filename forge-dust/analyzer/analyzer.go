@@ -1,41 +1,105 @@
 package analyzer
 
 import (
-	"crypto/md5"
-	"encoding/hex"
-	"io"
+	"context"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"time"
 
 	"forge-dust/scanner"
+	"forge-dust/timing"
 )
 
 type Analysis struct {
 	LargeFiles      []FileReport
 	OldFiles        []FileReport
 	CacheDirs       []CacheReport
+	GlobalCaches    []CacheReport // Package managers' shared caches in home
+	SystemCaches    []CacheReport // Caches outside home, with --system; not in TotalReclaimable
+	SystemDenied    []string      // System caches there wasn't access to size
+	Trash           []CacheReport // Home's trash folders, emptied rather than deleted
 	DuplicateGroups []DuplicateGroup
 	Downloads       []FileReport
+	SizeBand        []FileReport // Files inside the --size-range band, largest first
+	SizeBandCount   int          // All files in the band, not just those listed
+	SizeBandTotal   int64
+	KeptRecent      int // Files held back by --keep-recent
+	RecentlyUsed    int // Files held back by --exclude-recent-access
+	TrackedFiles    []TrackedReport // Large files committed to git (--git-aware), largest first
+	SmallFileDirs   []SmallFilesReport // Directories whose many small files add up, largest first
+	OldInstallers   []InstallerGroup   // Superseded installer versions in Downloads, most to free first
+	MountedImages   []MountedReport    // Backing files of attached disk images, held out of the rest; largest first
+	Offloaded       OffloadedReport    // Files kept in iCloud: counted, never suggested
+	ExtensionBreakdown map[string]int64 // Bytes by lowercased extension, "" for none; hard-linked data once, the Trash left out
+	DuplicateReclaimable int64 // Freed by keeping one copy in each duplicate group
 	TotalReclaimable int64
 	ScanStats       ScanStats
 }
 
+// OffloadedReport tallies files iCloud keeps for the user, whose local
+// copies are placeholders. Deleting one frees next to nothing and reading one
+// downloads it, so they're left out of every other finding.
+type OffloadedReport struct {
+	Files   int
+	OnDisk  int64 // Taken by the placeholders
+	Logical int64 // In iCloud, for the files whose size is known
+}
+
 type FileReport struct {
 	Path        string
 	Size        int64
 	ModTime     time.Time
+	AccessTime  time.Time // Zero where the filesystem doesn't record it
 	Age         time.Duration
 	Description string
+	HardLinks   int // Paths sharing this file's data, if more than one
+	Archive     *scanner.ArchiveContents // What's inside, for archives peeked into
+
+	id scanner.FileID // Tells hard links to the same data apart from copies
+}
+
+// newFileReport reports on file, which is age old
+func newFileReport(file scanner.FileInfo, age time.Duration) FileReport {
+	return FileReport{
+		Path:       file.Path,
+		Size:       file.Size,
+		ModTime:    file.ModTime,
+		AccessTime: file.AccessTime,
+		Age:        age,
+		HardLinks:  file.Links,
+		id:         file.ID,
+	}
+}
+
+// TrackedReport is a large file that git tracks; the fix is untracking, not deleting
+type TrackedReport struct {
+	Path   string
+	Size   int64
+	Repo   string
+	Advice string
 }
 
 type CacheReport struct {
-	Path        string
-	Size        int64
-	Type        string
-	Description string
+	Path         string
+	Size         int64
+	Type         string
+	Description  string
+	CleanCommand string // set for global caches, which have their own cleanup command
+	Risk         string // set for project caches, from scanner.CacheDirs
+	Reversible   bool
+	Rebuild      string // how a project cache comes back, if known
+	ModTime      time.Time // Directory's own modtime; recent means the cache is in use
+}
+
+// SmallFilesReport is a directory holding many files too small to report on
+// their own, counting those in subdirectories up to smallFileDepth down
+type SmallFilesReport struct {
+	Path  string
+	Files int
+	Size  int64
 }
 
 type DuplicateGroup struct {
@@ -55,20 +119,74 @@ type Analyzer struct {
 	MinLargeFile   int64         // Minimum size to consider "large" (default 100MB)
 	OldFileAge     time.Duration // Age threshold for "old" files (default 1 year)
 	DownloadsPath  string
+	HomeDir        string
 	CheckDuplicates bool
+	MinDuplicateSize   int64 // Files must be larger than this to be checked for duplicates (default 1MB)
+	MaxDuplicateGroups int   // Largest groups to report; 0 reports them all
+	FullHash           bool  // Confirm first-megabyte matches by hashing whole files
+	HashWorkers        int   // Files hashed at once when looking for duplicates (1 = serial)
+	OnHashProgress     func(hashed, total int) // Called about every 100ms while hashing for duplicates; total grows as matches need confirming
+	SizeWorkers     int // Concurrent cache directory size walks (1 = serial)
+	MinCacheSize    int64 // Caches, the Trash included, must be larger than this to be reported (default 1MB)
+	SizeBandMin     int64 // Smallest file in the size band (inclusive)
+	SizeBandMax     int64 // Upper bound of the size band (exclusive); 0 disables the band
+	KeepRecent      int   // Newest files to leave out of each age/size-based category
+	RecentAccess    time.Duration // Leave out age/size-based findings read within this long; 0 disables
+	GitAware        bool  // Report large files tracked by git
+	System          bool  // Also size the system caches outside home
+	MinTrackedFile  int64 // Minimum size for a tracked file to be reported (default 10MB)
+	ListTracked     scanner.TrackedLister
+	ListMounts      scanner.MountLister // Attached disk images, whose backing files are held back; nil checks none
+	SmallFileMax      int64 // Files under this size count as small (default 64KB)
+	MinSmallFiles     int   // Small files a directory needs before it's reported (default 1000)
+	MinSmallFileTotal int64 // ...and how much they must add up to (default 100MB)
+	Timings           *timing.Timer // Records the slow steps as "analyze: ..."; nil records nothing
+	PeekArchives      bool  // List what's inside reported zip and tar.gz files
+	MinPeekSize       int64 // Smallest archive to peek into (default 100MB)
 }
 
+// RecentAccessWindow is how recently a file must have been opened for
+// --exclude-recent-access to take it as still in use
+const RecentAccessWindow = 30 * 24 * time.Hour
+
+// trashType marks a trash folder among the cache candidates
+const trashType = "trash"
+
+// smallFileDepth is how many levels of subdirectories a small file also
+// counts towards, so caches sharded into ab/cd/ directories still add up
+const smallFileDepth = 2
+
 func New() *Analyzer {
 	home, _ := os.UserHomeDir()
 	return &Analyzer{
 		MinLargeFile:    100 * 1024 * 1024,  // 100MB
 		OldFileAge:      365 * 24 * time.Hour, // 1 year
 		DownloadsPath:   filepath.Join(home, "Downloads"),
+		HomeDir:         home,
 		CheckDuplicates: false, // Disabled by default (slow)
+		MinDuplicateSize:   1024 * 1024, // 1MB
+		MaxDuplicateGroups: 10,
+		HashWorkers:        4,
+		SizeWorkers:     4,
+		MinCacheSize:    1024 * 1024, // 1MB
+		MinTrackedFile:  10 * 1024 * 1024, // 10MB
+		ListTracked:     scanner.GitLsFiles,
+		ListMounts:      scanner.HdiutilInfo,
+		SmallFileMax:      64 * 1024,         // 64KB
+		MinSmallFiles:     1000,
+		MinSmallFileTotal: 100 * 1024 * 1024, // 100MB
+		MinPeekSize:       100 * 1024 * 1024, // 100MB
 	}
 }
 
 func (a *Analyzer) Analyze(result *scanner.ScanResult) *Analysis {
+	return a.AnalyzeContext(context.Background(), result)
+}
+
+// AnalyzeContext is Analyze, with ctx able to cut the duplicate search short:
+// files not yet hashed when it's done are left out of the duplicate groups
+func (a *Analyzer) AnalyzeContext(ctx context.Context, result *scanner.ScanResult) *Analysis {
+	defer a.Timings.Start("analyze")()
 	analysis := &Analysis{
 		ScanStats: ScanStats{
 			TotalFiles: result.TotalFiles,
@@ -76,6 +194,7 @@ func (a *Analyzer) Analyze(result *scanner.ScanResult) *Analysis {
 			TotalSize:  result.TotalSize,
 			ScanTime:   result.ScanTime,
 		},
+		ExtensionBreakdown: make(map[string]int64),
 	}
 
 	now := time.Now()
@@ -83,78 +202,247 @@ func (a *Analyzer) Analyze(result *scanner.ScanResult) *Analysis {
 	// Maps for deduplication
 	sizeMap := make(map[int64][]string) // For potential duplicates
 
+	// Cache directories are sized after the loop so the walks can run in parallel
+	var cacheCandidates []CacheReport
+	var gitCandidates []FileReport
+	smallFiles := make(map[string]*SmallFilesReport)
+	linked := make(map[scanner.FileID]bool) // Hard-linked data already counted
+	var installers []FileReport
+
 	for _, file := range result.Files {
 		// Skip directories for file analysis
+		// The trash is reported as a whole; what's inside was already thrown away
+		if scanner.InTrash(file.Path, a.HomeDir) {
+			continue
+		}
+
 		if file.IsDir {
+			if scanner.IsTrash(file.Path, a.HomeDir) {
+				cacheCandidates = append(cacheCandidates, CacheReport{
+					Path:        file.Path,
+					Type:        trashType,
+					Description: "Deleted files waiting for the Trash to be emptied",
+					ModTime:     file.ModTime,
+				})
+				continue
+			}
+
+			// Global caches first: ~/.npm is both, and the global guidance is better
+			if gc, ok := scanner.ClassifyGlobalCache(file.Path, a.HomeDir); ok {
+				cacheCandidates = append(cacheCandidates, CacheReport{
+					Path:         file.Path,
+					Type:         gc.Manager,
+					Description:  gc.Description,
+					CleanCommand: gc.CleanCommand,
+					ModTime:      file.ModTime,
+				})
+				continue
+			}
+
 			// Check if it's a cache directory
-			name := filepath.Base(file.Path)
-			if isCache, desc := scanner.IsCacheDir(name); isCache {
-				size, _ := scanner.GetDirSize(file.Path)
-				if size > 1024*1024 { // Only report if > 1MB
-					analysis.CacheDirs = append(analysis.CacheDirs, CacheReport{
-						Path:        file.Path,
-						Size:        size,
-						Type:        name,
-						Description: desc,
-					})
-					analysis.TotalReclaimable += size
-				}
+			if cd, ok := scanner.ClassifyCacheDir(file.Path); ok {
+				cacheCandidates = append(cacheCandidates, CacheReport{
+					Path:        file.Path,
+					Type:        filepath.Base(file.Path),
+					Description: cd.Description,
+					Risk:        cd.Risk,
+					Reversible:  cd.Reversible,
+					Rebuild:     cd.Rebuild,
+					ModTime:     file.ModTime,
+				})
 			}
 			continue
 		}
 
+		if file.Offloaded {
+			analysis.Offloaded.Files++
+			analysis.Offloaded.OnDisk += file.Size
+			analysis.Offloaded.Logical += file.Logical
+			continue
+		}
+
 		age := now.Sub(file.ModTime)
 
+		// Data reached through several hard links is only counted at the first
+		firstLink := true
+		if file.HardLinked() {
+			firstLink = !linked[file.ID]
+			linked[file.ID] = true
+		}
+
+		if firstLink {
+			analysis.ExtensionBreakdown[strings.ToLower(filepath.Ext(file.Path))] += file.Size
+		}
+
 		// Large files
 		if file.Size >= a.MinLargeFile {
-			analysis.LargeFiles = append(analysis.LargeFiles, FileReport{
-				Path:    file.Path,
-				Size:    file.Size,
-				ModTime: file.ModTime,
-				Age:     age,
-			})
+			analysis.LargeFiles = append(analysis.LargeFiles, newFileReport(file, age))
 		}
 
 		// Old files (> 1 year old and > 10MB)
 		if age > a.OldFileAge && file.Size > 10*1024*1024 {
-			analysis.OldFiles = append(analysis.OldFiles, FileReport{
-				Path:    file.Path,
-				Size:    file.Size,
-				ModTime: file.ModTime,
-				Age:     age,
-			})
+			analysis.OldFiles = append(analysis.OldFiles, newFileReport(file, age))
+		}
+
+		// Size band ("medium clutter")
+		if a.inSizeBand(file.Size) {
+			analysis.SizeBandCount++
+			if firstLink {
+				analysis.SizeBandTotal += file.Size
+			}
+			analysis.SizeBand = append(analysis.SizeBand, newFileReport(file, age))
 		}
 
-		// Track for duplicates
-		if a.CheckDuplicates && file.Size > 1024*1024 { // Only check files > 1MB
+		// Small files are only interesting in bulk, so tally them by directory
+		if file.Size < a.SmallFileMax && firstLink {
+			dir := filepath.Dir(file.Path)
+			for level := 0; level <= smallFileDepth; level++ {
+				tally, ok := smallFiles[dir]
+				if !ok {
+					tally = &SmallFilesReport{Path: dir}
+					smallFiles[dir] = tally
+				}
+				tally.Files++
+				tally.Size += file.Size
+				if parent := filepath.Dir(dir); parent != dir {
+					dir = parent
+				} else {
+					break
+				}
+			}
+		}
+
+		// Checked against git after the loop, one ls-files per repo
+		if a.GitAware && file.Size >= a.MinTrackedFile {
+			gitCandidates = append(gitCandidates, FileReport{Path: file.Path, Size: file.Size})
+		}
+
+		// Track for duplicates; other links to the same data aren't copies
+		if a.CheckDuplicates && file.Size > a.MinDuplicateSize && firstLink {
 			sizeMap[file.Size] = append(sizeMap[file.Size], file.Path)
 		}
 
 		// Downloads folder analysis
 		if strings.HasPrefix(file.Path, a.DownloadsPath) && file.Size > 50*1024*1024 {
-			analysis.Downloads = append(analysis.Downloads, FileReport{
-				Path:    file.Path,
-				Size:    file.Size,
-				ModTime: file.ModTime,
-				Age:     age,
-			})
+			analysis.Downloads = append(analysis.Downloads, newFileReport(file, age))
+		}
+
+		// Installers of any size, grouped by name after the loop
+		if within(file.Path, a.DownloadsPath) && IsInstaller(file.Path) && firstLink {
+			installers = append(installers, newFileReport(file, age))
 		}
 	}
 
+	// Size cache directories (independent subtrees, so safe to walk concurrently)
+	cacheCandidates = outermost(cacheCandidates)
+	cachePaths := make([]string, len(cacheCandidates))
+	for i, c := range cacheCandidates {
+		cachePaths[i] = c.Path
+	}
+	stop := a.Timings.Start("analyze: cache sizes")
+	cacheSizes := scanner.GetDirSizes(cachePaths, a.SizeWorkers)
+	stop()
+	for i, size := range cacheSizes {
+		if size > a.MinCacheSize {
+			cache := cacheCandidates[i]
+			cache.Size = size
+			switch {
+			case cache.Type == trashType:
+				analysis.Trash = append(analysis.Trash, cache)
+			case cache.CleanCommand != "":
+				analysis.GlobalCaches = append(analysis.GlobalCaches, cache)
+			default:
+				analysis.CacheDirs = append(analysis.CacheDirs, cache)
+			}
+			analysis.TotalReclaimable += size
+		}
+	}
+
+	if a.GitAware {
+		stop := a.Timings.Start("analyze: git")
+		analysis.TrackedFiles = a.findTracked(gitCandidates)
+		stop()
+	}
+
+	if a.System {
+		stop := a.Timings.Start("analyze: system caches")
+		analysis.SystemCaches, analysis.SystemDenied = a.findSystemCaches(scanner.FindSystemCaches())
+		stop()
+	}
+
+	analysis.SmallFileDirs = a.findSmallFileDirs(smallFiles, cacheCandidates)
+	for _, d := range analysis.SmallFileDirs {
+		analysis.TotalReclaimable += d.Size
+	}
+
 	// Find duplicates (only if enabled)
 	if a.CheckDuplicates {
-		analysis.DuplicateGroups = findDuplicates(sizeMap)
-		for _, group := range analysis.DuplicateGroups {
-			// Can reclaim all but one copy
-			analysis.TotalReclaimable += group.Size * int64(len(group.Files)-1)
+		stop := a.Timings.Start("analyze: duplicates")
+		analysis.DuplicateGroups = a.findDuplicates(ctx, sizeMap)
+		stop()
+	}
+
+	// Leave files still being opened alone, however long since they changed
+	if a.RecentAccess > 0 {
+		used := make(map[string]bool)
+		since := now.Add(-a.RecentAccess)
+		analysis.LargeFiles = dropUsedSince(analysis.LargeFiles, since, used)
+		analysis.OldFiles = dropUsedSince(analysis.OldFiles, since, used)
+		analysis.Downloads = dropUsedSince(analysis.Downloads, since, used)
+		analysis.RecentlyUsed = len(used)
+	}
+
+	// Leave the newest few of each age/size-based category alone
+	if a.KeepRecent > 0 {
+		kept := make(map[string]bool)
+		analysis.LargeFiles = keepRecent(analysis.LargeFiles, a.KeepRecent, kept)
+		analysis.OldFiles = keepRecent(analysis.OldFiles, a.KeepRecent, kept)
+		analysis.Downloads = keepRecent(analysis.Downloads, a.KeepRecent, kept)
+		analysis.KeptRecent = len(kept)
+	}
+
+	// A mounted image's backing file is never offered for deletion
+	bandBefore := len(analysis.SizeBand)
+	analysis.MountedImages = a.holdMounted(&analysis.DuplicateGroups, &analysis.LargeFiles, &analysis.OldFiles, &analysis.Downloads, &analysis.SizeBand, &installers)
+	if held := bandBefore - len(analysis.SizeBand); held > 0 {
+		analysis.SizeBandCount -= held
+		for _, m := range analysis.MountedImages {
+			if a.inSizeBand(m.Size) {
+				analysis.SizeBandTotal -= m.Size
+			}
 		}
 	}
 
-	// Add large files to reclaimable (user's choice)
+	for _, group := range analysis.DuplicateGroups {
+		// Can reclaim all but one copy
+		analysis.DuplicateReclaimable += group.Size * int64(len(group.Files)-1)
+	}
+	analysis.TotalReclaimable += analysis.DuplicateReclaimable
+
+	// Add large files to reclaimable (user's choice), hard-linked data once
+	counted := make(map[scanner.FileID]bool)
+	countedLarge := make(map[string]bool)
 	for _, f := range analysis.LargeFiles {
+		countedLarge[f.Path] = true
+		if f.HardLinks > 1 {
+			if counted[f.id] {
+				continue
+			}
+			counted[f.id] = true
+		}
 		analysis.TotalReclaimable += f.Size
 	}
 
+	// Older installer versions, unless already counted as large files
+	analysis.OldInstallers = groupInstallers(installers)
+	for _, g := range analysis.OldInstallers {
+		for _, f := range g.Older {
+			if !countedLarge[f.Path] {
+				analysis.TotalReclaimable += f.Size
+			}
+		}
+	}
+
 	// Sort results by size
 	sort.Slice(analysis.LargeFiles, func(i, j int) bool {
 		return analysis.LargeFiles[i].Size > analysis.LargeFiles[j].Size
@@ -165,9 +453,15 @@ func (a *Analyzer) Analyze(result *scanner.ScanResult) *Analysis {
 	sort.Slice(analysis.CacheDirs, func(i, j int) bool {
 		return analysis.CacheDirs[i].Size > analysis.CacheDirs[j].Size
 	})
+	sort.Slice(analysis.GlobalCaches, func(i, j int) bool {
+		return analysis.GlobalCaches[i].Size > analysis.GlobalCaches[j].Size
+	})
 	sort.Slice(analysis.Downloads, func(i, j int) bool {
 		return analysis.Downloads[i].Size > analysis.Downloads[j].Size
 	})
+	sort.Slice(analysis.SizeBand, func(i, j int) bool {
+		return analysis.SizeBand[i].Size > analysis.SizeBand[j].Size
+	})
 
 	// Limit results
 	if len(analysis.LargeFiles) > 20 {
@@ -182,63 +476,267 @@ func (a *Analyzer) Analyze(result *scanner.ScanResult) *Analysis {
 	if len(analysis.Downloads) > 15 {
 		analysis.Downloads = analysis.Downloads[:15]
 	}
+	if len(analysis.SizeBand) > 20 {
+		analysis.SizeBand = analysis.SizeBand[:20]
+	}
+	if len(analysis.SmallFileDirs) > 15 {
+		analysis.SmallFileDirs = analysis.SmallFileDirs[:15]
+	}
+	if len(analysis.OldInstallers) > 15 {
+		analysis.OldInstallers = analysis.OldInstallers[:15]
+	}
+
+	// Only what's reported is peeked into, so the limits above bound the work
+	if a.PeekArchives {
+		stop := a.Timings.Start("analyze: archives")
+		a.peekArchives(analysis.LargeFiles, analysis.OldFiles, analysis.Downloads, analysis.SizeBand)
+		stop()
+	}
 
 	return analysis
 }
 
-func findDuplicates(sizeMap map[int64][]string) []DuplicateGroup {
-	var groups []DuplicateGroup
+// peekArchives fills in Archive for the reported archives of at least
+// MinPeekSize, reading each only once however many lists it's in. Archives
+// that can't be read are left without contents.
+func (a *Analyzer) peekArchives(lists ...[]FileReport) {
+	peeked := make(map[string]*scanner.ArchiveContents)
+	for _, files := range lists {
+		for i := range files {
+			f := &files[i]
+			if f.Size < a.MinPeekSize || !scanner.IsArchive(f.Path) {
+				continue
+			}
+			contents, ok := peeked[f.Path]
+			if !ok {
+				contents, _ = scanner.PeekArchive(f.Path)
+				peeked[f.Path] = contents
+			}
+			f.Archive = contents
+		}
+	}
+}
 
-	for size, files := range sizeMap {
-		if len(files) < 2 {
+// ExtensionTotal is the space files of one extension take up
+type ExtensionTotal struct {
+	Ext  string // "" for files without one
+	Size int64
+}
+
+// TopExtensions returns the n extensions taking the most space, largest
+// first; n <= 0 returns them all
+func (a *Analysis) TopExtensions(n int) []ExtensionTotal {
+	var totals []ExtensionTotal
+	for ext, size := range a.ExtensionBreakdown {
+		totals = append(totals, ExtensionTotal{Ext: ext, Size: size})
+	}
+	sort.Slice(totals, func(i, j int) bool {
+		if totals[i].Size != totals[j].Size {
+			return totals[i].Size > totals[j].Size
+		}
+		return totals[i].Ext < totals[j].Ext
+	})
+	if n > 0 && len(totals) > n {
+		totals = totals[:n]
+	}
+	return totals
+}
+
+// findSmallFileDirs returns the directories whose small files pass both
+// thresholds. Only the deepest qualifying directory is kept, so its parents
+// don't repeat it, and caches and home itself are left out: the caches are
+// reported already, and deleting home is never the answer.
+func (a *Analyzer) findSmallFileDirs(tallies map[string]*SmallFilesReport, caches []CacheReport) []SmallFilesReport {
+	var candidates []SmallFilesReport
+	for _, t := range tallies {
+		if t.Files < a.MinSmallFiles || t.Size < a.MinSmallFileTotal {
+			continue
+		}
+		if within(a.HomeDir, t.Path) || a.underCache(t.Path, caches) {
 			continue
 		}
+		candidates = append(candidates, *t)
+	}
 
-		// Hash files with same size
-		hashMap := make(map[string][]string)
-		for _, path := range files {
-			hash, err := hashFile(path)
-			if err != nil {
-				continue
+	// Deepest first, so a directory can check whether a child was already taken
+	sort.Slice(candidates, func(i, j int) bool {
+		di := strings.Count(candidates[i].Path, string(filepath.Separator))
+		dj := strings.Count(candidates[j].Path, string(filepath.Separator))
+		if di != dj {
+			return di > dj
+		}
+		return candidates[i].Path < candidates[j].Path
+	})
+
+	var dirs []SmallFilesReport
+	for _, c := range candidates {
+		covered := false
+		for _, d := range dirs {
+			if within(d.Path, c.Path) {
+				covered = true
+				break
 			}
-			hashMap[hash] = append(hashMap[hash], path)
 		}
+		if !covered {
+			dirs = append(dirs, c)
+		}
+	}
 
-		// Find actual duplicates
-		for hash, paths := range hashMap {
-			if len(paths) > 1 {
-				groups = append(groups, DuplicateGroup{
-					Hash:  hash,
-					Size:  size,
-					Files: paths,
-				})
+	sort.Slice(dirs, func(i, j int) bool {
+		return dirs[i].Size > dirs[j].Size
+	})
+	return dirs
+}
+
+// underCache reports whether dir is inside, or is, one of the cache candidates
+func (a *Analyzer) underCache(dir string, caches []CacheReport) bool {
+	for _, c := range caches {
+		if within(dir, c.Path) {
+			return true
+		}
+	}
+	return false
+}
+
+// outermost drops the caches inside another cache, such as the
+// node_modules of a package inside node_modules: the outer one's size
+// already includes them
+func outermost(caches []CacheReport) []CacheReport {
+	paths := make(map[string]bool, len(caches))
+	for _, c := range caches {
+		paths[c.Path] = true
+	}
+	return slices.DeleteFunc(caches, func(c CacheReport) bool {
+		for dir := filepath.Dir(c.Path); dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+			if paths[dir] {
+				return true
 			}
 		}
+		return false
+	})
+}
+
+// within reports whether path is dir or somewhere beneath it
+func within(path, dir string) bool {
+	return path == dir || strings.HasPrefix(path, dir+string(filepath.Separator))
+}
+
+// findSystemCaches sizes the system caches in dirs, largest first, and
+// lists those it wasn't allowed to read. They're all high risk: other users
+// and system services depend on them, and clearing them takes sudo.
+func (a *Analyzer) findSystemCaches(dirs []string) ([]CacheReport, []string) {
+	var readable, denied []string
+	for _, dir := range dirs {
+		if _, err := os.ReadDir(dir); err != nil {
+			denied = append(denied, dir)
+			continue
+		}
+		readable = append(readable, dir)
 	}
 
-	sort.Slice(groups, func(i, j int) bool {
-		return groups[i].Size*int64(len(groups[i].Files)) > groups[j].Size*int64(len(groups[j].Files))
+	var reports []CacheReport
+	for i, size := range scanner.GetDirSizes(readable, a.SizeWorkers) {
+		sc, _ := scanner.ClassifySystemCache(readable[i])
+		if size == 0 {
+			continue
+		}
+		reports = append(reports, CacheReport{
+			Path:         readable[i],
+			Size:         size,
+			Type:         "system",
+			Description:  sc.Description,
+			CleanCommand: sc.Advice,
+			Risk:         "high",
+			Reversible:   true,
+		})
+	}
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].Size > reports[j].Size
 	})
+	return reports, denied
+}
 
-	if len(groups) > 10 {
-		groups = groups[:10]
+// findTracked returns the candidates that git tracks, grouped by repository
+func (a *Analyzer) findTracked(candidates []FileReport) []TrackedReport {
+	repoOf := make(map[string]string)           // directory -> repo root
+	tracked := make(map[string]map[string]bool) // repo root -> tracked relative paths
+	var reports []TrackedReport
+
+	for _, f := range candidates {
+		dir := filepath.Dir(f.Path)
+		repo, ok := repoOf[dir]
+		if !ok {
+			repo = scanner.FindRepo(f.Path)
+			repoOf[dir] = repo
+		}
+		if repo == "" {
+			continue
+		}
+
+		files, ok := tracked[repo]
+		if !ok {
+			files = make(map[string]bool)
+			if list, err := a.ListTracked(repo); err == nil {
+				for _, rel := range list {
+					files[filepath.FromSlash(rel)] = true
+				}
+			}
+			tracked[repo] = files // An unreadable repo is remembered as tracking nothing
+		}
+
+		rel, err := filepath.Rel(repo, f.Path)
+		if err != nil || !files[rel] {
+			continue
+		}
+		reports = append(reports, TrackedReport{
+			Path:   f.Path,
+			Size:   f.Size,
+			Repo:   repo,
+			Advice: scanner.UntrackAdvice(rel),
+		})
 	}
 
-	return groups
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].Size > reports[j].Size
+	})
+	return reports
 }
 
-func hashFile(path string) (string, error) {
-	file, err := os.Open(path)
-	if err != nil {
-		return "", err
+// keepRecent drops the n most recently modified files, recording them in kept
+func keepRecent(files []FileReport, n int, kept map[string]bool) []FileReport {
+	if len(files) <= n {
+		for _, f := range files {
+			kept[f.Path] = true
+		}
+		return nil
 	}
-	defer file.Close()
 
-	// Only hash first 1MB for speed
-	hash := md5.New()
-	if _, err := io.CopyN(hash, file, 1024*1024); err != nil && err != io.EOF {
-		return "", err
+	sorted := make([]FileReport, len(files))
+	copy(sorted, files)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].ModTime.After(sorted[j].ModTime)
+	})
+	for _, f := range sorted[:n] {
+		kept[f.Path] = true
 	}
+	return sorted[n:]
+}
+
+// dropUsedSince drops the files read or written after since, recording them
+// in used. Files whose filesystem doesn't record reads go by ModTime alone.
+func dropUsedSince(files []FileReport, since time.Time, used map[string]bool) []FileReport {
+	var kept []FileReport
+	for _, f := range files {
+		if f.ModTime.After(since) || f.AccessTime.After(since) {
+			used[f.Path] = true
+			continue
+		}
+		kept = append(kept, f)
+	}
+	return kept
+}
 
-	return hex.EncodeToString(hash.Sum(nil)), nil
+// inSizeBand reports whether size falls in [SizeBandMin, SizeBandMax)
+func (a *Analyzer) inSizeBand(size int64) bool {
+	return a.SizeBandMax > 0 && size >= a.SizeBandMin && size < a.SizeBandMax
 }
@@ -0,0 +1,682 @@
+package analyzer
+
+import (
+	"archive/zip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"forge-dust/scanner"
+)
+
+func TestSizeBandBoundaries(t *testing.T) {
+	const mb = 1024 * 1024
+	sizes := []int64{
+		10*mb - 1, // just below the band
+		10 * mb,   // lower bound is inclusive
+		50 * mb,
+		100*mb - 1, // largest in the band
+		100 * mb,   // upper bound is exclusive (and already a large file)
+		300 * mb,
+	}
+
+	result := &scanner.ScanResult{}
+	for i, size := range sizes {
+		result.Files = append(result.Files, scanner.FileInfo{
+			Path:    fmt.Sprintf("/data/f%d.bin", i),
+			Size:    size,
+			ModTime: time.Now(),
+		})
+	}
+
+	a := New()
+	a.SizeBandMin = 10 * mb
+	a.SizeBandMax = 100 * mb
+	analysis := a.Analyze(result)
+
+	if analysis.SizeBandCount != 3 {
+		t.Errorf("SizeBandCount = %d, want 3", analysis.SizeBandCount)
+	}
+	if want := int64(10*mb + 50*mb + 100*mb - 1); analysis.SizeBandTotal != want {
+		t.Errorf("SizeBandTotal = %d, want %d", analysis.SizeBandTotal, want)
+	}
+	wantPaths := []string{"/data/f3.bin", "/data/f2.bin", "/data/f1.bin"}
+	if len(analysis.SizeBand) != len(wantPaths) {
+		t.Fatalf("SizeBand has %d files, want %d", len(analysis.SizeBand), len(wantPaths))
+	}
+	for i, f := range analysis.SizeBand {
+		if f.Path != wantPaths[i] {
+			t.Errorf("SizeBand[%d] = %s, want %s", i, f.Path, wantPaths[i])
+		}
+	}
+
+	// Without a band, nothing is reported
+	if got := New().Analyze(result); got.SizeBandCount != 0 || len(got.SizeBand) != 0 {
+		t.Errorf("band disabled: SizeBandCount = %d, want 0", got.SizeBandCount)
+	}
+}
+
+func TestKeepRecentProtectsNewestDownloads(t *testing.T) {
+	const mb = 1024 * 1024
+	now := time.Now()
+	ages := []time.Duration{
+		2 * time.Hour, // newest
+		30 * 24 * time.Hour,
+		24 * time.Hour,
+		200 * 24 * time.Hour,
+		90 * 24 * time.Hour,
+	}
+
+	result := &scanner.ScanResult{}
+	for i, age := range ages {
+		result.Files = append(result.Files, scanner.FileInfo{
+			Path:    fmt.Sprintf("/home/u/Downloads/f%d.dmg", i),
+			Size:    60 * mb,
+			ModTime: now.Add(-age),
+		})
+	}
+
+	a := New()
+	a.DownloadsPath = "/home/u/Downloads"
+	a.KeepRecent = 2
+	analysis := a.Analyze(result)
+
+	if analysis.KeptRecent != 2 {
+		t.Errorf("KeptRecent = %d, want 2", analysis.KeptRecent)
+	}
+	if len(analysis.Downloads) != 3 {
+		t.Fatalf("Downloads has %d files, want 3", len(analysis.Downloads))
+	}
+	for _, f := range analysis.Downloads {
+		if f.Path == "/home/u/Downloads/f0.dmg" || f.Path == "/home/u/Downloads/f2.dmg" {
+			t.Errorf("Downloads contains %s, one of the 2 newest files", f.Path)
+		}
+	}
+
+	// Keeping more than there are leaves nothing to delete
+	a.KeepRecent = 10
+	if got := a.Analyze(result); len(got.Downloads) != 0 || got.KeptRecent != 5 {
+		t.Errorf("KeepRecent=10: %d downloads, %d kept, want 0 and 5", len(got.Downloads), got.KeptRecent)
+	}
+}
+
+func TestGitAwareReportsOnlyTrackedFiles(t *testing.T) {
+	const mb = 1024 * 1024
+	root := t.TempDir()
+	repo := filepath.Join(root, "app")
+	if err := os.MkdirAll(filepath.Join(repo, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	files := []scanner.FileInfo{
+		{Path: filepath.Join(repo, "assets", "intro.mov"), Size: 80 * mb}, // tracked
+		{Path: filepath.Join(repo, "dist", "bundle.js"), Size: 20 * mb},   // tracked
+		{Path: filepath.Join(repo, "scratch", "dump.sql"), Size: 50 * mb}, // untracked
+		{Path: filepath.Join(repo, "assets", "logo.png"), Size: 1 * mb},   // tracked but small
+		{Path: filepath.Join(root, "outside.iso"), Size: 700 * mb},        // not in a repo
+	}
+	result := &scanner.ScanResult{}
+	for _, f := range files {
+		f.ModTime = time.Now()
+		result.Files = append(result.Files, f)
+	}
+
+	calls := 0
+	a := New()
+	a.GitAware = true
+	a.ListTracked = func(r string) ([]string, error) {
+		calls++
+		if r != repo {
+			t.Errorf("ListTracked(%q), want %q", r, repo)
+		}
+		return []string{"assets/intro.mov", "dist/bundle.js", "assets/logo.png", "README.md"}, nil
+	}
+	analysis := a.Analyze(result)
+
+	if calls != 1 {
+		t.Errorf("ListTracked called %d times, want once per repo", calls)
+	}
+	want := []string{files[0].Path, files[1].Path}
+	if len(analysis.TrackedFiles) != len(want) {
+		t.Fatalf("TrackedFiles = %+v, want %v", analysis.TrackedFiles, want)
+	}
+	for i, f := range analysis.TrackedFiles {
+		if f.Path != want[i] || f.Repo != repo || f.Advice == "" {
+			t.Errorf("TrackedFiles[%d] = %+v, want %s in %s with advice", i, f, want[i], repo)
+		}
+	}
+
+	// Off unless asked for
+	a.GitAware = false
+	if got := a.Analyze(result); len(got.TrackedFiles) != 0 {
+		t.Errorf("GitAware off: TrackedFiles = %+v, want none", got.TrackedFiles)
+	}
+}
+
+func TestMountedImagesAreHeldBack(t *testing.T) {
+	const mb = 1024 * 1024
+	home := t.TempDir()
+	downloads := filepath.Join(home, "Downloads")
+	old := time.Now().Add(-2 * 365 * 24 * time.Hour)
+
+	files := []scanner.FileInfo{
+		{Path: filepath.Join(downloads, "Xcode.dmg"), Size: 900 * mb, ModTime: old},                   // mounted
+		{Path: filepath.Join(home, "Work.sparsebundle", "bands", "1f"), Size: 200 * mb, ModTime: old}, // inside a mounted bundle
+		{Path: filepath.Join(downloads, "Old.dmg"), Size: 300 * mb, ModTime: old},                     // not mounted
+		{Path: filepath.Join(home, "movie.mov"), Size: 500 * mb, ModTime: old},                        // not an image
+	}
+	result := &scanner.ScanResult{}
+	for _, f := range files {
+		result.Files = append(result.Files, f)
+	}
+
+	calls := 0
+	a := New()
+	a.HomeDir, a.DownloadsPath = home, downloads
+	a.ListMounts = func() ([]scanner.MountedImage, error) {
+		calls++
+		return []scanner.MountedImage{
+			{Image: files[0].Path, Device: "/dev/disk4", MountPoint: "/Volumes/Xcode"},
+			{Image: filepath.Join(home, "Work.sparsebundle"), Device: "/dev/disk5"},
+		}, nil
+	}
+	analysis := a.Analyze(result)
+
+	if calls != 1 {
+		t.Errorf("ListMounts called %d times, want once", calls)
+	}
+	if len(analysis.MountedImages) != 2 {
+		t.Fatalf("MountedImages = %+v, want the dmg and the bundle's band", analysis.MountedImages)
+	}
+	if m := analysis.MountedImages[0]; m.Path != files[0].Path || m.MountPoint != "/Volumes/Xcode" || !strings.Contains(m.Advice, "/Volumes/Xcode") {
+		t.Errorf("MountedImages[0] = %+v, want %s at /Volumes/Xcode with advice to detach it", m, files[0].Path)
+	}
+	if m := analysis.MountedImages[1]; m.Path != files[1].Path || !strings.Contains(m.Advice, "/dev/disk5") {
+		t.Errorf("MountedImages[1] = %+v, want %s, detached by device", m, files[1].Path)
+	}
+
+	for name, list := range map[string][]FileReport{"LargeFiles": analysis.LargeFiles, "OldFiles": analysis.OldFiles, "Downloads": analysis.Downloads} {
+		for _, f := range list {
+			if f.Path == files[0].Path || f.Path == files[1].Path {
+				t.Errorf("%s still offers mounted %s", name, f.Path)
+			}
+		}
+	}
+	if len(analysis.LargeFiles) != 2 {
+		t.Errorf("LargeFiles = %+v, want Old.dmg and movie.mov", analysis.LargeFiles)
+	}
+	if want := int64(800 * mb); analysis.TotalReclaimable != want {
+		t.Errorf("TotalReclaimable = %d, want %d without the mounted images", analysis.TotalReclaimable, want)
+	}
+
+	// Without a disk image among the findings, nothing is listed
+	calls = 0
+	a.Analyze(&scanner.ScanResult{Files: files[3:]})
+	if calls != 0 {
+		t.Errorf("ListMounts called %d times with no disk images, want none", calls)
+	}
+}
+
+func TestMountedImagesAreHeldOutOfDuplicates(t *testing.T) {
+	const mb = 1024 * 1024
+	dir := t.TempDir()
+	result := &scanner.ScanResult{}
+	write := func(name string, size int) string {
+		path := filepath.Join(dir, name)
+		data := make([]byte, size)
+		copy(data, name[:1])
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		result.Files = append(result.Files, scanner.FileInfo{Path: path, Size: int64(size), ModTime: time.Now()})
+		return path
+	}
+	// A mounted image with one copy, and one with two
+	alone := write("a.dmg", 2*mb)
+	write("a copy.dmg", 2*mb)
+	pair := write("b.dmg", 3*mb)
+	write("b copy.dmg", 3*mb)
+	write("b copy 2.dmg", 3*mb)
+
+	a := New()
+	a.HomeDir, a.DownloadsPath = dir, filepath.Join(dir, "Downloads")
+	a.MinLargeFile = 100 * mb
+	a.CheckDuplicates = true
+	a.ListMounts = func() ([]scanner.MountedImage, error) {
+		return []scanner.MountedImage{
+			{Image: alone, Device: "/dev/disk4", MountPoint: "/Volumes/A"},
+			{Image: pair, Device: "/dev/disk5", MountPoint: "/Volumes/B"},
+		}, nil
+	}
+	analysis := a.Analyze(result)
+
+	if len(analysis.MountedImages) != 2 {
+		t.Errorf("MountedImages = %+v, want both images", analysis.MountedImages)
+	}
+	if len(analysis.DuplicateGroups) != 1 {
+		t.Fatalf("DuplicateGroups = %+v, want only the b copies", analysis.DuplicateGroups)
+	}
+	for _, path := range analysis.DuplicateGroups[0].Files {
+		if path == alone || path == pair {
+			t.Errorf("DuplicateGroups still offers mounted %s", path)
+		}
+	}
+	if want := int64(3 * mb); analysis.DuplicateReclaimable != want || analysis.TotalReclaimable != want {
+		t.Errorf("DuplicateReclaimable, TotalReclaimable = %d, %d, want %d", analysis.DuplicateReclaimable, analysis.TotalReclaimable, want)
+	}
+}
+
+func TestAggressiveDuplicatesFindSmallFiles(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, data []byte) scanner.FileInfo {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return scanner.FileInfo{Path: path, Size: int64(len(data)), ModTime: time.Now()}
+	}
+
+	small := make([]byte, 64*1024)
+	copy(small, "config backup")
+	// Same size and same first megabyte, different tails
+	head := make([]byte, 2*1024*1024)
+	tail := append([]byte{}, head...)
+	tail[len(tail)-1] = 1
+
+	result := &scanner.ScanResult{Files: []scanner.FileInfo{
+		write("a.json", small),
+		write("copy of a.json", small),
+		write("big1.bin", head),
+		write("big2.bin", tail),
+	}}
+
+	// The default scan skips files this small
+	a := New()
+	a.CheckDuplicates = true
+	if got := a.Analyze(result); len(got.DuplicateGroups) != 1 {
+		t.Errorf("default: %d duplicate groups, want 1 (the big files, matching on their first MB)", len(got.DuplicateGroups))
+	}
+
+	a.MinDuplicateSize = 4 * 1024
+	a.MaxDuplicateGroups = 0
+	a.FullHash = true
+	analysis := a.Analyze(result)
+
+	if len(analysis.DuplicateGroups) != 1 {
+		t.Fatalf("aggressive: DuplicateGroups = %+v, want just the small pair", analysis.DuplicateGroups)
+	}
+	if g := analysis.DuplicateGroups[0]; g.Size != int64(len(small)) || len(g.Files) != 2 {
+		t.Errorf("aggressive: group = %+v, want 2 files of %d bytes", g, len(small))
+	}
+	if analysis.DuplicateReclaimable != int64(len(small)) {
+		t.Errorf("DuplicateReclaimable = %d, want %d", analysis.DuplicateReclaimable, len(small))
+	}
+}
+
+func TestTrashIsReportedOnItsOwn(t *testing.T) {
+	const mb = 1024 * 1024
+	home := t.TempDir()
+	trash := filepath.Join(home, ".Trash")
+	bigInTrash := filepath.Join(trash, "old.iso")
+	if err := os.MkdirAll(filepath.Join(trash, "project", "node_modules"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(bigInTrash, make([]byte, 3*mb), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result := &scanner.ScanResult{Files: []scanner.FileInfo{
+		{Path: home, IsDir: true},
+		{Path: trash, IsDir: true},
+		{Path: filepath.Join(trash, "project"), IsDir: true},
+		{Path: filepath.Join(trash, "project", "node_modules"), IsDir: true},
+		{Path: bigInTrash, Size: 3 * mb, ModTime: time.Now().Add(-2 * 365 * 24 * time.Hour)},
+	}}
+
+	a := New()
+	a.HomeDir = home
+	a.MinLargeFile = mb
+	analysis := a.Analyze(result)
+
+	if len(analysis.Trash) != 1 || analysis.Trash[0].Path != trash || analysis.Trash[0].Size != 3*mb {
+		t.Fatalf("Trash = %+v, want %s with 3MB", analysis.Trash, trash)
+	}
+	if len(analysis.CacheDirs) != 0 || len(analysis.LargeFiles) != 0 || len(analysis.OldFiles) != 0 {
+		t.Errorf("trash contents reported again: caches %+v, large %+v, old %+v",
+			analysis.CacheDirs, analysis.LargeFiles, analysis.OldFiles)
+	}
+	if analysis.TotalReclaimable != 3*mb {
+		t.Errorf("TotalReclaimable = %d, want %d", analysis.TotalReclaimable, 3*mb)
+	}
+}
+
+func TestNestedCacheDirsReportedOnce(t *testing.T) {
+	const mb = 1024 * 1024
+	project := t.TempDir()
+	outer := filepath.Join(project, "node_modules")
+	inner := filepath.Join(outer, "left-pad", "node_modules")
+	deeper := filepath.Join(inner, "chalk", "node_modules")
+	for _, dir := range []string{outer, inner, deeper} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "index.js"), make([]byte, 2*mb), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result := &scanner.ScanResult{Files: []scanner.FileInfo{
+		// Deepest first: the outer one must win whatever order the scan found them in
+		{Path: deeper, IsDir: true},
+		{Path: inner, IsDir: true},
+		{Path: project, IsDir: true},
+		{Path: outer, IsDir: true},
+		{Path: filepath.Join(outer, "left-pad"), IsDir: true},
+	}}
+
+	a := New()
+	a.HomeDir = t.TempDir()
+	analysis := a.Analyze(result)
+
+	if len(analysis.CacheDirs) != 1 || analysis.CacheDirs[0].Path != outer {
+		t.Fatalf("CacheDirs = %+v, want only %s", analysis.CacheDirs, outer)
+	}
+	if analysis.CacheDirs[0].Size != 6*mb {
+		t.Errorf("CacheDirs[0].Size = %d, want %d including the nested ones", analysis.CacheDirs[0].Size, 6*mb)
+	}
+	if analysis.TotalReclaimable != 6*mb {
+		t.Errorf("TotalReclaimable = %d, want %d", analysis.TotalReclaimable, 6*mb)
+	}
+}
+
+func TestMinCacheSizeFiltersCaches(t *testing.T) {
+	const kb = 1024
+	project := t.TempDir()
+	sizes := map[string]int{"node_modules": 2048 * kb, "__pycache__": 512 * kb, ".pytest_cache": 8 * kb}
+	result := &scanner.ScanResult{}
+	for name, size := range sizes {
+		dir := filepath.Join(project, name)
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "data"), make([]byte, size), 0644); err != nil {
+			t.Fatal(err)
+		}
+		result.Files = append(result.Files, scanner.FileInfo{Path: dir, IsDir: true})
+	}
+
+	tests := []struct {
+		min  int64
+		want []string
+	}{
+		{New().MinCacheSize, []string{"node_modules"}},
+		{100 * kb, []string{"node_modules", "__pycache__"}},
+		{1, []string{"node_modules", "__pycache__", ".pytest_cache"}},
+		{4096 * kb, nil},
+	}
+	for _, tt := range tests {
+		a := New()
+		a.HomeDir = t.TempDir()
+		a.MinCacheSize = tt.min
+		var got []string
+		for _, c := range a.Analyze(result).CacheDirs {
+			got = append(got, filepath.Base(c.Path))
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("MinCacheSize %d: CacheDirs = %q, want %q", tt.min, got, tt.want)
+		}
+	}
+}
+
+func TestManySmallFilesAddUp(t *testing.T) {
+	const kb = 1024
+	result := &scanner.ScanResult{}
+	for i := 0; i < 10000; i++ {
+		// Sharded like many caches, so no one directory holds them all
+		result.Files = append(result.Files, scanner.FileInfo{
+			Path:    fmt.Sprintf("/home/u/proj/.tool-cache/%02x/%d.bin", i%256, i),
+			Size:    16 * kb,
+			ModTime: time.Now(),
+		})
+	}
+	// A handful of small files elsewhere stays below the count threshold
+	for i := 0; i < 10; i++ {
+		result.Files = append(result.Files, scanner.FileInfo{
+			Path: fmt.Sprintf("/home/u/notes/%d.txt", i),
+			Size: 16 * kb,
+		})
+	}
+
+	a := New()
+	a.HomeDir = "/home/u"
+	analysis := a.Analyze(result)
+
+	if len(analysis.SmallFileDirs) != 1 {
+		t.Fatalf("SmallFileDirs = %+v, want just the cache directory", analysis.SmallFileDirs)
+	}
+	got := analysis.SmallFileDirs[0]
+	if got.Path != "/home/u/proj/.tool-cache" || got.Files != 10000 || got.Size != 10000*16*kb {
+		t.Errorf("SmallFileDirs[0] = %+v, want /home/u/proj/.tool-cache with 10000 files, %d bytes", got, 10000*16*kb)
+	}
+}
+
+func TestHardLinkedFilesCountOnce(t *testing.T) {
+	const mb = 1024 * 1024
+	id := scanner.FileID{Dev: 1, Ino: 42}
+	result := &scanner.ScanResult{Files: []scanner.FileInfo{
+		{Path: "/data/a/backup.img", Size: 500 * mb, ModTime: time.Now(), ID: id, Links: 2},
+		{Path: "/data/b/backup.img", Size: 500 * mb, ModTime: time.Now(), ID: id, Links: 2},
+	}}
+
+	a := New()
+	a.HomeDir = "/home/u"
+	a.CheckDuplicates = true
+	analysis := a.Analyze(result)
+
+	if analysis.TotalReclaimable != 500*mb {
+		t.Errorf("TotalReclaimable = %d, want %d (one copy of the data)", analysis.TotalReclaimable, 500*mb)
+	}
+	if len(analysis.LargeFiles) != 2 {
+		t.Fatalf("LargeFiles has %d entries, want both paths listed", len(analysis.LargeFiles))
+	}
+	for _, f := range analysis.LargeFiles {
+		if f.HardLinks != 2 {
+			t.Errorf("%s HardLinks = %d, want 2", f.Path, f.HardLinks)
+		}
+	}
+	if len(analysis.DuplicateGroups) != 0 {
+		t.Errorf("DuplicateGroups = %+v, want none: links to one file aren't copies", analysis.DuplicateGroups)
+	}
+}
+
+func TestOffloadedFilesAreNeverSuggested(t *testing.T) {
+	const gb = 1024 * 1024 * 1024
+	old := time.Now().AddDate(-3, 0, 0)
+	home := t.TempDir()
+	a := New()
+	a.HomeDir = home
+	a.DownloadsPath = filepath.Join(home, "Downloads")
+	a.MinLargeFile = 1
+
+	path := filepath.Join(a.DownloadsPath, ".Big Movie.mov.icloud")
+	if err := os.MkdirAll(a.DownloadsPath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, make([]byte, 180), 0644); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	placeholder := scanner.NewFileInfo(path, info)
+	if !placeholder.Offloaded {
+		t.Fatalf("NewFileInfo(%q).Offloaded = false, want true", path)
+	}
+	placeholder.ModTime = old
+	dataless := scanner.FileInfo{Path: filepath.Join(home, "Documents", "archive.zip"), ModTime: old, Offloaded: true, Logical: 3 * gb}
+
+	analysis := a.Analyze(&scanner.ScanResult{Files: []scanner.FileInfo{placeholder, dataless}})
+
+	if n := len(analysis.LargeFiles) + len(analysis.OldFiles) + len(analysis.Downloads); n != 0 {
+		t.Errorf("Analyze() suggested %d offloaded files, want none", n)
+	}
+	if analysis.TotalReclaimable != 0 {
+		t.Errorf("TotalReclaimable = %d, want 0", analysis.TotalReclaimable)
+	}
+	want := OffloadedReport{Files: 2, OnDisk: 180, Logical: 3 * gb}
+	if analysis.Offloaded != want {
+		t.Errorf("Offloaded = %+v, want %+v", analysis.Offloaded, want)
+	}
+}
+
+func TestPeekArchivesAboveThreshold(t *testing.T) {
+	const mb = 1024 * 1024
+	dir := t.TempDir()
+	var zips []string
+	for _, name := range []string{"backup.zip", "small.zip"} {
+		path := filepath.Join(dir, name)
+		f, err := os.Create(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		zw := zip.NewWriter(f)
+		w, _ := zw.Create("photos/a.jpg")
+		w.Write([]byte("jpeg"))
+		zw.Close()
+		f.Close()
+		zips = append(zips, path)
+	}
+	// The sizes the scan saw, not the fixtures' real ones
+	result := &scanner.ScanResult{Files: []scanner.FileInfo{
+		{Path: zips[0], Size: 200 * mb, ModTime: time.Now()},
+		{Path: zips[1], Size: 20 * mb, ModTime: time.Now()},
+	}}
+
+	a := New()
+	a.HomeDir = "/home/u"
+	a.MinLargeFile = 10 * mb
+	if analysis := a.Analyze(result); analysis.LargeFiles[0].Archive != nil {
+		t.Errorf("Archive = %+v without PeekArchives, want nil", analysis.LargeFiles[0].Archive)
+	}
+
+	a.PeekArchives = true
+	analysis := a.Analyze(result)
+	if len(analysis.LargeFiles) != 2 {
+		t.Fatalf("LargeFiles has %d entries, want 2", len(analysis.LargeFiles))
+	}
+	big, small := analysis.LargeFiles[0], analysis.LargeFiles[1]
+	if big.Archive == nil || len(big.Archive.Entries) != 1 || big.Archive.Entries[0].Name != "photos/" {
+		t.Errorf("%s Archive = %+v, want photos/", big.Path, big.Archive)
+	}
+	if small.Archive != nil {
+		t.Errorf("%s Archive = %+v, want nil below MinPeekSize", small.Path, small.Archive)
+	}
+}
+
+func TestExtensionBreakdown(t *testing.T) {
+	const mb = 1024 * 1024
+	id := scanner.FileID{Dev: 1, Ino: 7}
+	result := &scanner.ScanResult{Files: []scanner.FileInfo{
+		{Path: "/data/movie.MOV", Size: 300 * mb, ModTime: time.Now()},
+		{Path: "/data/clip.mov", Size: 200 * mb, ModTime: time.Now()},
+		{Path: "/data/a/disk.img", Size: 400 * mb, ModTime: time.Now(), ID: id, Links: 2},
+		{Path: "/data/b/disk.img", Size: 400 * mb, ModTime: time.Now(), ID: id, Links: 2},
+		{Path: "/data/notes.txt", Size: 1 * mb, ModTime: time.Now()},
+		{Path: "/data/Makefile", Size: 2 * mb, ModTime: time.Now()},
+		{Path: "/data/photos", IsDir: true, ModTime: time.Now()},
+	}}
+
+	a := New()
+	a.HomeDir = "/home/u"
+	analysis := a.Analyze(result)
+
+	want := map[string]int64{
+		".mov": 500 * mb, // case folded together
+		".img": 400 * mb, // hard-linked data counted once
+		".txt": 1 * mb,
+		"":     2 * mb,
+	}
+	if len(analysis.ExtensionBreakdown) != len(want) {
+		t.Errorf("ExtensionBreakdown = %v, want %v", analysis.ExtensionBreakdown, want)
+	}
+	for ext, size := range want {
+		if got := analysis.ExtensionBreakdown[ext]; got != size {
+			t.Errorf("ExtensionBreakdown[%q] = %d, want %d", ext, got, size)
+		}
+	}
+
+	top := analysis.TopExtensions(2)
+	if len(top) != 2 || top[0].Ext != ".mov" || top[1].Ext != ".img" {
+		t.Errorf("TopExtensions(2) = %+v, want .mov then .img", top)
+	}
+	if all := analysis.TopExtensions(0); len(all) != len(want) {
+		t.Errorf("TopExtensions(0) has %d entries, want %d", len(all), len(want))
+	}
+}
+
+func TestExcludeRecentAccessProtectsFilesInUse(t *testing.T) {
+	const mb = 1024 * 1024
+	now := time.Now()
+	old := now.Add(-2 * 365 * 24 * time.Hour)
+	result := &scanner.ScanResult{Files: []scanner.FileInfo{
+		// Unchanged for two years, but opened yesterday
+		{Path: "/data/opened.iso", Size: 200 * mb, ModTime: old, AccessTime: now.Add(-24 * time.Hour)},
+		// Last opened long ago
+		{Path: "/data/stale.iso", Size: 200 * mb, ModTime: old, AccessTime: old.Add(time.Hour)},
+		// On a noatime filesystem: judged by ModTime alone
+		{Path: "/data/noatime.iso", Size: 200 * mb, ModTime: old},
+	}}
+
+	a := New()
+	a.RecentAccess = RecentAccessWindow
+	analysis := a.Analyze(result)
+
+	if analysis.RecentlyUsed != 1 {
+		t.Errorf("RecentlyUsed = %d, want 1", analysis.RecentlyUsed)
+	}
+	for name, files := range map[string][]FileReport{"LargeFiles": analysis.LargeFiles, "OldFiles": analysis.OldFiles} {
+		if len(files) != 2 {
+			t.Errorf("%s has %d files, want 2", name, len(files))
+		}
+		for _, f := range files {
+			if f.Path == "/data/opened.iso" {
+				t.Errorf("%s contains %s, opened yesterday", name, f.Path)
+			}
+		}
+	}
+
+	// Without the option, access times don't matter
+	if got := New().Analyze(result); len(got.OldFiles) != 3 || got.RecentlyUsed != 0 {
+		t.Errorf("RecentAccess=0: %d old files, %d recently used, want 3 and 0", len(got.OldFiles), got.RecentlyUsed)
+	}
+}
+
+func TestFindSystemCaches(t *testing.T) {
+	root := t.TempDir()
+	small, big := filepath.Join(root, "small"), filepath.Join(root, "big")
+	for dir, size := range map[string]int{small: 10, big: 1000} {
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "blob"), make([]byte, size), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	unreadable := filepath.Join(root, "gone")
+
+	reports, denied := New().findSystemCaches([]string{small, unreadable, big})
+	if len(reports) != 2 || reports[0].Path != big || reports[1].Path != small {
+		t.Fatalf("findSystemCaches() = %+v, want big then small", reports)
+	}
+	if reports[0].Risk != "high" || reports[0].Size != 1000 {
+		t.Errorf("findSystemCaches()[0] = %+v, want 1000 bytes at high risk", reports[0])
+	}
+	if len(denied) != 1 || denied[0] != unreadable {
+		t.Errorf("denied = %q, want %q", denied, unreadable)
+	}
+}
@@ -0,0 +1,43 @@
+package analyzer
+
+import (
+	"sort"
+	"time"
+
+	"forge-dust/scanner"
+)
+
+const (
+	// LargeAppSize is when an app is big enough to be worth a second look
+	LargeAppSize = 1024 * 1024 * 1024
+	// UnusedAppAge is how long an app can go unopened before it's flagged
+	UnusedAppAge = 180 * 24 * time.Hour
+)
+
+// AppReport is an installed app worth reviewing, and why
+type AppReport struct {
+	scanner.App
+	Large  bool // At least LargeAppSize
+	Unused bool // Not opened in UnusedAppAge
+}
+
+// ReviewApps returns the apps that are large or long unused as of now,
+// largest first. An app with no last-opened date is judged on size alone,
+// since an unknown date says nothing about whether it's used.
+func ReviewApps(apps []scanner.App, now time.Time) []AppReport {
+	var reports []AppReport
+	for _, app := range apps {
+		r := AppReport{
+			App:    app,
+			Large:  app.Size >= LargeAppSize,
+			Unused: !app.LastUsed.IsZero() && now.Sub(app.LastUsed) >= UnusedAppAge,
+		}
+		if r.Large || r.Unused {
+			reports = append(reports, r)
+		}
+	}
+	sort.SliceStable(reports, func(i, j int) bool {
+		return reports[i].Size > reports[j].Size
+	})
+	return reports
+}
@@ -0,0 +1,39 @@
+package analyzer
+
+import (
+	"testing"
+	"time"
+
+	"forge-dust/scanner"
+)
+
+func TestReviewApps(t *testing.T) {
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	const gb = 1024 * 1024 * 1024
+	apps := []scanner.App{
+		{Path: "/Applications/Xcode.app", Size: 12 * gb, LastUsed: now.Add(-24 * time.Hour)},
+		{Path: "/Applications/OldGame.app", Size: 200 << 20, LastUsed: now.Add(-400 * 24 * time.Hour)},
+		{Path: "/Applications/Notes.app", Size: 50 << 20, LastUsed: now.Add(-2 * time.Hour)},
+		{Path: "/Applications/Mystery.app", Size: 300 << 20}, // unknown use, small: left alone
+		{Path: "/Applications/Unindexed.app", Size: 2 * gb},  // unknown use, large
+	}
+
+	got := ReviewApps(apps, now)
+	want := []struct {
+		name          string
+		large, unused bool
+	}{
+		{"Xcode", true, false},
+		{"Unindexed", true, false},
+		{"OldGame", false, true},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("ReviewApps() = %+v, want %d apps", got, len(want))
+	}
+	for i, w := range want {
+		if got[i].Name() != w.name || got[i].Large != w.large || got[i].Unused != w.unused {
+			t.Errorf("ReviewApps()[%d] = %s large=%v unused=%v, want %s large=%v unused=%v",
+				i, got[i].Name(), got[i].Large, got[i].Unused, w.name, w.large, w.unused)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package analyzer
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// partialHashSize is how much of each file hashFile reads
+const partialHashSize = 1024 * 1024
+
+// hashProgressInterval is how often OnHashProgress is called
+const hashProgressInterval = 100 * time.Millisecond
+
+// findDuplicates groups same-size files by a hash of their first megabyte,
+// then, with FullHash, splits those groups by a hash of everything. Files
+// are streamed through the hash, so memory stays flat however big they are,
+// and HashWorkers of them are hashed at once.
+func (a *Analyzer) findDuplicates(ctx context.Context, sizeMap map[int64][]string) []DuplicateGroup {
+	h := &hasher{ctx: ctx, workers: a.HashWorkers, progress: a.OnHashProgress}
+
+	// Every same-size candidate is quick-hashed in one pass, so the workers
+	// aren't held up by groups of two
+	var candidates []string
+	for _, files := range sizeMap {
+		if len(files) > 1 {
+			candidates = append(candidates, files...)
+		}
+	}
+	quick := h.hashAll(candidates, hashFile)
+
+	type match struct {
+		size  int64
+		paths []string
+	}
+	var matches []match
+	for size, files := range sizeMap {
+		if len(files) > 1 {
+			for _, paths := range groupByHash(files, quick) {
+				matches = append(matches, match{size, paths})
+			}
+		}
+	}
+
+	// Files past the first megabyte may still differ
+	sums := quick
+	if a.FullHash {
+		var confirm []string
+		for _, m := range matches {
+			if m.size > partialHashSize {
+				confirm = append(confirm, m.paths...)
+			}
+		}
+		full := h.hashAll(confirm, hashWholeFile)
+		sums = make(map[string]string, len(quick))
+		for path, sum := range quick {
+			sums[path] = sum
+		}
+		for _, path := range confirm {
+			if sum, ok := full[path]; ok {
+				sums[path] = sum
+			} else {
+				delete(sums, path) // Unreadable, or cut short: not confirmed
+			}
+		}
+	}
+	h.report(true)
+
+	var groups []DuplicateGroup
+	for _, m := range matches {
+		for _, paths := range groupByHash(m.paths, sums) {
+			groups = append(groups, DuplicateGroup{
+				Hash:  sums[paths[0]],
+				Size:  m.size,
+				Files: paths,
+			})
+		}
+	}
+
+	// Ties broken by path, so the same files always give the same order
+	sort.Slice(groups, func(i, j int) bool {
+		wi, wj := groups[i].Size*int64(len(groups[i].Files)), groups[j].Size*int64(len(groups[j].Files))
+		if wi != wj {
+			return wi > wj
+		}
+		return groups[i].Files[0] < groups[j].Files[0]
+	})
+
+	if a.MaxDuplicateGroups > 0 && len(groups) > a.MaxDuplicateGroups {
+		groups = groups[:a.MaxDuplicateGroups]
+	}
+
+	return groups
+}
+
+// groupByHash splits paths, in their order, into the groups of more than
+// one that share a hash in sums. Paths without a hash are left out.
+func groupByHash(paths []string, sums map[string]string) [][]string {
+	byHash := make(map[string][]string)
+	var order []string
+	for _, path := range paths {
+		sum, ok := sums[path]
+		if !ok {
+			continue
+		}
+		if _, seen := byHash[sum]; !seen {
+			order = append(order, sum)
+		}
+		byHash[sum] = append(byHash[sum], path)
+	}
+	var groups [][]string
+	for _, sum := range order {
+		if len(byHash[sum]) > 1 {
+			groups = append(groups, byHash[sum])
+		}
+	}
+	return groups
+}
+
+// hasher hashes files for findDuplicates, workers at a time, counting them
+// for progress
+type hasher struct {
+	ctx      context.Context
+	workers  int
+	progress func(hashed, total int)
+
+	// Only touched by the goroutine collecting results
+	hashed, total int
+	lastReport    time.Time
+}
+
+// hashAll hashes paths with hash, returning each readable file's hash. Once
+// ctx is done it stops handing out files, and the ones being hashed stop
+// partway, leaving them all out.
+func (h *hasher) hashAll(paths []string, hash func(context.Context, string) (string, error)) map[string]string {
+	type result struct {
+		path, sum string
+		err       error
+	}
+
+	h.total += len(paths)
+	jobs := make(chan string)
+	results := make(chan result)
+	var wg sync.WaitGroup
+	for w := 0; w < max(1, min(h.workers, len(paths))); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range jobs {
+				sum, err := hash(h.ctx, path)
+				results <- result{path, sum, err}
+			}
+		}()
+	}
+	go func() {
+		defer close(jobs)
+		for _, path := range paths {
+			if h.ctx.Err() != nil {
+				return // Checked first, as select picks at random when both are ready
+			}
+			select {
+			case jobs <- path:
+			case <-h.ctx.Done():
+				return
+			}
+		}
+	}()
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	sums := make(map[string]string, len(paths))
+	for r := range results {
+		if r.err == nil {
+			sums[r.path] = r.sum
+		}
+		h.hashed++
+		h.report(false)
+	}
+	return sums
+}
+
+// report passes the counts to the progress callback, at most every
+// hashProgressInterval unless final
+func (h *hasher) report(final bool) {
+	if h.progress == nil || (!final && time.Since(h.lastReport) < hashProgressInterval) {
+		return
+	}
+	h.lastReport = time.Now()
+	h.progress(h.hashed, h.total)
+}
+
+func hashFile(ctx context.Context, path string) (string, error) {
+	// Only hash first 1MB for speed
+	return hashReader(ctx, path, md5.New(), partialHashSize)
+}
+
+// hashWholeFile hashes all of path's contents
+func hashWholeFile(ctx context.Context, path string) (string, error) {
+	return hashReader(ctx, path, sha256.New(), -1)
+}
+
+// hashReader feeds up to limit bytes of path (all of it if negative) to h,
+// giving up with ctx's error once it's done
+func hashReader(ctx context.Context, path string, h hash.Hash, limit int64) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	r := ctxReader{ctx, file}
+	if limit < 0 {
+		_, err = io.Copy(h, r)
+	} else {
+		_, err = io.CopyN(h, r, limit)
+	}
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ctxReader reads from r until ctx is done, so a long copy can be stopped
+// between reads
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c ctxReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
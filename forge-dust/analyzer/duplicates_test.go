@@ -0,0 +1,107 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// makeDuplicates writes groups of files under dir: in each, copies of one
+// file plus, for files past a megabyte, a copy that differs only at the end.
+// It returns the files by size, as the analysis gathers them.
+func makeDuplicates(tb testing.TB, dir string, groups, copies int, size int) map[int64][]string {
+	tb.Helper()
+	sizeMap := make(map[int64][]string)
+	for g := 0; g < groups; g++ {
+		data := make([]byte, size+g)
+		copy(data, fmt.Sprintf("group %d", g))
+		for c := 0; c <= copies; c++ {
+			content := data
+			if c == copies {
+				content = append([]byte{}, data...)
+				content[len(content)-1] = 1 // Same first megabyte when large enough
+			}
+			path := filepath.Join(dir, fmt.Sprintf("g%d-c%d", g, c))
+			if err := os.WriteFile(path, content, 0644); err != nil {
+				tb.Fatal(err)
+			}
+			sizeMap[int64(len(content))] = append(sizeMap[int64(len(content))], path)
+		}
+	}
+	return sizeMap
+}
+
+func TestParallelDuplicatesMatchSerial(t *testing.T) {
+	sizeMap := makeDuplicates(t, t.TempDir(), 6, 3, partialHashSize+512)
+	for g, files := range makeDuplicates(t, t.TempDir(), 10, 2, 8*1024) {
+		sizeMap[g] = append(sizeMap[g], files...)
+	}
+
+	for _, fullHash := range []bool{false, true} {
+		find := func(workers int) []DuplicateGroup {
+			a := New()
+			a.FullHash = fullHash
+			a.MaxDuplicateGroups = 0
+			a.HashWorkers = workers
+			return a.findDuplicates(context.Background(), sizeMap)
+		}
+
+		serial := find(1)
+		// Large groups keep their odd copy out only when whole files are hashed
+		if want := 16; len(serial) != want {
+			t.Errorf("fullHash %v: %d groups serially, want %d", fullHash, len(serial), want)
+		}
+		for _, workers := range []int{2, 8, 32} {
+			if got := find(workers); !reflect.DeepEqual(got, serial) {
+				t.Errorf("fullHash %v: %d workers found\n%v\nwant the serial\n%v", fullHash, workers, got, serial)
+			}
+		}
+	}
+}
+
+func TestDuplicatesProgressAndCancel(t *testing.T) {
+	sizeMap := makeDuplicates(t, t.TempDir(), 5, 2, 4*1024)
+
+	var last [2]int
+	a := New()
+	a.HashWorkers = 4
+	a.OnHashProgress = func(hashed, total int) { last = [2]int{hashed, total} }
+	if groups := a.findDuplicates(context.Background(), sizeMap); len(groups) != 5 {
+		t.Errorf("findDuplicates() = %d groups, want 5", len(groups))
+	}
+	if want := [2]int{15, 15}; last != want {
+		t.Errorf("last progress = %v, want %v", last, want)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if groups := a.findDuplicates(ctx, sizeMap); len(groups) != 0 {
+		t.Errorf("findDuplicates() after cancel = %d groups, want none", len(groups))
+	}
+
+	// A file already being hashed stops partway too
+	for _, files := range sizeMap {
+		if _, err := hashWholeFile(ctx, files[0]); !errors.Is(err, context.Canceled) {
+			t.Errorf("hashWholeFile() after cancel error = %v, want canceled", err)
+		}
+		break
+	}
+}
+
+func BenchmarkFindDuplicates(b *testing.B) {
+	sizeMap := makeDuplicates(b, b.TempDir(), 50, 3, 2*partialHashSize)
+	for _, workers := range []int{1, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			a := New()
+			a.FullHash = true
+			a.HashWorkers = workers
+			for b.Loop() {
+				a.findDuplicates(context.Background(), sizeMap)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package analyzer
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// InstallerExts are the file types that only install something; once a newer
+// one is downloaded, the old one is dead weight
+var InstallerExts = map[string]bool{
+	".dmg":      true,
+	".pkg":      true,
+	".mpkg":     true,
+	".exe":      true,
+	".msi":      true,
+	".deb":      true,
+	".rpm":      true,
+	".appimage": true,
+}
+
+// InstallerGroup is several versions of one installer in Downloads
+type InstallerGroup struct {
+	Name  string       // Version-free name the installers share
+	Keep  FileReport   // Newest, which is left alone
+	Older []FileReport // Superseded versions, newest first
+	Size  int64        // What deleting the older versions frees
+}
+
+// IsInstaller reports whether path is an installer by its extension
+func IsInstaller(path string) bool {
+	return InstallerExts[strings.ToLower(filepath.Ext(path))]
+}
+
+// InstallerName returns a file name with its version stripped, so versions of
+// one installer compare equal: "App-1.2.dmg" and "app_1.3 (1).dmg" are both
+// "app.dmg". Words in the name are kept, so architectures and editions
+// ("arm64", "beta") stay apart. It returns "" if nothing but a version is left.
+func InstallerName(file string) string {
+	ext := strings.ToLower(filepath.Ext(file))
+	stem := strings.ToLower(strings.TrimSuffix(file, filepath.Ext(file)))
+
+	var words []string
+	for _, word := range strings.FieldsFunc(stem, isNameSeparator) {
+		if !isVersion(word) {
+			words = append(words, word)
+		}
+	}
+	if len(words) == 0 {
+		return ""
+	}
+	return strings.Join(words, "-") + ext
+}
+
+// isNameSeparator splits a file name into words and version numbers
+func isNameSeparator(r rune) bool {
+	switch r {
+	case '-', '_', '.', ' ', '(', ')', '[', ']':
+		return true
+	}
+	return false
+}
+
+// isVersion reports whether a word of a file name is a version number,
+// such as "1", "125" or "v3"
+func isVersion(word string) bool {
+	word = strings.TrimPrefix(word, "v")
+	if word == "" {
+		return false
+	}
+	for _, r := range word {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// groupInstallers finds the installers that share a name, keeping the most
+// recently modified of each and returning the groups that free the most first
+func groupInstallers(files []FileReport) []InstallerGroup {
+	byName := make(map[string][]FileReport)
+	var names []string
+	for _, f := range files {
+		name := InstallerName(filepath.Base(f.Path))
+		if name == "" {
+			continue
+		}
+		if _, ok := byName[name]; !ok {
+			names = append(names, name)
+		}
+		byName[name] = append(byName[name], f)
+	}
+
+	var groups []InstallerGroup
+	for _, name := range names {
+		versions := byName[name]
+		if len(versions) < 2 {
+			continue
+		}
+		sort.SliceStable(versions, func(i, j int) bool {
+			return versions[i].ModTime.After(versions[j].ModTime)
+		})
+		group := InstallerGroup{Name: name, Keep: versions[0], Older: versions[1:]}
+		for _, f := range group.Older {
+			group.Size += f.Size
+		}
+		groups = append(groups, group)
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].Size > groups[j].Size
+	})
+	return groups
+}
@@ -0,0 +1,63 @@
+package analyzer
+
+import (
+	"testing"
+	"time"
+
+	"forge-dust/scanner"
+)
+
+func TestInstallerName(t *testing.T) {
+	tests := []struct {
+		file string
+		want string
+	}{
+		{"App-1.2.dmg", "app.dmg"},
+		{"App-1.3.dmg", "app.dmg"},
+		{"app_1.3 (1).dmg", "app.dmg"},
+		{"Firefox 125.0.2.dmg", "firefox.dmg"},
+		{"Docker-v4.30.0-arm64.dmg", "docker-arm64.dmg"},
+		{"Docker-v4.30.0-amd64.dmg", "docker-amd64.dmg"},
+		{"Zoom-5.17.pkg", "zoom.pkg"},
+		{"Setup Tool 2.0 beta.exe", "setup-tool-beta.exe"},
+		{"1.2.3.dmg", ""},
+	}
+
+	for _, tt := range tests {
+		if got := InstallerName(tt.file); got != tt.want {
+			t.Errorf("InstallerName(%q) = %q, want %q", tt.file, got, tt.want)
+		}
+	}
+}
+
+func TestOldInstallersKeepTheNewest(t *testing.T) {
+	const mb = 1024 * 1024
+	now := time.Now()
+	a := New()
+	a.HomeDir = "/home/u"
+	a.DownloadsPath = "/home/u/Downloads"
+
+	result := &scanner.ScanResult{Files: []scanner.FileInfo{
+		{Path: "/home/u/Downloads/App-1.2.dmg", Size: 80 * mb, ModTime: now.Add(-60 * 24 * time.Hour)},
+		{Path: "/home/u/Downloads/App-1.3.dmg", Size: 90 * mb, ModTime: now.Add(-24 * time.Hour)},
+		{Path: "/home/u/Downloads/App-1.1.dmg", Size: 70 * mb, ModTime: now.Add(-120 * 24 * time.Hour)},
+		{Path: "/home/u/Downloads/Other-2.0.pkg", Size: 10 * mb, ModTime: now},
+		{Path: "/home/u/Downloads/App-1.0.zip", Size: 10 * mb, ModTime: now}, // not an installer
+		{Path: "/home/u/Projects/App-0.9.dmg", Size: 10 * mb, ModTime: now},  // not in Downloads
+	}}
+	analysis := a.Analyze(result)
+
+	if len(analysis.OldInstallers) != 1 {
+		t.Fatalf("OldInstallers = %+v, want one group", analysis.OldInstallers)
+	}
+	g := analysis.OldInstallers[0]
+	if g.Name != "app.dmg" || g.Keep.Path != "/home/u/Downloads/App-1.3.dmg" {
+		t.Errorf("group %q keeps %s, want app.dmg keeping App-1.3.dmg", g.Name, g.Keep.Path)
+	}
+	if len(g.Older) != 2 || g.Older[0].Path != "/home/u/Downloads/App-1.2.dmg" || g.Older[1].Path != "/home/u/Downloads/App-1.1.dmg" {
+		t.Errorf("Older = %+v, want App-1.2.dmg then App-1.1.dmg", g.Older)
+	}
+	if g.Size != 150*mb || analysis.TotalReclaimable != 150*mb {
+		t.Errorf("Size = %d, TotalReclaimable = %d, want %d", g.Size, analysis.TotalReclaimable, 150*mb)
+	}
+}
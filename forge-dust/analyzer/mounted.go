@@ -0,0 +1,97 @@
+package analyzer
+
+import (
+	"sort"
+
+	"forge-dust/scanner"
+)
+
+// MountedReport is the backing file of a disk image that's attached right
+// now. Deleting it under the mounted volume can corrupt that volume, so it's
+// kept out of every other finding until the image is detached.
+type MountedReport struct {
+	Path       string
+	Size       int64
+	MountPoint string // "" when attached but not mounted
+	Advice     string
+}
+
+// holdMounted takes the backing files of attached disk images out of
+// groups and lists, returning each once, largest first. A duplicate group
+// left with one file is dropped. Images are only listed if a disk image was
+// found, and a failed listing holds nothing back.
+func (a *Analyzer) holdMounted(groups *[]DuplicateGroup, lists ...*[]FileReport) []MountedReport {
+	if a.ListMounts == nil || !anyDiskImage(*groups, lists) {
+		return nil
+	}
+	images, err := a.ListMounts()
+	if err != nil || len(images) == 0 {
+		return nil
+	}
+
+	held := make(map[string]bool)
+	var reports []MountedReport
+	// hold reports whether path is a backing file, noting it the first time
+	hold := func(path string, size int64) bool {
+		img, ok := scanner.FindMounted(path, images)
+		if ok && !held[path] {
+			held[path] = true
+			reports = append(reports, MountedReport{
+				Path:       path,
+				Size:       size,
+				MountPoint: img.MountPoint,
+				Advice:     scanner.DetachAdvice(img),
+			})
+		}
+		return ok
+	}
+
+	for _, files := range lists {
+		kept := (*files)[:0]
+		for _, f := range *files {
+			if !hold(f.Path, f.Size) {
+				kept = append(kept, f)
+			}
+		}
+		*files = kept
+	}
+
+	keptGroups := (*groups)[:0]
+	for _, g := range *groups {
+		var files []string
+		for _, path := range g.Files {
+			if !hold(path, g.Size) {
+				files = append(files, path)
+			}
+		}
+		if len(files) > 1 {
+			g.Files = files
+			keptGroups = append(keptGroups, g)
+		}
+	}
+	*groups = keptGroups
+
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].Size > reports[j].Size
+	})
+	return reports
+}
+
+// anyDiskImage reports whether any of the files is a disk image
+func anyDiskImage(groups []DuplicateGroup, lists []*[]FileReport) bool {
+	for _, g := range groups {
+		for _, path := range g.Files {
+			if scanner.IsDiskImage(path) {
+				return true
+			}
+		}
+	}
+	for _, files := range lists {
+		for _, f := range *files {
+			if scanner.IsDiskImage(f.Path) {
+				return true
+			}
+		}
+	}
+	return false
+}
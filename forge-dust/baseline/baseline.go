@@ -0,0 +1,180 @@
+// Package baseline snapshots rolled-up directory sizes so a later scan can
+// show what grew in between
+package baseline
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"forge-dust/analyzer"
+	"forge-dust/scanner"
+	"forge-shared/datadir"
+)
+
+// Depth is how many levels below the root get their own entry; deeper
+// directories count towards their ancestor at this depth
+const Depth = 3
+
+// MinDirSize keeps the baseline compact: smaller directories are left out
+const MinDirSize = 1024 * 1024 // 1MB
+
+// Baseline is the disk state of one scan root at one point in time
+type Baseline struct {
+	Root       string           `json:"root"`
+	Created    time.Time        `json:"created"`
+	Total      int64            `json:"total"`
+	Dirs       map[string]int64 `json:"dirs"`       // Rolled-up size by path relative to Root
+	Categories map[string]int64 `json:"categories"` // Size of each finding category
+}
+
+// Growth is how much one directory or category changed
+type Growth struct {
+	Name   string
+	Before int64
+	After  int64
+}
+
+// Delta is the change in bytes; negative if it shrank
+func (g Growth) Delta() int64 {
+	return g.After - g.Before
+}
+
+// Comparison is what grew between a baseline and now, largest growth first
+type Comparison struct {
+	Since      time.Time
+	Before     int64
+	After      int64
+	Dirs       []Growth
+	Categories []Growth
+}
+
+// New builds a baseline of root from a scan and its analysis
+func New(root string, result *scanner.ScanResult, analysis *analyzer.Analysis) *Baseline {
+	return &Baseline{
+		Root:       root,
+		Created:    time.Now(),
+		Total:      result.TotalSize,
+		Dirs:       Rollup(result, root, Depth),
+		Categories: categorySizes(analysis),
+	}
+}
+
+// Rollup totals file sizes into every directory up to depth levels below
+// root, dropping those under MinDirSize. Paths are relative to root.
+func Rollup(result *scanner.ScanResult, root string, depth int) map[string]int64 {
+	sizes := make(map[string]int64)
+	for _, f := range result.Files {
+		if f.IsDir {
+			continue
+		}
+		rel, err := filepath.Rel(root, filepath.Dir(f.Path))
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+
+		// Credit the file to each ancestor down to depth; files directly in
+		// root only count towards Total
+		if rel == "." {
+			continue
+		}
+		parts := strings.Split(rel, string(filepath.Separator))
+		for i := 1; i <= len(parts) && i <= depth; i++ {
+			sizes[filepath.Join(parts[:i]...)] += f.Size
+		}
+	}
+
+	for dir, size := range sizes {
+		if size < MinDirSize {
+			delete(sizes, dir)
+		}
+	}
+	return sizes
+}
+
+func categorySizes(analysis *analyzer.Analysis) map[string]int64 {
+	sizes := make(map[string]int64)
+	for _, c := range analysis.CacheDirs {
+		sizes["Cache directories"] += c.Size
+	}
+	for _, c := range analysis.GlobalCaches {
+		sizes["Global caches"] += c.Size
+	}
+	for _, f := range analysis.LargeFiles {
+		sizes["Large files"] += f.Size
+	}
+	for _, f := range analysis.Downloads {
+		sizes["Downloads"] += f.Size
+	}
+	for _, f := range analysis.OldFiles {
+		sizes["Old files"] += f.Size
+	}
+	return sizes
+}
+
+// Compare lists the directories and categories that grew since old
+func Compare(old, now *Baseline) *Comparison {
+	return &Comparison{
+		Since:      old.Created,
+		Before:     old.Total,
+		After:      now.Total,
+		Dirs:       grown(old.Dirs, now.Dirs),
+		Categories: grown(old.Categories, now.Categories),
+	}
+}
+
+// grown returns the entries of now that are bigger than in old, growth first
+func grown(old, now map[string]int64) []Growth {
+	var growth []Growth
+	for name, after := range now {
+		if before := old[name]; after > before {
+			growth = append(growth, Growth{Name: name, Before: before, After: after})
+		}
+	}
+	sort.Slice(growth, func(i, j int) bool {
+		if growth[i].Delta() != growth[j].Delta() {
+			return growth[i].Delta() > growth[j].Delta()
+		}
+		return growth[i].Name < growth[j].Name
+	})
+	return growth
+}
+
+// Path returns where the baseline for root is kept, under baselines in the
+// forge data directory
+func Path(root string) string {
+	sum := sha256.Sum256([]byte(root))
+	name := filepath.Base(root) + "-" + hex.EncodeToString(sum[:6]) + ".json"
+	return datadir.Path("baselines", name)
+}
+
+// Load reads a baseline; unlike other forge state, a missing file is an
+// error, since there is nothing to compare against
+func Load(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	b := &Baseline{}
+	if err := json.Unmarshal(data, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Save writes the baseline to path
+func (b *Baseline) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(b)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
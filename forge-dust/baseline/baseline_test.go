@@ -0,0 +1,120 @@
+package baseline
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"forge-dust/analyzer"
+	"forge-dust/scanner"
+)
+
+const mb = 1024 * 1024
+
+func scanOf(root string, sizes map[string]int64) *scanner.ScanResult {
+	result := &scanner.ScanResult{}
+	for rel, size := range sizes {
+		result.Files = append(result.Files, scanner.FileInfo{Path: filepath.Join(root, rel), Size: size, ModTime: time.Now()})
+		result.TotalFiles++
+		result.TotalSize += size
+	}
+	return result
+}
+
+func TestRollup(t *testing.T) {
+	root := "/home/u"
+	result := scanOf(root, map[string]int64{
+		"top.bin":                   2 * mb,
+		"src/app/main.go":           3 * mb,
+		"src/app/deep/er/still.bin": 5 * mb, // counted into src/app/deep, not below
+		"notes/todo.txt":            1024,   // notes stays under MinDirSize
+		"../elsewhere/outside.bin":  50 * mb,
+	})
+	result.Files = append(result.Files, scanner.FileInfo{Path: filepath.Join(root, "src"), Size: 4096, IsDir: true})
+
+	got := Rollup(result, root, 3)
+	want := map[string]int64{
+		"src":          8 * mb,
+		"src/app":      8 * mb,
+		"src/app/deep": 5 * mb,
+	}
+	if len(got) != len(want) {
+		t.Errorf("Rollup() = %v, want %v", got, want)
+	}
+	for dir, size := range want {
+		if got[dir] != size {
+			t.Errorf("Rollup()[%q] = %d, want %d", dir, got[dir], size)
+		}
+	}
+}
+
+func TestSaveAndCompare(t *testing.T) {
+	root := "/home/u"
+	before := scanOf(root, map[string]int64{
+		"Downloads/a.dmg":        100 * mb,
+		"src/app/node_modules/x": 20 * mb,
+		"Music/song.mp3":         10 * mb,
+	})
+	beforeAnalysis := &analyzer.Analysis{
+		Downloads: []analyzer.FileReport{{Path: "/home/u/Downloads/a.dmg", Size: 100 * mb}},
+		CacheDirs: []analyzer.CacheReport{{Path: "/home/u/src/app/node_modules", Size: 20 * mb}},
+	}
+
+	path := filepath.Join(t.TempDir(), "baselines", "u.json")
+	if err := New(root, before, beforeAnalysis).Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	old, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	after := scanOf(root, map[string]int64{
+		"Downloads/a.dmg":        100 * mb,
+		"Downloads/b.iso":        700 * mb,
+		"src/app/node_modules/x": 50 * mb,
+		"Music/song.mp3":         5 * mb, // shrank, so not listed
+	})
+	afterAnalysis := &analyzer.Analysis{
+		Downloads: []analyzer.FileReport{{Size: 100 * mb}, {Size: 700 * mb}},
+		CacheDirs: []analyzer.CacheReport{{Size: 50 * mb}},
+	}
+	cmp := Compare(old, New(root, after, afterAnalysis))
+
+	if cmp.Before != 130*mb || cmp.After != 855*mb {
+		t.Errorf("totals = %d -> %d, want %d -> %d", cmp.Before, cmp.After, 130*mb, 855*mb)
+	}
+	wantDirs := []Growth{
+		{"Downloads", 100 * mb, 800 * mb},
+		{"src", 20 * mb, 50 * mb},
+		{"src/app", 20 * mb, 50 * mb},
+		{"src/app/node_modules", 20 * mb, 50 * mb},
+	}
+	if len(cmp.Dirs) != len(wantDirs) {
+		t.Fatalf("Dirs = %+v, want %+v", cmp.Dirs, wantDirs)
+	}
+	for i, g := range cmp.Dirs {
+		if g != wantDirs[i] {
+			t.Errorf("Dirs[%d] = %+v, want %+v", i, g, wantDirs[i])
+		}
+	}
+
+	wantCats := []Growth{{"Downloads", 100 * mb, 800 * mb}, {"Cache directories", 20 * mb, 50 * mb}}
+	if len(cmp.Categories) != len(wantCats) {
+		t.Fatalf("Categories = %+v, want %+v", cmp.Categories, wantCats)
+	}
+	for i, g := range cmp.Categories {
+		if g != wantCats[i] {
+			t.Errorf("Categories[%d] = %+v, want %+v", i, g, wantCats[i])
+		}
+	}
+	if d := cmp.Dirs[0].Delta(); d != 700*mb {
+		t.Errorf("Downloads Delta() = %d, want %d", d, 700*mb)
+	}
+}
+
+func TestLoadMissingBaseline(t *testing.T) {
+	if _, err := Load(filepath.Join(t.TempDir(), "none.json")); err == nil {
+		t.Error("Load(missing) error = nil, want an error")
+	}
+}
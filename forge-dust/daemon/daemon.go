@@ -0,0 +1,246 @@
+package daemon
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"forge-dust/scanner"
+)
+
+// Event reports that the entries directly inside Dir changed
+type Event struct {
+	Dir string
+}
+
+// Daemon holds a scan of Root and keeps it current as Events arrive
+type Daemon struct {
+	Root    string
+	Options Options // What the scan was made with; only queries made with the same get it
+
+	mu      sync.RWMutex
+	result  *scanner.ScanResult
+	updated time.Time
+}
+
+// New scans root and returns a daemon holding the result
+func New(root string) (*Daemon, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	s := scanner.New(root)
+	result, err := s.Scan()
+	if err != nil {
+		return nil, err
+	}
+	return &Daemon{Root: root, Options: OptionsOf(s), result: result, updated: time.Now()}, nil
+}
+
+// Apply re-reads ev.Dir's direct entries into the scan: files are restatted,
+// new subdirectories scanned and vanished ones dropped with their contents.
+// Subdirectories that are still there keep what they had.
+func (d *Daemon) Apply(ev Event) error {
+	dir := filepath.Clean(ev.Dir)
+	if !within(dir, d.Root) {
+		return nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	present := make(map[string]os.DirEntry, len(entries))
+	for _, e := range entries {
+		present[filepath.Join(dir, e.Name())] = e
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	known := make(map[string]bool)
+	var kept []scanner.FileInfo
+	for _, f := range d.result.Files {
+		if os.IsNotExist(err) && within(f.Path, dir) {
+			continue // The directory itself is gone
+		}
+		if f.Path == dir {
+			if info, err := os.Stat(dir); err == nil {
+				f.ModTime = info.ModTime()
+			}
+		} else if within(f.Path, dir) {
+			child := childOf(f.Path, dir)
+			e, ok := present[child]
+			if !ok || (f.Path != child && !e.IsDir()) {
+				continue // Removed or no longer a directory, along with everything under it
+			}
+			if f.Path == child {
+				known[child] = f.IsDir && e.IsDir()
+				continue // Re-added below with fresh info
+			}
+		}
+		kept = append(kept, f)
+	}
+
+	for path, e := range present {
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		if e.IsDir() && !known[path] {
+			sub, err := scanner.New(path).Scan()
+			if err == nil {
+				kept = append(kept, sub.Files...)
+			}
+			continue
+		}
+		kept = append(kept, scanner.NewFileInfo(path, info))
+	}
+
+	d.result = withTotals(kept, d.result)
+	d.updated = time.Now()
+	return nil
+}
+
+// Snapshot returns the part of the scan under path and when it last changed
+func (d *Daemon) Snapshot(path string) (*scanner.ScanResult, time.Time, error) {
+	path = filepath.Clean(path)
+	if !within(path, d.Root) {
+		return nil, time.Time{}, fmt.Errorf("%s is outside %s", path, d.Root)
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if path == d.Root {
+		return d.result, d.updated, nil
+	}
+	var files []scanner.FileInfo
+	for _, f := range d.result.Files {
+		if within(f.Path, path) {
+			files = append(files, f)
+		}
+	}
+	return withTotals(files, d.result), d.updated, nil
+}
+
+// Serve answers requests on l until it is closed
+func (d *Daemon) Serve(l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go d.handle(conn)
+	}
+}
+
+func (d *Daemon) handle(conn net.Conn) {
+	defer conn.Close()
+
+	var req Request
+	if err := Decode(bufio.NewReader(conn), &req); err != nil {
+		Encode(conn, Response{Error: "bad request: " + err.Error()})
+		return
+	}
+
+	resp := Response{Root: d.Root}
+	switch req.Op {
+	case OpPing:
+	case OpScan:
+		if req.Options != d.Options {
+			resp.Error = fmt.Sprintf("scan made with %+v, not %+v", d.Options, req.Options)
+			break
+		}
+		result, updated, err := d.Snapshot(req.Path)
+		if err != nil {
+			resp.Error = err.Error()
+		} else {
+			resp.Result, resp.Updated = result, updated
+		}
+	default:
+		resp.Error = fmt.Sprintf("unknown op %q", req.Op)
+	}
+	Encode(conn, resp)
+}
+
+// Ping reports whether a daemon is answering on socket
+func Ping(socket string) error {
+	conn, err := net.DialTimeout("unix", socket, 200*time.Millisecond)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if err := Encode(conn, Request{Op: OpPing}); err != nil {
+		return err
+	}
+	var resp Response
+	return Decode(bufio.NewReader(conn), &resp)
+}
+
+// Query asks the daemon at socket for its scan of path, made with opts. Any
+// error, including no daemon running or one whose scan was made with other
+// options, means the caller should scan for itself.
+func Query(socket, path string, opts Options) (*scanner.ScanResult, time.Time, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	conn, err := net.DialTimeout("unix", socket, 200*time.Millisecond)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	defer conn.Close()
+
+	if err := Encode(conn, Request{Op: OpScan, Path: path, Options: opts}); err != nil {
+		return nil, time.Time{}, err
+	}
+	var resp Response
+	if err := Decode(bufio.NewReader(conn), &resp); err != nil {
+		return nil, time.Time{}, err
+	}
+	if resp.Error != "" {
+		return nil, time.Time{}, errors.New(resp.Error)
+	}
+	if resp.Result == nil {
+		return nil, time.Time{}, errors.New("daemon returned no scan")
+	}
+	return resp.Result, resp.Updated, nil
+}
+
+// withTotals builds a result from files, recounting its totals
+func withTotals(files []scanner.FileInfo, from *scanner.ScanResult) *scanner.ScanResult {
+	r := &scanner.ScanResult{Files: files, ScanTime: from.ScanTime, Errors: from.Errors, Denied: from.Denied}
+	for _, f := range files {
+		if f.IsDir {
+			r.TotalDirs++
+		} else {
+			r.TotalFiles++
+			r.TotalSize += f.Size
+		}
+	}
+	return r
+}
+
+// within reports whether path is dir or inside it
+func within(path, dir string) bool {
+	return path == dir || strings.HasPrefix(path, strings.TrimSuffix(dir, string(filepath.Separator))+string(filepath.Separator))
+}
+
+// childOf returns the entry directly inside dir that path is, or is under
+func childOf(path, dir string) string {
+	rel, _ := filepath.Rel(dir, path)
+	first, _, _ := strings.Cut(rel, string(filepath.Separator))
+	return filepath.Join(dir, first)
+}
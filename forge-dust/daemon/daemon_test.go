@@ -0,0 +1,148 @@
+package daemon
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, path string, size int) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func hasPath(d *Daemon, path string) bool {
+	result, _, _ := d.Snapshot(d.Root)
+	for _, f := range result.Files {
+		if f.Path == path {
+			return true
+		}
+	}
+	return false
+}
+
+func TestApplyUpdatesCacheOnEvent(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "src", "main.go"), 100)
+	writeFile(t, filepath.Join(root, "old", "build", "out.o"), 300)
+
+	d, err := New(root)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	before, _, _ := d.Snapshot(root)
+
+	// Simulate the watcher: a file appears in src, old/ is deleted, a new tree appears
+	added := filepath.Join(root, "src", "big.bin")
+	writeFile(t, added, 5000)
+	if err := os.RemoveAll(filepath.Join(root, "old")); err != nil {
+		t.Fatal(err)
+	}
+	newTree := filepath.Join(root, "fresh", "deep", "data.db")
+	writeFile(t, newTree, 700)
+
+	events := make(chan Event)
+	done := make(chan struct{})
+	go func() {
+		d.Run(events)
+		close(done)
+	}()
+	events <- Event{Dir: filepath.Join(root, "src")}
+	events <- Event{Dir: root}
+	close(events)
+	<-done
+
+	if !hasPath(d, added) {
+		t.Errorf("scan is missing %s after its directory changed", added)
+	}
+	if !hasPath(d, newTree) {
+		t.Errorf("scan is missing %s from a new directory", newTree)
+	}
+	for _, gone := range []string{filepath.Join(root, "old"), filepath.Join(root, "old", "build", "out.o")} {
+		if hasPath(d, gone) {
+			t.Errorf("scan still has %s after it was removed", gone)
+		}
+	}
+	if !hasPath(d, filepath.Join(root, "src", "main.go")) {
+		t.Error("scan lost src/main.go, which did not change")
+	}
+
+	after, _, _ := d.Snapshot(root)
+	if want := before.TotalSize - 300 + 5000 + 700; after.TotalSize != want {
+		t.Errorf("TotalSize = %d, want %d", after.TotalSize, want)
+	}
+	if after.TotalFiles != 3 {
+		t.Errorf("TotalFiles = %d, want 3", after.TotalFiles)
+	}
+}
+
+func TestQueryOverSocket(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "a", "one.bin"), 10)
+	writeFile(t, filepath.Join(root, "b", "two.bin"), 20)
+
+	d, err := New(root)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	socket := filepath.Join(t.TempDir(), "dust.sock")
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	go d.Serve(l)
+	defer l.Close()
+
+	if err := Ping(socket); err != nil {
+		t.Fatalf("Ping() error = %v", err)
+	}
+
+	result, _, err := Query(socket, filepath.Join(root, "b"), d.Options)
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if result.TotalFiles != 1 || result.TotalSize != 20 {
+		t.Errorf("Query(b) = %d files, %d bytes; want 1 file, 20 bytes", result.TotalFiles, result.TotalSize)
+	}
+
+	if _, _, err := Query(socket, t.TempDir(), d.Options); err == nil {
+		t.Error("Query(outside root) error = nil, want an error")
+	}
+	if _, _, err := Query(filepath.Join(t.TempDir(), "none.sock"), root, d.Options); err == nil {
+		t.Error("Query(no daemon) error = nil, want an error")
+	}
+	quick := d.Options
+	quick.SkipHidden, quick.MaxDepth = true, 5
+	if _, _, err := Query(socket, root, quick); err == nil {
+		t.Error("Query(quick options) error = nil, want the full scan refused")
+	}
+}
+
+func TestChangedDirs(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "logs", "app.log"), 100)
+	writeFile(t, filepath.Join(root, "src", "main.go"), 100)
+	writeFile(t, filepath.Join(root, "old", "a.bin"), 100)
+	before := stamps(root)
+
+	// Rewritten in place, which leaves its directory's modtime alone
+	later := time.Now().Add(time.Hour)
+	writeFile(t, filepath.Join(root, "logs", "app.log"), 5000)
+	os.Chtimes(filepath.Join(root, "logs", "app.log"), later, later)
+	// A new file, which moves only its directory's modtime
+	writeFile(t, filepath.Join(root, "src", "new.go"), 10)
+	os.Chtimes(filepath.Join(root, "src"), later, later)
+
+	want := []string{filepath.Join(root, "logs"), filepath.Join(root, "src")}
+	if got := changedDirs(before, stamps(root)); !slices.Equal(got, want) {
+		t.Errorf("changedDirs() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,68 @@
+// Package daemon keeps a warm scan of a directory tree and answers scan
+// queries over a unix socket, so forge-dust can skip walking the disk
+package daemon
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"time"
+
+	"forge-dust/scanner"
+	"forge-shared/datadir"
+)
+
+// Ops understood by the daemon
+const (
+	OpPing = "ping" // Is anyone there?
+	OpScan = "scan" // Return the warm scan under Path
+)
+
+// Request is one query, sent as a single line of JSON
+type Request struct {
+	Op      string  `json:"op"`
+	Path    string  `json:"path,omitempty"`
+	Options Options `json:"options"` // What the asker would scan with
+}
+
+// Options are the settings a scan is made with. The daemon's scan only
+// answers a query with the same ones: a quick scan, say, skips hidden
+// directories and stops partway down, so it's a different tree.
+type Options struct {
+	MinSize     int64 `json:"min_size,omitempty"`
+	MaxDepth    int   `json:"max_depth"`
+	SkipHidden  bool  `json:"skip_hidden,omitempty"`
+	FollowLinks bool  `json:"follow_links,omitempty"`
+}
+
+// OptionsOf returns the options s scans with
+func OptionsOf(s *scanner.Scanner) Options {
+	return Options{MinSize: s.MinSize, MaxDepth: s.MaxDepth, SkipHidden: s.SkipHidden, FollowLinks: s.FollowLinks}
+}
+
+// Response answers a Request, as a single line of JSON
+type Response struct {
+	Error   string              `json:"error,omitempty"`
+	Root    string              `json:"root,omitempty"`   // What the daemon watches
+	Updated time.Time           `json:"updated,omitzero"` // When the scan last changed
+	Result  *scanner.ScanResult `json:"result,omitempty"`
+}
+
+// SocketPath returns dust.sock in the forge data directory
+func SocketPath() string {
+	return datadir.Path("dust.sock")
+}
+
+// Encode writes v as one line of JSON
+func Encode(w io.Writer, v any) error {
+	return json.NewEncoder(w).Encode(v)
+}
+
+// Decode reads one line of JSON into v
+func Decode(r *bufio.Reader, v any) error {
+	line, err := r.ReadBytes('\n')
+	if err != nil && (err != io.EOF || len(line) == 0) {
+		return err
+	}
+	return json.Unmarshal(line, v)
+}
@@ -0,0 +1,54 @@
+package daemon
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+	"time"
+
+	"forge-dust/scanner"
+)
+
+func TestProtocolRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	req := Request{Op: OpScan, Path: "/Users/me/src"}
+	updated := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	resp := Response{
+		Root:    "/Users/me",
+		Updated: updated,
+		Result: &scanner.ScanResult{
+			Files:      []scanner.FileInfo{{Path: "/Users/me/src/a.bin", Size: 42}},
+			TotalFiles: 1,
+			TotalSize:  42,
+		},
+	}
+
+	// Both messages on one stream: each is exactly one line
+	if err := Encode(&buf, req); err != nil {
+		t.Fatalf("Encode(request) error = %v", err)
+	}
+	if err := Encode(&buf, resp); err != nil {
+		t.Fatalf("Encode(response) error = %v", err)
+	}
+	if n := bytes.Count(buf.Bytes(), []byte("\n")); n != 2 {
+		t.Fatalf("encoded %d lines, want 2:\n%s", n, buf.String())
+	}
+
+	r := bufio.NewReader(&buf)
+	var gotReq Request
+	if err := Decode(r, &gotReq); err != nil || gotReq != req {
+		t.Errorf("Decode(request) = %+v, %v; want %+v", gotReq, err, req)
+	}
+	var gotResp Response
+	if err := Decode(r, &gotResp); err != nil {
+		t.Fatalf("Decode(response) error = %v", err)
+	}
+	if gotResp.Root != resp.Root || !gotResp.Updated.Equal(updated) || gotResp.Result == nil ||
+		len(gotResp.Result.Files) != 1 || gotResp.Result.Files[0].Size != 42 {
+		t.Errorf("Decode(response) = %+v, want %+v", gotResp, resp)
+	}
+
+	if err := Decode(r, &gotReq); err == nil {
+		t.Error("Decode() past the end = nil error, want EOF")
+	}
+}
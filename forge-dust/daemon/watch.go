@@ -0,0 +1,101 @@
+package daemon
+
+import (
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// PollInterval is how often Poll looks for changed directories
+const PollInterval = 2 * time.Minute
+
+// Poll sends an Event for each directory under root whose contents changed
+// since the last look, until stop is closed; then it closes events. A
+// directory's modtime moves whenever entries are added, removed or renamed,
+// and a file's size or modtime when it's rewritten in place, so comparing
+// both catches what makes a scan stale without a platform file-event API.
+func Poll(root string, interval time.Duration, events chan<- Event, stop <-chan struct{}) {
+	defer close(events)
+
+	seen := stamps(root)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		now := stamps(root)
+		changed := changedDirs(seen, now)
+		seen = now
+
+		for _, dir := range changed {
+			select {
+			case events <- Event{Dir: dir}:
+			case <-stop:
+				return
+			}
+		}
+	}
+}
+
+// Run applies events to the scan until events is closed
+func (d *Daemon) Run(events <-chan Event) {
+	for ev := range events {
+		d.Apply(ev) // A directory that can't be read stays as it was
+	}
+}
+
+// stamp is what Poll compares of an entry between looks
+type stamp struct {
+	dir     bool
+	size    int64
+	modTime time.Time
+}
+
+func (s stamp) equal(o stamp) bool {
+	return s.dir == o.dir && s.size == o.size && s.modTime.Equal(o.modTime)
+}
+
+// stamps returns the stamp of every entry under root
+func stamps(root string) map[string]stamp {
+	all := make(map[string]stamp)
+	filepath.WalkDir(root, func(path string, e fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info, err := e.Info(); err == nil {
+			all[path] = stamp{dir: e.IsDir(), size: info.Size(), modTime: info.ModTime()}
+		}
+		return nil
+	})
+	return all
+}
+
+// changedDirs returns, sorted, the directories whose entries differ between
+// two looks: a directory whose own stamp moved, and the directory holding
+// each file that changed. New and vanished entries move their directory's
+// modtime, so they're caught by that.
+func changedDirs(before, after map[string]stamp) []string {
+	dirs := make(map[string]bool)
+	for path, now := range after {
+		prev, ok := before[path]
+		switch {
+		case !ok || prev.equal(now):
+		case now.dir && prev.dir:
+			dirs[path] = true
+		default:
+			dirs[filepath.Dir(path)] = true
+		}
+	}
+	changed := make([]string, 0, len(dirs))
+	for dir := range dirs {
+		changed = append(changed, dir)
+	}
+	sort.Strings(changed)
+	return changed
+}
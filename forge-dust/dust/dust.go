@@ -0,0 +1,167 @@
+// Package dust runs forge-dust's scan and analysis as a library: no
+// printing, no exiting and no LLM, just the findings.
+//
+// Run does everything in one call. Scan and NewAnalyzer are the two halves,
+// for callers that want the raw scan as well, or to reuse one scan with
+// different analysis settings.
+package dust
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"forge-dust/analyzer"
+	"forge-dust/scanner"
+	"forge-dust/timing"
+)
+
+// QuickMaxDepth is how deep a quick scan goes below the scanned directory
+const QuickMaxDepth = 5
+
+// AggressiveMinDuplicate is the AggressiveDuplicates floor: smaller files
+// mostly share a single disk block, so removing a copy frees next to nothing
+const AggressiveMinDuplicate = 4 * 1024
+
+// Options are the forge-dust flags that shape a scan and its analysis. The
+// zero value scans home with the CLI's defaults.
+type Options struct {
+	Path    string // Directory to scan; home if empty
+	Home    string // Whose Downloads and Trash the findings use; derived from Path if empty
+	Quick   bool   // Skip hidden directories and stop QuickMaxDepth levels down
+	Workers int    // Directories sized in parallel; 0 picks from the disk type
+
+	MinLargeFile         int64 // Bytes for a file to count as large; 0 means 100MB
+	MinCacheSize         int64 // Bytes a cache directory must exceed to be reported; 0 means 1MB
+	Duplicates           bool  // Look for duplicate files (slow)
+	AggressiveDuplicates bool  // Every duplicate over 4KB, confirmed by hashing whole files (slower)
+	SizeBandMin          int64 // Also report files of at least this size...
+	SizeBandMax          int64 // ...and under this one; 0 leaves the band off
+	KeepRecent           int   // Newest files left out of large, old and download findings
+	ExcludeRecentAccess  bool  // Leave out large, old and download files opened in the last RecentAccessWindow
+	GitAware             bool  // Report large files that git repositories track
+	System               bool  // Also size the system caches outside home, which may need sudo
+	PeekArchives         bool  // List the top-level contents of large zip and tar.gz files
+
+	// OnProgress is called about every 100ms while the disk is walked, on
+	// the walking goroutine, so it should return quickly
+	OnProgress scanner.ProgressFunc
+
+	// OnHashProgress is called about every 100ms while files are hashed
+	// for duplicates, with how many are done out of how many are known to
+	// need it so far
+	OnHashProgress func(hashed, total int)
+
+	// OnScanned is called once the walk is done, before the analysis starts,
+	// with what was found; Errors lists what couldn't be read
+	OnScanned func(*scanner.ScanResult)
+
+	// Timings, if set, records how long the scan and the analysis's slow
+	// steps take
+	Timings *timing.Timer
+}
+
+// Run scans opts.Path and analyzes what it finds. Unreadable files and
+// directories don't fail the run; OnScanned sees them in the result's Errors.
+func Run(opts Options) (*analyzer.Analysis, error) {
+	return RunContext(context.Background(), opts)
+}
+
+// RunContext is Run, with ctx able to cut the duplicate search short, as
+// analyzer.AnalyzeContext does
+func RunContext(ctx context.Context, opts Options) (*analyzer.Analysis, error) {
+	result, err := Scan(opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.OnScanned != nil {
+		opts.OnScanned(result)
+	}
+	return NewAnalyzer(opts).AnalyzeContext(ctx, result), nil
+}
+
+// Scan walks opts.Path, reporting progress to opts.OnProgress
+func Scan(opts Options) (*scanner.ScanResult, error) {
+	path, err := opts.root()
+	if err != nil {
+		return nil, err
+	}
+
+	s := NewScanner(path, opts)
+	s.OnProgress = opts.OnProgress
+	s.Timings = opts.Timings
+	return s.Scan()
+}
+
+// NewScanner returns a scanner of path set up as opts asks
+func NewScanner(path string, opts Options) *scanner.Scanner {
+	s := scanner.New(path)
+	if opts.Quick {
+		s.SkipHidden = true
+		s.MaxDepth = QuickMaxDepth
+	}
+	return s
+}
+
+// NewAnalyzer returns an analyzer configured from opts
+func NewAnalyzer(opts Options) *analyzer.Analyzer {
+	a := analyzer.New()
+	if opts.MinLargeFile > 0 {
+		a.MinLargeFile = opts.MinLargeFile
+	}
+	if opts.MinCacheSize > 0 {
+		a.MinCacheSize = opts.MinCacheSize
+	}
+	a.CheckDuplicates = opts.Duplicates
+	if opts.AggressiveDuplicates {
+		a.CheckDuplicates = true
+		a.MinDuplicateSize = AggressiveMinDuplicate
+		a.MaxDuplicateGroups = 0
+		a.FullHash = true
+	}
+	a.HomeDir = opts.HomeDir()
+	a.DownloadsPath = filepath.Join(a.HomeDir, "Downloads")
+	a.SizeWorkers = opts.Workers
+	if a.SizeWorkers <= 0 {
+		if path, err := opts.root(); err == nil {
+			a.SizeWorkers = scanner.WorkersFor(scanner.DetectMedia(path), runtime.NumCPU())
+		}
+	}
+	// Hashing is as hard on the disk as sizing
+	a.HashWorkers = a.SizeWorkers
+	a.OnHashProgress = opts.OnHashProgress
+	a.SizeBandMin = opts.SizeBandMin
+	a.SizeBandMax = opts.SizeBandMax
+	a.KeepRecent = opts.KeepRecent
+	if opts.ExcludeRecentAccess {
+		a.RecentAccess = analyzer.RecentAccessWindow
+	}
+	a.GitAware = opts.GitAware
+	a.System = opts.System
+	a.PeekArchives = opts.PeekArchives
+	a.Timings = opts.Timings
+	return a
+}
+
+// HomeDir is the home directory the scan's findings are relative to:
+// opts.Home if set, otherwise the one scanner.HomeFor picks for the path
+func (opts Options) HomeDir() string {
+	if opts.Home != "" {
+		return opts.Home
+	}
+	invoker, _ := os.UserHomeDir()
+	root, err := opts.root()
+	if err != nil {
+		return invoker
+	}
+	return scanner.HomeFor(root, invoker)
+}
+
+// root is the directory to scan
+func (opts Options) root() (string, error) {
+	if opts.Path != "" {
+		return opts.Path, nil
+	}
+	return os.UserHomeDir()
+}
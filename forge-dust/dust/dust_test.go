@@ -0,0 +1,75 @@
+package dust_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"forge-dust/dust"
+)
+
+func TestDownloadsUseGivenHome(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	target := t.TempDir()
+	download := filepath.Join(target, "Downloads", "movie.mkv")
+	if err := os.MkdirAll(filepath.Dir(download), 0755); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Create(download)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Truncate(60 << 20); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	// The scan root is outside the process's home, so without Home its
+	// Downloads would go unnoticed
+	analysis, err := dust.Run(dust.Options{Path: target})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(analysis.Downloads) != 0 {
+		t.Errorf("Run() without Home found %d downloads, want 0", len(analysis.Downloads))
+	}
+
+	analysis, err = dust.Run(dust.Options{Path: target, Home: target})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(analysis.Downloads) != 1 || analysis.Downloads[0].Path != download {
+		t.Errorf("Run() with Home = %s found downloads %v, want %s", target, analysis.Downloads, download)
+	}
+}
+
+func TestRunContextStopsDuplicateSearch(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	target := t.TempDir()
+	data := make([]byte, 2<<20)
+	for _, name := range []string{"a.bin", "b.bin"} {
+		if err := os.WriteFile(filepath.Join(target, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	opts := dust.Options{Path: target, Duplicates: true}
+	analysis, err := dust.Run(opts)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(analysis.DuplicateGroups) != 1 {
+		t.Errorf("Run() found %d duplicate groups, want 1", len(analysis.DuplicateGroups))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	analysis, err = dust.RunContext(ctx, opts)
+	if err != nil {
+		t.Fatalf("RunContext() error = %v", err)
+	}
+	if len(analysis.DuplicateGroups) != 0 {
+		t.Errorf("RunContext() after cancel found %d duplicate groups, want none", len(analysis.DuplicateGroups))
+	}
+}
@@ -0,0 +1,47 @@
+package dust_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"forge-dust/dust"
+	"forge-dust/scanner"
+)
+
+func ExampleRun() {
+	dir, err := os.MkdirTemp("", "dust-example")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	if err := os.WriteFile(filepath.Join(dir, "disk.img"), make([]byte, 2<<20), 0644); err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	analysis, err := dust.Run(dust.Options{
+		Path:         dir,
+		MinLargeFile: 1 << 20,
+		OnProgress: func(p scanner.Progress) {
+			// Update a progress bar with p.FilesScanned and p.BytesScanned
+		},
+		OnScanned: func(result *scanner.ScanResult) {
+			fmt.Printf("%d unreadable\n", len(result.Errors))
+		},
+	})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	for _, f := range analysis.LargeFiles {
+		fmt.Printf("%s: %d bytes\n", filepath.Base(f.Path), f.Size)
+	}
+	fmt.Println("reclaimable:", analysis.TotalReclaimable)
+	// Output:
+	// 0 unreadable
+	// disk.img: 2097152 bytes
+	// reclaimable: 2097152
+}
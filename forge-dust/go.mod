@@ -1,3 +1,7 @@
 module forge-dust
 
 go 1.25.5
+
+require forge-shared v0.0.0
+
+replace forge-shared => ../forge-shared
@@ -6,16 +6,23 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"forge-dust/analyzer"
+	"forge-dust/timing"
 )
 
 type OllamaClient struct {
 	BaseURL string
 	Model   string
 	Timeout time.Duration
+	Timings *timing.Timer // Records each request as "llm: <model>"; nil records nothing
+
+	// PromptFile is a text/template to use instead of the built-in prompt,
+	// rendered with PromptData; if empty, PromptPath is used when it exists
+	PromptFile string
 }
 
 type generateRequest struct {
@@ -38,7 +45,10 @@ func NewClient(model string) *OllamaClient {
 }
 
 func (c *OllamaClient) GetRecommendations(analysis *analyzer.Analysis) (string, error) {
-	prompt := buildPrompt(analysis)
+	prompt, err := c.prompt(analysis)
+	if err != nil {
+		return "", err
+	}
 
 	reqBody := generateRequest{
 		Model:  c.Model,
@@ -51,6 +61,7 @@ func (c *OllamaClient) GetRecommendations(analysis *analyzer.Analysis) (string,
 		return "", fmt.Errorf("failed to marshal request: %w", err)
 	}
 
+	defer c.Timings.Start("llm: " + c.Model)()
 	client := &http.Client{Timeout: c.Timeout}
 	resp, err := client.Post(c.BaseURL+"/api/generate", "application/json", bytes.NewBuffer(jsonBody))
 	if err != nil {
@@ -103,7 +114,31 @@ func buildPrompt(analysis *analyzer.Analysis) string {
 	if len(analysis.CacheDirs) > 0 {
 		sb.WriteString("### Cache Directories Found\n")
 		for _, cache := range analysis.CacheDirs {
-			sb.WriteString(fmt.Sprintf("- `%s` (%s) - %s\n", cache.Path, formatSize(cache.Size), cache.Description))
+			sb.WriteString(fmt.Sprintf("- `%s` (%s) - %s", cache.Path, formatSize(cache.Size), cache.Description))
+			if cache.Rebuild != "" {
+				sb.WriteString(fmt.Sprintf("; rebuilt by %s", cache.Rebuild))
+			}
+			sb.WriteString("\n")
+		}
+		sb.WriteString("\n")
+	}
+
+	// Global caches
+	if len(analysis.GlobalCaches) > 0 {
+		sb.WriteString("### Global Package Manager Caches\n")
+		for _, cache := range analysis.GlobalCaches {
+			sb.WriteString(fmt.Sprintf("- `%s` (%s) - %s, clean with `%s`\n",
+				cache.Path, formatSize(cache.Size), cache.Description, cache.CleanCommand))
+		}
+		sb.WriteString("\n")
+	}
+
+	// System caches (--system)
+	if len(analysis.SystemCaches) > 0 {
+		sb.WriteString("### System Caches (outside home, need sudo - high caution)\n")
+		for _, cache := range analysis.SystemCaches {
+			sb.WriteString(fmt.Sprintf("- `%s` (%s) - %s; %s\n",
+				cache.Path, formatSize(cache.Size), cache.Description, cache.CleanCommand))
 		}
 		sb.WriteString("\n")
 	}
@@ -145,6 +180,50 @@ func buildPrompt(analysis *analyzer.Analysis) string {
 		sb.WriteString("\n")
 	}
 
+	// Installers superseded by a newer download
+	if len(analysis.OldInstallers) > 0 {
+		sb.WriteString("### Older Installer Versions in Downloads\n")
+		for i, g := range analysis.OldInstallers {
+			if i >= 8 {
+				break
+			}
+			for _, f := range g.Older {
+				sb.WriteString(fmt.Sprintf("- `%s` (%s, superseded by `%s`)\n", f.Path, formatSize(f.Size), filepath.Base(g.Keep.Path)))
+			}
+		}
+		sb.WriteString("\n")
+	}
+
+	// Size band
+	if analysis.SizeBandCount > 0 {
+		sb.WriteString(fmt.Sprintf("### Medium Clutter (%d files in the requested size range, %s total)\n",
+			analysis.SizeBandCount, formatSize(analysis.SizeBandTotal)))
+		for i, f := range analysis.SizeBand {
+			if i >= 8 {
+				break
+			}
+			sb.WriteString(fmt.Sprintf("- `%s` (%s)\n", f.Path, formatSize(f.Size)))
+		}
+		sb.WriteString("\n")
+	}
+
+	// Directories of many small files
+	if len(analysis.SmallFileDirs) > 0 {
+		sb.WriteString("### Directories of Many Small Files\n")
+		for i, d := range analysis.SmallFileDirs {
+			if i >= 8 {
+				break
+			}
+			sb.WriteString(fmt.Sprintf("- `%s` (%d files, %s)\n", d.Path, d.Files, formatSize(d.Size)))
+		}
+		sb.WriteString("\n")
+	}
+
+	// Offloaded to iCloud: touching them downloads them
+	if off := analysis.Offloaded; off.Files > 0 {
+		sb.WriteString(fmt.Sprintf("### Files Offloaded to iCloud\n- %d files kept in iCloud, with only placeholders on disk. Don't suggest opening, moving or deleting them: that downloads them or removes them from iCloud.\n\n", off.Files))
+	}
+
 	sb.WriteString(`
 ## Your Task
 
@@ -0,0 +1,61 @@
+package llm
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"forge-dust/analyzer"
+	"forge-shared/datadir"
+)
+
+// PromptName is the template in the data directory's prompts that
+// replaces the built-in recommendations prompt
+const PromptName = "dust_recommendations.tmpl"
+
+// PromptData is what a recommendations prompt template is rendered with
+type PromptData struct {
+	Analysis *analyzer.Analysis
+	Default  string // The built-in prompt, for templates that only add to it
+}
+
+// PromptPath returns prompts/dust_recommendations.tmpl in the forge data
+// directory
+func PromptPath() string {
+	return datadir.Path("prompts", PromptName)
+}
+
+// prompt renders c.PromptFile, or PromptPath if that's unset and exists,
+// and is the built-in prompt otherwise
+func (c *OllamaClient) prompt(analysis *analyzer.Analysis) (string, error) {
+	builtin := buildPrompt(analysis)
+	path := c.PromptFile
+	if path == "" {
+		path = PromptPath()
+		if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+			return builtin, nil
+		}
+	}
+	return renderPrompt(path, PromptData{Analysis: analysis, Default: builtin})
+}
+
+// renderPrompt executes the text/template at path with data
+func renderPrompt(path string, data PromptData) (string, error) {
+	text, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	tmpl, err := template.New(filepath.Base(path)).Funcs(template.FuncMap{"size": formatSize}).Parse(string(text))
+	if err != nil {
+		return "", fmt.Errorf("prompt template %s: %w", path, err)
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("prompt template %s: %w", path, err)
+	}
+	return sb.String(), nil
+}
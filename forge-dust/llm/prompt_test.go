@@ -0,0 +1,59 @@
+package llm
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"forge-dust/analyzer"
+)
+
+func TestPromptTemplate(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	analysis := &analyzer.Analysis{
+		TotalReclaimable: 5 << 30,
+		CacheDirs: []analyzer.CacheReport{
+			{Path: "/Users/me/src/app/node_modules", Size: 300 << 20, Description: "Node.js dependencies"},
+		},
+	}
+	c := NewClient("test")
+
+	got, err := c.prompt(analysis)
+	if err != nil || got != buildPrompt(analysis) {
+		t.Fatalf("prompt() without a template = %q, %v, want the built-in prompt", got, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(PromptPath()), 0755); err != nil {
+		t.Fatal(err)
+	}
+	tmpl := "Réponds en français. {{size .Analysis.TotalReclaimable}} à libérer.\n" +
+		"{{range .Analysis.CacheDirs}}- {{.Path}} ({{size .Size}})\n{{end}}"
+	if err := os.WriteFile(PromptPath(), []byte(tmpl), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err = c.prompt(analysis)
+	if err != nil {
+		t.Fatalf("prompt() error = %v", err)
+	}
+	want := "Réponds en français. 5.0 GB à libérer.\n- /Users/me/src/app/node_modules (300.0 MB)\n"
+	if got != want {
+		t.Errorf("prompt() = %q, want %q", got, want)
+	}
+
+	// An explicit file wins, and may build on the default
+	c.PromptFile = filepath.Join(t.TempDir(), "terse.tmpl")
+	if err := os.WriteFile(c.PromptFile, []byte("{{.Default}}Be terse."), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err = c.prompt(analysis)
+	if err != nil || !strings.HasPrefix(got, buildPrompt(analysis)) || !strings.HasSuffix(got, "Be terse.") {
+		t.Errorf("prompt() with PromptFile = %q, %v, want the built-in prompt then \"Be terse.\"", got, err)
+	}
+
+	c.PromptFile = filepath.Join(t.TempDir(), "missing.tmpl")
+	if _, err := c.prompt(analysis); err == nil {
+		t.Error("prompt() with a missing PromptFile error = nil, want one")
+	}
+}
@@ -1,144 +1,610 @@
 package main
 
 import (
+	"bufio"
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
+	"math"
+	"net"
 	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
 	"forge-dust/analyzer"
+	"forge-dust/baseline"
+	"forge-dust/daemon"
+	"forge-dust/dust"
 	"forge-dust/llm"
 	"forge-dust/output"
 	"forge-dust/scanner"
+	"forge-dust/timing"
+	"forge-shared/pager"
+	"forge-shared/size"
 )
 
 var version = "0.1.0"
 
+// Exit codes, shared with the forge wrapper. When several apply, the
+// first in this list (after generic errors) wins.
+const (
+	exitOK             = 0 // Scan and analysis completed
+	exitError          = 1 // Generic error (bad flags, unreadable root)
+	exitNothingToDo    = 2 // Nothing worth reporting was found
+	exitLLMUnavailable = 3 // Completed, but AI recommendations failed
+	exitPartialScan    = 4 // Some files or directories could not be read
+	exitAborted        = 5 // User aborted
+)
+
 func main() {
-	// CLI flags
-	scanPath := flag.String("path", "", "Path to scan (default: home directory)")
-	minSize := flag.Int64("min-size", 100, "Minimum file size in MB to report as 'large'")
-	noLLM := flag.Bool("no-llm", false, "Skip LLM analysis")
-	model := flag.String("model", "kimi-k2-thinking:cloud", "Ollama model for recommendations")
-	checkDupes := flag.Bool("duplicates", false, "Check for duplicate files (slower)")
-	showVersion := flag.Bool("version", false, "Show version")
-	quick := flag.Bool("quick", false, "Quick scan (skip hidden directories, limit depth)")
-	jsonOutput := flag.Bool("json", false, "Output results as JSON (for forge wrapper)")
-
-	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, `forge-dust - Find disk space optimization opportunities
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run is forge-dust given args, without the program name, returning the
+// exit code rather than exiting so it can be driven from tests
+func run(args []string, stdout, stderr io.Writer) int {
+	// CLI flags; bad ones exit 1 rather than the flag package's 2, which
+	// means "nothing to do"
+	flags := flag.NewFlagSet("forge-dust", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	scanPath := flags.String("path", "", "Path to scan (default: home directory)")
+	minSize := flags.Int64("min-size", 100, "Minimum file size in MB to report as 'large'")
+	noLLM := flags.Bool("no-llm", false, "Skip LLM analysis")
+	model := flags.String("model", "kimi-k2-thinking:cloud", "Ollama model for recommendations")
+	checkDupes := flags.Bool("duplicates", false, "Check for duplicate files (slower)")
+	aggressiveDupes := flags.Bool("duplicates-aggressive", false, "Find every duplicate over 4KB, confirmed by hashing whole files (slowest)")
+	showVersion := flags.Bool("version", false, "Show version")
+	quick := flags.Bool("quick", false, "Quick scan (skip hidden directories, limit depth)")
+	jsonOutput := flags.Bool("json", false, "Output results as JSON (for forge wrapper)")
+	workers := flags.Int("workers", 0, "Directories to size, and files to hash for duplicates, in parallel (0 = pick from the disk type; use 1 for HDDs and network drives)")
+	sizeWorkers := flags.Int("size-workers", 0, "Deprecated: use --workers")
+	sizeRange := flags.String("size-range", "", "Also report files in a size band, e.g. 10MB:100MB (upper bound exclusive)")
+	minCacheSize := flags.String("min-cache-size", "1MB", "Smallest cache directory to report, e.g. 256KB or 50MB (bare numbers are MB)")
+	scriptPath := flags.String("script", "", "Write a reviewable shell script of safe cleanup commands instead of AI recommendations (- for stdout)")
+	gitAware := flags.Bool("git-aware", false, "Report large files that git repositories track, with advice on untracking them")
+	system := flags.Bool("system", false, "Also size system caches outside your home (/Library/Caches, /private/var/folders, /var/cache), as a separate high-risk category; reading them may need sudo or Full Disk Access")
+	keepRecent := flags.Int("keep-recent", 0, "Leave the N most recently modified files out of large, old and download findings")
+	excludeRecentAccess := flags.Bool("exclude-recent-access", false, "Leave files opened in the last 30 days out of large, old and download findings (by modification time where access times aren't recorded)")
+	runDaemon := flags.Bool("daemon", false, "Stay running, keeping a warm scan of --path that later runs reuse")
+	noDaemon := flags.Bool("no-daemon", false, "Scan the disk even if a daemon has a warm scan")
+	emptyTrash := flags.Bool("empty-trash", false, "Empty the Trash, after showing its size and asking")
+	summary := flags.Bool("summary", false, "Print one line with the reclaimable total, category and item counts, and exit (no LLM)")
+	applications := flags.Bool("applications", false, "Size installed apps in /Applications and ~/Applications, flagging large and long-unused ones")
+	noPager := flags.Bool("no-pager", false, "Print the report straight to the terminal instead of through $PAGER when it's long")
+	showTimings := flags.Bool("timings", false, "Print how long each stage took (scan, analysis steps, LLM calls) to stderr")
+	homeDir := flags.String("home", "", "Home directory whose Downloads and Trash the findings use (default: the one containing --path)")
+	promptFile := flags.String("prompt-file", "", "text/template to use instead of the built-in AI prompt (default: "+llm.PromptPath()+" if it exists)")
+	peekArchives := flags.Bool("peek-archives", false, "List what's inside zip and tar.gz files over 100MB, without extracting them")
+	baselineMode := flags.String("baseline", "", "Either save a snapshot of directory sizes, or compare to show what grew since")
+	quietFlag := flags.Bool("quiet", false, "Print just the report: no scan progress or closing next steps")
+
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, `forge-dust - Find disk space optimization opportunities
 
 Usage:
   forge-dust [flags]
 
 Flags:
 `)
-		flag.PrintDefaults()
-		fmt.Fprintf(os.Stderr, `
+		flags.PrintDefaults()
+		fmt.Fprintf(stderr, `
 Examples:
   forge-dust                      # Scan home directory
   forge-dust --path ~/Projects    # Scan specific directory
+  forge-dust --home /Users/alice  # Scan another user's home as theirs
   forge-dust --quick              # Fast scan, less thorough
   forge-dust --duplicates         # Also find duplicate files
+  forge-dust --duplicates-aggressive  # Every duplicate, small ones too, for a serious cleanup
   forge-dust --no-llm             # Skip AI recommendations
+  forge-dust --size-range 10MB:100MB  # Find medium-sized clutter
+  forge-dust --min-cache-size 50MB    # Only caches worth the trouble
+  forge-dust --workers 1          # Gentle on spinning disks and network shares
+  forge-dust --keep-recent 5      # Never suggest the 5 newest downloads
+  forge-dust --exclude-recent-access  # Skip old files you've opened lately
+  forge-dust --git-aware          # Find large files committed to your repos
+  forge-dust --system             # Include system caches (best with Full Disk Access)
+  forge-dust --script cleanup.sh  # Write safe cleanup commands to review and run yourself
+  forge-dust --daemon &           # Keep a warm scan so later runs return instantly
+  forge-dust --empty-trash        # Empty the Trash (asks first)
+  forge-dust --summary            # One line for a dashboard or status bar
+  forge-dust --applications       # Find big apps you never open
+  forge-dust --timings            # See where the time goes
+  forge-dust --quiet              # Just the report, without progress or next steps
+  forge-dust --baseline save      # Snapshot directory sizes...
+  forge-dust --baseline compare   # ...and later see what grew
 `)
 	}
 
-	flag.Parse()
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitError
+	}
 
 	if *showVersion {
-		fmt.Printf("forge-dust v%s\n", version)
-		os.Exit(0)
+		fmt.Fprintf(stdout, "forge-dust v%s\n", version)
+		return exitOK
+	}
+
+	if *baselineMode != "" && *baselineMode != "save" && *baselineMode != "compare" {
+		fmt.Fprintf(stderr, "Invalid --baseline %q: expected save or compare\n", *baselineMode)
+		return exitError
+	}
+
+	cacheMin, err := size.Parse(*minCacheSize)
+	if err != nil {
+		fmt.Fprintf(stderr, "Invalid --min-cache-size: %v\n", err)
+		return exitError
+	}
+	if cacheMin == 0 {
+		cacheMin = 1 // Every cache with anything in it; 0 would mean the default
+	}
+
+	var bandMin, bandMax int64
+	if *sizeRange != "" {
+		if bandMin, bandMax, err = parseSizeRange(*sizeRange); err != nil {
+			fmt.Fprintf(stderr, "Invalid --size-range: %v\n", err)
+			return exitError
+		}
 	}
 
-	// Determine scan path
+	// Determine scan path; --home on its own scans that home
 	path := *scanPath
+	if path == "" {
+		path = *homeDir
+	}
 	if path == "" {
 		home, err := os.UserHomeDir()
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error getting home directory: %v\n", err)
-			os.Exit(1)
+			fmt.Fprintf(stderr, "Error getting home directory: %v\n", err)
+			return exitError
 		}
 		path = home
 	}
+	home := dust.Options{Path: path, Home: *homeDir}.HomeDir()
+
+	if *emptyTrash {
+		return runEmptyTrash(stdout, stderr, bufio.NewReader(os.Stdin))
+	}
+
+	if *runDaemon {
+		return serveDaemon(stdout, stderr, path)
+	}
+
+	// Pick concurrency for the disk: parallel walks help SSDs but make HDDs seek
+	media := scanner.DetectMedia(path)
+	if *sizeWorkers > 0 {
+		fmt.Fprintln(stderr, "--size-workers is deprecated; use --workers")
+		if *workers <= 0 {
+			*workers = *sizeWorkers
+		}
+	}
+	if *workers <= 0 {
+		*workers = scanner.WorkersFor(media, runtime.NumCPU())
+	}
+
+	if *applications {
+		return runApplications(stdout, home, *workers)
+	}
+
+	var timings *timing.Timer
+	if *showTimings {
+		timings = timing.New()
+	}
+	// exit reports the timings, if asked for, on the way out; they go to
+	// stderr so --json and --summary output stays clean
+	exit := func(code int) int {
+		timings.Report(stderr)
+		return code
+	}
+
+	// Machine-readable output keeps stdout free of progress
+	quiet := *quietFlag || *jsonOutput || *summary || *scriptPath == "-"
+
+	opts := dust.Options{
+		Path:                 path,
+		Home:                 home,
+		Quick:                *quick,
+		Workers:              *workers,
+		MinLargeFile:         *minSize * 1024 * 1024,
+		MinCacheSize:         cacheMin,
+		Duplicates:           *checkDupes,
+		AggressiveDuplicates: *aggressiveDupes,
+		SizeBandMin:          bandMin,
+		SizeBandMax:          bandMax,
+		KeepRecent:           *keepRecent,
+		ExcludeRecentAccess:  *excludeRecentAccess,
+		GitAware:             *gitAware,
+		System:               *system,
+		PeekArchives:         *peekArchives,
+		Timings:              timings,
+	}
+
+	// A running daemon already has the tree, if it scanned it the way this
+	// run would; a quick scan skips hidden dirs, so can't use its full scan
+	var result *scanner.ScanResult
+	if !*noDaemon {
+		stop := timings.Start("daemon query")
+		scanOpts := daemon.OptionsOf(dust.NewScanner(path, opts))
+		warm, updated, err := daemon.Query(daemon.SocketPath(), path, scanOpts)
+		stop()
+		if err == nil {
+			result = warm
+			if !quiet {
+				fmt.Fprintln(stdout)
+				output.PrintInfo(stdout, fmt.Sprintf("Using the daemon's scan of %s (updated %s ago)",
+					path, time.Since(updated).Round(time.Second)))
+				fmt.Fprintln(stdout)
+			}
+		}
+	}
+	if result == nil {
+		if result, err = scan(stdout, opts, media, quiet); err != nil {
+			fmt.Fprintf(stderr, "Scan error: %v\n", err)
+			return exit(exitError)
+		}
+	}
+
+	// The user's own cache directories, ahead of the built-in list
+	if err := scanner.LoadCacheDirs(scanner.UserCacheDirsPath()); err != nil {
+		fmt.Fprintf(stderr, "Ignoring your cache directory list: %v\n", err)
+	}
+
+	// Analyze
+	if !quiet && (opts.Duplicates || opts.AggressiveDuplicates) {
+		opts.OnHashProgress = func(hashed, total int) {
+			fmt.Fprintf(stdout, "\r\033[K  Checking for duplicates: %s%d/%d files%s", output.Cyan, hashed, total, output.Reset)
+		}
+	}
+	// Ctrl-C while looking for duplicates stops the search, not the run;
+	// once the analysis is done it interrupts as usual
+	ctx, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	a := dust.NewAnalyzer(opts)
+	analysis := a.AnalyzeContext(ctx, result)
+	interrupted := ctx.Err() != nil
+	stopSignals()
+	if opts.OnHashProgress != nil {
+		fmt.Fprint(stdout, "\r\033[K")
+	}
+	if interrupted && a.CheckDuplicates {
+		fmt.Fprintln(stderr, "Stopped looking for duplicates; only the ones already found are reported")
+	}
 
-	// Setup scanner
-	s := scanner.New(path)
-	if *quick {
-		s.SkipHidden = true
-		s.MaxDepth = 5
+	if *baselineMode != "" {
+		return exit(runBaseline(stdout, stderr, *baselineMode, path, result, analysis))
 	}
 
-	if !*jsonOutput {
+	// JSON output for forge wrapper
+	if *jsonOutput {
+		outputJSON(stdout, analysis)
+		return exit(exitCode(analysis, result, false))
+	}
+
+	// Just the headline, for scripts and status bars
+	if *summary {
+		fmt.Fprintln(stdout, summaryLine(analysis))
+		return exit(exitCode(analysis, result, false))
+	}
+
+	// Cleanup script instead of recommendations
+	if *scriptPath != "" {
+		script, commands := output.CleanupScript(analysis)
+		if *scriptPath == "-" {
+			fmt.Fprint(stdout, script)
+			return exit(exitCode(analysis, result, false))
+		}
+
+		output.PrintAnalysis(stdout, analysis)
+		if err := os.WriteFile(*scriptPath, []byte(script), 0755); err != nil {
+			output.PrintError(stdout, fmt.Sprintf("Could not write %s: %v", *scriptPath, err))
+			return exit(exitError)
+		}
+		output.PrintInfo(stdout, fmt.Sprintf("Wrote %d cleanup commands to %s", commands, *scriptPath))
+		output.PrintInfo(stdout, fmt.Sprintf("Review it, then run: DRY_RUN=0 sh %s", *scriptPath))
+		return exit(exitCode(analysis, result, false))
+	}
+
+	// Output, paged if it's longer than the terminal
+	report := pager.Start(*noPager || stdout != os.Stdout)
+	output.PrintAnalysis(report.Writer(stdout), analysis)
+	report.Stop()
+
+	// LLM recommendations
+	llmFailed := false
+	if !*noLLM {
+		output.PrintInfo(stdout, "Getting AI recommendations...")
+		client := llm.NewClient(*model)
+		client.Timings = timings
+		client.PromptFile = *promptFile
+		recommendations, err := client.GetRecommendations(analysis)
+		if err != nil {
+			llmFailed = true
+			output.PrintError(stdout, fmt.Sprintf("Could not get AI recommendations: %v", err))
+			output.PrintInfo(stdout, "Run with --no-llm to skip AI analysis")
+		} else {
+			output.PrintLLMRecommendations(stdout, recommendations)
+		}
+	}
+
+	// Print errors if any
+	if len(result.Errors) > 0 {
+		output.PrintInfo(stdout, fmt.Sprintf("\n%d files/directories could not be accessed", len(result.Errors)))
+		output.PrintDenied(stdout, result.Denied, home)
+	}
+
+	// Bridge the report to action, unless asked for the report alone
+	if !quiet {
+		output.PrintNextSteps(stdout, output.NextSteps(analysis))
+	}
+
+	return exit(exitCode(analysis, result, llmFailed))
+}
+
+// scan walks opts.Path, showing progress unless quiet
+func scan(stdout io.Writer, opts dust.Options, media scanner.MediaType, quiet bool) (*scanner.ScanResult, error) {
+	path, quick, workers := opts.Path, opts.Quick, opts.Workers
+
+	if !quiet {
 		// Pre-scan messaging
-		fmt.Println()
-		output.PrintInfo(fmt.Sprintf("Scanning %s", path))
-		if *quick {
-			output.PrintInfo("Quick mode: skipping hidden dirs, max depth 5")
+		fmt.Fprintln(stdout)
+		output.PrintInfo(stdout, fmt.Sprintf("Scanning %s", path))
+		if quick {
+			output.PrintInfo(stdout, fmt.Sprintf("Quick mode: skipping hidden dirs, max depth %d", dust.QuickMaxDepth))
+		}
+		if media != scanner.MediaUnknown {
+			output.PrintDim(stdout, fmt.Sprintf("Storage: %s, %d workers", media, workers))
+		}
+		if opts.System {
+			output.PrintInfo(stdout, "Also sizing system caches (--system); some can only be read with sudo or Full Disk Access")
 		}
-		fmt.Println()
-		output.PrintDim("Note: macOS may prompt for folder access permissions.")
-		output.PrintDim("Grant access to allow scanning those directories.\n")
+		fmt.Fprintln(stdout)
+		output.PrintDim(stdout, "Note: macOS may prompt for folder access permissions.")
+		output.PrintDim(stdout, "Grant access to allow scanning those directories.\n")
 
 		// Setup progress callback for interactive mode
-		s.OnProgress = func(p scanner.Progress) {
+		opts.OnProgress = func(p scanner.Progress) {
 			// Shorten the path for display
 			dir := p.CurrentDir
 			if len(dir) > 50 {
 				dir = "..." + dir[len(dir)-47:]
 			}
-			fmt.Printf("\r\033[K  %s%d files%s | %s%s%s | %s",
+			fmt.Fprintf(stdout, "\r\033[K  %s%d files%s | %s%s%s | %s",
 				output.Cyan, p.FilesScanned, output.Reset,
 				output.Cyan, formatBytes(p.BytesScanned), output.Reset,
 				dir)
 		}
 	}
 
-	// Scan
-	result, err := s.Scan()
+	result, err := dust.Scan(opts)
 
 	// Clear progress line
-	if !*jsonOutput {
-		fmt.Print("\r\033[K")
+	if !quiet {
+		fmt.Fprint(stdout, "\r\033[K")
 	}
+	return result, err
+}
+
+// serveDaemon scans path, then answers queries on the daemon socket while
+// polling for changes, until interrupted
+func serveDaemon(stdout, stderr io.Writer, path string) int {
+	socket := daemon.SocketPath()
+	if err := daemon.Ping(socket); err == nil {
+		fmt.Fprintf(stderr, "A forge-dust daemon is already running on %s\n", socket)
+		return exitError
+	}
+
+	output.PrintInfo(stdout, fmt.Sprintf("Scanning %s...", path))
+	d, err := daemon.New(path)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Scan error: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintf(stderr, "Scan error: %v\n", err)
+		return exitError
 	}
 
-	// Analyze
-	a := analyzer.New()
-	a.MinLargeFile = *minSize * 1024 * 1024
-	a.CheckDuplicates = *checkDupes
+	// Nothing answered the ping, so any socket file left over is stale
+	os.Remove(socket)
+	if err := os.MkdirAll(filepath.Dir(socket), 0700); err != nil {
+		fmt.Fprintf(stderr, "Error creating %s: %v\n", filepath.Dir(socket), err)
+		return exitError
+	}
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error listening on %s: %v\n", socket, err)
+		return exitError
+	}
+	defer os.Remove(socket)
 
-	analysis := a.Analyze(result)
+	events := make(chan daemon.Event)
+	stop := make(chan struct{})
+	go daemon.Poll(d.Root, daemon.PollInterval, events, stop)
+	go d.Run(events)
 
-	// JSON output for forge wrapper
-	if *jsonOutput {
-		outputJSON(analysis, result)
-		return
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigs
+		close(stop)
+		l.Close()
+	}()
+
+	output.PrintInfo(stdout, fmt.Sprintf("Serving the scan of %s on %s (checking for changes every %s)",
+		d.Root, socket, daemon.PollInterval))
+	if err := d.Serve(l); err != nil {
+		fmt.Fprintf(stderr, "Daemon error: %v\n", err)
+		return exitError
 	}
+	return exitOK
+}
 
-	// Output
-	output.PrintAnalysis(analysis)
+// runEmptyTrash shows what the trash folders hold and empties them once the
+// user agrees
+func runEmptyTrash(stdout, stderr io.Writer, in *bufio.Reader) int {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error getting home directory: %v\n", err)
+		return exitError
+	}
 
-	// LLM recommendations
-	if !*noLLM {
-		output.PrintInfo("Getting AI recommendations...")
-		client := llm.NewClient(*model)
-		recommendations, err := client.GetRecommendations(analysis)
-		if err != nil {
-			output.PrintError(fmt.Sprintf("Could not get AI recommendations: %v", err))
-			output.PrintInfo("Run with --no-llm to skip AI analysis")
+	var trashes []string
+	var total int64
+	for _, trash := range scanner.TrashPaths(home) {
+		entries, err := os.ReadDir(trash)
+		if err != nil || len(entries) == 0 {
+			continue
+		}
+		size, _ := scanner.GetDirSize(trash)
+		fmt.Fprintf(stdout, "  %s%8s%s  %s (%d items)\n", output.Yellow, formatBytes(size), output.Reset, trash, len(entries))
+		trashes = append(trashes, trash)
+		total += size
+	}
+	if len(trashes) == 0 {
+		output.PrintInfo(stdout, "The Trash is already empty.")
+		return exitNothingToDo
+	}
+
+	fmt.Fprintf(stdout, "\nPermanently delete everything in the Trash (%s)? This can't be undone. [y/N] ", formatBytes(total))
+	answer, _ := in.ReadString('\n')
+	if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+		fmt.Fprintln(stdout, "Left the Trash alone.")
+		return exitAborted
+	}
+
+	failed := false
+	for _, trash := range trashes {
+		if err := emptyTrashFolder(trash, home); err != nil {
+			output.PrintError(stdout, fmt.Sprintf("Could not empty %s: %v", trash, err))
+			failed = true
+		}
+	}
+	if failed {
+		return exitError
+	}
+	output.PrintInfo(stdout, fmt.Sprintf("Emptied the Trash, freeing %s.", formatBytes(total)))
+	return exitOK
+}
+
+// emptyTrashFolder asks Finder to empty the macOS Trash, which also clears
+// trashed files on other volumes, and otherwise deletes the folder's contents
+func emptyTrashFolder(trash, home string) error {
+	if runtime.GOOS == "darwin" && trash == filepath.Join(home, ".Trash") {
+		if exec.Command("osascript", "-e", `tell application "Finder" to empty trash`).Run() == nil {
+			return nil
+		}
+	}
+	return scanner.EmptyTrash(trash, home)
+}
+
+// runApplications sizes the installed apps and lists those worth reviewing.
+// It only reports: an app is never removed for the user.
+func runApplications(stdout io.Writer, home string, workers int) int {
+	dirs := scanner.AppDirs(home)
+	apps := scanner.FindApps(dirs, workers)
+	if len(apps) == 0 {
+		output.PrintInfo(stdout, fmt.Sprintf("No applications found in %s.", strings.Join(dirs, " or ")))
+		return exitNothingToDo
+	}
+
+	now := time.Now()
+	review := analyzer.ReviewApps(apps, now)
+	output.PrintApps(stdout, apps, review, now)
+	if len(review) == 0 {
+		return exitNothingToDo
+	}
+	return exitOK
+}
+
+// runBaseline saves the scan as path's baseline, or compares it with the saved one
+func runBaseline(stdout, stderr io.Writer, mode, path string, result *scanner.ScanResult, analysis *analyzer.Analysis) int {
+	root, err := filepath.Abs(path)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error resolving %s: %v\n", path, err)
+		return exitError
+	}
+	current := baseline.New(root, result, analysis)
+	file := baseline.Path(root)
+
+	if mode == "save" {
+		if err := current.Save(file); err != nil {
+			output.PrintError(stdout, fmt.Sprintf("Could not save baseline: %v", err))
+			return exitError
+		}
+		output.PrintInfo(stdout, fmt.Sprintf("Saved a baseline of %s (%s) to %s", root, formatBytes(current.Total), file))
+		output.PrintInfo(stdout, "Run forge-dust --baseline compare later to see what grew")
+		return exitOK
+	}
+
+	saved, err := baseline.Load(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			output.PrintError(stdout, fmt.Sprintf("No baseline for %s yet; run forge-dust --baseline save first", root))
 		} else {
-			output.PrintLLMRecommendations(recommendations)
+			output.PrintError(stdout, fmt.Sprintf("Could not read baseline: %v", err))
 		}
+		return exitError
+	}
+	cmp := baseline.Compare(saved, current)
+	output.PrintComparison(stdout, cmp)
+	if len(cmp.Dirs) == 0 {
+		return exitNothingToDo
 	}
+	return exitOK
+}
 
-	// Print errors if any
-	if len(result.Errors) > 0 {
-		output.PrintInfo(fmt.Sprintf("\n%d files/directories could not be accessed", len(result.Errors)))
+// exitCode maps a completed run to its exit code. An incomplete scan is
+// reported first, since "nothing found" may just mean "couldn't look".
+func exitCode(analysis *analyzer.Analysis, result *scanner.ScanResult, llmFailed bool) int {
+	switch {
+	case len(result.Errors) > 0:
+		return exitPartialScan
+	case !hasFindings(analysis):
+		return exitNothingToDo
+	case llmFailed:
+		return exitLLMUnavailable
+	default:
+		return exitOK
+	}
+}
+
+func hasFindings(analysis *analyzer.Analysis) bool {
+	return len(analysis.CacheDirs) > 0 || len(analysis.GlobalCaches) > 0 || len(analysis.SystemCaches) > 0 || len(analysis.LargeFiles) > 0 ||
+		len(analysis.Downloads) > 0 || len(analysis.OldFiles) > 0 ||
+		len(analysis.DuplicateGroups) > 0 || analysis.SizeBandCount > 0 || len(analysis.TrackedFiles) > 0 ||
+		len(analysis.Trash) > 0 || len(analysis.SmallFileDirs) > 0 || len(analysis.OldInstallers) > 0 ||
+		len(analysis.MountedImages) > 0
+}
+
+// parseSizeRange parses "MIN:MAX" such as "10MB:100MB". Either side may be
+// empty to leave that end open; bare numbers are MB, like --min-size.
+func parseSizeRange(s string) (int64, int64, error) {
+	lo, hi, ok := strings.Cut(s, ":")
+	if !ok {
+		return 0, 0, fmt.Errorf("%q: expected MIN:MAX", s)
 	}
+
+	min, max := int64(0), int64(math.MaxInt64)
+	var err error
+	if lo != "" {
+		if min, err = size.Parse(lo); err != nil {
+			return 0, 0, err
+		}
+	}
+	if hi != "" {
+		if max, err = size.Parse(hi); err != nil {
+			return 0, 0, err
+		}
+	}
+	if min >= max {
+		return 0, 0, fmt.Errorf("%q: minimum must be below maximum", s)
+	}
+	return min, max, nil
 }
 
 // JSONOutput is the structure for forge wrapper integration
@@ -147,6 +613,7 @@ type JSONOutput struct {
 	Version     string        `json:"version"`
 	ScanSummary ScanSummary   `json:"scan_summary"`
 	Categories  []JSONCategory `json:"categories"`
+	ExtensionBreakdown map[string]int64 `json:"extension_breakdown,omitempty"` // bytes by extension, "" for none
 }
 
 type ScanSummary struct {
@@ -172,13 +639,22 @@ type JSONMetadata struct {
 }
 
 type JSONItem struct {
-	Path    string `json:"path"`
-	Size    int64  `json:"size"`
-	Type    string `json:"type"`
-	AgeDays int    `json:"age_days,omitempty"`
+	Path    string            `json:"path"`
+	Size    int64             `json:"size"`
+	Type    string            `json:"type"`
+	AgeDays  int               `json:"age_days,omitempty"`
+	Modified time.Time         `json:"modified,omitzero"` // set for caches, to tell active from stale
+	Context  map[string]string `json:"context,omitempty"`
+}
+
+func outputJSON(stdout io.Writer, analysis *analyzer.Analysis) {
+	enc := json.NewEncoder(stdout)
+	enc.SetIndent("", "  ")
+	enc.Encode(jsonReport(analysis))
 }
 
-func outputJSON(analysis *analyzer.Analysis, result *scanner.ScanResult) {
+// jsonReport arranges the analysis into the categories the forge wrapper reads
+func jsonReport(analysis *analyzer.Analysis) JSONOutput {
 	out := JSONOutput{
 		Tool:    "forge-dust",
 		Version: version,
@@ -187,27 +663,110 @@ func outputJSON(analysis *analyzer.Analysis, result *scanner.ScanResult) {
 			TotalFiles:   analysis.ScanStats.TotalFiles,
 			ScanTimeMs:   analysis.ScanStats.ScanTime.Milliseconds(),
 		},
+		ExtensionBreakdown: analysis.ExtensionBreakdown,
 	}
 
 	// Cache directories
 	if len(analysis.CacheDirs) > 0 {
+		risk, reversible := cacheMetadata(analysis.CacheDirs)
 		cat := JSONCategory{
 			ID:        "cache_directories",
 			Name:      "Cache Directories",
 			ItemCount: len(analysis.CacheDirs),
 			Metadata: JSONMetadata{
-				TypicalRisk:  "low",
-				Reversible:   true,
+				TypicalRisk:  risk,
+				Reversible:   reversible,
 				Description:  "Build caches and package managers - all rebuildable",
 				SafeAction:   "delete",
 			},
 		}
 		for _, c := range analysis.CacheDirs {
 			cat.TotalSize += c.Size
+			item := JSONItem{
+				Path:     c.Path,
+				Size:     c.Size,
+				Type:     c.Type,
+				Modified: c.ModTime,
+			}
+			if c.Rebuild != "" {
+				item.Context = map[string]string{"rebuild": c.Rebuild}
+			}
+			cat.Items = append(cat.Items, item)
+		}
+		out.Categories = append(out.Categories, cat)
+	}
+
+	// Global caches
+	if len(analysis.GlobalCaches) > 0 {
+		cat := JSONCategory{
+			ID:        "global_caches",
+			Name:      "Global Caches",
+			ItemCount: len(analysis.GlobalCaches),
+			Metadata: JSONMetadata{
+				TypicalRisk: "low",
+				Reversible:  true,
+				Description: "Package manager caches shared by all projects - re-downloaded on demand",
+				SafeAction:  "delete",
+			},
+		}
+		for _, c := range analysis.GlobalCaches {
+			cat.TotalSize += c.Size
+			cat.Items = append(cat.Items, JSONItem{
+				Path:     c.Path,
+				Size:     c.Size,
+				Type:     c.Type,
+				Modified: c.ModTime,
+				Context:  map[string]string{"clean_command": c.CleanCommand},
+			})
+		}
+		out.Categories = append(out.Categories, cat)
+	}
+
+	// System caches, only with --system
+	if len(analysis.SystemCaches) > 0 {
+		cat := JSONCategory{
+			ID:        "system_caches",
+			Name:      "System Caches",
+			ItemCount: len(analysis.SystemCaches),
+			Metadata: JSONMetadata{
+				TypicalRisk: "high",
+				Reversible:  true,
+				Description: "Caches outside home used by the system and every user - clearing them takes sudo, and some only safely on restart",
+				SafeAction:  "review",
+			},
+		}
+		for _, c := range analysis.SystemCaches {
+			cat.TotalSize += c.Size
+			cat.Items = append(cat.Items, JSONItem{
+				Path:    c.Path,
+				Size:    c.Size,
+				Type:    c.Type,
+				Context: map[string]string{"advice": c.CleanCommand},
+			})
+		}
+		out.Categories = append(out.Categories, cat)
+	}
+
+	// Trash
+	if len(analysis.Trash) > 0 {
+		cat := JSONCategory{
+			ID:        "trash",
+			Name:      "Trash",
+			ItemCount: len(analysis.Trash),
+			Metadata: JSONMetadata{
+				TypicalRisk: "low",
+				Reversible:  false,
+				Description: "Files already deleted to the Trash - empty it rather than deleting the folder",
+				SafeAction:  "empty_trash",
+			},
+		}
+		for _, t := range analysis.Trash {
+			cat.TotalSize += t.Size
 			cat.Items = append(cat.Items, JSONItem{
-				Path: c.Path,
-				Size: c.Size,
-				Type: c.Type,
+				Path:    t.Path,
+				Size:    t.Size,
+				Type:    "trash",
+				Context: map[string]string{"empty_command": "forge-dust --empty-trash"},
 			})
 		}
 		out.Categories = append(out.Categories, cat)
@@ -233,6 +792,7 @@ func outputJSON(analysis *analyzer.Analysis, result *scanner.ScanResult) {
 				Size:    f.Size,
 				Type:    "large_file",
 				AgeDays: int(f.Age.Hours() / 24),
+				Context: fileContext(f),
 			})
 		}
 		out.Categories = append(out.Categories, cat)
@@ -258,11 +818,40 @@ func outputJSON(analysis *analyzer.Analysis, result *scanner.ScanResult) {
 				Size:    f.Size,
 				Type:    "download",
 				AgeDays: int(f.Age.Hours() / 24),
+				Context: fileContext(f),
 			})
 		}
 		out.Categories = append(out.Categories, cat)
 	}
 
+	// Installers superseded by a newer download
+	if len(analysis.OldInstallers) > 0 {
+		cat := JSONCategory{
+			ID:   "old_installers",
+			Name: "Old Installers",
+			Metadata: JSONMetadata{
+				TypicalRisk: "low",
+				Reversible:  false,
+				Description: "Older versions of installers in Downloads - the newest is kept",
+				SafeAction:  "suggest_delete",
+			},
+		}
+		for _, g := range analysis.OldInstallers {
+			for _, f := range g.Older {
+				cat.TotalSize += f.Size
+				cat.ItemCount++
+				cat.Items = append(cat.Items, JSONItem{
+					Path:    f.Path,
+					Size:    f.Size,
+					Type:    "old_installer",
+					AgeDays: int(f.Age.Hours() / 24),
+					Context: map[string]string{"newest": g.Keep.Path},
+				})
+			}
+		}
+		out.Categories = append(out.Categories, cat)
+	}
+
 	// Old files
 	if len(analysis.OldFiles) > 0 {
 		cat := JSONCategory{
@@ -283,15 +872,188 @@ func outputJSON(analysis *analyzer.Analysis, result *scanner.ScanResult) {
 				Size:    f.Size,
 				Type:    "old_file",
 				AgeDays: int(f.Age.Hours() / 24),
+				Context: fileContext(f),
 			})
 		}
 		out.Categories = append(out.Categories, cat)
 	}
 
-	// Output JSON
-	enc := json.NewEncoder(os.Stdout)
-	enc.SetIndent("", "  ")
-	enc.Encode(out)
+	// Size band
+	if analysis.SizeBandCount > 0 {
+		cat := JSONCategory{
+			ID:        "size_range",
+			Name:      "Size Range",
+			TotalSize: analysis.SizeBandTotal,
+			ItemCount: analysis.SizeBandCount,
+			Metadata: JSONMetadata{
+				TypicalRisk: "medium",
+				Reversible:  false,
+				Description: "Files in the requested size range that add up",
+				SafeAction:  "review",
+			},
+		}
+		for _, f := range analysis.SizeBand {
+			cat.Items = append(cat.Items, JSONItem{
+				Path:    f.Path,
+				Size:    f.Size,
+				Type:    "size_range",
+				AgeDays: int(f.Age.Hours() / 24),
+				Context: fileContext(f),
+			})
+		}
+		out.Categories = append(out.Categories, cat)
+	}
+
+	// Directories of many small files
+	if len(analysis.SmallFileDirs) > 0 {
+		cat := JSONCategory{
+			ID:        "many_small_files",
+			Name:      "Many Small Files",
+			ItemCount: len(analysis.SmallFileDirs),
+			Metadata: JSONMetadata{
+				TypicalRisk: "medium",
+				Reversible:  false,
+				Description: "Directories of thousands of small files that add up - often caches nothing else names",
+				SafeAction:  "suggest_delete",
+			},
+		}
+		for _, d := range analysis.SmallFileDirs {
+			cat.TotalSize += d.Size
+			cat.Items = append(cat.Items, JSONItem{
+				Path:    d.Path,
+				Size:    d.Size,
+				Type:    "small_files_dir",
+				Context: map[string]string{"file_count": strconv.Itoa(d.Files)},
+			})
+		}
+		out.Categories = append(out.Categories, cat)
+	}
+
+	// Large files tracked by git
+	if len(analysis.TrackedFiles) > 0 {
+		cat := JSONCategory{
+			ID:        "git_tracked",
+			Name:      "Tracked in Git",
+			ItemCount: len(analysis.TrackedFiles),
+			Metadata: JSONMetadata{
+				TypicalRisk: "high",
+				Reversible:  false,
+				Description: "Large files committed to git - untrack or move to LFS, don't delete",
+				SafeAction:  "review",
+			},
+		}
+		for _, f := range analysis.TrackedFiles {
+			cat.TotalSize += f.Size
+			cat.Items = append(cat.Items, JSONItem{
+				Path:    f.Path,
+				Size:    f.Size,
+				Type:    "git_tracked",
+				Context: map[string]string{"repo": f.Repo, "advice": f.Advice},
+			})
+		}
+		out.Categories = append(out.Categories, cat)
+	}
+
+	// Disk images mounted right now, held out of the categories above
+	if len(analysis.MountedImages) > 0 {
+		cat := JSONCategory{
+			ID:        "mounted_images",
+			Name:      "Mounted Disk Images",
+			ItemCount: len(analysis.MountedImages),
+			Metadata: JSONMetadata{
+				TypicalRisk: "high",
+				Reversible:  false,
+				Description: "Disk images whose volume is mounted - deleting one can corrupt the volume, so unmount it first",
+				SafeAction:  "review",
+			},
+		}
+		for _, m := range analysis.MountedImages {
+			cat.TotalSize += m.Size
+			context := map[string]string{"advice": m.Advice}
+			if m.MountPoint != "" {
+				context["mounted_at"] = m.MountPoint
+			}
+			cat.Items = append(cat.Items, JSONItem{
+				Path:    m.Path,
+				Size:    m.Size,
+				Type:    "mounted_image",
+				Context: context,
+			})
+		}
+		out.Categories = append(out.Categories, cat)
+	}
+
+	return out
+}
+
+// fileContext notes a hard-linked file, since deleting one link frees nothing
+// while the others remain, and what a peeked archive holds
+func fileContext(f analyzer.FileReport) map[string]string {
+	context := make(map[string]string)
+	if f.HardLinks >= 2 {
+		context["hard_links"] = strconv.Itoa(f.HardLinks)
+	}
+	if f.Archive != nil {
+		context["contains"] = output.ArchiveSummary(f.Archive)
+	}
+	if len(context) == 0 {
+		return nil
+	}
+	return context
+}
+
+// cacheMetadata is the riskiest of the caches' risks, and whether every
+// one of them is rebuilt on demand
+func cacheMetadata(caches []analyzer.CacheReport) (risk string, reversible bool) {
+	levels := map[string]int{"low": 0, "medium": 1, "high": 2}
+	risk, reversible = "low", true
+	for _, c := range caches {
+		if levels[c.Risk] > levels[risk] {
+			risk = c.Risk
+		}
+		reversible = reversible && c.Reversible
+	}
+	return risk, reversible
+}
+
+// reclaimableCategories are the report categories TotalReclaimable adds up.
+// The rest, downloads and old files say, are reported but not counted.
+var reclaimableCategories = map[string]bool{
+	"cache_directories": true,
+	"global_caches":     true,
+	"trash":             true,
+	"large_files":       true,
+	"old_installers":    true,
+	"many_small_files":  true,
+}
+
+// summaryLine is the one-line headline for --summary, counting only the
+// categories and items that make up the reclaimable total
+func summaryLine(analysis *analyzer.Analysis) string {
+	categories, items := 0, 0
+	for _, cat := range jsonReport(analysis).Categories {
+		if reclaimableCategories[cat.ID] {
+			categories++
+			items += cat.ItemCount
+		}
+	}
+	if len(analysis.DuplicateGroups) > 0 {
+		// Not a JSON category, but every copy past the first is counted
+		categories++
+		for _, g := range analysis.DuplicateGroups {
+			items += len(g.Files) - 1
+		}
+	}
+	return fmt.Sprintf("Reclaimable: %s across %d %s (%d %s)",
+		formatBytes(analysis.TotalReclaimable), categories, plural(categories, "category", "categories"),
+		items, plural(items, "item", "items"))
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
 }
 
 func formatBytes(b int64) string {
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"forge-dust/analyzer"
+	"forge-dust/scanner"
+)
+
+func TestExitCode(t *testing.T) {
+	withCache := &analyzer.Analysis{CacheDirs: []analyzer.CacheReport{{Path: "/p/node_modules", Size: 1 << 20}}}
+	empty := &analyzer.Analysis{}
+	clean := &scanner.ScanResult{}
+	partial := &scanner.ScanResult{Errors: []string{"/private: permission denied"}}
+
+	tests := []struct {
+		name      string
+		analysis  *analyzer.Analysis
+		result    *scanner.ScanResult
+		llmFailed bool
+		want      int
+	}{
+		{"findings", withCache, clean, false, exitOK},
+		{"no findings", empty, clean, false, exitNothingToDo},
+		{"llm failed", withCache, clean, true, exitLLMUnavailable},
+		{"scan errors", withCache, partial, false, exitPartialScan},
+		{"scan errors hide empty result", empty, partial, true, exitPartialScan},
+		{"nothing to do beats llm failure", empty, clean, true, exitNothingToDo},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCode(tt.analysis, tt.result, tt.llmFailed); got != tt.want {
+				t.Errorf("exitCode() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSizeRange(t *testing.T) {
+	const mb = 1 << 20
+	tests := []struct {
+		in       string
+		min, max int64
+		wantErr  bool
+	}{
+		{"10MB:100MB", 10 * mb, 100 * mb, false},
+		{"10mb:1gb", 10 * mb, 1 << 30, false},
+		{"512KB:1.5MB", 512 << 10, 3 * mb / 2, false},
+		{"10:100", 10 * mb, 100 * mb, false},
+		{"10MB:", 10 * mb, math.MaxInt64, false},
+		{":1GB", 0, 1 << 30, false},
+		{"100MB:10MB", 0, 0, true},
+		{"10MB", 0, 0, true},
+		{"ten:100MB", 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		min, max, err := parseSizeRange(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseSizeRange(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && (min != tt.min || max != tt.max) {
+			t.Errorf("parseSizeRange(%q) = %d, %d, want %d, %d", tt.in, min, max, tt.min, tt.max)
+		}
+	}
+}
+
+func TestRunEmptyTrashAsksFirst(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	trashed := filepath.Join(home, ".local", "share", "Trash", "files", "report.pdf")
+	if err := os.MkdirAll(filepath.Dir(trashed), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(trashed, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if code := runEmptyTrash(io.Discard, io.Discard, bufio.NewReader(strings.NewReader("\n"))); code != exitAborted {
+		t.Errorf("declined: exit %d, want %d", code, exitAborted)
+	}
+	if _, err := os.Stat(trashed); err != nil {
+		t.Fatalf("declined, but %s is gone: %v", trashed, err)
+	}
+
+	if code := runEmptyTrash(io.Discard, io.Discard, bufio.NewReader(strings.NewReader("y\n"))); code != exitOK {
+		t.Errorf("confirmed: exit %d, want %d", code, exitOK)
+	}
+	if _, err := os.Stat(trashed); !os.IsNotExist(err) {
+		t.Errorf("confirmed, but %s is still there", trashed)
+	}
+
+	if code := runEmptyTrash(io.Discard, io.Discard, bufio.NewReader(strings.NewReader("y\n"))); code != exitNothingToDo {
+		t.Errorf("already empty: exit %d, want %d", code, exitNothingToDo)
+	}
+}
+
+func TestSummaryLineMatchesAnalysis(t *testing.T) {
+	analysis := &analyzer.Analysis{
+		CacheDirs: []analyzer.CacheReport{
+			{Path: "/p/node_modules", Size: 3 << 30},
+			{Path: "/q/target", Size: 1 << 30},
+		},
+		LargeFiles: []analyzer.FileReport{{Path: "/m/film.mov", Size: 2 << 30}},
+		DuplicateGroups: []analyzer.DuplicateGroup{
+			{Size: 1 << 30, Files: []string{"/a/copy.iso", "/b/copy.iso", "/c/copy.iso"}},
+		},
+		// Reported, but not part of the total
+		OldFiles:     []analyzer.FileReport{{Path: "/o/notes.txt", Size: 1 << 20}},
+		TrackedFiles: []analyzer.TrackedReport{{Path: "/r/data.bin", Size: 1 << 30}},
+
+		TotalReclaimable: 8 << 30,
+	}
+
+	want := "Reclaimable: 8.0 GB across 3 categories (5 items)"
+	if got := summaryLine(analysis); got != want {
+		t.Errorf("summaryLine() = %q, want %q", got, want)
+	}
+
+	if got, want := summaryLine(&analyzer.Analysis{}), "Reclaimable: 0 B across 0 categories (0 items)"; got != want {
+		t.Errorf("summaryLine(empty) = %q, want %q", got, want)
+	}
+}
+
+func TestRunVersion(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"--version"}, &stdout, &stderr); code != exitOK {
+		t.Errorf("run(--version) = %d, want %d", code, exitOK)
+	}
+	if want := "forge-dust v" + version + "\n"; stdout.String() != want {
+		t.Errorf("run(--version) printed %q, want %q", stdout.String(), want)
+	}
+	if stderr.Len() > 0 {
+		t.Errorf("run(--version) wrote %q to stderr, want nothing", stderr.String())
+	}
+
+	// Bad flags go to stderr, with the exit code that means an error
+	stdout.Reset()
+	if code := run([]string{"--no-such-flag"}, &stdout, &stderr); code != exitError {
+		t.Errorf("run(--no-such-flag) = %d, want %d", code, exitError)
+	}
+	if stdout.Len() > 0 || !strings.Contains(stderr.String(), "no-such-flag") {
+		t.Errorf("run(--no-such-flag) printed %q, %q; want the error on stderr", stdout.String(), stderr.String())
+	}
+}
+
+func TestRunReportsToItsWriters(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "big.bin"), make([]byte, 2<<20), 0644); err != nil {
+		t.Fatal(err)
+	}
+	base := []string{"--path", root, "--no-llm", "--no-daemon", "--min-size", "1"}
+
+	var stdout, stderr bytes.Buffer
+	run(append(base, "--json"), &stdout, &stderr)
+	var report JSONOutput
+	if err := json.Unmarshal(stdout.Bytes(), &report); err != nil || report.Tool != "forge-dust" {
+		t.Errorf("run(--json) stdout isn't the JSON report: %v\n%s", err, stdout.String())
+	}
+
+	stdout.Reset()
+	run(append(base, "--quiet"), &stdout, &stderr)
+	if !strings.Contains(stdout.String(), "Disk Space Analysis") || !strings.Contains(stdout.String(), "big.bin") {
+		t.Errorf("run() report didn't reach stdout:\n%s", stdout.String())
+	}
+}
+
+func TestSizeWorkersStillAccepted(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"--path", t.TempDir(), "--no-llm", "--no-daemon", "--json", "--size-workers", "2"}, &stdout, &stderr)
+	if code == exitError {
+		t.Fatalf("run(--size-workers 2) = %d, stderr %q", code, stderr.String())
+	}
+	if !strings.Contains(stderr.String(), "use --workers") {
+		t.Errorf("run(--size-workers 2) stderr = %q, want a pointer to --workers", stderr.String())
+	}
+	if !json.Valid(stdout.Bytes()) {
+		t.Errorf("run(--size-workers 2 --json) stdout isn't JSON:\n%s", stdout.String())
+	}
+}
@@ -0,0 +1,56 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"forge-dust/analyzer"
+	"forge-dust/scanner"
+)
+
+// PrintApps shows the installed apps and the ones worth reviewing. Nothing
+// here offers a command: removing an app is the user's call, made with its
+// own uninstaller or the Finder.
+func PrintApps(w io.Writer, apps []scanner.App, review []analyzer.AppReport, now time.Time) {
+	printHeader(w, "FORGE-DUST", "Installed Applications")
+
+	var total int64
+	for _, app := range apps {
+		total += app.Size
+	}
+	fmt.Fprintf(w, "\n%sInstalled:%s %s%d%s apps using %s\n", Dim, Reset, Bold, len(apps), Reset, FormatSize(total))
+
+	if len(review) == 0 {
+		fmt.Fprintf(w, "\n  %sNo app is over %s or unopened for %d months.%s\n\n",
+			Green, FormatSize(analyzer.LargeAppSize), int(analyzer.UnusedAppAge.Hours()/24/30), Reset)
+		return
+	}
+
+	printSection(w, "APPS TO REVIEW")
+	fmt.Fprintf(w, "  %sLarge or long unused. Deleting an app can't be undone, so check each one:%s\n\n", Dim, Reset)
+
+	for _, r := range review {
+		used := "last use unknown"
+		if !r.LastUsed.IsZero() {
+			used = "opened " + FormatAge(now.Sub(r.LastUsed))
+		}
+		color := Yellow
+		if r.Large {
+			color = Red
+		}
+		fmt.Fprintf(w, "  %s%8s%s  %s%-16s%s  %s%s\n",
+			color, FormatSize(r.Size), Reset,
+			Dim, used, Reset,
+			r.Name(), appNote(r))
+	}
+	fmt.Fprintf(w, "\n  %sRemove with the app's own uninstaller if it has one, or drag it to the Trash.%s\n\n", Dim, Reset)
+}
+
+// appNote says why an app is listed, when its size alone doesn't show it
+func appNote(r analyzer.AppReport) string {
+	if r.Unused {
+		return fmt.Sprintf("  %s(unused)%s", Yellow, Reset)
+	}
+	return ""
+}
@@ -0,0 +1,53 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"forge-dust/baseline"
+)
+
+// maxGrowthShown caps each list in a baseline comparison
+const maxGrowthShown = 15
+
+// PrintComparison shows what grew since a baseline was saved
+func PrintComparison(w io.Writer, cmp *baseline.Comparison) {
+	printHeader(w, "FORGE-DUST", "Growth Since Baseline")
+
+	fmt.Fprintf(w, "\n%sBaseline:%s %s (%s)\n", Dim, Reset, cmp.Since.Format("2006-01-02 15:04"), FormatAge(time.Since(cmp.Since)))
+	delta := cmp.After - cmp.Before
+	color, sign := Red, "+"
+	if delta < 0 {
+		color, sign, delta = Green, "-", -delta
+	}
+	fmt.Fprintf(w, "%sTotal:%s %s → %s  %s%s%s%s\n", Dim, Reset,
+		FormatSize(cmp.Before), FormatSize(cmp.After), Bold+color, sign, FormatSize(delta), Reset)
+
+	if len(cmp.Categories) > 0 {
+		printSection(w, "CATEGORIES THAT GREW")
+		printGrowth(w, cmp.Categories)
+	}
+
+	if len(cmp.Dirs) > 0 {
+		printSection(w, "DIRECTORIES THAT GREW")
+		printGrowth(w, cmp.Dirs)
+	} else {
+		fmt.Fprintf(w, "\n  %sNothing grew since the baseline.%s\n", Green, Reset)
+	}
+
+	fmt.Fprintln(w)
+}
+
+func printGrowth(w io.Writer, growth []baseline.Growth) {
+	for i, g := range growth {
+		if i >= maxGrowthShown {
+			fmt.Fprintf(w, "  %s... and %d more%s\n", Dim, len(growth)-maxGrowthShown, Reset)
+			break
+		}
+		fmt.Fprintf(w, "  %s%9s%s  %s%8s → %-8s%s  %s\n",
+			Red, "+"+FormatSize(g.Delta()), Reset,
+			Dim, FormatSize(g.Before), FormatSize(g.After), Reset,
+			shortenPath(g.Name, 45))
+	}
+}
@@ -0,0 +1,45 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+)
+
+// maxDeniedShown is how many refused directories are listed by name
+const maxDeniedShown = 5
+
+// PrintDenied lists the directories the scan wasn't allowed into and how to
+// scan just those again with the access they need
+func PrintDenied(w io.Writer, denied []string, home string) {
+	if len(denied) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "\n  %s%d directories need more access than forge-dust had. To include them, scan just those:%s\n\n",
+		Dim, len(denied), Reset)
+	for _, cmd := range retryCommands(denied, home) {
+		fmt.Fprintf(w, "    %s\n", cmd)
+	}
+	if len(denied) > maxDeniedShown {
+		fmt.Fprintf(w, "    %s...and %d more%s\n", Dim, len(denied)-maxDeniedShown, Reset)
+	}
+	if runtime.GOOS == "darwin" {
+		fmt.Fprintf(w, "\n  %sOr give your terminal Full Disk Access (System Settings → Privacy & Security →%s\n", Dim, Reset)
+		fmt.Fprintf(w, "  %sFull Disk Access) and run forge-dust again, without sudo.%s\n", Dim, Reset)
+	}
+	fmt.Fprintln(w)
+}
+
+// retryCommands returns a sudo forge-dust command for each of the first few
+// denied directories. They name home, since root's own would be used otherwise.
+func retryCommands(denied []string, home string) []string {
+	var cmds []string
+	for i, dir := range denied {
+		if i == maxDeniedShown {
+			break
+		}
+		cmds = append(cmds, fmt.Sprintf("sudo forge-dust --path %s --home %s --no-llm", shellQuote(dir), shellQuote(home)))
+	}
+	return cmds
+}
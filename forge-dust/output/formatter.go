@@ -2,11 +2,14 @@ package output
 
 import (
 	"fmt"
+	"io"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"time"
 
 	"forge-dust/analyzer"
+	"forge-dust/scanner"
 )
 
 // ANSI color codes
@@ -51,64 +54,147 @@ func FormatAge(d time.Duration) string {
 	return "recent"
 }
 
-func PrintAnalysis(analysis *analyzer.Analysis) {
-	printHeader("FORGE-DUST", "Disk Space Analysis")
+// maxDuplicateGroupsShown keeps an aggressive duplicate scan from flooding the terminal
+const maxDuplicateGroupsShown = 20
+
+func PrintAnalysis(w io.Writer, analysis *analyzer.Analysis) {
+	printHeader(w, "FORGE-DUST", "Disk Space Analysis")
 
 	// Stats summary
-	fmt.Printf("\n%sScanned:%s %s across %s%d%s files in %s%d%s directories\n",
+	fmt.Fprintf(w, "\n%sScanned:%s %s across %s%d%s files in %s%d%s directories\n",
 		Dim, Reset,
 		FormatSize(analysis.ScanStats.TotalSize),
 		Bold, analysis.ScanStats.TotalFiles, Reset,
 		Bold, analysis.ScanStats.TotalDirs, Reset)
-	fmt.Printf("%sScan time:%s %v\n", Dim, Reset, analysis.ScanStats.ScanTime.Round(time.Millisecond))
+	fmt.Fprintf(w, "%sScan time:%s %v\n", Dim, Reset, analysis.ScanStats.ScanTime.Round(time.Millisecond))
 
 	if analysis.TotalReclaimable > 0 {
-		fmt.Printf("\n%s%s⚡ Potential space to reclaim: %s%s\n",
+		fmt.Fprintf(w, "\n%s%s⚡ Potential space to reclaim: %s%s\n",
 			Bold, Green, FormatSize(analysis.TotalReclaimable), Reset)
+		if analysis.DuplicateReclaimable > 0 {
+			fmt.Fprintf(w, "  %s%s of it from duplicate files%s\n", Dim, FormatSize(analysis.DuplicateReclaimable), Reset)
+		}
+	}
+	if off := analysis.Offloaded; off.Files > 0 {
+		inCloud := ""
+		if off.Logical > 0 {
+			inCloud = fmt.Sprintf(", %s in iCloud", FormatSize(off.Logical))
+		}
+		fmt.Fprintf(w, "%siCloud:%s %d offloaded files (%s on disk%s), left alone so nothing downloads\n",
+			Dim, Reset, off.Files, FormatSize(off.OnDisk), inCloud)
+	}
+
+	// Space by type
+	if top := analysis.TopExtensions(topExtensions); len(top) > 0 {
+		printSection(w, "SPACE BY TYPE")
+		fmt.Fprintf(w, "  %sWhat kinds of files the space goes to:%s\n\n", Dim, Reset)
+
+		for _, e := range top {
+			ext := e.Ext
+			if ext == "" {
+				ext = "(none)"
+			}
+			share := ""
+			if analysis.ScanStats.TotalSize > 0 {
+				share = fmt.Sprintf("%3.0f%%", float64(e.Size)*100/float64(analysis.ScanStats.TotalSize))
+			}
+			fmt.Fprintf(w, "  %s%8s%s  %s%4s%s  %s\n",
+				Cyan, FormatSize(e.Size), Reset,
+				Dim, share, Reset, ext)
+		}
 	}
 
 	// Cache directories
 	if len(analysis.CacheDirs) > 0 {
-		printSection("CACHE DIRECTORIES")
-		fmt.Printf("  %sThese can usually be safely deleted (will rebuild as needed):%s\n\n", Dim, Reset)
+		printSection(w, "CACHE DIRECTORIES")
+		fmt.Fprintf(w, "  %sThese can usually be safely deleted (will rebuild as needed):%s\n\n", Dim, Reset)
 
 		var totalCache int64
 		for _, cache := range analysis.CacheDirs {
 			totalCache += cache.Size
 			sizeStr := FormatSize(cache.Size)
 			path := shortenPath(cache.Path, 50)
-			fmt.Printf("  %s%8s%s  %s%-12s%s  %s%s%s\n",
+			fmt.Fprintf(w, "  %s%8s%s  %s%-12s%s  %s%s%s\n",
 				Yellow, sizeStr, Reset,
 				Cyan, cache.Type, Reset,
 				Dim, path, Reset)
 		}
-		fmt.Printf("\n  %sTotal cache: %s%s%s\n", Dim, Green, FormatSize(totalCache), Reset)
+		fmt.Fprintf(w, "\n  %sTotal cache: %s%s%s\n", Dim, Green, FormatSize(totalCache), Reset)
+	}
+
+	// Global caches
+	if len(analysis.GlobalCaches) > 0 {
+		printSection(w, "GLOBAL CACHES")
+		fmt.Fprintf(w, "  %sShared package manager caches; projects re-download what they need:%s\n\n", Dim, Reset)
+
+		for _, cache := range analysis.GlobalCaches {
+			fmt.Fprintf(w, "  %s%8s%s  %s%-6s%s  %s%s%s\n",
+				Yellow, FormatSize(cache.Size), Reset,
+				Cyan, cache.Type, Reset,
+				Dim, shortenPath(cache.Path, 50), Reset)
+			fmt.Fprintf(w, "  %8s  %s→ %s%s\n", "", Green, cache.CleanCommand, Reset)
+		}
+	}
+
+	// System caches, only with --system
+	if len(analysis.SystemCaches) > 0 || len(analysis.SystemDenied) > 0 {
+		printSection(w, "SYSTEM CACHES")
+		fmt.Fprintf(w, "  %sOutside your home and shared with the system; not in the total. Clear with care:%s\n\n", Dim, Reset)
+
+		for _, cache := range analysis.SystemCaches {
+			fmt.Fprintf(w, "  %s%8s%s  %s%s%s\n",
+				Red, FormatSize(cache.Size), Reset,
+				Dim, shortenPath(cache.Path, 50), Reset)
+			fmt.Fprintf(w, "  %8s  %s%s%s\n", "", Dim, cache.Description, Reset)
+			fmt.Fprintf(w, "  %8s  %s→ %s%s\n", "", Yellow, cache.CleanCommand, Reset)
+		}
+		if len(analysis.SystemDenied) > 0 {
+			fmt.Fprintf(w, "\n  %sCouldn't read %d more: %s%s\n", Dim, len(analysis.SystemDenied), strings.Join(analysis.SystemDenied, ", "), Reset)
+			if runtime.GOOS == "darwin" {
+				fmt.Fprintf(w, "  %sGive your terminal Full Disk Access (System Settings → Privacy & Security) to size them.%s\n", Dim, Reset)
+			} else {
+				fmt.Fprintf(w, "  %sRun with sudo, naming your home with --home, to size them.%s\n", Dim, Reset)
+			}
+		}
+	}
+
+	// Trash
+	if len(analysis.Trash) > 0 {
+		printSection(w, "TRASH")
+		fmt.Fprintf(w, "  %sDeleted files still taking up space until the Trash is emptied:%s\n\n", Dim, Reset)
+
+		for _, trash := range analysis.Trash {
+			fmt.Fprintf(w, "  %s%8s%s  %s%s%s\n",
+				Yellow, FormatSize(trash.Size), Reset,
+				Dim, shortenPath(trash.Path, 50), Reset)
+		}
+		fmt.Fprintf(w, "  %8s  %s→ forge-dust --empty-trash%s\n", "", Green, Reset)
 	}
 
 	// Large files
 	if len(analysis.LargeFiles) > 0 {
-		printSection("LARGE FILES")
-		fmt.Printf("  %sFiles over 100MB:%s\n\n", Dim, Reset)
+		printSection(w, "LARGE FILES")
+		fmt.Fprintf(w, "  %sFiles over 100MB:%s\n\n", Dim, Reset)
 
 		for i, f := range analysis.LargeFiles {
 			if i >= 15 {
-				fmt.Printf("  %s... and %d more%s\n", Dim, len(analysis.LargeFiles)-15, Reset)
+				fmt.Fprintf(w, "  %s... and %d more%s\n", Dim, len(analysis.LargeFiles)-15, Reset)
 				break
 			}
 			sizeStr := FormatSize(f.Size)
 			path := shortenPath(f.Path, 55)
 			age := FormatAge(f.Age)
-			fmt.Printf("  %s%8s%s  %s%6s%s  %s%s%s\n",
+			fmt.Fprintf(w, "  %s%8s%s  %s%6s%s  %s%s%s%s\n",
 				Red, sizeStr, Reset,
 				Dim, age, Reset,
-				Reset, path, Reset)
+				Reset, path, Reset, linkNote(f)+archiveNote(f))
 		}
 	}
 
 	// Downloads
 	if len(analysis.Downloads) > 0 {
-		printSection("DOWNLOADS FOLDER")
-		fmt.Printf("  %sLarge files in ~/Downloads:%s\n\n", Dim, Reset)
+		printSection(w, "DOWNLOADS FOLDER")
+		fmt.Fprintf(w, "  %sLarge files in ~/Downloads:%s\n\n", Dim, Reset)
 
 		for _, f := range analysis.Downloads {
 			sizeStr := FormatSize(f.Size)
@@ -117,77 +203,209 @@ func PrintAnalysis(analysis *analyzer.Analysis) {
 				name = name[:47] + "..."
 			}
 			age := FormatAge(f.Age)
-			fmt.Printf("  %s%8s%s  %s%6s%s  %s%s%s\n",
+			fmt.Fprintf(w, "  %s%8s%s  %s%6s%s  %s%s%s%s\n",
 				Magenta, sizeStr, Reset,
 				Dim, age, Reset,
-				Reset, name, Reset)
+				Reset, name, Reset, linkNote(f)+archiveNote(f))
+		}
+	}
+
+	// Installers superseded by a newer download
+	if len(analysis.OldInstallers) > 0 {
+		printSection(w, "OLD INSTALLERS")
+		fmt.Fprintf(w, "  %sOlder versions of installers in ~/Downloads; the newest is kept:%s\n\n", Dim, Reset)
+
+		for _, g := range analysis.OldInstallers {
+			for _, f := range g.Older {
+				fmt.Fprintf(w, "  %s%8s%s  %s%6s%s  %s%s\n",
+					Magenta, FormatSize(f.Size), Reset,
+					Dim, FormatAge(f.Age), Reset,
+					filepath.Base(f.Path), linkNote(f))
+			}
+			fmt.Fprintf(w, "  %8s  %s→ keep %s%s\n", "", Green, filepath.Base(g.Keep.Path), Reset)
 		}
 	}
 
+	if analysis.RecentlyUsed > 0 {
+		fmt.Fprintf(w, "\n  %sKept %d files opened in the last 30 days out of these lists (--exclude-recent-access)%s\n", Dim, analysis.RecentlyUsed, Reset)
+	}
+	if analysis.KeptRecent > 0 {
+		fmt.Fprintf(w, "\n  %sKept %d recent files out of these lists (--keep-recent)%s\n", Dim, analysis.KeptRecent, Reset)
+	}
+
 	// Old files
 	if len(analysis.OldFiles) > 0 {
-		printSection("OLD FILES")
-		fmt.Printf("  %sLarge files not modified in over a year:%s\n\n", Dim, Reset)
+		printSection(w, "OLD FILES")
+		fmt.Fprintf(w, "  %sLarge files not modified in over a year:%s\n\n", Dim, Reset)
 
 		for _, f := range analysis.OldFiles {
 			sizeStr := FormatSize(f.Size)
 			path := shortenPath(f.Path, 50)
 			age := FormatAge(f.Age)
-			fmt.Printf("  %s%8s%s  %s%6s%s  %s%s%s\n",
+			fmt.Fprintf(w, "  %s%8s%s  %s%6s%s  %s%s%s%s\n",
 				Blue, sizeStr, Reset,
 				Yellow, age, Reset,
-				Dim, path, Reset)
+				Dim, path, Reset, linkNote(f)+archiveNote(f))
 		}
 	}
 
+	// Size band
+	if analysis.SizeBandCount > 0 {
+		printSection(w, "SIZE RANGE")
+		fmt.Fprintf(w, "  %s%d files in the requested size range add up to %s%s%s:%s\n\n",
+			Dim, analysis.SizeBandCount, Green, FormatSize(analysis.SizeBandTotal), Dim, Reset)
+
+		for _, f := range analysis.SizeBand {
+			sizeStr := FormatSize(f.Size)
+			path := shortenPath(f.Path, 55)
+			age := FormatAge(f.Age)
+			fmt.Fprintf(w, "  %s%8s%s  %s%6s%s  %s%s%s%s\n",
+				Cyan, sizeStr, Reset,
+				Dim, age, Reset,
+				Reset, path, Reset, linkNote(f)+archiveNote(f))
+		}
+		if analysis.SizeBandCount > len(analysis.SizeBand) {
+			fmt.Fprintf(w, "  %s... and %d more%s\n", Dim, analysis.SizeBandCount-len(analysis.SizeBand), Reset)
+		}
+	}
+
+	// Directories of many small files
+	if len(analysis.SmallFileDirs) > 0 {
+		printSection(w, "MANY SMALL FILES")
+		fmt.Fprintf(w, "  %sDirectories where files too small to list add up:%s\n\n", Dim, Reset)
+
+		for _, d := range analysis.SmallFileDirs {
+			fmt.Fprintf(w, "  %s%8s%s  %s%7d files%s  %s%s%s\n",
+				Yellow, FormatSize(d.Size), Reset,
+				Dim, d.Files, Reset,
+				Reset, shortenPath(d.Path, 50), Reset)
+		}
+		fmt.Fprintf(w, "\n  %sCheck what wrote them before deleting the directory.%s\n", Dim, Reset)
+	}
+
 	// Duplicates
 	if len(analysis.DuplicateGroups) > 0 {
-		printSection("DUPLICATE FILES")
-		fmt.Printf("  %sFiles with identical content:%s\n\n", Dim, Reset)
+		printSection(w, "DUPLICATE FILES")
+		fmt.Fprintf(w, "  %s%s%s reclaimable by keeping one copy of each %s(%d groups)%s\n\n",
+			Bold+Green, FormatSize(analysis.DuplicateReclaimable), Reset, Dim, len(analysis.DuplicateGroups), Reset)
 
-		for _, group := range analysis.DuplicateGroups {
-			fmt.Printf("  %s%s each × %d copies%s\n",
+		for i, group := range analysis.DuplicateGroups {
+			if i >= maxDuplicateGroupsShown {
+				fmt.Fprintf(w, "  %s... and %d more groups%s\n\n", Dim, len(analysis.DuplicateGroups)-maxDuplicateGroupsShown, Reset)
+				break
+			}
+			fmt.Fprintf(w, "  %s%s each × %d copies%s\n",
 				Cyan, FormatSize(group.Size), len(group.Files), Reset)
 			for _, path := range group.Files {
-				fmt.Printf("    %s%s%s\n", Dim, shortenPath(path, 60), Reset)
+				fmt.Fprintf(w, "    %s%s%s\n", Dim, shortenPath(path, 60), Reset)
 			}
-			fmt.Println()
+			fmt.Fprintln(w)
 		}
 	}
 
-	fmt.Println()
+	// Large files committed to git
+	if len(analysis.TrackedFiles) > 0 {
+		printSection(w, "TRACKED IN GIT")
+		fmt.Fprintf(w, "  %sLarge files committed to a repository; untrack them rather than delete:%s\n\n", Dim, Reset)
+
+		for _, f := range analysis.TrackedFiles {
+			fmt.Fprintf(w, "  %s%8s%s  %s%s%s\n",
+				Yellow, FormatSize(f.Size), Reset,
+				Reset, shortenPath(f.Path, 55), Reset)
+			fmt.Fprintf(w, "  %8s  %s→ %s%s\n", "", Green, f.Advice, Reset)
+		}
+	}
+
+	// Disk images in use, which no other section lists
+	if len(analysis.MountedImages) > 0 {
+		printSection(w, "MOUNTED DISK IMAGES")
+		fmt.Fprintf(w, "  %sIn use right now; deleting one can corrupt its volume:%s\n\n", Dim, Reset)
+
+		for _, m := range analysis.MountedImages {
+			fmt.Fprintf(w, "  %s%8s%s  %s\n",
+				Yellow, FormatSize(m.Size), Reset,
+				shortenPath(m.Path, 55))
+			fmt.Fprintf(w, "  %8s  %s→ %s%s\n", "", Green, m.Advice, Reset)
+		}
+	}
+
+	fmt.Fprintln(w)
+}
+
+// topExtensions is how many extensions the space-by-type section lists
+const topExtensions = 10
+
+// linkNote marks a hard-linked file, whose space only comes back once every link is gone
+func linkNote(f analyzer.FileReport) string {
+	if f.HardLinks < 2 {
+		return ""
+	}
+	return fmt.Sprintf("  %s(hard-linked ×%d, counted once)%s", Dim, f.HardLinks, Reset)
+}
+
+// archiveNote puts what's inside a peeked archive on the line below it
+func archiveNote(f analyzer.FileReport) string {
+	if f.Archive == nil {
+		return ""
+	}
+	return fmt.Sprintf("\n  %8s  %scontains: %s%s", "", Dim, ArchiveSummary(f.Archive), Reset)
+}
+
+// archiveEntries is how many top-level entries ArchiveSummary names
+const archiveEntries = 4
+
+// ArchiveSummary lists the largest top-level entries of an archive, noting
+// those that also sit next to it on disk, e.g.
+// "project_backup/ (2.1 GB, also on disk), notes.txt (4 KB)"
+func ArchiveSummary(c *scanner.ArchiveContents) string {
+	if len(c.Entries) == 0 {
+		return "nothing"
+	}
+	var parts []string
+	for i, e := range c.Entries {
+		if i == archiveEntries {
+			parts = append(parts, fmt.Sprintf("%d more", len(c.Entries)-archiveEntries))
+			break
+		}
+		note := FormatSize(e.Size)
+		if e.OnDisk {
+			note += ", also on disk"
+		}
+		parts = append(parts, fmt.Sprintf("%s (%s)", e.Name, note))
+	}
+	return strings.Join(parts, ", ")
 }
 
-func PrintLLMRecommendations(recommendations string) {
-	printSection("AI RECOMMENDATIONS")
-	fmt.Println()
-	fmt.Println(recommendations)
-	fmt.Println()
+func PrintLLMRecommendations(w io.Writer, recommendations string) {
+	printSection(w, "AI RECOMMENDATIONS")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, recommendations)
+	fmt.Fprintln(w)
 }
 
-func PrintError(msg string) {
-	fmt.Printf("\n%s⚠ %s%s\n", Yellow, msg, Reset)
+func PrintError(w io.Writer, msg string) {
+	fmt.Fprintf(w, "\n%s⚠ %s%s\n", Yellow, msg, Reset)
 }
 
-func PrintInfo(msg string) {
-	fmt.Printf("%s%s%s\n", Dim, msg, Reset)
+func PrintInfo(w io.Writer, msg string) {
+	fmt.Fprintf(w, "%s%s%s\n", Dim, msg, Reset)
 }
 
-func PrintDim(msg string) {
-	fmt.Printf("%s%s%s\n", Dim, msg, Reset)
+func PrintDim(w io.Writer, msg string) {
+	fmt.Fprintf(w, "%s%s%s\n", Dim, msg, Reset)
 }
 
-func printHeader(title, subtitle string) {
+func printHeader(w io.Writer, title, subtitle string) {
 	width := 60
-	fmt.Println()
-	fmt.Printf("%s%s%s\n", Bold+Cyan, strings.Repeat("─", width), Reset)
-	fmt.Printf("%s  🧹 %s%s\n", Bold+Cyan, title, Reset)
-	fmt.Printf("%s  %s%s\n", Dim, subtitle, Reset)
-	fmt.Printf("%s%s%s\n", Bold+Cyan, strings.Repeat("─", width), Reset)
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "%s%s%s\n", Bold+Cyan, strings.Repeat("─", width), Reset)
+	fmt.Fprintf(w, "%s  🧹 %s%s\n", Bold+Cyan, title, Reset)
+	fmt.Fprintf(w, "%s  %s%s\n", Dim, subtitle, Reset)
+	fmt.Fprintf(w, "%s%s%s\n", Bold+Cyan, strings.Repeat("─", width), Reset)
 }
 
-func printSection(title string) {
-	fmt.Printf("\n%s%s ─── %s %s%s\n\n", Bold, Cyan, title, strings.Repeat("─", 40-len(title)), Reset)
+func printSection(w io.Writer, title string) {
+	fmt.Fprintf(w, "\n%s%s ─── %s %s%s\n\n", Bold, Cyan, title, strings.Repeat("─", 40-len(title)), Reset)
 }
 
 func shortenPath(path string, maxLen int) string {
@@ -0,0 +1,69 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"forge-dust/analyzer"
+)
+
+// NextStep is a command that acts on the report, and why to run it
+type NextStep struct {
+	Command string
+	Why     string
+}
+
+// dockerImages are the names of Docker Desktop's disk image, which grows as
+// images and containers pile up and never shrinks by deleting files
+var dockerImages = map[string]bool{"Docker.raw": true, "Docker.qcow2": true}
+
+// NextSteps suggests how to act on what analysis found, most useful first.
+// Nothing found means nothing to suggest.
+func NextSteps(analysis *analyzer.Analysis) []NextStep {
+	var steps []NextStep
+	if analysis.TotalReclaimable == 0 && len(analysis.SystemCaches) == 0 && len(analysis.TrackedFiles) == 0 {
+		return nil
+	}
+	steps = append(steps, NextStep{"forge dust", "Clean up interactively, a category at a time"})
+
+	if _, commands := CleanupScript(analysis); commands > 0 {
+		steps = append(steps, NextStep{"forge-dust --script cleanup.sh", fmt.Sprintf("Write the %d safe cache cleanups to a script to review and run", commands)})
+	}
+
+	seen := make(map[string]bool)
+	for _, c := range analysis.GlobalCaches {
+		if !seen[c.CleanCommand] {
+			seen[c.CleanCommand] = true
+			steps = append(steps, NextStep{c.CleanCommand, "Empty the " + c.Description})
+		}
+	}
+
+	if len(analysis.Trash) > 0 {
+		steps = append(steps, NextStep{"forge-dust --empty-trash", "Empty the Trash"})
+	}
+
+	for _, f := range analysis.LargeFiles {
+		if dockerImages[filepath.Base(f.Path)] {
+			steps = append(steps, NextStep{"docker system prune", fmt.Sprintf("Docker's disk image is %s; prune unused containers, images and build cache", FormatSize(f.Size))})
+			break
+		}
+	}
+	return steps
+}
+
+// PrintNextSteps closes the report with the commands to act on it
+func PrintNextSteps(w io.Writer, steps []NextStep) {
+	if len(steps) == 0 {
+		return
+	}
+	printSection(w, "NEXT STEPS")
+	width := 0
+	for _, s := range steps {
+		width = max(width, len(s.Command))
+	}
+	for _, s := range steps {
+		fmt.Fprintf(w, "  %s%-*s%s  %s%s%s\n", Green, width, s.Command, Reset, Dim, s.Why, Reset)
+	}
+	fmt.Fprintln(w)
+}
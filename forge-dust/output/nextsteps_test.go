@@ -0,0 +1,55 @@
+package output
+
+import (
+	"slices"
+	"testing"
+
+	"forge-dust/analyzer"
+)
+
+func TestNextStepsFollowFindings(t *testing.T) {
+	goCache := analyzer.CacheReport{Path: "/Users/me/go/pkg/mod", Size: 2 << 30, Type: "go", Description: "Go module cache", CleanCommand: "go clean -modcache"}
+	brewCache := analyzer.CacheReport{Path: "/Users/me/Library/Caches/Homebrew", Size: 1 << 30, Type: "brew", Description: "Homebrew download cache", CleanCommand: "brew cleanup --prune=all"}
+	dockerImage := analyzer.FileReport{Path: "/Users/me/Library/Containers/com.docker.docker/Data/vms/0/data/Docker.raw", Size: 60 << 30}
+	trash := analyzer.CacheReport{Path: "/Users/me/.Trash", Size: 3 << 30, Type: "trash"}
+
+	tests := []struct {
+		name     string
+		analysis *analyzer.Analysis
+		want     []string
+	}{
+		{"nothing found", &analyzer.Analysis{}, nil},
+		{
+			"old files only",
+			&analyzer.Analysis{OldFiles: []analyzer.FileReport{{Path: "/Users/me/old.iso", Size: 1 << 30}}, TotalReclaimable: 1 << 30},
+			[]string{"forge dust"},
+		},
+		{
+			"brew cache",
+			&analyzer.Analysis{GlobalCaches: []analyzer.CacheReport{brewCache}, TotalReclaimable: brewCache.Size},
+			[]string{"forge dust", "forge-dust --script cleanup.sh", "brew cleanup --prune=all"},
+		},
+		{
+			"the same cache twice",
+			&analyzer.Analysis{GlobalCaches: []analyzer.CacheReport{goCache, goCache}, TotalReclaimable: 2 * goCache.Size},
+			[]string{"forge dust", "forge-dust --script cleanup.sh", "go clean -modcache"},
+		},
+		{
+			"docker and trash",
+			&analyzer.Analysis{LargeFiles: []analyzer.FileReport{dockerImage}, Trash: []analyzer.CacheReport{trash}, TotalReclaimable: dockerImage.Size + trash.Size},
+			[]string{"forge dust", "forge-dust --empty-trash", "docker system prune"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, s := range NextSteps(tt.analysis) {
+				got = append(got, s.Command)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("NextSteps() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
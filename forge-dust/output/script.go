@@ -0,0 +1,65 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+
+	"forge-dust/analyzer"
+)
+
+// scriptHeader starts every cleanup script; commands only run with DRY_RUN=0
+const scriptHeader = `#!/bin/sh
+# Cleanup script generated by forge-dust.
+#
+# Only caches that rebuild themselves are included: package manager caches
+# are emptied with their own commands, project caches are removed.
+# Large, old and downloaded files are left for you to decide.
+#
+# Review it, then run:  DRY_RUN=0 sh cleanup.sh
+set -e
+
+DRY_RUN=${DRY_RUN:-1}
+
+run() {
+	echo "+ $*"
+	if [ "$DRY_RUN" = "0" ]; then
+		"$@"
+	fi
+}
+`
+
+// CleanupScript renders the safe findings as a reviewable shell script, with
+// one commented command per cache. It does not need the LLM.
+func CleanupScript(analysis *analyzer.Analysis) (string, int) {
+	var sb strings.Builder
+	commands := 0
+	sb.WriteString(scriptHeader)
+
+	if len(analysis.GlobalCaches) > 0 {
+		sb.WriteString("\n# Global caches\n")
+		for _, c := range analysis.GlobalCaches {
+			sb.WriteString(fmt.Sprintf("\n# %s, %s (%s)\nrun %s\n",
+				c.Description, FormatSize(c.Size), c.Path, c.CleanCommand))
+			commands++
+		}
+	}
+
+	if len(analysis.CacheDirs) > 0 {
+		sb.WriteString("\n# Project caches\n")
+		for _, c := range analysis.CacheDirs {
+			sb.WriteString(fmt.Sprintf("\n# %s, %s\nrun rm -rf -- %s\n",
+				c.Description, FormatSize(c.Size), shellQuote(c.Path)))
+			commands++
+		}
+	}
+
+	if commands == 0 {
+		sb.WriteString("\n# Nothing safe to clean was found.\n")
+	}
+	return sb.String(), commands
+}
+
+// shellQuote wraps s in single quotes for sh
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
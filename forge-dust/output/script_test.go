@@ -0,0 +1,53 @@
+package output
+
+import (
+	"strings"
+	"testing"
+
+	"forge-dust/analyzer"
+)
+
+func TestCleanupScriptCommands(t *testing.T) {
+	analysis := &analyzer.Analysis{
+		GlobalCaches: []analyzer.CacheReport{
+			{Path: "/Users/me/go/pkg/mod", Size: 2 << 30, Type: "go", Description: "Go module cache", CleanCommand: "go clean -modcache"},
+		},
+		CacheDirs: []analyzer.CacheReport{
+			{Path: "/Users/me/src/app/node_modules", Size: 300 << 20, Type: "node_modules", Description: "Node.js dependencies"},
+			{Path: "/Users/me/src/it's/target", Size: 1 << 30, Type: "target", Description: "Rust build output"},
+		},
+		// Never scripted: deleting these is the user's call
+		LargeFiles: []analyzer.FileReport{{Path: "/Users/me/Movies/trip.mov", Size: 4 << 30}},
+		Downloads:  []analyzer.FileReport{{Path: "/Users/me/Downloads/old.dmg", Size: 500 << 20}},
+	}
+
+	script, n := CleanupScript(analysis)
+
+	if n != 3 {
+		t.Errorf("CleanupScript() commands = %d, want 3", n)
+	}
+	wants := []string{
+		"#!/bin/sh\n",
+		"set -e\n",
+		"DRY_RUN=${DRY_RUN:-1}\n",
+		`echo "+ $*"`,
+		"run go clean -modcache\n",
+		"run rm -rf -- '/Users/me/src/app/node_modules'\n",
+		`run rm -rf -- '/Users/me/src/it'\''s/target'` + "\n",
+		"# Go module cache, 2.0 GB",
+	}
+	for _, want := range wants {
+		if !strings.Contains(script, want) {
+			t.Errorf("CleanupScript() missing %q in:\n%s", want, script)
+		}
+	}
+	for _, unwanted := range []string{"trip.mov", "old.dmg"} {
+		if strings.Contains(script, unwanted) {
+			t.Errorf("CleanupScript() includes %s, which is not a safe category", unwanted)
+		}
+	}
+
+	if again, _ := CleanupScript(analysis); again != script {
+		t.Error("CleanupScript() is not deterministic")
+	}
+}
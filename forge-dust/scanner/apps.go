@@ -0,0 +1,98 @@
+package scanner
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// App is an installed application bundle
+type App struct {
+	Path     string
+	Size     int64
+	LastUsed time.Time // Zero if the system doesn't record when it was last opened
+}
+
+// Name is the app's name without the .app suffix
+func (a App) Name() string {
+	return strings.TrimSuffix(filepath.Base(a.Path), ".app")
+}
+
+// AppLastUsed reports when the app at path was last opened. It's a variable
+// so tests can stand in for Spotlight.
+var AppLastUsed = spotlightLastUsed
+
+// AppDirs returns where macOS keeps installed applications
+func AppDirs(home string) []string {
+	return []string{"/Applications", filepath.Join(home, "Applications")}
+}
+
+// FindApps lists the .app bundles in dirs, and in the folders directly inside
+// them such as /Applications/Utilities, sizing them with workers in parallel.
+// Bundles are sized as directories; nothing inside them is ever listed alone.
+func FindApps(dirs []string, workers int) []App {
+	var paths []string
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			path := filepath.Join(dir, e.Name())
+			if isApp(e) {
+				paths = append(paths, path)
+				continue
+			}
+			if !e.IsDir() {
+				continue
+			}
+			inner, err := os.ReadDir(path)
+			if err != nil {
+				continue
+			}
+			for _, ie := range inner {
+				if isApp(ie) {
+					paths = append(paths, filepath.Join(path, ie.Name()))
+				}
+			}
+		}
+	}
+
+	sizes := GetDirSizes(paths, workers)
+	apps := make([]App, len(paths))
+	for i, path := range paths {
+		apps[i] = App{Path: path, Size: sizes[i], LastUsed: AppLastUsed(path)}
+	}
+	return apps
+}
+
+// isApp reports whether e is an application bundle
+func isApp(e os.DirEntry) bool {
+	return e.IsDir() && strings.HasSuffix(e.Name(), ".app")
+}
+
+// spotlightLastUsed asks Spotlight for the app's last-opened date. It knows
+// nothing off macOS, or when indexing is off.
+func spotlightLastUsed(path string) time.Time {
+	if runtime.GOOS != "darwin" {
+		return time.Time{}
+	}
+	out, err := exec.Command("mdls", "-raw", "-name", "kMDItemLastUsedDate", path).Output()
+	if err != nil {
+		return time.Time{}
+	}
+	return parseMdlsDate(string(out))
+}
+
+// parseMdlsDate reads a date printed by `mdls -raw`, which is "(null)" when
+// the attribute isn't set
+func parseMdlsDate(out string) time.Time {
+	t, err := time.Parse("2006-01-02 15:04:05 -0700", strings.TrimSpace(out))
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
@@ -0,0 +1,69 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFindAppsSizesBundles(t *testing.T) {
+	apps := t.TempDir()
+	files := map[string]int{
+		"Big.app/Contents/MacOS/Big":             3000,
+		"Big.app/Contents/Resources/icon.icns":   1000,
+		"Utilities/Tool.app/Contents/MacOS/Tool": 500,
+		"Utilities/Deeper/Hidden.app/Contents/x": 100, // too deep to be an install
+		"Utilities/readme.txt":                   10,
+		"notes.txt":                              10,
+	}
+	for rel, size := range files {
+		path := filepath.Join(apps, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	opened := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	defer func(orig func(string) time.Time) { AppLastUsed = orig }(AppLastUsed)
+	AppLastUsed = func(path string) time.Time {
+		if filepath.Base(path) == "Big.app" {
+			return opened
+		}
+		return time.Time{}
+	}
+
+	got := map[string]App{}
+	for _, app := range FindApps([]string{apps, filepath.Join(apps, "missing")}, 2) {
+		got[app.Name()] = app
+	}
+	if len(got) != 2 {
+		t.Fatalf("FindApps() found %v, want Big and Tool", got)
+	}
+	if big := got["Big"]; big.Size != 4000 || !big.LastUsed.Equal(opened) {
+		t.Errorf("Big = %+v, want 4000 bytes opened %v", big, opened)
+	}
+	if tool := got["Tool"]; tool.Size != 500 || !tool.LastUsed.IsZero() {
+		t.Errorf("Tool = %+v, want 500 bytes and no last-used date", tool)
+	}
+}
+
+func TestParseMdlsDate(t *testing.T) {
+	tests := []struct {
+		out  string
+		want time.Time
+	}{
+		{"2025-11-03 08:15:42 +0000", time.Date(2025, 11, 3, 8, 15, 42, 0, time.UTC)},
+		{"(null)", time.Time{}},
+		{"", time.Time{}},
+	}
+
+	for _, tt := range tests {
+		if got := parseMdlsDate(tt.out); !got.Equal(tt.want) {
+			t.Errorf("parseMdlsDate(%q) = %v, want %v", tt.out, got, tt.want)
+		}
+	}
+}
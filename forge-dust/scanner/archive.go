@@ -0,0 +1,123 @@
+package scanner
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ArchiveContents is what an archive holds at its top level
+type ArchiveContents struct {
+	Entries []ArchiveEntry // Largest first
+	Size    int64          // Uncompressed, everything in the archive
+}
+
+// ArchiveEntry is a top-level file or directory inside an archive
+type ArchiveEntry struct {
+	Name   string // Ends in / for a directory
+	Size   int64  // Uncompressed, everything beneath it for a directory
+	OnDisk bool   // Something of the same name sits next to the archive, as if already extracted
+}
+
+// ErrNotArchive is returned by PeekArchive for files it can't read into
+var ErrNotArchive = errors.New("not a zip or tar.gz archive")
+
+// IsArchive reports whether PeekArchive can read path, going by its name
+func IsArchive(path string) bool {
+	name := strings.ToLower(path)
+	return strings.HasSuffix(name, ".zip") || strings.HasSuffix(name, ".tar.gz") || strings.HasSuffix(name, ".tgz")
+}
+
+// PeekArchive lists an archive's top-level contents without extracting it.
+// A zip's listing is read from its index; a tar.gz has none, so it is
+// decompressed from start to end, though nothing is written.
+func PeekArchive(archive string) (*ArchiveContents, error) {
+	sizes := make(map[string]int64)
+	add := func(name string, isDir bool, size int64) {
+		name = strings.TrimPrefix(path.Clean("/"+name), "/")
+		top, _, nested := strings.Cut(name, "/")
+		if top == "" || top == "__MACOSX" {
+			return
+		}
+		if nested || isDir {
+			top += "/"
+		}
+		sizes[top] += size
+	}
+
+	var err error
+	switch lower := strings.ToLower(archive); {
+	case strings.HasSuffix(lower, ".zip"):
+		err = peekZip(archive, add)
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		err = peekTarGz(archive, add)
+	default:
+		err = ErrNotArchive
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	contents := &ArchiveContents{}
+	dir := filepath.Dir(archive)
+	for name, size := range sizes {
+		_, statErr := os.Lstat(filepath.Join(dir, strings.TrimSuffix(name, "/")))
+		contents.Entries = append(contents.Entries, ArchiveEntry{Name: name, Size: size, OnDisk: statErr == nil})
+		contents.Size += size
+	}
+	sort.Slice(contents.Entries, func(i, j int) bool {
+		if contents.Entries[i].Size != contents.Entries[j].Size {
+			return contents.Entries[i].Size > contents.Entries[j].Size
+		}
+		return contents.Entries[i].Name < contents.Entries[j].Name
+	})
+	return contents, nil
+}
+
+// peekZip passes each entry in a zip's index to add
+func peekZip(archive string, add func(name string, isDir bool, size int64)) error {
+	r, err := zip.OpenReader(archive)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	for _, f := range r.File {
+		add(f.Name, f.FileInfo().IsDir(), int64(f.UncompressedSize64))
+	}
+	return nil
+}
+
+// peekTarGz reads through a gzipped tar, passing each header to add
+func peekTarGz(archive string, add func(name string, isDir bool, size int64)) error {
+	file, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		add(hdr.Name, hdr.Typeflag == tar.TypeDir, hdr.Size)
+	}
+}
@@ -0,0 +1,112 @@
+package scanner
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// archiveFiles is the fixture both archive formats are built from
+var archiveFiles = []struct {
+	name string
+	data string
+}{
+	{"project_backup/", ""},
+	{"project_backup/main.go", "package main\n"},
+	{"project_backup/docs/notes.md", "# Notes\n\nKeep these.\n"},
+	{"readme.txt", "hello"},
+	{"__MACOSX/._readme.txt", "resource fork"},
+}
+
+var wantArchive = &ArchiveContents{
+	Entries: []ArchiveEntry{
+		{Name: "project_backup/", Size: 34, OnDisk: true},
+		{Name: "readme.txt", Size: 5},
+	},
+	Size: 39,
+}
+
+func TestPeekZip(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "backup.zip")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	for _, af := range archiveFiles {
+		w, err := zw.Create(af.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(af.data))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	// Already extracted next to the archive
+	if err := os.Mkdir(filepath.Join(dir, "project_backup"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := PeekArchive(path)
+	if err != nil {
+		t.Fatalf("PeekArchive() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, wantArchive) {
+		t.Errorf("PeekArchive() = %+v, want %+v", got, wantArchive)
+	}
+}
+
+func TestPeekTarGz(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "backup.tar.gz")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for _, af := range archiveFiles {
+		hdr := &tar.Header{Name: "./" + af.name, Mode: 0644, Size: int64(len(af.data)), Typeflag: tar.TypeReg}
+		if af.data == "" {
+			hdr.Typeflag = tar.TypeDir
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		tw.Write([]byte(af.data))
+	}
+	tw.Close()
+	gz.Close()
+	f.Close()
+	if err := os.Mkdir(filepath.Join(dir, "project_backup"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := PeekArchive(path)
+	if err != nil {
+		t.Fatalf("PeekArchive() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, wantArchive) {
+		t.Errorf("PeekArchive() = %+v, want %+v", got, wantArchive)
+	}
+}
+
+func TestPeekArchiveRejectsOthers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "movie.mov")
+	if err := os.WriteFile(path, []byte("not an archive"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if IsArchive(path) {
+		t.Errorf("IsArchive(%q) = true, want false", path)
+	}
+	if _, err := PeekArchive(path); err != ErrNotArchive {
+		t.Errorf("PeekArchive(%q) error = %v, want ErrNotArchive", path, err)
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !darwin && !linux
+
+package scanner
+
+import (
+	"os"
+	"time"
+)
+
+// accessTime isn't read here, so files are judged by when they were modified
+func accessTime(info os.FileInfo) (time.Time, bool) {
+	return time.Time{}, false
+}
@@ -0,0 +1,145 @@
+package scanner
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"forge-shared/datadir"
+)
+
+// CacheDir describes a kind of directory that tools rebuild, so it's safe
+// to delete wherever it turns up
+type CacheDir struct {
+	Name        string   `json:"name"` // Directory name, a pattern such as bazel-*, or the path ending in it, such as .m2/repository
+	Description string   `json:"description"`
+	Risk        string   `json:"risk"`       // low, medium or high; low if unset
+	Reversible  bool     `json:"reversible"` // Rebuilt on demand; false unless set
+	Rebuild     string   `json:"rebuild,omitempty"`
+	Beside      []string `json:"beside,omitempty"` // If set, one of these must sit next to it: a name or pattern, or package.json:<script> for a package.json with that script
+}
+
+// embeddedCacheDirs is the curated list shipped in the binary
+//
+//go:embed cachedirs.json
+var embeddedCacheDirs []byte
+
+// CacheDirs are the known cache directories, the first match winning
+var CacheDirs = mustParseCacheDirs(embeddedCacheDirs)
+
+// mustParseCacheDirs parses the embedded list, which tests check is valid
+func mustParseCacheDirs(data []byte) []CacheDir {
+	dirs, err := parseCacheDirs(data)
+	if err != nil {
+		panic("scanner: embedded cachedirs.json: " + err.Error())
+	}
+	return dirs
+}
+
+// parseCacheDirs reads a JSON list of cache directories, checking each
+func parseCacheDirs(data []byte) ([]CacheDir, error) {
+	var dirs []CacheDir
+	if err := json.Unmarshal(data, &dirs); err != nil {
+		return nil, err
+	}
+	for i, d := range dirs {
+		if d.Name == "" {
+			return nil, fmt.Errorf("entry %d has no name", i+1)
+		}
+		for _, pattern := range append([]string{d.Name}, d.Beside...) {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("%s: bad pattern %q", d.Name, pattern)
+			}
+		}
+		switch d.Risk {
+		case "":
+			dirs[i].Risk = "low"
+		case "low", "medium", "high":
+		default:
+			return nil, fmt.Errorf("%s: risk %q is not low, medium or high", d.Name, d.Risk)
+		}
+	}
+	return dirs, nil
+}
+
+// UserCacheDirsPath returns cachedirs.json in the forge data directory,
+// where users list cache directories of their own
+func UserCacheDirsPath() string {
+	return datadir.Path("cachedirs.json")
+}
+
+// LoadCacheDirs adds the cache directories listed in the file at path ahead
+// of the built-in ones, so an entry of the same name replaces the built-in
+// one. A missing file adds nothing.
+func LoadCacheDirs(path string) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	dirs, err := parseCacheDirs(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	CacheDirs = append(dirs, CacheDirs...)
+	return nil
+}
+
+// ClassifyCacheDir reports which known cache directory dir is, by its name
+// and, for names that are only caches in some projects, what sits beside it
+func ClassifyCacheDir(dir string) (CacheDir, bool) {
+	elems := strings.Split(filepath.ToSlash(dir), "/")
+	for _, d := range CacheDirs {
+		n := strings.Count(d.Name, "/") + 1
+		name := strings.Join(elems[max(0, len(elems)-n):], "/")
+		if ok, _ := path.Match(d.Name, name); ok && besideAny(filepath.Dir(dir), d.Beside) {
+			return d, true
+		}
+	}
+	return CacheDir{}, false
+}
+
+// besideAny reports whether one of patterns matches a name in parent, or
+// there are no patterns to match
+func besideAny(parent string, patterns []string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	entries, err := os.ReadDir(parent)
+	if err != nil {
+		return false
+	}
+	return slices.ContainsFunc(entries, func(e os.DirEntry) bool {
+		return slices.ContainsFunc(patterns, func(pattern string) bool {
+			pattern, script, isScript := strings.Cut(pattern, ":")
+			if ok, _ := path.Match(pattern, e.Name()); !ok {
+				return false
+			}
+			return !isScript || hasScript(filepath.Join(parent, e.Name()), script)
+		})
+	})
+}
+
+// hasScript reports whether the package.json at path defines script, which
+// for build means the project does build into the directory beside it
+func hasScript(path, script string) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	var pkg struct {
+		Scripts map[string]string `json:"scripts"`
+	}
+	if json.Unmarshal(data, &pkg) != nil {
+		return false
+	}
+	_, ok := pkg.Scripts[script]
+	return ok
+}
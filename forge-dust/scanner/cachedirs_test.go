@@ -0,0 +1,186 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEmbeddedCacheDirs(t *testing.T) {
+	dirs, err := parseCacheDirs(embeddedCacheDirs)
+	if err != nil {
+		t.Fatalf("embedded cachedirs.json: %v", err)
+	}
+	if len(dirs) < 40 {
+		t.Errorf("embedded list has %d entries, want the curated 40 or more", len(dirs))
+	}
+}
+
+// tree creates each path under root, as a directory if it ends in /
+func tree(t *testing.T, root string, paths ...string) {
+	t.Helper()
+	for _, p := range paths {
+		full := filepath.Join(root, p)
+		if p[len(p)-1] == '/' {
+			if err := os.MkdirAll(full, 0755); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestClassifyCacheDir(t *testing.T) {
+	root := t.TempDir()
+	tree(t, root,
+		"app/node_modules/",
+		"flutter_app/.dart_tool/", "flutter_app/pubspec.yaml",
+		"mono/bazel-out/", "mono/bazel-mono/", "mono/MODULE.bazel",
+		"game/Library/", "game/Temp/", "game/Assets/", "game/ProjectSettings/",
+		"shooter/Intermediate/", "shooter/DerivedDataCache/", "shooter/Shooter.uproject",
+		"android/app/.cxx/", "android/app/build.gradle.kts", "android/app/build/", "android/.gradle/",
+		"home/Library/", "home/Temp/", "docs/Intermediate/", "nobazel/bazel-out/",
+		"home/.Trash/",
+		"home/.m2/repository/", "home/.m2/settings.xml", "svn/repository/",
+		"web/.yarn/cache/", "web/.yarn/releases/", "web/cache/",
+	)
+
+	tests := []struct {
+		path        string
+		wantDesc    string // "" when it isn't a cache
+		wantRebuild string
+	}{
+		{"app/node_modules", "npm packages (can reinstall)", "npm install"},
+		{"flutter_app/.dart_tool", "Dart/Flutter tool cache", "flutter pub get"},
+		{"mono/bazel-out", "Bazel output", "bazel build"},
+		{"mono/bazel-mono", "Bazel output", "bazel build"},
+		{"game/Library", "Unity import cache", "reopening the project in Unity (the reimport can take a while)"},
+		{"game/Temp", "Unity temporary files", ""},
+		{"shooter/Intermediate", "Unreal Engine intermediate build files", "rebuilding the project in Unreal"},
+		{"shooter/DerivedDataCache", "Unreal Engine derived data", "reopening the project in Unreal"},
+		{"android/app/.cxx", "Android NDK build output", "./gradlew build"},
+		{"android/app/build", "build output", ""},
+		{"android/.gradle", "Gradle cache", "./gradlew build"},
+		{"home/.m2/repository", "Maven repository cache", ""},
+		{"web/.yarn/cache", "yarn cache", "yarn install"},
+
+		// Only caches inside the right kind of project
+		{"home/Library", "", ""},
+		{"home/Temp", "", ""},
+		{"docs/Intermediate", "", ""},
+		{"nobazel/bazel-out", "", ""},
+		{"home/.Trash", "", ""},
+
+		// Only the cache inside, not the settings and releases beside it
+		{"home/.m2", "", ""},
+		{"svn/repository", "", ""},
+		{"web/.yarn", "", ""},
+		{"web/.yarn/releases", "", ""},
+		{"web/cache", "", ""},
+	}
+
+	for _, tt := range tests {
+		cd, ok := ClassifyCacheDir(filepath.Join(root, tt.path))
+		if ok != (tt.wantDesc != "") || cd.Description != tt.wantDesc || cd.Rebuild != tt.wantRebuild {
+			t.Errorf("ClassifyCacheDir(%s) = %q (rebuild %q), %v; want %q (rebuild %q)",
+				tt.path, cd.Description, cd.Rebuild, ok, tt.wantDesc, tt.wantRebuild)
+		}
+		if ok && (cd.Risk != "low" || !cd.Reversible) {
+			t.Errorf("ClassifyCacheDir(%s) risk %s, reversible %v; want low and reversible", tt.path, cd.Risk, cd.Reversible)
+		}
+	}
+}
+
+func TestLoadCacheDirs(t *testing.T) {
+	builtin := CacheDirs
+	t.Cleanup(func() { CacheDirs = builtin })
+
+	dir := t.TempDir()
+	if err := LoadCacheDirs(filepath.Join(dir, "missing.json")); err != nil {
+		t.Errorf("LoadCacheDirs(missing) error = %v, want none", err)
+	}
+
+	list := filepath.Join(dir, "cachedirs.json")
+	data := `[
+  {"name": "node_modules", "description": "our vendored packages", "risk": "medium"},
+  {"name": ".renders", "description": "render cache", "reversible": true}
+]`
+	if err := os.WriteFile(list, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadCacheDirs(list); err != nil {
+		t.Fatalf("LoadCacheDirs() error = %v", err)
+	}
+
+	// The user's entry replaces the built-in one of the same name
+	cd, ok := ClassifyCacheDir("/src/app/node_modules")
+	if !ok || cd.Description != "our vendored packages" || cd.Risk != "medium" || cd.Reversible {
+		t.Errorf("node_modules = %+v, %v; want the user's entry, medium risk and not reversible", cd, ok)
+	}
+	cd, ok = ClassifyCacheDir("/src/film/.renders")
+	if !ok || cd.Risk != "low" || !cd.Reversible {
+		t.Errorf(".renders = %+v, %v; want a low-risk, reversible cache", cd, ok)
+	}
+	if _, ok := ClassifyCacheDir("/src/app/.gradle"); !ok {
+		t.Error("built-in .gradle no longer matches after loading the user's list")
+	}
+
+	if err := os.WriteFile(list, []byte(`[{"name": "out", "risk": "none"}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadCacheDirs(list); err == nil {
+		t.Error("LoadCacheDirs() with a bad risk succeeded, want an error")
+	}
+}
+
+func TestBuildDirsNeedABuildSystem(t *testing.T) {
+	root := t.TempDir()
+	tree(t, root,
+		"cmake/build/", "cmake/CMakeLists.txt",
+		"rust/build/", "rust/Cargo.toml",
+		"web/build/", "web/dist/",
+		"lib/build/", "lib/dist/",
+		"py/dist/", "py/pyproject.toml",
+
+		// A project that happens to be called build, holding its own source
+		"notes/build/", "notes/build/main.go", "notes/build/README.md",
+		// A package.json that doesn't build anything
+		"scripts/build/", "scripts/dist/",
+	)
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("web/package.json", `{"name": "web", "scripts": {"build": "vite build", "test": "vitest"}}`)
+	write("scripts/package.json", `{"name": "scripts", "scripts": {"test": "node test.js"}}`)
+	write("lib/package.json", `not json`)
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"cmake/build", true},
+		{"rust/build", true},
+		{"web/build", true},
+		{"web/dist", true},
+		{"py/dist", true},
+
+		{"notes/build", false},
+		{"scripts/build", false},
+		{"scripts/dist", false},
+		{"lib/build", false},
+		{"lib/dist", false},
+	}
+	for _, tt := range tests {
+		if _, got := ClassifyCacheDir(filepath.Join(root, tt.path)); got != tt.want {
+			t.Errorf("ClassifyCacheDir(%s) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+package scanner
+
+import (
+	"os"
+	"syscall"
+)
+
+// sfDataless is the file flag macOS sets when a file's contents have been
+// evicted to the cloud; reading it downloads them again
+const sfDataless = 0x40000000
+
+// isDataless reports whether info is a file whose data lives only in the cloud
+func isDataless(info os.FileInfo) bool {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	return ok && st.Flags&sfDataless != 0
+}
@@ -0,0 +1,10 @@
+//go:build !darwin
+
+package scanner
+
+import "os"
+
+// isDataless is always false here: only macOS evicts file data to the cloud
+func isDataless(info os.FileInfo) bool {
+	return false
+}
@@ -0,0 +1,104 @@
+package scanner
+
+import (
+	"bufio"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// diskImageExts are the disk image formats macOS mounts; a sparse bundle is
+// a directory of band files rather than one file
+var diskImageExts = map[string]bool{
+	".dmg":          true,
+	".sparseimage":  true,
+	".sparsebundle": true,
+	".iso":          true,
+	".cdr":          true,
+}
+
+// MountedImage is an attached disk image and where its volume is mounted
+type MountedImage struct {
+	Image      string // The backing file, or bundle directory
+	Device     string // The whole disk, e.g. /dev/disk4
+	MountPoint string // "" when attached but not mounted
+}
+
+// MountLister lists the disk images attached right now
+type MountLister func() ([]MountedImage, error)
+
+// IsDiskImage reports whether path is a disk image, or inside a sparse bundle
+func IsDiskImage(path string) bool {
+	for dir := path; ; dir = filepath.Dir(dir) {
+		if diskImageExts[strings.ToLower(filepath.Ext(dir))] {
+			return true
+		}
+		if dir == filepath.Dir(dir) {
+			return false
+		}
+	}
+}
+
+// HdiutilInfo lists attached images with `hdiutil info`. Only macOS attaches
+// images this way; elsewhere there are none.
+func HdiutilInfo() ([]MountedImage, error) {
+	if runtime.GOOS != "darwin" {
+		return nil, nil
+	}
+	out, err := exec.Command("hdiutil", "info").Output()
+	if err != nil {
+		return nil, err
+	}
+	return parseHdiutilInfo(string(out)), nil
+}
+
+// parseHdiutilInfo reads `hdiutil info` output: a block per image, split by
+// lines of "=", with its image-path and then a tab-separated line for each
+// device, whose last field is the mount point if it has one
+func parseHdiutilInfo(out string) []MountedImage {
+	var images []MountedImage
+	var current *MountedImage
+	sc := bufio.NewScanner(strings.NewReader(out))
+	for sc.Scan() {
+		line := sc.Text()
+		switch {
+		case strings.HasPrefix(line, "====="):
+			current = nil
+		case strings.HasPrefix(line, "image-path"):
+			_, path, _ := strings.Cut(line, ":")
+			images = append(images, MountedImage{Image: strings.TrimSpace(path)})
+			current = &images[len(images)-1]
+		case strings.HasPrefix(line, "/dev/") && current != nil:
+			fields := strings.Split(line, "\t")
+			if current.Device == "" {
+				current.Device = strings.TrimSpace(fields[0])
+			}
+			if mount := strings.TrimSpace(fields[len(fields)-1]); len(fields) > 1 && strings.HasPrefix(mount, "/") {
+				current.MountPoint = mount
+			}
+		}
+	}
+	return images
+}
+
+// FindMounted returns the attached image that path is the backing file of,
+// or inside of for a sparse bundle
+func FindMounted(path string, images []MountedImage) (MountedImage, bool) {
+	for _, img := range images {
+		if path == img.Image || strings.HasPrefix(path, img.Image+string(filepath.Separator)) {
+			return img, true
+		}
+	}
+	return MountedImage{}, false
+}
+
+// DetachAdvice says how to get an attached image out of the way before
+// deleting its backing file
+func DetachAdvice(img MountedImage) string {
+	target := img.MountPoint
+	if target == "" {
+		target = img.Device
+	}
+	return "unmount first: hdiutil detach '" + target + "'"
+}
@@ -0,0 +1,81 @@
+package scanner
+
+import (
+	"reflect"
+	"testing"
+)
+
+// hdiutilSample is `hdiutil info` with one image mounted and one attached
+// without a volume mounted
+const hdiutilSample = "framework       : 671.100.1\n" +
+	"driver          : 671.100.1\n" +
+	"================================================\n" +
+	"image-path      : /Users/me/Downloads/Xcode 16.dmg\n" +
+	"image-alias     : /Users/me/Downloads/Xcode 16.dmg\n" +
+	"shadow-path     : <none>\n" +
+	"image-type      : read-only disk image\n" +
+	"writeable       : FALSE\n" +
+	"process ID      : 812\n" +
+	"/dev/disk4\tGUID_partition_scheme\t\n" +
+	"/dev/disk4s1\tApple_HFS\t/Volumes/Xcode 16\n" +
+	"================================================\n" +
+	"image-path      : /Users/me/Work.sparsebundle\n" +
+	"image-type      : sparse bundle disk image\n" +
+	"/dev/disk5\tGUID_partition_scheme\t\n" +
+	"/dev/disk5s1\tApple_APFS\t\n"
+
+func TestParseHdiutilInfo(t *testing.T) {
+	want := []MountedImage{
+		{Image: "/Users/me/Downloads/Xcode 16.dmg", Device: "/dev/disk4", MountPoint: "/Volumes/Xcode 16"},
+		{Image: "/Users/me/Work.sparsebundle", Device: "/dev/disk5"},
+	}
+	if got := parseHdiutilInfo(hdiutilSample); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseHdiutilInfo() = %+v, want %+v", got, want)
+	}
+	if got := parseHdiutilInfo("framework : 671.100.1\ndriver : 671.100.1\n"); len(got) != 0 {
+		t.Errorf("parseHdiutilInfo() with nothing attached = %+v, want none", got)
+	}
+}
+
+func TestFindMounted(t *testing.T) {
+	images := parseHdiutilInfo(hdiutilSample)
+	tests := []struct {
+		path   string
+		image  string
+		advice string
+	}{
+		{"/Users/me/Downloads/Xcode 16.dmg", "/Users/me/Downloads/Xcode 16.dmg", "unmount first: hdiutil detach '/Volumes/Xcode 16'"},
+		{"/Users/me/Work.sparsebundle/bands/2a", "/Users/me/Work.sparsebundle", "unmount first: hdiutil detach '/dev/disk5'"},
+		{"/Users/me/Downloads/Xcode 15.dmg", "", ""},
+		{"/Users/me/Work.sparsebundle-old/bands/2a", "", ""},
+	}
+	for _, tt := range tests {
+		img, ok := FindMounted(tt.path, images)
+		if ok != (tt.image != "") || img.Image != tt.image {
+			t.Errorf("FindMounted(%q) = %q, %v, want %q", tt.path, img.Image, ok, tt.image)
+			continue
+		}
+		if ok && DetachAdvice(img) != tt.advice {
+			t.Errorf("DetachAdvice(%q) = %q, want %q", tt.path, DetachAdvice(img), tt.advice)
+		}
+	}
+}
+
+func TestIsDiskImage(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"/Users/me/Downloads/App.DMG", true},
+		{"/Users/me/Backup.sparseimage", true},
+		{"/Users/me/Work.sparsebundle/bands/2a", true},
+		{"/Users/me/ubuntu.iso", true},
+		{"/Users/me/movie.mov", false},
+		{"/Users/me/dmg/notes.txt", false},
+	}
+	for _, tt := range tests {
+		if got := IsDiskImage(tt.path); got != tt.want {
+			t.Errorf("IsDiskImage(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package scanner
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// TrackedLister lists the files git tracks in a repository, relative to its root
+type TrackedLister func(repo string) ([]string, error)
+
+// GitLsFiles lists tracked files with `git ls-files`
+func GitLsFiles(repo string) ([]string, error) {
+	out, err := exec.Command("git", "-C", repo, "ls-files", "-z").Output()
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, f := range bytes.Split(out, []byte{0}) {
+		if len(f) > 0 {
+			files = append(files, string(f))
+		}
+	}
+	return files, nil
+}
+
+// FindRepo returns the root of the git repository containing path, or "" if
+// there is none. Worktrees and submodules, where .git is a file, count too.
+func FindRepo(path string) string {
+	for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir
+		}
+		if dir == filepath.Dir(dir) {
+			return ""
+		}
+	}
+}
+
+// lfsExtensions are binary media and archives that belong in Git LFS rather than history
+var lfsExtensions = map[string]bool{
+	".mov": true, ".mp4": true, ".mkv": true, ".avi": true, ".webm": true,
+	".wav": true, ".mp3": true, ".flac": true,
+	".psd": true, ".tif": true, ".tiff": true, ".raw": true,
+	".zip": true, ".tar": true, ".gz": true, ".tgz": true, ".7z": true, ".dmg": true, ".iso": true,
+	".pdf": true, ".sqlite": true, ".db": true,
+}
+
+// buildDirs are path components that mark generated output
+var buildDirs = []string{"build", "dist", "target", "out", "bin", "obj", "node_modules", ".next", "__pycache__"}
+
+// UntrackAdvice suggests what to do about a large tracked file, given its
+// path relative to the repository root
+func UntrackAdvice(rel string) string {
+	rel = filepath.ToSlash(rel)
+	parts := strings.Split(path.Dir(rel), "/")
+	for i, part := range parts {
+		if slices.Contains(buildDirs, part) {
+			// The whole way down from the root, as git rm and .gitignore need it
+			dir := strings.Join(parts[:i+1], "/") + "/"
+			return "build output: git rm -r --cached " + dir + " and add " + dir + " to .gitignore"
+		}
+	}
+
+	ext := strings.ToLower(filepath.Ext(rel))
+	switch {
+	case lfsExtensions[ext]:
+		return "binary asset: git lfs track '*" + ext + "'"
+	case ext == ".o" || ext == ".so" || ext == ".dylib" || ext == ".a" || ext == ".exe" ||
+		ext == ".jar" || ext == ".class" || ext == ".pyc" || ext == ".wasm":
+		return "build artifact: git rm --cached " + rel + " and add '*" + ext + "' to .gitignore"
+	default:
+		return "large file in history: consider Git LFS or .gitignore"
+	}
+}
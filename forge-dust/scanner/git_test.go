@@ -0,0 +1,43 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFindRepo(t *testing.T) {
+	root := t.TempDir()
+	repo := filepath.Join(root, "app")
+	if err := os.MkdirAll(filepath.Join(repo, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := FindRepo(filepath.Join(repo, "assets", "intro.mov")); got != repo {
+		t.Errorf("FindRepo(inside repo) = %q, want %q", got, repo)
+	}
+	if got := FindRepo(filepath.Join(root, "loose.bin")); got != "" {
+		t.Errorf("FindRepo(outside repo) = %q, want \"\"", got)
+	}
+}
+
+func TestUntrackAdvice(t *testing.T) {
+	tests := []struct {
+		rel  string
+		want string
+	}{
+		{"assets/intro.mov", "git lfs track '*.mov'"},
+		{"dist/app.js", "build output: git rm -r --cached dist/ and add dist/ to .gitignore"},
+		{"web/build/bundle.js", "build output: git rm -r --cached web/build/ and add web/build/ to .gitignore"},
+		{"web/build/js/out/app.js", "git rm -r --cached web/build/ "},
+		{"lib/libfoo.so", "add '*.so' to .gitignore"},
+		{"data/model.ckpt", "consider Git LFS"},
+	}
+
+	for _, tt := range tests {
+		if got := UntrackAdvice(tt.rel); !strings.Contains(got, tt.want) {
+			t.Errorf("UntrackAdvice(%q) = %q, want it to contain %q", tt.rel, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package scanner
+
+import "path/filepath"
+
+// GlobalCache is a package manager's shared download cache under the home directory
+type GlobalCache struct {
+	Path         string // relative to home
+	Manager      string
+	Description  string
+	CleanCommand string // the manager's own way to empty it
+}
+
+// GlobalCaches are the shared caches worth reporting. Cleaning them is safe
+// but every project re-downloads its dependencies afterwards.
+var GlobalCaches = []GlobalCache{
+	{".cargo/registry", "cargo", "Cargo crate registry and sources", "cargo cache -a"},
+	{"go/pkg/mod", "go", "Go module cache", "go clean -modcache"},
+	{"Library/Caches/go-build", "go", "Go build cache", "go clean -cache"},
+	{".cache/go-build", "go", "Go build cache", "go clean -cache"},
+	{"Library/Caches/pip", "pip", "pip download cache", "pip cache purge"},
+	{".cache/pip", "pip", "pip download cache", "pip cache purge"},
+	{".npm", "npm", "npm package cache", "npm cache clean --force"},
+	{"Library/Caches/Homebrew", "brew", "Homebrew download cache", "brew cleanup --prune=all"},
+	{".cache/Homebrew", "brew", "Homebrew download cache", "brew cleanup --prune=all"},
+}
+
+// ClassifyGlobalCache reports whether path is one of the GlobalCaches under home
+func ClassifyGlobalCache(path, home string) (GlobalCache, bool) {
+	rel, err := filepath.Rel(home, path)
+	if err != nil {
+		return GlobalCache{}, false
+	}
+	rel = filepath.ToSlash(rel)
+	for _, gc := range GlobalCaches {
+		if rel == gc.Path {
+			return gc, true
+		}
+	}
+	return GlobalCache{}, false
+}
@@ -0,0 +1,38 @@
+package scanner
+
+import "testing"
+
+func TestClassifyGlobalCache(t *testing.T) {
+	const home = "/Users/me"
+	tests := []struct {
+		path        string
+		wantManager string
+		wantCommand string
+	}{
+		{"/Users/me/.cargo/registry", "cargo", "cargo cache -a"},
+		{"/Users/me/go/pkg/mod", "go", "go clean -modcache"},
+		{"/Users/me/Library/Caches/go-build", "go", "go clean -cache"},
+		{"/Users/me/.cache/go-build", "go", "go clean -cache"},
+		{"/Users/me/Library/Caches/pip", "pip", "pip cache purge"},
+		{"/Users/me/.cache/pip", "pip", "pip cache purge"},
+		{"/Users/me/.npm", "npm", "npm cache clean --force"},
+		{"/Users/me/Library/Caches/Homebrew", "brew", "brew cleanup --prune=all"},
+		{"/Users/me/.cache/Homebrew", "brew", "brew cleanup --prune=all"},
+
+		// Not global caches
+		{"/Users/me/.cargo", "", ""},
+		{"/Users/me/.cargo/bin", "", ""},
+		{"/Users/me/go/pkg/mod/github.com", "", ""},
+		{"/Users/me/Projects/app/.npm", "", ""},
+		{"/Users/other/.npm", "", ""},
+		{"/Users/me/Library/Caches", "", ""},
+	}
+
+	for _, tt := range tests {
+		gc, ok := ClassifyGlobalCache(tt.path, home)
+		if ok != (tt.wantManager != "") || gc.Manager != tt.wantManager || gc.CleanCommand != tt.wantCommand {
+			t.Errorf("ClassifyGlobalCache(%q) = %+v, %v, want manager %q, command %q",
+				tt.path, gc, ok, tt.wantManager, tt.wantCommand)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package scanner
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// HomeParents are the directories that hold users' homes on macOS and Linux
+var HomeParents = []string{"/Users", "/home"}
+
+// HomeFor returns the home directory that findings under root belong to,
+// so that scanning another user's files finds their Downloads and Trash
+// rather than the invoker's. It's invoker's home when root lies inside it,
+// then the home of root's owner if root lies inside that, then the home
+// whose path contains root, and invoker's home otherwise.
+func HomeFor(root, invoker string) string {
+	root = filepath.Clean(root)
+	if isWithin(root, invoker) {
+		return invoker
+	}
+	if home, ok := ownerHome(root); ok && isWithin(root, home) {
+		return home
+	}
+	for _, parent := range HomeParents {
+		rel, err := filepath.Rel(parent, root)
+		if err != nil || rel == "." || !filepath.IsLocal(rel) {
+			continue
+		}
+		user, _, _ := strings.Cut(rel, string(filepath.Separator))
+		return filepath.Join(parent, user)
+	}
+	return invoker
+}
+
+// isWithin reports whether path is dir or lies inside it
+func isWithin(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && filepath.IsLocal(rel)
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package scanner
+
+// ownerHome can't see file owners here, so HomeFor goes by path alone
+func ownerHome(path string) (string, bool) {
+	return "", false
+}
@@ -0,0 +1,24 @@
+package scanner
+
+import "testing"
+
+func TestHomeFor(t *testing.T) {
+	tests := []struct {
+		root, invoker string
+		want          string
+	}{
+		{"/Users/bob/Projects", "/Users/bob", "/Users/bob"},
+		{"/Users/bob", "/Users/bob", "/Users/bob"},
+		{"/Users/alice", "/Users/bob", "/Users/alice"},
+		{"/Users/alice/Downloads/", "/Users/bob", "/Users/alice"},
+		{"/home/carol/src", "/root", "/home/carol"},
+		{"/Users", "/Users/bob", "/Users/bob"}, // many homes, none of them the root's
+		{"/Volumes/Backup", "/Users/bob", "/Users/bob"},
+	}
+
+	for _, tt := range tests {
+		if got := HomeFor(tt.root, tt.invoker); got != tt.want {
+			t.Errorf("HomeFor(%q, %q) = %q, want %q", tt.root, tt.invoker, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package scanner
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// icloudSuffix ends the placeholder left where an offloaded file was
+const icloudSuffix = ".icloud"
+
+// IsICloudPlaceholder reports whether path is the stand-in iCloud Drive
+// leaves for a file it offloaded, named like ".report.pdf.icloud". The
+// placeholder is tiny; the file itself is only in iCloud.
+func IsICloudPlaceholder(path string) bool {
+	name := filepath.Base(path)
+	return strings.HasPrefix(name, ".") && strings.HasSuffix(name, icloudSuffix) &&
+		len(name) > len("."+icloudSuffix)
+}
+
+// ICloudName is the name of the file a placeholder stands in for
+func ICloudName(path string) string {
+	name := filepath.Base(path)
+	if !IsICloudPlaceholder(path) {
+		return name
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(name, "."), icloudSuffix)
+}
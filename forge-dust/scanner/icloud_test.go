@@ -0,0 +1,27 @@
+package scanner
+
+import "testing"
+
+func TestICloudPlaceholder(t *testing.T) {
+	tests := []struct {
+		path        string
+		placeholder bool
+		name        string
+	}{
+		{"/Users/me/Documents/.Taxes 2024.pdf.icloud", true, "Taxes 2024.pdf"},
+		{"/Users/me/Desktop/.movie.mov.icloud", true, "movie.mov"},
+		{"/Users/me/Documents/notes.icloud", false, "notes.icloud"}, // not hidden
+		{"/Users/me/Documents/.icloud", false, ".icloud"},
+		{"/Users/me/Documents/.zshrc", false, ".zshrc"},
+		{"/Users/me/Documents/report.pdf", false, "report.pdf"},
+	}
+
+	for _, tt := range tests {
+		if got := IsICloudPlaceholder(tt.path); got != tt.placeholder {
+			t.Errorf("IsICloudPlaceholder(%q) = %v, want %v", tt.path, got, tt.placeholder)
+		}
+		if got := ICloudName(tt.path); got != tt.name {
+			t.Errorf("ICloudName(%q) = %q, want %q", tt.path, got, tt.name)
+		}
+	}
+}
@@ -0,0 +1,18 @@
+//go:build unix
+
+package scanner
+
+import (
+	"os"
+	"syscall"
+)
+
+// fileID returns the device and inode behind info, and how many paths link to it
+func fileID(info os.FileInfo) (FileID, int, bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return FileID{}, 0, false
+	}
+	// Field widths differ between macOS and Linux, hence the conversions
+	return FileID{Dev: uint64(st.Dev), Ino: uint64(st.Ino)}, int(st.Nlink), true
+}
@@ -0,0 +1,75 @@
+package scanner
+
+import (
+	"bufio"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// MediaType is the kind of storage a path lives on
+type MediaType string
+
+const (
+	MediaUnknown MediaType = "unknown"
+	MediaSSD     MediaType = "ssd"
+	MediaHDD     MediaType = "hdd"
+	MediaNetwork MediaType = "network"
+)
+
+// DetectMedia reports the storage behind path. Only macOS (via diskutil) is
+// supported; elsewhere, or if diskutil can't tell, it returns MediaUnknown.
+func DetectMedia(path string) MediaType {
+	if runtime.GOOS != "darwin" {
+		return MediaUnknown
+	}
+	out, err := exec.Command("diskutil", "info", path).Output()
+	if err != nil {
+		// diskutil only knows local disks, so a mounted path it can't find is usually a share
+		if strings.HasPrefix(path, "/Volumes/") || strings.HasPrefix(path, "/net/") {
+			return MediaNetwork
+		}
+		return MediaUnknown
+	}
+	return parseDiskutil(string(out))
+}
+
+// parseDiskutil reads the media type from `diskutil info` output
+func parseDiskutil(out string) MediaType {
+	sc := bufio.NewScanner(strings.NewReader(out))
+	for sc.Scan() {
+		key, value, ok := strings.Cut(sc.Text(), ":")
+		if !ok {
+			continue
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+
+		switch key {
+		case "Solid State":
+			if value == "Yes" {
+				return MediaSSD
+			}
+			if value == "No" {
+				return MediaHDD
+			}
+		case "Protocol":
+			if strings.Contains(value, "Network") || strings.Contains(value, "SMB") || strings.Contains(value, "AFP") {
+				return MediaNetwork
+			}
+		}
+	}
+	return MediaUnknown
+}
+
+// WorkersFor picks a default scan concurrency. SSDs gain from parallel
+// walks; spinning disks and network shares lose to seeking, so they get 1.
+func WorkersFor(media MediaType, cpus int) int {
+	switch media {
+	case MediaSSD:
+		return min(max(cpus, 2), 8)
+	case MediaHDD, MediaNetwork:
+		return 1
+	default:
+		return 4
+	}
+}
@@ -0,0 +1,44 @@
+package scanner
+
+import "testing"
+
+func TestWorkersFor(t *testing.T) {
+	tests := []struct {
+		media MediaType
+		cpus  int
+		want  int
+	}{
+		{MediaSSD, 1, 2},
+		{MediaSSD, 4, 4},
+		{MediaSSD, 16, 8},
+		{MediaHDD, 16, 1},
+		{MediaNetwork, 16, 1},
+		{MediaUnknown, 16, 4},
+		{MediaUnknown, 1, 4},
+	}
+
+	for _, tt := range tests {
+		if got := WorkersFor(tt.media, tt.cpus); got != tt.want {
+			t.Errorf("WorkersFor(%s, %d) = %d, want %d", tt.media, tt.cpus, got, tt.want)
+		}
+	}
+}
+
+func TestParseDiskutil(t *testing.T) {
+	tests := []struct {
+		name string
+		out  string
+		want MediaType
+	}{
+		{"internal ssd", "   Device Identifier:         disk3s1\n   Protocol:                  Apple Fabric\n   Solid State:               Yes\n", MediaSSD},
+		{"external hdd", "   Protocol:                  USB\n   Solid State:               No\n", MediaHDD},
+		{"disk image", "   Protocol:                  Disk Image\n   Solid State:               Info not available\n", MediaUnknown},
+		{"empty", "", MediaUnknown},
+	}
+
+	for _, tt := range tests {
+		if got := parseDiskutil(tt.out); got != tt.want {
+			t.Errorf("%s: parseDiskutil() = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
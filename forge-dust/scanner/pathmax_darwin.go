@@ -0,0 +1,4 @@
+package scanner
+
+// maxPathLen is PATH_MAX on macOS, counting the terminating NUL
+const maxPathLen = 1024
@@ -0,0 +1,7 @@
+//go:build !darwin
+
+package scanner
+
+// maxPathLen is PATH_MAX on Linux, counting the terminating NUL, and a
+// generous limit elsewhere
+const maxPathLen = 4096
@@ -0,0 +1,111 @@
+package scanner
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// progressInterval is how often progress is reported
+const progressInterval = 100 * time.Millisecond
+
+// Aggregator merges the progress of walkers running at once, over several
+// roots or in several workers, into a single report. Each walker counts on
+// a Counter of its own; reports go out one at a time, at most every
+// progressInterval, with totals that never go backwards.
+type Aggregator struct {
+	report ProgressFunc
+	start  time.Time
+	next   atomic.Int64 // When the next report is due, in Unix nanoseconds
+
+	mu       sync.Mutex // Guards counters and reporting
+	counters []*Counter
+}
+
+// Counter is one walker's share of the progress. Only that walker adds to
+// it; the aggregator reads it from whichever walker reports.
+type Counter struct {
+	agg   *Aggregator
+	files atomic.Int64
+	dirs  atomic.Int64
+	bytes atomic.Int64
+	dir   string // The walker's current directory, only touched by the walker
+}
+
+// NewAggregator starts the clock for a scan reporting to report, which may
+// be nil to report nothing
+func NewAggregator(report ProgressFunc) *Aggregator {
+	return &Aggregator{report: report, start: time.Now()}
+}
+
+// Counter adds a walker, returning the counter it's to add to
+func (a *Aggregator) Counter() *Counter {
+	c := &Counter{agg: a}
+	a.mu.Lock()
+	a.counters = append(a.counters, c)
+	a.mu.Unlock()
+	return c
+}
+
+// Total is the progress of all the walkers so far
+func (a *Aggregator) Total() Progress {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.total("")
+}
+
+// Flush reports the totals now, however soon after the last report, so
+// the final one is complete
+func (a *Aggregator) Flush() {
+	if a.report == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.report(a.total(""))
+}
+
+// total sums the counters; a.mu must be held
+func (a *Aggregator) total(dir string) Progress {
+	p := Progress{CurrentDir: dir, Elapsed: time.Since(a.start)}
+	for _, c := range a.counters {
+		p.FilesScanned += int(c.files.Load())
+		p.DirsScanned += int(c.dirs.Load())
+		p.BytesScanned += c.bytes.Load()
+	}
+	return p
+}
+
+// maybeReport reports the totals, showing dir as the current directory, if
+// a report is due. Reporting under the lock keeps reports in order, each
+// summed after the one before, so the line never flickers back.
+func (a *Aggregator) maybeReport(dir string) {
+	if a.report == nil {
+		return
+	}
+	now := time.Now().UnixNano()
+	if now < a.next.Load() {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if now < a.next.Load() {
+		return // Another walker reported first
+	}
+	a.next.Store(now + int64(progressInterval))
+	a.report(a.total(dir))
+}
+
+// AddDir counts a directory, which becomes the walker's current one
+func (c *Counter) AddDir(path string) {
+	c.dirs.Add(1)
+	c.dir = path
+	c.agg.maybeReport(c.dir)
+}
+
+// AddFile counts a file of size bytes
+func (c *Counter) AddFile(size int64) {
+	c.files.Add(1)
+	c.bytes.Add(size)
+	c.agg.maybeReport(c.dir)
+}
@@ -0,0 +1,73 @@
+package scanner
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Run with -race: walkers add at once while reports go out
+func TestAggregatorConcurrentWalkers(t *testing.T) {
+	const walkers, files = 8, 5000
+
+	var reports []Progress
+	var inReport atomic.Bool
+	agg := NewAggregator(func(p Progress) {
+		if inReport.Swap(true) {
+			t.Error("report called while another was running")
+		}
+		reports = append(reports, p)
+		inReport.Store(false)
+	})
+
+	var wg sync.WaitGroup
+	for w := 0; w < walkers; w++ {
+		c := agg.Counter()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for f := 0; f < files; f++ {
+				if f%100 == 0 {
+					c.AddDir(fmt.Sprintf("/root%d/dir%d", w, f))
+					time.Sleep(time.Millisecond)
+				}
+				c.AddFile(10)
+			}
+		}()
+	}
+	wg.Wait()
+	agg.Flush()
+
+	want := Progress{FilesScanned: walkers * files, DirsScanned: walkers * files / 100, BytesScanned: walkers * files * 10}
+	got := agg.Total()
+	if got.FilesScanned != want.FilesScanned || got.DirsScanned != want.DirsScanned || got.BytesScanned != want.BytesScanned {
+		t.Errorf("Total() = %+v, want %+v", got, want)
+	}
+
+	if len(reports) < 2 {
+		t.Fatalf("got %d reports, want at least 2", len(reports))
+	}
+	for i := 1; i < len(reports); i++ {
+		prev, p := reports[i-1], reports[i]
+		if p.FilesScanned < prev.FilesScanned || p.DirsScanned < prev.DirsScanned || p.BytesScanned < prev.BytesScanned {
+			t.Errorf("report %d = %+v went back from %+v", i, p, prev)
+		}
+	}
+	if last := reports[len(reports)-1]; last.FilesScanned != want.FilesScanned {
+		t.Errorf("last report has %d files, want %d", last.FilesScanned, want.FilesScanned)
+	}
+}
+
+func TestAggregatorThrottlesReports(t *testing.T) {
+	var reports int
+	agg := NewAggregator(func(Progress) { reports++ })
+	c := agg.Counter()
+	for i := 0; i < 1000; i++ {
+		c.AddFile(1)
+	}
+	if reports != 1 {
+		t.Errorf("1000 files at once gave %d reports, want 1", reports)
+	}
+}
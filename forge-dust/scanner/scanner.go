@@ -1,11 +1,15 @@
 package scanner
 
 import (
+	"errors"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
 	"time"
+
+	"forge-dust/timing"
 )
 
 type FileInfo struct {
@@ -13,6 +17,55 @@ type FileInfo struct {
 	Size    int64
 	ModTime time.Time
 	IsDir   bool
+	ID      FileID `json:",omitzero"` // Set for files with more than one hard link
+	Links   int    `json:",omitempty"`
+
+	// Offloaded files are kept in iCloud, leaving a placeholder or an empty
+	// (dataless) file; Size is what's on disk and Logical the size in iCloud,
+	// when known. Reading one downloads it, so they're never suggested.
+	Offloaded bool  `json:",omitempty"`
+	Logical   int64 `json:",omitempty"`
+
+	// AccessTime is when the file was last read, if the filesystem keeps
+	// track: zero on filesystems mounted noatime, where it never moves past
+	// the last write
+	AccessTime time.Time `json:",omitzero"`
+}
+
+// FileID identifies a file's data on disk, whichever path it's reached by
+type FileID struct {
+	Dev uint64
+	Ino uint64
+}
+
+// NewFileInfo describes path from its stat info, noting hard links
+func NewFileInfo(path string, info os.FileInfo) FileInfo {
+	f := FileInfo{
+		Path:    path,
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+		IsDir:   info.IsDir(),
+	}
+	if at, ok := accessTime(info); ok && at.After(f.ModTime) {
+		f.AccessTime = at
+	}
+	if id, links, ok := fileID(info); ok && links > 1 && !info.IsDir() {
+		f.ID, f.Links = id, links
+	}
+	if !info.IsDir() {
+		if IsICloudPlaceholder(path) {
+			f.Offloaded = true
+		} else if isDataless(info) {
+			f.Offloaded, f.Logical, f.Size = true, f.Size, 0
+		}
+	}
+	return f
+}
+
+// HardLinked reports whether other paths share this file's data, so
+// deleting this one alone frees nothing
+func (f FileInfo) HardLinked() bool {
+	return f.Links > 1
 }
 
 type ScanResult struct {
@@ -22,31 +75,7 @@ type ScanResult struct {
 	TotalDirs   int
 	ScanTime    time.Duration
 	Errors      []string
-}
-
-// Known cache/temp directories that are safe to clean
-var CacheDirs = map[string]string{
-	"node_modules":    "npm packages (can reinstall)",
-	".npm":            "npm cache",
-	".pnpm-store":     "pnpm cache",
-	".yarn":           "yarn cache",
-	"__pycache__":     "Python bytecode cache",
-	".pytest_cache":   "pytest cache",
-	".mypy_cache":     "mypy cache",
-	".cache":          "generic cache",
-	".gradle":         "Gradle cache",
-	".m2":             "Maven cache",
-	"target":          "Rust/Java build output",
-	"build":           "build output",
-	"dist":            "distribution output",
-	".next":           "Next.js cache",
-	".nuxt":           "Nuxt.js cache",
-	".svelte-kit":     "SvelteKit cache",
-	".turbo":          "Turborepo cache",
-	"Pods":            "CocoaPods (iOS)",
-	"DerivedData":     "Xcode derived data",
-	".Trash":          "Trash",
-	"Library/Caches":  "macOS app caches",
+	Denied      []string `json:",omitempty"` // Directories refused for lack of permission, worth retrying with more
 }
 
 // File patterns that are often safe to clean
@@ -81,8 +110,10 @@ type Scanner struct {
 	SkipHidden   bool
 	FollowLinks  bool
 	OnProgress   ProgressFunc // Called during scan with progress updates
+	Timings      *timing.Timer // Records the walk as "scan"; nil records nothing
 	mu           sync.Mutex
 	errors       []string
+	denied       []string
 }
 
 func New(rootPath string) *Scanner {
@@ -95,7 +126,23 @@ func New(rootPath string) *Scanner {
 	}
 }
 
+// errPathTooLong is recorded for paths past maxPathLen, which the OS would
+// refuse to stat or open
+var errPathTooLong = errors.New("path too long")
+
+// recordError notes a path the walk couldn't read, keeping directories
+// refused for lack of permission apart so they can be offered for a retry
+func (s *Scanner) recordError(path string, isDir bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errors = append(s.errors, path+": "+err.Error())
+	if errors.Is(err, fs.ErrPermission) && isDir {
+		s.denied = append(s.denied, path)
+	}
+}
+
 func (s *Scanner) Scan() (*ScanResult, error) {
+	defer s.Timings.Start("scan")()
 	start := time.Now()
 	result := &ScanResult{}
 
@@ -104,67 +151,73 @@ func (s *Scanner) Scan() (*ScanResult, error) {
 		return nil, err
 	}
 
-	var lastProgress time.Time
-	var currentDir string
+	progress := NewAggregator(s.OnProgress).Counter()
+
+	// The directories being walked below root, innermost last. Walking is
+	// depth first, so an entry's depth is how many of them remain once those
+	// that aren't its ancestors are popped: no need to parse its path.
+	var open []string
 
-	err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
-			s.mu.Lock()
-			s.errors = append(s.errors, path+": "+err.Error())
-			s.mu.Unlock()
+			s.recordError(path, d != nil && d.IsDir(), err)
 			return nil // Continue walking
 		}
 
 		// Skip hidden files if configured
-		name := info.Name()
+		name := d.Name()
 		if s.SkipHidden && strings.HasPrefix(name, ".") && path != root {
-			if info.IsDir() {
+			if d.IsDir() {
 				return filepath.SkipDir
 			}
 			return nil
 		}
 
 		// Check depth
-		if s.MaxDepth >= 0 {
-			relPath, _ := filepath.Rel(root, path)
-			depth := strings.Count(relPath, string(os.PathSeparator))
-			if depth > s.MaxDepth {
-				if info.IsDir() {
+		if s.MaxDepth >= 0 && path != root {
+			parent := filepath.Dir(path)
+			for len(open) > 0 && open[len(open)-1] != parent {
+				open = open[:len(open)-1]
+			}
+			if len(open) > s.MaxDepth {
+				if d.IsDir() {
 					return filepath.SkipDir
 				}
 				return nil
 			}
+			if d.IsDir() {
+				open = append(open, path)
+			}
+		}
+
+		// Past the OS's limit, neither this nor anything under it can be read
+		if len(path) >= maxPathLen {
+			s.recordError(path, false, errPathTooLong)
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
 		}
 
-		fileInfo := FileInfo{
-			Path:    path,
-			Size:    info.Size(),
-			ModTime: info.ModTime(),
-			IsDir:   info.IsDir(),
+		info, err := d.Info()
+		if err != nil {
+			s.recordError(path, d.IsDir(), err)
+			return nil // Gone since the directory was read
 		}
+		fileInfo := NewFileInfo(path, info)
 
 		if info.IsDir() {
 			result.TotalDirs++
-			currentDir = path
+			progress.AddDir(path)
 		} else {
 			result.TotalFiles++
-			result.TotalSize += info.Size()
-		}
-
-		// Report progress every 100ms
-		if s.OnProgress != nil && time.Since(lastProgress) > 100*time.Millisecond {
-			lastProgress = time.Now()
-			s.OnProgress(Progress{
-				CurrentDir:   currentDir,
-				FilesScanned: result.TotalFiles,
-				DirsScanned:  result.TotalDirs,
-				BytesScanned: result.TotalSize,
-				Elapsed:      time.Since(start),
-			})
+			result.TotalSize += fileInfo.Size
+			progress.AddFile(fileInfo.Size)
 		}
 
-		// Only add files above min size, or all directories
-		if info.IsDir() || info.Size() >= s.MinSize {
+		// Only add files above min size, or all directories; offloaded
+		// files are kept so they can be counted
+		if info.IsDir() || fileInfo.Size >= s.MinSize || fileInfo.Offloaded {
 			result.Files = append(result.Files, fileInfo)
 		}
 
@@ -173,29 +226,66 @@ func (s *Scanner) Scan() (*ScanResult, error) {
 
 	result.ScanTime = time.Since(start)
 	result.Errors = s.errors
+	result.Denied = s.denied
 
 	return result, err
 }
 
-// IsCacheDir checks if a directory name is a known cache directory
-func IsCacheDir(name string) (bool, string) {
-	if desc, ok := CacheDirs[name]; ok {
-		return true, desc
-	}
-	return false, ""
-}
-
-// GetDirSize calculates the total size of a directory
+// GetDirSize calculates the total size of a directory, counting hard-linked
+// files once
 func GetDirSize(path string) (int64, error) {
 	var size int64
+	seen := make(map[FileID]bool)
 	err := filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil
 		}
-		if !info.IsDir() {
-			size += info.Size()
+		if info.IsDir() || isDataless(info) {
+			return nil // Dataless files take no space here
+		}
+		if id, links, ok := fileID(info); ok && links > 1 {
+			if seen[id] {
+				return nil
+			}
+			seen[id] = true
 		}
+		size += info.Size()
 		return nil
 	})
 	return size, err
 }
+
+// GetDirSizes calculates the sizes of several independent directories using
+// a bounded pool of workers. Sizes are returned in the same order as paths.
+// A workers value below 2 computes them serially.
+func GetDirSizes(paths []string, workers int) []int64 {
+	sizes := make([]int64, len(paths))
+
+	if workers < 2 || len(paths) < 2 {
+		for i, path := range paths {
+			sizes[i], _ = GetDirSize(path)
+		}
+		return sizes
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(workers, len(paths)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				// Each worker writes only its own index, so no locking is needed
+				sizes[i], _ = GetDirSize(paths[i])
+			}
+		}()
+	}
+
+	for i := range paths {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return sizes
+}
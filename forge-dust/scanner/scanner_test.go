@@ -0,0 +1,253 @@
+package scanner
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// makeTree creates dirs directories under root, each holding files files of
+// increasing size, and returns their paths.
+func makeTree(tb testing.TB, root string, dirs, files int) []string {
+	tb.Helper()
+	var paths []string
+	for d := 0; d < dirs; d++ {
+		dir := filepath.Join(root, fmt.Sprintf("cache%d", d), "nested")
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			tb.Fatal(err)
+		}
+		for f := 0; f < files; f++ {
+			data := make([]byte, (d+1)*(f+1)*100)
+			if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("f%d", f)), data, 0644); err != nil {
+				tb.Fatal(err)
+			}
+		}
+		paths = append(paths, filepath.Dir(dir))
+	}
+	return paths
+}
+
+func TestGetDirSizesMatchesSerial(t *testing.T) {
+	paths := makeTree(t, t.TempDir(), 12, 5)
+
+	var serialTotal int64
+	serial := make([]int64, len(paths))
+	for i, p := range paths {
+		size, err := GetDirSize(p)
+		if err != nil {
+			t.Fatalf("GetDirSize(%q) error = %v", p, err)
+		}
+		serial[i] = size
+		serialTotal += size
+	}
+
+	for _, workers := range []int{0, 1, 3, 8, 32} {
+		t.Run(fmt.Sprintf("workers=%d", workers), func(t *testing.T) {
+			got := GetDirSizes(paths, workers)
+			if len(got) != len(serial) {
+				t.Fatalf("GetDirSizes() returned %d sizes, want %d", len(got), len(serial))
+			}
+
+			var total int64
+			for i := range got {
+				if got[i] != serial[i] {
+					t.Errorf("size of %s = %d, want %d", paths[i], got[i], serial[i])
+				}
+				total += got[i]
+			}
+			if total != serialTotal {
+				t.Errorf("total = %d, want %d", total, serialTotal)
+			}
+		})
+	}
+}
+
+func BenchmarkGetDirSizes(b *testing.B) {
+	paths := makeTree(b, b.TempDir(), 12, 200)
+
+	for _, workers := range []int{1, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				GetDirSizes(paths, workers)
+			}
+		})
+	}
+}
+
+func TestScanNotesHardLinks(t *testing.T) {
+	root := t.TempDir()
+	original := filepath.Join(root, "original")
+	if err := os.WriteFile(original, make([]byte, 4096), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Link(original, filepath.Join(root, "link")); err != nil {
+		t.Skipf("hard links not supported here: %v", err)
+	}
+
+	result, err := New(root).Scan()
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	var files []FileInfo
+	for _, f := range result.Files {
+		if !f.IsDir {
+			files = append(files, f)
+		}
+	}
+	if len(files) != 2 {
+		t.Fatalf("Scan() found %d files, want 2", len(files))
+	}
+	if info, err := os.Stat(original); err == nil {
+		if _, _, ok := fileID(info); !ok {
+			t.Skip("this platform doesn't report inodes")
+		}
+	}
+	if !files[0].HardLinked() || files[0].ID != files[1].ID {
+		t.Errorf("Scan() = %+v, want both paths marked as links to the same file", files)
+	}
+
+	if size, _ := GetDirSize(root); size != 4096 {
+		t.Errorf("GetDirSize() = %d, want 4096 (the linked data once)", size)
+	}
+}
+
+func TestDeniedDirectoriesCollected(t *testing.T) {
+	root := t.TempDir()
+	denied := &os.PathError{Op: "open", Path: "/Users/bob/Library/Mail", Err: fs.ErrPermission}
+	missing := &os.PathError{Op: "open", Path: "/Users/bob/gone", Err: fs.ErrNotExist}
+
+	s := New(root)
+	s.recordError("/Users/bob/Library/Mail", true, denied)
+	s.recordError("/Users/bob/secret.txt", false, denied) // a file, not worth a rescan
+	s.recordError("/Users/bob/gone", true, missing)
+
+	if len(s.errors) != 3 {
+		t.Errorf("errors = %q, want all three", s.errors)
+	}
+	if want := []string{"/Users/bob/Library/Mail"}; !reflect.DeepEqual(s.denied, want) {
+		t.Errorf("denied = %q, want %q", s.denied, want)
+	}
+}
+
+func TestScanCollectsDeniedDirectories(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root can read any directory")
+	}
+	root := t.TempDir()
+	locked := filepath.Join(root, "locked")
+	if err := os.Mkdir(locked, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(locked, 0); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chmod(locked, 0755) })
+
+	result, err := New(root).Scan()
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if want := []string{locked}; !reflect.DeepEqual(result.Denied, want) {
+		t.Errorf("Scan() Denied = %q, want %q", result.Denied, want)
+	}
+}
+
+func TestNewFileInfoAccessTime(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(path, []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mtime := time.Now().Add(-365 * 24 * time.Hour).Truncate(time.Second)
+
+	tests := []struct {
+		name  string
+		atime time.Time
+		want  time.Time
+	}{
+		{"read since written", mtime.Add(48 * time.Hour), mtime.Add(48 * time.Hour)},
+		{"not read since written, as under noatime", mtime.Add(-time.Hour), time.Time{}},
+	}
+	for _, tt := range tests {
+		if err := os.Chtimes(path, tt.atime, mtime); err != nil {
+			t.Fatal(err)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := accessTime(info); !ok {
+			t.Skip("this platform doesn't report access times")
+		}
+		if got := NewFileInfo(path, info).AccessTime; !got.Equal(tt.want) {
+			t.Errorf("%s: AccessTime = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestScanMaxDepth(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"a/b/c/d", "a/x", "y"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, file := range []string{"top", "a/b/mid", "a/b/c/deep", "a/x/side"} {
+		if err := os.WriteFile(filepath.Join(root, file), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s := New(root)
+	s.MaxDepth = 1
+	result, err := s.Scan()
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	var got []string
+	for _, f := range result.Files {
+		rel, _ := filepath.Rel(root, f.Path)
+		got = append(got, filepath.ToSlash(rel))
+	}
+	// Entries of root are at depth 0, theirs at 1
+	want := []string{".", "a", "a/b", "a/x", "top", "y"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Scan() with MaxDepth 1 found %q, want %q", got, want)
+	}
+}
+
+// makeDeepTree nests depth directories under root, with a file in each
+func makeDeepTree(tb testing.TB, root string, depth int) {
+	tb.Helper()
+	dir := root
+	for i := 0; i < depth; i++ {
+		dir = filepath.Join(dir, "node_modules")
+		if err := os.Mkdir(dir, 0755); err != nil {
+			tb.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "index.js"), []byte("x"), 0644); err != nil {
+			tb.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkScanDeepTree(b *testing.B) {
+	root := b.TempDir()
+	// Deep enough for long paths, short of the shortest PATH_MAX
+	makeDeepTree(b, root, (1000-len(root))/len("/node_modules"))
+
+	for _, maxDepth := range []int{-1, 5, 1000} {
+		b.Run(fmt.Sprintf("maxdepth=%d", maxDepth), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				s := New(root)
+				s.MaxDepth = maxDepth
+				if _, err := s.Scan(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
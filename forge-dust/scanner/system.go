@@ -0,0 +1,62 @@
+package scanner
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// SystemCache is a cache kept outside any home directory, by the OS or a
+// system package manager. Reading one usually takes sudo or Full Disk
+// Access, and clearing it is riskier than clearing a user's own caches.
+type SystemCache struct {
+	Path        string // Absolute, with * for the parts that vary between machines
+	Description string
+	Advice      string // How to clear it safely
+}
+
+// SystemCaches are the system caches worth reporting with --system
+var SystemCaches = []SystemCache{
+	{"/Library/Caches", "Caches shared by every user and by system services", "Quit apps, then delete its subfolders with sudo; they're rebuilt"},
+	{"/private/var/folders/*/*/C", "Per-user app caches macOS keeps outside the home folder", "Restart in Safe Mode, which clears them; deleting them under running apps can crash those apps"},
+	{"/private/var/folders/*/*/T", "Per-user temporary files macOS keeps outside the home folder", "Restart, which clears them, rather than deleting them by hand"},
+	{"/var/cache/apt/archives", "Downloaded .deb packages", "sudo apt-get clean"},
+	{"/var/cache/dnf", "dnf package downloads and metadata", "sudo dnf clean all"},
+	{"/var/cache/pacman/pkg", "Downloaded pacman packages", "sudo paccache -r (keeps the last three versions)"},
+	{"/var/lib/snapd/cache", "Downloaded snap packages", "sudo find /var/lib/snapd/cache -type f -delete"},
+}
+
+// ClassifySystemCache reports which of the SystemCaches dir is
+func ClassifySystemCache(dir string) (SystemCache, bool) {
+	dir = filepath.ToSlash(filepath.Clean(dir))
+	candidates := []string{dir}
+	// On macOS /var is a link to /private/var, so either may be given
+	if rest, ok := strings.CutPrefix(dir, "/var/"); ok {
+		candidates = append(candidates, "/private/var/"+rest)
+	}
+
+	for _, sc := range SystemCaches {
+		for _, c := range candidates {
+			if ok, _ := path.Match(sc.Path, c); ok {
+				return sc, true
+			}
+		}
+	}
+	return SystemCache{}, false
+}
+
+// FindSystemCaches returns the SystemCaches present on this machine, with
+// their patterns expanded
+func FindSystemCaches() []string {
+	var dirs []string
+	for _, sc := range SystemCaches {
+		matches, _ := filepath.Glob(filepath.FromSlash(sc.Path))
+		for _, m := range matches {
+			if info, err := os.Stat(m); err == nil && info.IsDir() {
+				dirs = append(dirs, m)
+			}
+		}
+	}
+	return dirs
+}
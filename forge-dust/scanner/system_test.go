@@ -0,0 +1,40 @@
+package scanner
+
+import "testing"
+
+func TestClassifySystemCache(t *testing.T) {
+	tests := []struct {
+		dir  string
+		want string // Pattern of the SystemCache it is, "" for none
+	}{
+		{"/Library/Caches", "/Library/Caches"},
+		{"/Library/Caches/", "/Library/Caches"},
+		{"/private/var/folders/zz/zyxvpxvq6csfxvn_n0000000000000/C", "/private/var/folders/*/*/C"},
+		{"/var/folders/zz/zyxvpxvq6csfxvn_n0000000000000/T", "/private/var/folders/*/*/T"}, // /var links to /private/var
+		{"/var/cache/apt/archives", "/var/cache/apt/archives"},
+		{"/var/cache/pacman/pkg", "/var/cache/pacman/pkg"},
+
+		{"/Users/alice/Library/Caches", ""}, // a user's own, found by the normal scan
+		{"/Library/Caches/com.apple.iconservices", ""},
+		{"/private/var/folders/zz/zyxvpxvq6csfxvn_n0000000000000/0", ""}, // per-user data, not a cache
+		{"/private/var/folders/zz/C", ""},
+		{"/var/cache", ""},
+	}
+	for _, tt := range tests {
+		sc, ok := ClassifySystemCache(tt.dir)
+		if ok != (tt.want != "") || sc.Path != tt.want {
+			t.Errorf("ClassifySystemCache(%q) = %q, %v, want %q", tt.dir, sc.Path, ok, tt.want)
+		}
+	}
+}
+
+func TestSystemCachesHaveAdvice(t *testing.T) {
+	for _, sc := range SystemCaches {
+		if sc.Description == "" || sc.Advice == "" {
+			t.Errorf("%s has no description or advice", sc.Path)
+		}
+		if _, ok := ClassifySystemCache(sc.Path); !ok {
+			t.Errorf("ClassifySystemCache(%q) doesn't recognise its own entry", sc.Path)
+		}
+	}
+}
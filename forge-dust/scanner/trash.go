@@ -0,0 +1,66 @@
+package scanner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// TrashDirs are where files go when deleted from the desktop, relative to home
+var TrashDirs = []string{
+	".Trash",             // macOS
+	".local/share/Trash", // freedesktop (Linux)
+}
+
+// TrashPaths returns the TrashDirs under home
+func TrashPaths(home string) []string {
+	paths := make([]string, len(TrashDirs))
+	for i, dir := range TrashDirs {
+		paths[i] = filepath.Join(home, filepath.FromSlash(dir))
+	}
+	return paths
+}
+
+// IsTrash reports whether path is one of home's trash folders
+func IsTrash(path, home string) bool {
+	for _, trash := range TrashPaths(home) {
+		if path == trash {
+			return true
+		}
+	}
+	return false
+}
+
+// InTrash reports whether path is inside one of home's trash folders
+func InTrash(path, home string) bool {
+	for _, trash := range TrashPaths(home) {
+		if rel, err := filepath.Rel(trash, path); err == nil && rel != "." && filepath.IsLocal(rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// EmptyTrash deletes everything inside trash but keeps the folder itself,
+// which the desktop expects to find. It refuses any path that isn't one of
+// home's trash folders, so a bad argument can't turn into rm -rf.
+func EmptyTrash(trash, home string) error {
+	if !IsTrash(filepath.Clean(trash), home) {
+		return fmt.Errorf("%s is not a trash folder", trash)
+	}
+
+	entries, err := os.ReadDir(trash)
+	if err != nil {
+		return err
+	}
+	var failed int
+	for _, e := range entries {
+		if err := os.RemoveAll(filepath.Join(trash, e.Name())); err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("could not remove %d of %d items in %s", failed, len(entries), trash)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTrashClassification(t *testing.T) {
+	home := "/Users/me"
+	tests := []struct {
+		path          string
+		trash, inside bool
+	}{
+		{"/Users/me/.Trash", true, false},
+		{"/Users/me/.local/share/Trash", true, false},
+		{"/Users/me/.Trash/old-project/node_modules", false, true},
+		{"/Users/me/.local/share/Trash/files/a.iso", false, true},
+		{"/Users/me/Projects/.Trash", false, false}, // only home's trash is special
+		{"/Users/me/.TrashOther/x", false, false},
+		{"/Users/me", false, false},
+	}
+
+	for _, tt := range tests {
+		if got := IsTrash(tt.path, home); got != tt.trash {
+			t.Errorf("IsTrash(%q) = %v, want %v", tt.path, got, tt.trash)
+		}
+		if got := InTrash(tt.path, home); got != tt.inside {
+			t.Errorf("InTrash(%q) = %v, want %v", tt.path, got, tt.inside)
+		}
+	}
+
+	if _, isCache := ClassifyCacheDir(filepath.Join(home, ".Trash")); isCache {
+		t.Error("ClassifyCacheDir(.Trash) = true, want the Trash handled on its own")
+	}
+}
+
+func TestEmptyTrashIsGuarded(t *testing.T) {
+	home := t.TempDir()
+	trash := filepath.Join(home, ".Trash")
+	for _, p := range []string{"photo.jpg", "old-project/src/main.go"} {
+		path := filepath.Join(trash, p)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	keep := filepath.Join(home, "Documents", "thesis.tex")
+	if err := os.MkdirAll(filepath.Dir(keep), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keep, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Anything but a trash folder is refused outright
+	for _, bad := range []string{filepath.Join(home, "Documents"), home, filepath.Join(trash, "old-project")} {
+		if err := EmptyTrash(bad, home); err == nil {
+			t.Errorf("EmptyTrash(%s) error = nil, want a refusal", bad)
+		}
+	}
+	if _, err := os.Stat(keep); err != nil {
+		t.Fatalf("refused EmptyTrash still removed %s: %v", keep, err)
+	}
+
+	if err := EmptyTrash(trash, home); err != nil {
+		t.Fatalf("EmptyTrash() error = %v", err)
+	}
+	entries, err := os.ReadDir(trash)
+	if err != nil {
+		t.Fatalf("trash folder itself is gone: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("trash still holds %d entries after emptying", len(entries))
+	}
+}
@@ -0,0 +1,92 @@
+// Package timing records how long each stage of a run takes, for --timings.
+package timing
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Phase is one named stage and the time spent in it
+type Phase struct {
+	Name     string
+	Duration time.Duration
+	Count    int // Times the phase ran; its durations are summed
+}
+
+// Timer accumulates named phases. A nil Timer records nothing, so code can
+// time itself without checking whether anyone asked.
+type Timer struct {
+	mu     sync.Mutex
+	start  time.Time
+	phases []Phase
+	now    func() time.Time
+}
+
+// New returns a Timer whose total runs from now
+func New() *Timer {
+	return &Timer{start: time.Now(), now: time.Now}
+}
+
+// Start begins timing name and returns the func that ends it, for
+// `defer t.Start("scan")()`
+func (t *Timer) Start(name string) func() {
+	if t == nil {
+		return func() {}
+	}
+	began := t.now()
+	return func() { t.Add(name, t.now().Sub(began)) }
+}
+
+// Add records d against name, adding to any earlier runs of the phase
+func (t *Timer) Add(name string, d time.Duration) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for i := range t.phases {
+		if t.phases[i].Name == name {
+			t.phases[i].Duration += d
+			t.phases[i].Count++
+			return
+		}
+	}
+	t.phases = append(t.phases, Phase{Name: name, Duration: d, Count: 1})
+}
+
+// Phases returns the phases in the order they were first recorded
+func (t *Timer) Phases() []Phase {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]Phase(nil), t.phases...)
+}
+
+// Total is the time since the Timer was created
+func (t *Timer) Total() time.Duration {
+	if t == nil {
+		return 0
+	}
+	return t.now().Sub(t.start)
+}
+
+// Report writes a phase per line, then the total. Phases can overlap or nest
+// ("analyze" includes "analyze: duplicates"), so they needn't add up to it.
+func (t *Timer) Report(w io.Writer) {
+	if t == nil {
+		return
+	}
+	fmt.Fprintln(w, "Timings:")
+	for _, p := range t.Phases() {
+		line := fmt.Sprintf("  %-24s %10s", p.Name, p.Duration.Round(time.Millisecond))
+		if p.Count > 1 {
+			line += fmt.Sprintf("  (%d runs)", p.Count)
+		}
+		fmt.Fprintln(w, line)
+	}
+	fmt.Fprintf(w, "  %-24s %10s\n", "total", t.Total().Round(time.Millisecond))
+}
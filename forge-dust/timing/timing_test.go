@@ -0,0 +1,53 @@
+package timing
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTimerAccumulatesPhases(t *testing.T) {
+	clock := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	timer := &Timer{start: clock, now: func() time.Time { return clock }}
+
+	stop := timer.Start("scan")
+	clock = clock.Add(1500 * time.Millisecond)
+	stop()
+	timer.Add("llm: qwen3", 2*time.Second)
+	timer.Add("analyze", 250*time.Millisecond)
+	timer.Add("llm: qwen3", time.Second)
+	clock = clock.Add(4 * time.Second)
+
+	want := []Phase{
+		{"scan", 1500 * time.Millisecond, 1},
+		{"llm: qwen3", 3 * time.Second, 2},
+		{"analyze", 250 * time.Millisecond, 1},
+	}
+	if got := timer.Phases(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Phases() = %v, want %v", got, want)
+	}
+	if got := timer.Total(); got != 5500*time.Millisecond {
+		t.Errorf("Total() = %v, want 5.5s", got)
+	}
+
+	var buf bytes.Buffer
+	timer.Report(&buf)
+	wantReport := "Timings:\n" +
+		"  scan                           1.5s\n" +
+		"  llm: qwen3                       3s  (2 runs)\n" +
+		"  analyze                       250ms\n" +
+		"  total                          5.5s\n"
+	if buf.String() != wantReport {
+		t.Errorf("Report() =\n%s\nwant\n%s", buf.String(), wantReport)
+	}
+}
+
+func TestNilTimerRecordsNothing(t *testing.T) {
+	var timer *Timer
+	timer.Start("scan")()
+	timer.Add("analyze", time.Second)
+	if got := timer.Phases(); got != nil {
+		t.Errorf("nil Timer Phases() = %v, want nil", got)
+	}
+}
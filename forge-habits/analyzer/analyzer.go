@@ -9,11 +9,14 @@ import (
 
 type Analysis struct {
 	TotalCommands    int
+	RawCommands      int // TotalCommands plus repeats that were collapsed
 	TopCommands      []CommandCount
 	AliasCandidates  []CommandCount
 	DirectoryStats   []CommandCount
 	PipelineCommands []CommandCount
 	CommandSequences []SequenceCount
+	CommandChains    []CommandCount // Lines chaining commands with && or ;, by ChainPattern
+	LongRunning      []LongRunning  // Commands that often take LongRun or more; only for timed history
 	PossibleTypos    []Typo
 }
 
@@ -62,6 +65,7 @@ var knownValidCommands = map[string]bool{
 func Analyze(data *parser.HistoryData) *Analysis {
 	analysis := &Analysis{
 		TotalCommands: len(data.Commands),
+		RawCommands:   max(data.RawCount, len(data.Commands)),
 	}
 
 	// Count command frequencies
@@ -69,6 +73,7 @@ func Analyze(data *parser.HistoryData) *Analysis {
 	fullCmdCounts := make(map[string]int)
 	dirCounts := make(map[string]int)
 	pipelineCounts := make(map[string]int)
+	chainCounts := make(map[string]int)
 
 	for _, cmd := range data.Commands {
 		// First word (command name)
@@ -88,6 +93,11 @@ func Analyze(data *parser.HistoryData) *Analysis {
 		if strings.Contains(cmd.Raw, "|") {
 			pipelineCounts[cmd.Raw]++
 		}
+
+		// Chains within a line, like git add . && git commit && git push
+		if pattern, ok := ChainPattern(cmd.Raw); ok {
+			chainCounts[pattern]++
+		}
 	}
 
 	// Top commands
@@ -114,12 +124,24 @@ func Analyze(data *parser.HistoryData) *Analysis {
 	}
 	analysis.PipelineCommands = topN(pipelines, 10)
 
+	// Command chains
+	chains := make(map[string]int)
+	for pattern, count := range chainCounts {
+		if count >= 2 {
+			chains[pattern] = count
+		}
+	}
+	analysis.CommandChains = topN(chains, 10)
+
 	// Command sequences
 	analysis.CommandSequences = analyzeSequences(data.Commands)
 
 	// Typo detection
 	analysis.PossibleTypos = detectTypos(cmdCounts)
 
+	// Slow commands, where the history says how long each took
+	analysis.LongRunning = longRunning(data.Commands)
+
 	return analysis
 }
 
@@ -0,0 +1,110 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SplitChain splits a line into the commands chained by && and ; outside
+// quotes, returning them and the operator between each pair. A line with
+// neither is a single command.
+func SplitChain(line string) (steps, ops []string) {
+	start := 0
+	op := ""
+	flush := func(end int, next string) {
+		if step := strings.TrimSpace(line[start:end]); step != "" {
+			if len(steps) > 0 {
+				ops = append(ops, op)
+			}
+			steps = append(steps, step)
+		}
+		op = next
+	}
+
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\\':
+			i++
+		case c == '\'' || c == '"':
+			quote = c
+		case c == ';':
+			flush(i, ";")
+			start = i + 1
+		case c == '&' && i+1 < len(line) && line[i+1] == '&':
+			flush(i, "&&")
+			i++
+			start = i + 1
+		}
+	}
+	flush(len(line), "")
+	return steps, ops
+}
+
+// ChainPattern returns the chain on line with each quoted argument replaced
+// by "$1", "$2" and so on, so chains differing only in a commit message or a
+// file name count as one. ok is false unless line chains two or more
+// commands, and for pipelines, which are counted on their own.
+func ChainPattern(line string) (pattern string, ok bool) {
+	if strings.Contains(line, "|") {
+		return "", false
+	}
+	steps, ops := SplitChain(parameterize(line))
+	if len(steps) < 2 {
+		return "", false
+	}
+
+	var sb strings.Builder
+	sb.WriteString(steps[0])
+	for i, op := range ops {
+		if op == ";" {
+			sb.WriteString("; ")
+		} else {
+			sb.WriteString(" && ")
+		}
+		sb.WriteString(steps[i+1])
+	}
+	return sb.String(), true
+}
+
+// parameterize replaces each quoted string on line with "$1", "$2"...
+// in order. An unterminated quote is left as it is.
+func parameterize(line string) string {
+	var sb strings.Builder
+	n := 0
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		if c == '\\' && i+1 < len(line) {
+			sb.WriteString(line[i : i+2])
+			i++
+			continue
+		}
+		if c != '\'' && c != '"' {
+			sb.WriteByte(c)
+			continue
+		}
+
+		end := i + 1
+		for end < len(line) && line[end] != c {
+			if c == '"' && line[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end >= len(line) {
+			sb.WriteString(line[i:])
+			break
+		}
+		n++
+		fmt.Fprintf(&sb, `"$%d"`, n)
+		i = end
+	}
+	return sb.String()
+}
@@ -0,0 +1,70 @@
+package analyzer
+
+import (
+	"reflect"
+	"testing"
+
+	"forge-habits/parser"
+)
+
+func TestSplitChain(t *testing.T) {
+	tests := []struct {
+		line  string
+		steps []string
+		ops   []string
+	}{
+		{"ls -la", []string{"ls -la"}, nil},
+		{"git add . && git commit && git push", []string{"git add .", "git commit", "git push"}, []string{"&&", "&&"}},
+		{"cd src; make;make install", []string{"cd src", "make", "make install"}, []string{";", ";"}},
+		{"make && ./run || echo failed", []string{"make", "./run || echo failed"}, []string{"&&"}},
+		{`git commit -m "a && b; c" && git push`, []string{`git commit -m "a && b; c"`, "git push"}, []string{"&&"}},
+		{`find . -exec rm {} \; && ls`, []string{`find . -exec rm {} \;`, "ls"}, []string{"&&"}},
+		{"sleep 5 & wait", []string{"sleep 5 & wait"}, nil}, // backgrounding isn't chaining
+		{"make;", []string{"make"}, nil},
+	}
+	for _, tt := range tests {
+		steps, ops := SplitChain(tt.line)
+		if !reflect.DeepEqual(steps, tt.steps) || !reflect.DeepEqual(ops, tt.ops) {
+			t.Errorf("SplitChain(%q) = %q, %q, want %q, %q", tt.line, steps, ops, tt.steps, tt.ops)
+		}
+	}
+}
+
+func TestChainPattern(t *testing.T) {
+	tests := []struct {
+		line   string
+		want   string
+		wantOK bool
+	}{
+		{"git add . && git commit -m 'fix login' && git push", `git add . && git commit -m "$1" && git push`, true},
+		{`git add .&&git commit -m "wip"&&git push`, `git add . && git commit -m "$1" && git push`, true},
+		{`cp "a b" c; echo 'done'`, `cp "$1" c; echo "$2"`, true},
+		{"git status", "", false},
+		{"ps aux | grep node && echo found", "", false}, // a pipeline, counted as one
+	}
+	for _, tt := range tests {
+		got, ok := ChainPattern(tt.line)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("ChainPattern(%q) = %q, %v, want %q, %v", tt.line, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestAnalyzeCountsChains(t *testing.T) {
+	var data parser.HistoryData
+	for _, line := range []string{
+		"git add . && git commit -m 'fix login' && git push",
+		"ls",
+		`git add . && git commit -m "bump deps" && git push`,
+		"git add . && git commit -m 'typo' && git push",
+		"make && make install", // once isn't a habit
+	} {
+		data.Commands = append(data.Commands, parser.Command{Raw: line})
+	}
+
+	got := Analyze(&data).CommandChains
+	want := []CommandCount{{Command: `git add . && git commit -m "$1" && git push`, Count: 3}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CommandChains = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,91 @@
+package analyzer
+
+import (
+	"sort"
+	"time"
+
+	"forge-habits/parser"
+)
+
+// LongRun is how long a run must take to count as long: long enough to
+// sit waiting for
+const LongRun = time.Minute
+
+// MinLongRuns is how many long runs a command needs to be worth a tip
+const MinLongRuns = 3
+
+// LongRunning is a command that often keeps you waiting
+type LongRunning struct {
+	Command  string
+	Runs     int           // Every run, quick ones included
+	LongRuns int           // Runs of at least LongRun
+	Average  time.Duration // Per run, over every run
+	Longest  time.Duration
+}
+
+// Timed reports whether the history records how long commands took. zsh
+// writes 0 for every command unless it's set to, so one non-zero time is
+// the tell.
+func Timed(commands []parser.Command) bool {
+	for _, cmd := range commands {
+		if cmd.Elapsed > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// longRunning finds the commands, by their whole line, that took LongRun
+// or more at least MinLongRuns times, most total time first. Without
+// timings there is nothing to find.
+func longRunning(commands []parser.Command) []LongRunning {
+	if !Timed(commands) {
+		return nil
+	}
+
+	type tally struct {
+		LongRunning
+		total time.Duration
+	}
+	tallies := make(map[string]*tally)
+	for _, cmd := range commands {
+		t, ok := tallies[cmd.Raw]
+		if !ok {
+			t = &tally{LongRunning: LongRunning{Command: cmd.Raw}}
+			tallies[cmd.Raw] = t
+		}
+		runs := 1 + cmd.Repeats
+		elapsed := time.Duration(cmd.Elapsed) * time.Second
+		// Collapsed repeats only kept their total, so each counts as the average
+		each := elapsed / time.Duration(runs)
+		t.Runs += runs
+		t.total += elapsed
+		if each >= LongRun {
+			t.LongRuns += runs
+		}
+		t.Longest = max(t.Longest, each)
+	}
+
+	var long []tally
+	for _, t := range tallies {
+		if t.LongRuns >= MinLongRuns {
+			t.Average = t.total / time.Duration(t.Runs)
+			long = append(long, *t)
+		}
+	}
+	sort.Slice(long, func(i, j int) bool {
+		if long[i].total != long[j].total {
+			return long[i].total > long[j].total
+		}
+		return long[i].Command < long[j].Command
+	})
+
+	var result []LongRunning
+	for i, t := range long {
+		if i >= 10 {
+			break
+		}
+		result = append(result, t.LongRunning)
+	}
+	return result
+}
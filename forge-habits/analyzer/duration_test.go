@@ -0,0 +1,52 @@
+package analyzer
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"forge-habits/parser"
+)
+
+func TestLongRunning(t *testing.T) {
+	run := func(raw string, elapsed int64) parser.Command {
+		return parser.Command{Raw: raw, Elapsed: elapsed}
+	}
+	commands := []parser.Command{
+		run("make build", 240), run("make build", 300), run("make build", 5), run("make build", 180),
+		run("npm test", 90), run("npm test", 70), run("npm test", 65),
+		run("cargo build", 600), run("cargo build", 610), // Slow, but only twice
+		run("ls", 0), run("git status", 1), run("git status", 0),
+		{Raw: "pytest", Elapsed: 360, Repeats: 2}, // Three runs folded together, 2m each
+	}
+
+	got := longRunning(commands)
+	want := []LongRunning{
+		{Command: "make build", Runs: 4, LongRuns: 3, Average: 181*time.Second + 250*time.Millisecond, Longest: 5 * time.Minute},
+		{Command: "pytest", Runs: 3, LongRuns: 3, Average: 2 * time.Minute, Longest: 2 * time.Minute},
+		{Command: "npm test", Runs: 3, LongRuns: 3, Average: 75 * time.Second, Longest: 90 * time.Second},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("longRunning() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestLongRunningNeedsTimedHistory(t *testing.T) {
+	// Plain history, or zsh writing 0 for every command, says nothing about time
+	commands := []parser.Command{{Raw: "make build"}, {Raw: "make build"}, {Raw: "make build"}, {Raw: "make build"}}
+	if Timed(commands) {
+		t.Error("Timed() = true for history without elapsed times")
+	}
+	if got := longRunning(commands); got != nil {
+		t.Errorf("longRunning() = %+v, want nothing without timings", got)
+	}
+
+	analysis := Analyze(&parser.HistoryData{Commands: []parser.Command{
+		{Raw: "make", Command: "make", Elapsed: 120},
+		{Raw: "make", Command: "make", Elapsed: 125},
+		{Raw: "make", Command: "make", Elapsed: 130},
+	}})
+	if len(analysis.LongRunning) != 1 || analysis.LongRunning[0].Command != "make" {
+		t.Errorf("Analyze().LongRunning = %+v, want make", analysis.LongRunning)
+	}
+}
@@ -1,3 +1,12 @@
 module forge-habits
 
 go 1.25.5
+
+require forge-shared v0.0.0
+
+require (
+	golang.org/x/sys v0.41.0 // indirect
+	golang.org/x/term v0.40.0 // indirect
+)
+
+replace forge-shared => ../forge-shared
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"io"
+
 	"forge-habits/analyzer"
 	"forge-habits/parser"
 	"forge-habits/suggestions"
@@ -18,6 +20,8 @@ type LLMClient interface {
 type HistoryParser interface {
 	// Parse reads and parses a shell history file
 	Parse(filePath string, shellType string) (*parser.HistoryData, error)
+	// ParseReader parses commands from a reader, such as piped stdin
+	ParseReader(r io.Reader, shellType string) (*parser.HistoryData, error)
 }
 
 // ShellConfig defines the interface for shell configuration operations
@@ -34,10 +38,10 @@ type ShellConfig interface {
 
 // SuggestionGenerator defines the interface for generating suggestions
 type SuggestionGenerator interface {
-	// Generate creates suggestions using LLM
-	Generate(analysis *analyzer.Analysis, client LLMClient) *suggestions.SuggestionSet
-	// GenerateWithoutLLM creates suggestions using heuristics
-	GenerateWithoutLLM(analysis *analyzer.Analysis) *suggestions.SuggestionSet
+	// Generate creates suggestions using LLM, skipping dismissed patterns
+	Generate(analysis *analyzer.Analysis, client LLMClient, dismissed *suggestions.Dismissed) *suggestions.SuggestionSet
+	// GenerateWithoutLLM creates suggestions using heuristics, skipping dismissed patterns
+	GenerateWithoutLLM(analysis *analyzer.Analysis, dismissed *suggestions.Dismissed) *suggestions.SuggestionSet
 }
 
 // Analyzer defines the interface for analyzing shell history
@@ -195,6 +195,33 @@ func buildPrompt(analysis *analyzer.Analysis) string {
 		}
 	}
 
+	// Chains on one line, quoted arguments already replaced by "$1", "$2"...
+	if len(analysis.CommandChains) > 0 {
+		sb.WriteString("\n### Commands Chained on One Line (Function Candidates)\n")
+		for i, chain := range analysis.CommandChains {
+			if i >= 5 {
+				break
+			}
+			display := SanitizeCommand(chain.Command)
+			if len(display) > 60 {
+				display = display[:60] + "..."
+			}
+			sb.WriteString(fmt.Sprintf("- `%s`: %d times\n", display, chain.Count))
+		}
+	}
+
+	// Slow commands, only known from timed history
+	if len(analysis.LongRunning) > 0 {
+		sb.WriteString("\n### Long-Running Commands (Worth Backgrounding or Caching)\n")
+		for i, lr := range analysis.LongRunning {
+			if i >= 5 {
+				break
+			}
+			sb.WriteString(fmt.Sprintf("- `%s`: %d of %d runs took a minute or more, %s on average\n",
+				SanitizeCommand(lr.Command), lr.LongRuns, lr.Runs, lr.Average.Round(time.Second)))
+		}
+	}
+
 	// Typos
 	if len(analysis.PossibleTypos) > 0 {
 		sb.WriteString("\n### Possible Typos\n")
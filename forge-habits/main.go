@@ -1,155 +1,292 @@
 package main
 
 import (
-	"bufio"
 	"flag"
 	"fmt"
+	"io"
 	"os"
-	"strconv"
+	"os/exec"
+	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
 	"forge-habits/analyzer"
 	"forge-habits/llm"
 	"forge-habits/parser"
+	"forge-habits/scrub"
 	"forge-habits/shell"
+	"forge-habits/stats"
 	"forge-habits/suggestions"
 	. "forge-habits/ui" // Import colors into current namespace
+	"forge-shared/pager"
+	"forge-shared/prompt"
 )
 
 var (
 	version = "0.1.0"
-	reader  *bufio.Reader
+	reader  prompt.LineReader
 )
 
 func main() {
-	reader = bufio.NewReader(os.Stdin)
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
 
-	// CLI flags
-	historyFile := flag.String("file", "", "Path to history file (auto-detected if not specified)")
-	shellType := flag.String("shell", "", "Shell type: zsh or bash (auto-detected if not specified)")
-	showVersion := flag.Bool("version", false, "Show version")
-	reportOnly := flag.Bool("report", false, "Just show report, no interactive prompts")
-	noLLM := flag.Bool("no-llm", false, "Skip LLM analysis, use heuristics only")
-	model := flag.String("model", "kimi-k2-thinking:cloud", "Ollama model to use")
+// run is forge-habits given args, without the program name, returning the
+// exit code rather than exiting so it can be driven from tests
+func run(args []string, stdout, stderr io.Writer) int {
+	reader = prompt.NewLineReader(os.Stdin)
 
-	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, `forge-habits - Analyze shell history and forge better workflows
+	// CLI flags
+	flags := flag.NewFlagSet("forge-habits", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	historyFile := flags.String("file", "", "Path to history file (auto-detected if not specified)")
+	shellType := flags.String("shell", "", "Shell type: zsh or bash (auto-detected if not specified)")
+	showVersion := flags.Bool("version", false, "Show version")
+	reportOnly := flags.Bool("report", false, "Just show report, no interactive prompts")
+	noLLM := flags.Bool("no-llm", false, "Skip LLM analysis, use heuristics only")
+	model := flags.String("model", "kimi-k2-thinking:cloud", "Ollama model to use")
+	showStats := flags.Bool("stats", false, "Show how many suggestions you've accepted over time")
+	resetDismissed := flags.Bool("reset-dismissed", false, "Forget suggestions you marked as not useful")
+	targetShell := flags.String("target-shell", "", "Write suggestions for this shell instead of yours: bash, zsh, fish or pwsh")
+	fromStdin := flags.Bool("stdin", false, "Analyze commands piped on stdin instead of your history (implies --report)")
+	scrubHistory := flags.Bool("scrub", false, "Find secrets in your history file and offer to redact them")
+	collapseRepeats := flags.Bool("collapse-repeats", false, "Count a command entered several times in a row once, rather than each time")
+	exportPath := flags.String("export-dotfiles", "", "Write the suggestions you've accepted to a standalone file for a dotfiles repo, leaving your RC alone")
+	noPager := flags.Bool("no-pager", false, "Print the report straight to the terminal instead of through $PAGER when it's long")
+	exportHighImpact := flags.Bool("export-high-impact", false, "With --export-dotfiles, export this run's high-impact suggestions instead")
+
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, `forge-habits - Analyze shell history and forge better workflows
 
 Usage:
   forge-habits [flags]
 
 Flags:
 `)
-		flag.PrintDefaults()
-		fmt.Fprintf(os.Stderr, `
+		flags.PrintDefaults()
+		fmt.Fprintf(stderr, `
 Examples:
   forge-habits                    # Interactive analysis
   forge-habits --report           # Just show the report
   forge-habits --no-llm           # Skip LLM, use heuristics only
+  forge-habits --stats            # Show your acceptance history
+  forge-habits --scrub            # Redact passwords and tokens from your history
+  forge-habits --reset-dismissed  # Bring back suggestions you marked not useful
+  cat history | forge-habits --stdin  # Analyze someone else's history
+  forge-habits --target-shell fish    # Suggest fish functions from your zsh history
+  forge-habits --export-dotfiles ~/dotfiles/forge-habits.zsh  # Take your accepted suggestions elsewhere
 `)
 	}
 
-	flag.Parse()
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2 // What flag.Parse exited with
+	}
+	var err error
 
 	if *showVersion {
-		fmt.Printf("forge-habits v%s\n", version)
-		os.Exit(0)
+		fmt.Fprintf(stdout, "forge-habits v%s\n", version)
+		return 0
+	}
+
+	if *showStats {
+		return printStats(stdout, stderr)
+	}
+
+	if *resetDismissed {
+		if err := os.Remove(suggestions.DismissedPath()); err != nil && !os.IsNotExist(err) {
+			fmt.Fprintf(stderr, "Error clearing dismissed suggestions: %v\n", err)
+			return 1
+		}
+		printInfo(stdout, "Dismissed suggestions cleared; they can be suggested again.")
+		return 0
+	}
+
+	var target shell.Target
+	if *targetShell != "" {
+		if target, err = shell.ParseTarget(*targetShell); err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+	}
+
+	if *exportPath != "" && !*exportHighImpact {
+		if err := exportAccepted(stdout, *exportPath, target); err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	if *scrubHistory {
+		return runScrub(stdout, stderr, *historyFile, *shellType)
 	}
 
 	// Parse history
-	printInfo("Examining your command history...")
-	historyData, err := parser.Parse(*historyFile, *shellType)
+	var historyData *parser.HistoryData
+	if *fromStdin {
+		// Stdin holds the commands, so there is nothing left to answer prompts with
+		*reportOnly = true
+		printInfo(stdout, "Examining commands from stdin...")
+		historyData, err = parser.ParseReader(os.Stdin, *shellType)
+		if historyData != nil {
+			historyData.FilePath = "stdin"
+		}
+	} else {
+		printInfo(stdout, "Examining your command history...")
+		historyData, err = parser.Parse(*historyFile, *shellType)
+	}
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error parsing history: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintf(stderr, "Error parsing history: %v\n", err)
+		return 1
 	}
 
-	printInfo(fmt.Sprintf("Found %d commands in %s",
+	printInfo(stdout, fmt.Sprintf("Found %d commands in %s",
 		len(historyData.Commands),
 		historyData.FilePath))
+	if *collapseRepeats {
+		historyData.CollapseRepeats()
+		if repeats := historyData.RawCount - len(historyData.Commands); repeats > 0 {
+			printInfo(stdout, fmt.Sprintf("Counting %d back-to-back repeats once", repeats))
+		}
+	}
 
 	// Analyze
 	analysis := analyzer.Analyze(historyData)
 
+	dismissed, err := suggestions.LoadDismissed(suggestions.DismissedPath())
+	if err != nil {
+		fmt.Fprintf(stderr, "%sWarning: could not read dismissed suggestions: %v%s\n", Yellow, err, Reset)
+	}
+
 	// Generate actionable suggestions
 	var suggestionSet *suggestions.SuggestionSet
 	if *noLLM {
-		printInfo("Using heuristics (LLM disabled)")
-		suggestionSet = suggestions.GenerateWithoutLLM(analysis)
+		printInfo(stdout, "Using heuristics (LLM disabled)")
+		suggestionSet = suggestions.GenerateWithoutLLM(analysis, dismissed)
 	} else {
 		client := llm.NewClient(*model)
 		if !client.IsAvailable() {
-			printInfo("Ollama not available, using heuristics")
-			suggestionSet = suggestions.GenerateWithoutLLM(analysis)
+			printInfo(stdout, "Ollama not available, using heuristics")
+			suggestionSet = suggestions.GenerateWithoutLLM(analysis, dismissed)
 		} else {
-			printInfo(fmt.Sprintf("Consulting the oracle (%s)...", *model))
-			suggestionSet = suggestions.Generate(analysis, client)
+			printInfo(stdout, fmt.Sprintf("Consulting the oracle (%s)...", *model))
+			suggestionSet = suggestions.Generate(analysis, client, dismissed)
 		}
 	}
 
-	// Show header
-	printHeader()
+	if target != "" {
+		retarget(stdout, suggestionSet, target)
+	}
+
+	if *exportPath != "" {
+		var entries []string
+		for _, s := range suggestionSet.HighImpact {
+			entries = append(entries, s.Code)
+		}
+		if err := writeExport(stdout, *exportPath, entries, target); err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+		return 0
+	}
 
 	if *reportOnly {
-		showReport(analysis, suggestionSet)
-		return
+		// Paged if it's longer than the terminal
+		report := pager.Start(*noPager || stdout != os.Stdout)
+		out := report.Writer(stdout)
+		printHeader(out)
+		showReport(out, analysis, suggestionSet)
+		report.Stop()
+		return 0
 	}
 
 	// Interactive flow
-	runInteractive(analysis, suggestionSet)
+	printHeader(stdout)
+	runInteractive(stdout, stderr, analysis, suggestionSet, dismissed, target)
+	return 0
+}
+
+// retarget rewrites every suggestion's code for target, dropping the ones
+// that can't be translated
+func retarget(w io.Writer, set *suggestions.SuggestionSet, target shell.Target) {
+	translate := func(list []suggestions.Suggestion) []suggestions.Suggestion {
+		var kept []suggestions.Suggestion
+		for _, s := range list {
+			code, err := shell.Translate(s.Code, target)
+			if err != nil {
+				printInfo(w, fmt.Sprintf("Skipping %s: %v", s.Name, err))
+				continue
+			}
+			s.Code = code
+			kept = append(kept, s)
+		}
+		return kept
+	}
+	set.HighImpact = translate(set.HighImpact)
+	set.Review = translate(set.Review)
 }
 
-func printHeader() {
-	fmt.Println()
-	fmt.Printf("%s%s────────────────────────────────────────────────────────────%s\n", Bold, Cyan, Reset)
-	fmt.Printf("%s  ⚒  FORGE-HABITS%s\n", Bold+Cyan, Reset)
-	fmt.Printf("%s────────────────────────────────────────────────────────────%s\n", Bold+Cyan, Reset)
+func printHeader(w io.Writer) {
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "%s%s────────────────────────────────────────────────────────────%s\n", Bold, Cyan, Reset)
+	fmt.Fprintf(w, "%s  ⚒  FORGE-HABITS%s\n", Bold+Cyan, Reset)
+	fmt.Fprintf(w, "%s────────────────────────────────────────────────────────────%s\n", Bold+Cyan, Reset)
 }
 
-func printInfo(msg string) {
-	fmt.Printf("%s%s%s\n", Dim, msg, Reset)
+func printInfo(w io.Writer, msg string) {
+	fmt.Fprintf(w, "%s%s%s\n", Dim, msg, Reset)
 }
 
-func runInteractive(analysis *analyzer.Analysis, set *suggestions.SuggestionSet) {
-	// Get RC file path
-	rcPath, err := shell.GetRCFile()
+func runInteractive(stdout, stderr io.Writer, analysis *analyzer.Analysis, set *suggestions.SuggestionSet, dismissed *suggestions.Dismissed, target shell.Target) {
+	// Get RC file path, for the target shell if one was chosen
+	rcPath, err := rcFileFor(target)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Could not determine shell config file: %v\n", err)
+		fmt.Fprintf(stderr, "Could not determine shell config file: %v\n", err)
 		return
 	}
 
-	// Filter out suggestions that already exist
-	var highImpact []suggestions.Suggestion
-	for _, s := range set.HighImpact {
-		exists, err := shell.HasAlias(rcPath, s.Name)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "%sWarning: Could not check if %s exists: %v%s\n", Yellow, s.Name, err, Reset)
-		}
-		if !exists {
-			highImpact = append(highImpact, s)
+	// Tally what the user accepts and rejects, kept only on this machine
+	var decisions []stats.Decision
+	decide := func(list []suggestions.Suggestion, accepted bool) {
+		for _, s := range list {
+			decisions = append(decisions, stats.Decision{
+				Type:       string(s.Type),
+				Confidence: string(s.Confidence),
+				Accepted:   accepted,
+			})
 		}
 	}
+	defer func() { recordStats(stderr, decisions) }()
 
-	var review []suggestions.Suggestion
-	for _, s := range set.Review {
-		exists, err := shell.HasAlias(rcPath, s.Name)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "%sWarning: Could not check if %s exists: %v%s\n", Yellow, s.Name, err, Reset)
-		}
-		if !exists {
-			review = append(review, s)
-		}
+	// Filter out suggestions that already exist, by name or by what they expand to
+	aliases, err := shell.ExistingAliases(rcPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "%sWarning: Could not read aliases from %s: %v%s\n", Yellow, rcPath, err, Reset)
+	}
+	highImpact := filterExisting(stdout, stderr, set.HighImpact, rcPath, aliases)
+	review := filterExisting(stdout, stderr, set.Review, rcPath, aliases)
+	binDir, err := shell.BinDir()
+	if err != nil {
+		fmt.Fprintf(stderr, "%sWarning: Could not find ~/bin, so no scripts: %v%s\n", Yellow, err, Reset)
+	}
+	var scripts []suggestions.Suggestion
+	if binDir != "" {
+		scripts = filterExistingScripts(set.Scripts, binDir, rcPath)
 	}
 
-	if len(highImpact) == 0 && len(review) == 0 {
-		fmt.Printf("\n%sNo new suggestions found. Your workflow is already well-forged!%s\n", Dim, Reset)
-		showTips(set.Tips)
+	if len(highImpact) == 0 && len(review) == 0 && len(scripts) == 0 {
+		fmt.Fprintf(stdout, "\n%sNo new suggestions found. Your workflow is already well-forged!%s\n", Dim, Reset)
+		showTips(stdout, set.Tips)
 		return
 	}
 
 	// High impact suggestions
 	if len(highImpact) > 0 {
-		fmt.Printf("\n%sFound %d high-impact improvements ready to forge:%s\n\n", Bold, len(highImpact), Reset)
+		fmt.Fprintf(stdout, "\n%sFound %d high-impact improvements ready to forge:%s\n\n", Bold, len(highImpact), Reset)
 
 		for i, s := range highImpact {
 			typeLabel := "alias"
@@ -157,15 +294,14 @@ func runInteractive(analysis *analyzer.Analysis, set *suggestions.SuggestionSet)
 				typeLabel = "function"
 			}
 
-			fmt.Printf("  %s[%d]%s %s%s%s %s(%s)%s\n", Cyan, i+1, Reset, Bold, s.Name, Reset, Dim, typeLabel, Reset)
-			fmt.Printf("      %sUsage:%s %s\n", Dim, Reset, s.Usage)
-			fmt.Printf("      %s%s%s\n\n", Dim, s.Description, Reset)
+			fmt.Fprintf(stdout, "  %s[%d]%s %s%s%s %s(%s)%s\n", Cyan, i+1, Reset, Bold, s.Name, Reset, Dim, typeLabel, Reset)
+			fmt.Fprintf(stdout, "      %sUsage:%s %s\n", Dim, Reset, s.Usage)
+			fmt.Fprintf(stdout, "      %s%s%s\n\n", Dim, s.Description, Reset)
 		}
 
-		fmt.Printf("Add these to %s%s%s? %s[Y/n]%s ", Cyan, rcPath, Reset, Dim, Reset)
-		response := readLine()
-
-		if response == "" || strings.ToLower(response) == "y" || strings.ToLower(response) == "yes" {
+		fmt.Fprintf(stdout, "Add these to %s%s%s? %s[Y/n]%s ", Cyan, rcPath, Reset, Dim, Reset)
+		if prompt.IsYes(readLine(stdout), true) {
+			decide(highImpact, true)
 			var toAdd []string
 			for _, s := range highImpact {
 				toAdd = append(toAdd, s.Code)
@@ -174,150 +310,456 @@ func runInteractive(analysis *analyzer.Analysis, set *suggestions.SuggestionSet)
 			// Backup first
 			backupPath, backupErr := shell.Backup(rcPath)
 			if backupErr != nil {
-				fmt.Printf("%sWarning: Could not create backup: %v%s\n", Yellow, backupErr, Reset)
-				fmt.Printf("Continue without backup? %s[y/N]%s ", Dim, Reset)
-				confirm := readLine()
-				if strings.ToLower(confirm) != "y" && strings.ToLower(confirm) != "yes" {
-					fmt.Printf("%sCancelled. Your RC file was not modified.%s\n", Dim, Reset)
+				fmt.Fprintf(stdout, "%sWarning: Could not create backup: %v%s\n", Yellow, backupErr, Reset)
+				fmt.Fprintf(stdout, "Continue without backup? %s[y/N]%s ", Dim, Reset)
+				if !prompt.IsYes(readLine(stdout), false) {
+					fmt.Fprintf(stdout, "%sCancelled. Your RC file was not modified.%s\n", Dim, Reset)
 					return
 				}
 			} else if backupPath != "" {
-				printInfo(fmt.Sprintf("Backed up to %s", backupPath))
+				printInfo(stdout, fmt.Sprintf("Backed up to %s", backupPath))
 			}
 
 			if err := shell.AddToRC(rcPath, toAdd); err != nil {
-				fmt.Printf("%sError writing to %s: %v%s\n", Red, rcPath, err, Reset)
+				fmt.Fprintf(stdout, "%sError writing to %s: %v%s\n", Red, rcPath, err, Reset)
 				if backupPath != "" {
-					fmt.Printf("%sYou can restore from: %s%s\n", Yellow, backupPath, Reset)
+					fmt.Fprintf(stdout, "%sYou can restore from: %s%s\n", Yellow, backupPath, Reset)
 				}
 			} else {
-				fmt.Printf("\n%s✓ Forged %d improvements into %s%s\n", Green, len(toAdd), rcPath, Reset)
-				fmt.Printf("%sRun 'source %s' or open a new terminal to use them.%s\n", Dim, rcPath, Reset)
+				fmt.Fprintf(stdout, "\n%s✓ Forged %d improvements into %s%s\n", Green, len(toAdd), rcPath, Reset)
+				fmt.Fprintf(stdout, "%sRun 'source %s' or open a new terminal to use them.%s\n", Dim, rcPath, Reset)
 			}
 		} else {
-			fmt.Printf("%sSkipped.%s\n", Dim, Reset)
+			decide(highImpact, false)
+			fmt.Fprintf(stdout, "%sSkipped.%s\n", Dim, Reset)
 		}
 	}
 
 	// Review suggestions
 	if len(review) > 0 {
-		fmt.Printf("\n%s───%s\n", Cyan, Reset)
-		fmt.Printf("\n%sFound %d more patterns worth reviewing:%s\n\n", Bold, len(review), Reset)
+		fmt.Fprintf(stdout, "\n%s───%s\n", Cyan, Reset)
+		fmt.Fprintf(stdout, "\n%sFound %d more patterns worth reviewing:%s\n\n", Bold, len(review), Reset)
 
 		for i, s := range review {
-			fmt.Printf("  %s[%d]%s %s%s%s - %s\n",
+			fmt.Fprintf(stdout, "  %s[%d]%s %s%s%s - %s\n",
 				Cyan, i+1, Reset,
 				Bold, s.Name, Reset,
 				s.Description)
 		}
 
-		fmt.Printf("\n  %s[1-%d]%s Inspect  %s[a]%s Add all  %s[s]%s Skip\n",
+		fmt.Fprintf(stdout, "\n  %s[1-%d]%s Inspect  %s[a]%s Add all  %s[s]%s Skip\n",
 			Cyan, len(review), Reset,
 			Green, Reset,
 			Dim, Reset)
-		fmt.Printf("\n%s→%s ", Cyan, Reset)
+		fmt.Fprintf(stdout, "\n%s→%s ", Cyan, Reset)
 
-		input := readLine()
+		input := readLine(stdout)
 
 		// Check if number
-		if num, err := strconv.Atoi(input); err == nil && num >= 1 && num <= len(review) {
-			inspectSuggestion(review[num-1], rcPath)
+		if num, ok := prompt.ParseChoice(input, len(review)); ok {
+			chosen := review[num-1]
+			decide([]suggestions.Suggestion{chosen}, inspectSuggestion(stdout, chosen, rcPath, dismissed))
 		} else if strings.ToLower(input) == "a" {
+			decide(review, true)
 			var toAdd []string
 			for _, s := range review {
 				toAdd = append(toAdd, s.Code)
 			}
 			if err := shell.AddToRC(rcPath, toAdd); err != nil {
-				fmt.Printf("%sError: %v%s\n", Red, err, Reset)
+				fmt.Fprintf(stdout, "%sError: %v%s\n", Red, err, Reset)
 			} else {
-				fmt.Printf("\n%s✓ Forged %d more improvements.%s\n", Green, len(toAdd), Reset)
+				fmt.Fprintf(stdout, "\n%s✓ Forged %d more improvements.%s\n", Green, len(toAdd), Reset)
 			}
+		} else {
+			decide(review, false)
+		}
+	}
+
+	// Pipelines better kept as scripts
+	if len(scripts) > 0 {
+		fmt.Fprintf(stdout, "\n%s───%s\n", Cyan, Reset)
+		fmt.Fprintf(stdout, "\n%sFound %d pipelines too long for an alias, better as scripts:%s\n\n", Bold, len(scripts), Reset)
+
+		for i, s := range scripts {
+			fmt.Fprintf(stdout, "  %s[%d]%s %s%s%s - %s\n", Cyan, i+1, Reset, Bold, s.Name, Reset, s.Description)
+			fmt.Fprintf(stdout, "      %s%s%s\n", Dim, truncate(s.Command, 70), Reset)
+		}
+
+		fmt.Fprintf(stdout, "\nSave these as scripts in %s%s%s? %s[y/N]%s ", Cyan, binDir, Reset, Dim, Reset)
+		if prompt.IsYes(readLine(stdout), false) {
+			decide(scripts, true)
+			saveScripts(stdout, scripts, binDir, rcPath, target)
+		} else {
+			decide(scripts, false)
+			fmt.Fprintf(stdout, "%sSkipped.%s\n", Dim, Reset)
 		}
 	}
 
 	// Show tips
-	showTips(set.Tips)
+	showTips(stdout, set.Tips)
 
-	fmt.Printf("\n%sForged and finished.%s\n\n", Green, Reset)
+	fmt.Fprintf(stdout, "\n%sForged and finished.%s\n\n", Green, Reset)
 }
 
-func inspectSuggestion(s suggestions.Suggestion, rcPath string) {
-	fmt.Printf("\n%s────────────────────────────────────────────────%s\n", Cyan, Reset)
-	fmt.Printf("  %sName:%s %s\n", Bold, Reset, s.Name)
-	fmt.Printf("  %sOriginal:%s %s\n", Bold, Reset, s.Command)
-	fmt.Printf("  %sImpact:%s Used %d times\n", Bold, Reset, s.Impact)
-	fmt.Printf("\n  %sWould add:%s\n", Bold, Reset)
-	fmt.Printf("  %s%s%s\n", Dim, s.Code, Reset)
-	fmt.Printf("%s────────────────────────────────────────────────%s\n", Cyan, Reset)
+// filterExisting drops suggestions whose name is taken or whose command is
+// already aliased under another name
+func filterExisting(stdout, stderr io.Writer, list []suggestions.Suggestion, rcPath string, aliases map[string]string) []suggestions.Suggestion {
+	var kept []suggestions.Suggestion
+	for _, s := range list {
+		exists, err := shell.HasAlias(rcPath, s.Name)
+		if err != nil {
+			fmt.Fprintf(stderr, "%sWarning: Could not check if %s exists: %v%s\n", Yellow, s.Name, err, Reset)
+		}
+		if exists {
+			continue
+		}
+		if name, ok := shell.AliasedAs(aliases, s.Command); ok {
+			printInfo(stdout, fmt.Sprintf("Skipping %s: you already have %s for %s", s.Name, name, s.Command))
+			continue
+		}
+		kept = append(kept, s)
+	}
+	return kept
+}
 
-	fmt.Printf("\n  %s[a]%s Add  %s[s]%s Skip  %s[b]%s Back\n",
-		Green, Reset, Yellow, Reset, Dim, Reset)
-	fmt.Printf("\n%s→%s ", Cyan, Reset)
+// filterExistingScripts drops scripts whose name is already a command, an
+// alias or a file in binDir, since the new script would shadow it or be
+// shadowed
+func filterExistingScripts(list []suggestions.Suggestion, binDir, rcPath string) []suggestions.Suggestion {
+	var kept []suggestions.Suggestion
+	for _, s := range list {
+		if _, err := os.Lstat(filepath.Join(binDir, s.Name)); err == nil {
+			continue
+		}
+		if _, err := exec.LookPath(s.Name); err == nil {
+			continue
+		}
+		if exists, _ := shell.HasAlias(rcPath, s.Name); exists {
+			continue
+		}
+		kept = append(kept, s)
+	}
+	return kept
+}
 
-	input := readLine()
+// saveScripts writes each script to binDir and, if binDir isn't on PATH
+// yet, adds it in the RC file
+func saveScripts(stdout io.Writer, scripts []suggestions.Suggestion, binDir, rcPath string, target shell.Target) {
+	saved := 0
+	for _, s := range scripts {
+		path, err := shell.WriteScript(binDir, s.Name, s.Code)
+		if err != nil {
+			fmt.Fprintf(stdout, "%sCould not save %s: %v%s\n", Red, s.Name, err, Reset)
+			continue
+		}
+		saved++
+		fmt.Fprintf(stdout, "%s✓ Saved %s%s\n", Green, path, Reset)
+	}
+	if saved == 0 || shell.OnPath(binDir) {
+		return
+	}
+
+	if target == "" {
+		target = shell.CurrentTarget()
+	}
+	entry := shell.PathEntry(binDir, target)
+	if existing, err := shell.ForgeEntries(rcPath); err == nil && slices.Contains(existing, entry) {
+		fmt.Fprintf(stdout, "%sOpen a new terminal to put %s on your PATH.%s\n", Dim, binDir, Reset)
+		return
+	}
+	if err := shell.AddToRC(rcPath, []string{entry}); err != nil {
+		fmt.Fprintf(stdout, "%s%s isn't on your PATH, and adding it to %s failed: %v%s\n", Red, binDir, rcPath, err, Reset)
+		return
+	}
+	fmt.Fprintf(stdout, "%s✓ Added %s to your PATH in %s%s\n", Green, binDir, rcPath, Reset)
+	fmt.Fprintf(stdout, "%sRun 'source %s' or open a new terminal to use them.%s\n", Dim, rcPath, Reset)
+}
+
+// inspectSuggestion shows one suggestion in full and reports whether it was added
+func inspectSuggestion(stdout io.Writer, s suggestions.Suggestion, rcPath string, dismissed *suggestions.Dismissed) bool {
+	fmt.Fprintf(stdout, "\n%s────────────────────────────────────────────────%s\n", Cyan, Reset)
+	fmt.Fprintf(stdout, "  %sName:%s %s\n", Bold, Reset, s.Name)
+	fmt.Fprintf(stdout, "  %sOriginal:%s %s\n", Bold, Reset, s.Command)
+	fmt.Fprintf(stdout, "  %sImpact:%s Used %d times\n", Bold, Reset, s.Impact)
+	fmt.Fprintf(stdout, "\n  %sWould add:%s\n", Bold, Reset)
+	fmt.Fprintf(stdout, "  %s%s%s\n", Dim, s.Code, Reset)
+	fmt.Fprintf(stdout, "%s────────────────────────────────────────────────%s\n", Cyan, Reset)
+
+	fmt.Fprintf(stdout, "\n  %s[a]%s Add  %s[s]%s Skip  %s[n]%s Not useful  %s[b]%s Back\n",
+		Green, Reset, Yellow, Reset, Red, Reset, Dim, Reset)
+	fmt.Fprintf(stdout, "\n%s→%s ", Cyan, Reset)
+
+	input := readLine(stdout)
 
 	switch strings.ToLower(input) {
 	case "a", "add":
 		if err := shell.AddToRC(rcPath, []string{s.Code}); err != nil {
-			fmt.Printf("%sError: %v%s\n", Red, err, Reset)
+			fmt.Fprintf(stdout, "%sError: %v%s\n", Red, err, Reset)
 		} else {
-			fmt.Printf("%s✓ Added %s%s\n", Green, s.Name, Reset)
+			fmt.Fprintf(stdout, "%s✓ Added %s%s\n", Green, s.Name, Reset)
+		}
+		return true
+	case "n":
+		if dismissed == nil {
+			fmt.Fprintf(stdout, "%sCould not remember this; dismissed suggestions failed to load.%s\n", Red, Reset)
+			return false
 		}
+		dismissed.Add(s.Command, time.Now())
+		if err := dismissed.Save(suggestions.DismissedPath()); err != nil {
+			fmt.Fprintf(stdout, "%sCould not save: %v%s\n", Red, err, Reset)
+			return false
+		}
+		fmt.Fprintf(stdout, "%sWon't suggest %s again (--reset-dismissed to undo).%s\n", Dim, s.Name, Reset)
+		return false
 	default:
-		fmt.Printf("%sSkipped.%s\n", Dim, Reset)
+		fmt.Fprintf(stdout, "%sSkipped.%s\n", Dim, Reset)
+		return false
+	}
+}
+
+// recordStats adds this run's decisions to the local acceptance history
+func recordStats(stderr io.Writer, decisions []stats.Decision) {
+	if len(decisions) == 0 {
+		return
+	}
+	st, err := stats.Load(stats.Path())
+	if err != nil {
+		fmt.Fprintf(stderr, "%sWarning: could not read stats: %v%s\n", Yellow, err, Reset)
+		return
+	}
+	st.Record(decisions, time.Now())
+	if err := st.Save(stats.Path()); err != nil {
+		fmt.Fprintf(stderr, "%sWarning: could not save stats: %v%s\n", Yellow, err, Reset)
+	}
+}
+
+// exportAccepted writes what's already in the forge section of the RC file
+// for target (or the user's own shell) to path
+func exportAccepted(w io.Writer, path string, target shell.Target) error {
+	rcPath, err := rcFileFor(target)
+	if err != nil {
+		return err
+	}
+	entries, err := shell.ForgeEntries(rcPath)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", rcPath, err)
+	}
+	return writeExport(w, path, entries, target)
+}
+
+// writeExport writes entries as a standalone snippet, refusing to overwrite
+// the live RC file it's meant to be kept apart from
+func writeExport(w io.Writer, path string, entries []string, target shell.Target) error {
+	if len(entries) == 0 {
+		printInfo(w, "Nothing to export yet: accept some suggestions first, or use --export-high-impact.")
+		return nil
+	}
+
+	rcPath, err := rcFileFor(target)
+	if err != nil {
+		return err
+	}
+	if sameFile(path, rcPath) {
+		return fmt.Errorf("%s is your live shell config; export to a separate file", path)
+	}
+
+	if target == "" {
+		target = shell.CurrentTarget()
+	}
+	machine, err := os.Hostname()
+	if err != nil {
+		machine = "unknown host"
+	}
+	export := shell.Export{Entries: entries, Shell: target, Machine: machine, At: time.Now()}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(export.String()), 0644); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "%s✓ Exported %d suggestions to %s%s\n", Green, len(entries), path, Reset)
+	return nil
+}
+
+// rcFileFor returns target's RC file, or the user's own if no target was chosen
+func rcFileFor(target shell.Target) (string, error) {
+	if target == "" {
+		return shell.GetRCFile()
+	}
+	return shell.RCFileFor(target)
+}
+
+// sameFile reports whether a and b name the same file, existing or not
+func sameFile(a, b string) bool {
+	ai, aerr := os.Stat(a)
+	bi, berr := os.Stat(b)
+	if aerr == nil && berr == nil {
+		return os.SameFile(ai, bi)
 	}
+	absA, _ := filepath.Abs(a)
+	absB, _ := filepath.Abs(b)
+	return absA == absB
 }
 
-func showTips(tips []suggestions.Suggestion) {
+// printStats shows the acceptance history for --stats
+func printStats(stdout, stderr io.Writer) int {
+	st, err := stats.Load(stats.Path())
+	if err != nil {
+		fmt.Fprintf(stderr, "Error reading stats: %v\n", err)
+		return 1
+	}
+
+	printHeader(stdout)
+	if st.Runs == 0 {
+		fmt.Fprintf(stdout, "\n%sNo decisions recorded yet. Run forge-habits and answer a few suggestions.%s\n\n", Dim, Reset)
+		return 0
+	}
+
+	fmt.Fprintf(stdout, "\n%s%d runs since %s, last on %s%s\n",
+		Dim, st.Runs, st.FirstRun.Format("2006-01-02"), st.LastRun.Format("2006-01-02"), Reset)
+	fmt.Fprintf(stdout, "\n  %-12s %s\n", "Overall", formatTally(st.Total))
+
+	fmt.Fprintf(stdout, "\n%s── By confidence ──%s\n\n", Bold+Cyan, Reset)
+	for _, conf := range []suggestions.Confidence{suggestions.ConfHigh, suggestions.ConfMedium, suggestions.ConfLow} {
+		if t, ok := st.ByConfidence[string(conf)]; ok {
+			fmt.Fprintf(stdout, "  %-12s %s\n", conf, formatTally(t))
+		}
+	}
+
+	fmt.Fprintf(stdout, "\n%s── By type ──%s\n\n", Bold+Cyan, Reset)
+	for _, typ := range []suggestions.SuggestionType{suggestions.TypeAlias, suggestions.TypeFunction, suggestions.TypeScript} {
+		if t, ok := st.ByType[string(typ)]; ok {
+			fmt.Fprintf(stdout, "  %-12s %s\n", typ, formatTally(t))
+		}
+	}
+	fmt.Fprintln(stdout)
+	return 0
+}
+
+// runScrub redacts secret-bearing entries from the history file, after a backup
+func runScrub(stdout, stderr io.Writer, historyFile, shellType string) int {
+	historyData, err := parser.Parse(historyFile, shellType)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error reading history: %v\n", err)
+		return 1
+	}
+	path := historyData.FilePath
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error reading history: %v\n", err)
+		return 1
+	}
+	scrubbed, findings := scrub.Scrub(data)
+
+	printHeader(stdout)
+	if len(findings) == 0 {
+		fmt.Fprintf(stdout, "\n%sNo secrets found in %s.%s\n\n", Green, path, Reset)
+		return 0
+	}
+
+	// Show only the redacted form; the point is not to print the secrets again
+	fmt.Fprintf(stdout, "\n%s%d entries in %s look like they hold secrets:%s\n\n", Bold, len(findings), path, Reset)
+	for _, f := range findings {
+		fmt.Fprintf(stdout, "  %s%6d%s  %s\n", Dim, f.Line, Reset, truncate(f.Redacted, 70))
+	}
+
+	fmt.Fprintf(stdout, "\nRewrite %s%s%s with these redacted? %s[y/N]%s ", Cyan, path, Reset, Dim, Reset)
+	if !prompt.IsYes(readLine(stdout), false) {
+		fmt.Fprintf(stdout, "%sCancelled. Your history was not modified.%s\n", Dim, Reset)
+		return 0
+	}
+
+	backupPath, err := shell.Backup(path)
+	if err != nil {
+		fmt.Fprintf(stdout, "%sCould not create backup, so nothing was changed: %v%s\n", Red, err, Reset)
+		return 1
+	}
+	if err := scrub.Write(path, scrubbed); err != nil {
+		fmt.Fprintf(stdout, "%sError writing %s: %v%s\n", Red, path, err, Reset)
+		fmt.Fprintf(stdout, "%sYou can restore from: %s%s\n", Yellow, backupPath, Reset)
+		return 1
+	}
+
+	fmt.Fprintf(stdout, "\n%s✓ Redacted %d entries%s\n", Green, len(findings), Reset)
+	fmt.Fprintf(stdout, "%sThe backup at %s still holds the secrets; delete it once you're happy.%s\n", Yellow, backupPath, Reset)
+	fmt.Fprintf(stdout, "%sShells that are already open may write the old lines back when they exit.%s\n", Dim, Reset)
+	return 0
+}
+
+func formatTally(t stats.Tally) string {
+	return fmt.Sprintf("%s%3.0f%%%s accepted  %s(%d of %d)%s",
+		Green, t.AcceptRate()*100, Reset, Dim, t.Accepted, t.Accepted+t.Rejected, Reset)
+}
+
+func showTips(w io.Writer, tips []suggestions.Suggestion) {
 	if len(tips) == 0 {
 		return
 	}
 
-	fmt.Printf("\n%s───%s\n", Cyan, Reset)
-	fmt.Printf("\n%s💡 Tips:%s\n\n", Bold, Reset)
+	fmt.Fprintf(w, "\n%s───%s\n", Cyan, Reset)
+	fmt.Fprintf(w, "\n%s💡 Tips:%s\n\n", Bold, Reset)
 
 	for _, tip := range tips {
-		fmt.Printf("  %s•%s %s\n", Yellow, Reset, tip.Description)
+		fmt.Fprintf(w, "  %s•%s %s\n", Yellow, Reset, tip.Description)
 	}
 }
 
-func showReport(analysis *analyzer.Analysis, set *suggestions.SuggestionSet) {
-	fmt.Printf("\n%sTotal commands analyzed: %d%s\n", Dim, analysis.TotalCommands, Reset)
+func showReport(w io.Writer, analysis *analyzer.Analysis, set *suggestions.SuggestionSet) {
+	fmt.Fprintf(w, "\n%sTotal commands analyzed: %d%s\n", Dim, analysis.TotalCommands, Reset)
+	if analysis.RawCommands > analysis.TotalCommands {
+		fmt.Fprintf(w, "%s(%d as typed, with back-to-back repeats)%s\n", Dim, analysis.RawCommands, Reset)
+	}
 
 	// Top commands
-	fmt.Printf("\n%s── Top Commands ──%s\n\n", Bold+Cyan, Reset)
+	fmt.Fprintf(w, "\n%s── Top Commands ──%s\n\n", Bold+Cyan, Reset)
 	for i, tc := range analysis.TopCommands {
 		if i >= 10 {
 			break
 		}
 		bar := strings.Repeat("█", min(30, tc.Count/20+1))
-		fmt.Printf("  %-12s %4d %s%s%s\n", tc.Command, tc.Count, Cyan, bar, Reset)
+		fmt.Fprintf(w, "  %-12s %4d %s%s%s\n", tc.Command, tc.Count, Cyan, bar, Reset)
 	}
 
 	// High impact suggestions
 	if len(set.HighImpact) > 0 {
-		fmt.Printf("\n%s── High-Impact Suggestions ──%s\n\n", Bold+Cyan, Reset)
+		fmt.Fprintf(w, "\n%s── High-Impact Suggestions ──%s\n\n", Bold+Cyan, Reset)
 		for _, s := range set.HighImpact {
-			fmt.Printf("  %s%s%s - %s\n", Bold, s.Name, Reset, s.Description)
-			fmt.Printf("    %s%s%s\n\n", Dim, s.Code, Reset)
+			fmt.Fprintf(w, "  %s%s%s - %s\n", Bold, s.Name, Reset, s.Description)
+			fmt.Fprintf(w, "    %s%s%s\n\n", Dim, s.Code, Reset)
 		}
 	}
 
 	// Review suggestions
 	if len(set.Review) > 0 {
-		fmt.Printf("\n%s── Worth Reviewing ──%s\n\n", Bold+Cyan, Reset)
+		fmt.Fprintf(w, "\n%s── Worth Reviewing ──%s\n\n", Bold+Cyan, Reset)
 		for _, s := range set.Review {
-			fmt.Printf("  %s%s%s - %s\n", Bold, s.Name, Reset, s.Description)
+			fmt.Fprintf(w, "  %s%s%s - %s\n", Bold, s.Name, Reset, s.Description)
+		}
+	}
+
+	// Script suggestions
+	if len(set.Scripts) > 0 {
+		fmt.Fprintf(w, "\n%s── Better as Scripts ──%s\n\n", Bold+Cyan, Reset)
+		for _, s := range set.Scripts {
+			fmt.Fprintf(w, "  %s%s%s - %s\n", Bold, s.Name, Reset, s.Description)
+			fmt.Fprintf(w, "    %s%s%s\n\n", Dim, s.Command, Reset)
 		}
 	}
 
 	// Tips
-	showTips(set.Tips)
+	showTips(w, set.Tips)
 }
 
-func readLine() string {
-	line, _ := reader.ReadString('\n')
-	return strings.TrimSpace(line)
+func readLine(w io.Writer) string {
+	line, err := reader.ReadLine()
+	if err != nil {
+		// Ctrl-C or closed input; the terminal has already been restored
+		fmt.Fprintf(w, "\n%sStopped.%s\n", Dim, Reset)
+		os.Exit(5) // Aborted, matching forge and forge-dust
+	}
+	return line
 }
 
 func truncate(s string, max int) string {
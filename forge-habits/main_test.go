@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunVersion(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"--version"}, &stdout, &stderr); code != 0 {
+		t.Errorf("run(--version) = %d, want 0", code)
+	}
+	if want := "forge-habits v" + version + "\n"; stdout.String() != want {
+		t.Errorf("run(--version) printed %q, want %q", stdout.String(), want)
+	}
+	if stderr.Len() > 0 {
+		t.Errorf("run(--version) wrote %q to stderr, want nothing", stderr.String())
+	}
+
+	stdout.Reset()
+	if code := run([]string{"--no-such-flag"}, &stdout, &stderr); code != 2 {
+		t.Errorf("run(--no-such-flag) = %d, want 2", code)
+	}
+	if stdout.Len() > 0 || !strings.Contains(stderr.String(), "no-such-flag") {
+		t.Errorf("run(--no-such-flag) printed %q, %q; want the error on stderr", stdout.String(), stderr.String())
+	}
+}
+
+func TestRunReportsToItsWriters(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	history := filepath.Join(t.TempDir(), "history")
+	if err := os.WriteFile(history, []byte(strings.Repeat("git status\nls -la\n", 30)), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"--file", history, "--shell", "bash", "--no-llm", "--report"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("run(--report) = %d, want 0; stderr %q", code, stderr.String())
+	}
+	for _, want := range []string{"FORGE-HABITS", "Top Commands", "git"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("run(--report) stdout is missing %q:\n%s", want, stdout.String())
+		}
+	}
+}
+
+func TestScrubReportsToItsWriters(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	history := filepath.Join(t.TempDir(), "history")
+	data := "cd ~/src\ncurl -H 'Authorization: Bearer abc123def456' https://api.example.com\n"
+	if err := os.WriteFile(history, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.WriteString("n\n")
+	w.Close()
+	stdin := os.Stdin
+	os.Stdin = r
+	defer func() { os.Stdin = stdin }()
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"--scrub", "--file", history, "--shell", "bash"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("run(--scrub) = %d, want 0; stderr %q", code, stderr.String())
+	}
+	for _, want := range []string{"1 entries in " + history, "Cancelled"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("run(--scrub) stdout is missing %q:\n%s", want, stdout.String())
+		}
+	}
+	if got, _ := os.ReadFile(history); string(got) != data {
+		t.Errorf("declined scrub changed the history to %q", got)
+	}
+}
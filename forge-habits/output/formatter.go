@@ -2,7 +2,9 @@ package output
 
 import (
 	"fmt"
+	"io"
 	"strings"
+	"time"
 
 	"forge-habits/analyzer"
 )
@@ -19,18 +21,18 @@ const (
 	Blue    = "\033[34m"
 )
 
-func PrintAnalysis(analysis *analyzer.Analysis) {
-	printHeader("FORGE-HABITS", "Shell History Analysis")
-	fmt.Printf("\n%sTotal commands analyzed:%s %d\n", Dim, Reset, analysis.TotalCommands)
+func PrintAnalysis(w io.Writer, analysis *analyzer.Analysis) {
+	printHeader(w, "FORGE-HABITS", "Shell History Analysis")
+	fmt.Fprintf(w, "\n%sTotal commands analyzed:%s %d\n", Dim, Reset, analysis.TotalCommands)
 
 	// Top Commands
-	printSection("TOP COMMANDS")
+	printSection(w, "TOP COMMANDS")
 	for i, cmd := range analysis.TopCommands {
 		if i >= 15 {
 			break
 		}
 		bar := strings.Repeat("█", min(cmd.Count/20+1, 30))
-		fmt.Printf("  %s%-12s%s %s%4d%s %s%s%s\n",
+		fmt.Fprintf(w, "  %s%-12s%s %s%4d%s %s%s%s\n",
 			Cyan, cmd.Command, Reset,
 			Bold, cmd.Count, Reset,
 			Dim, bar, Reset)
@@ -38,8 +40,8 @@ func PrintAnalysis(analysis *analyzer.Analysis) {
 
 	// Alias Candidates
 	if len(analysis.AliasCandidates) > 0 {
-		printSection("ALIAS CANDIDATES")
-		fmt.Printf("  %sLong commands you type repeatedly:%s\n\n", Dim, Reset)
+		printSection(w, "ALIAS CANDIDATES")
+		fmt.Fprintf(w, "  %sLong commands you type repeatedly:%s\n\n", Dim, Reset)
 		for i, cmd := range analysis.AliasCandidates {
 			if i >= 10 {
 				break
@@ -48,14 +50,14 @@ func PrintAnalysis(analysis *analyzer.Analysis) {
 			if len(display) > 65 {
 				display = display[:65] + "..."
 			}
-			fmt.Printf("  %s%dx%s  %s%s%s\n", Yellow, cmd.Count, Reset, Dim, display, Reset)
+			fmt.Fprintf(w, "  %s%dx%s  %s%s%s\n", Yellow, cmd.Count, Reset, Dim, display, Reset)
 		}
 	}
 
 	// Pipeline Commands
 	if len(analysis.PipelineCommands) > 0 {
-		printSection("SCRIPT CANDIDATES")
-		fmt.Printf("  %sPipelines you run repeatedly (consider making these scripts):%s\n\n", Dim, Reset)
+		printSection(w, "SCRIPT CANDIDATES")
+		fmt.Fprintf(w, "  %sPipelines you run repeatedly (consider making these scripts):%s\n\n", Dim, Reset)
 		for i, cmd := range analysis.PipelineCommands {
 			if i >= 8 {
 				break
@@ -64,74 +66,102 @@ func PrintAnalysis(analysis *analyzer.Analysis) {
 			if len(display) > 65 {
 				display = display[:65] + "..."
 			}
-			fmt.Printf("  %s%dx%s  %s%s%s\n", Green, cmd.Count, Reset, Dim, display, Reset)
+			fmt.Fprintf(w, "  %s%dx%s  %s%s%s\n", Green, cmd.Count, Reset, Dim, display, Reset)
 		}
 	}
 
 	// Directory Stats
 	if len(analysis.DirectoryStats) > 0 {
-		printSection("MOST VISITED DIRECTORIES")
+		printSection(w, "MOST VISITED DIRECTORIES")
 		for i, dir := range analysis.DirectoryStats {
 			if i >= 10 {
 				break
 			}
-			fmt.Printf("  %s%4d%s  %s%s%s\n", Blue, dir.Count, Reset, Cyan, dir.Command, Reset)
+			fmt.Fprintf(w, "  %s%4d%s  %s%s%s\n", Blue, dir.Count, Reset, Cyan, dir.Command, Reset)
 		}
 	}
 
 	// Command Sequences
 	if len(analysis.CommandSequences) > 0 {
-		printSection("COMMAND SEQUENCES")
-		fmt.Printf("  %sCommands you often run back-to-back:%s\n\n", Dim, Reset)
+		printSection(w, "COMMAND SEQUENCES")
+		fmt.Fprintf(w, "  %sCommands you often run back-to-back:%s\n\n", Dim, Reset)
 		for i, seq := range analysis.CommandSequences {
 			if i >= 10 {
 				break
 			}
-			fmt.Printf("  %s%3d%s  %s%s%s → %s%s%s\n",
+			fmt.Fprintf(w, "  %s%3d%s  %s%s%s → %s%s%s\n",
 				Magenta, seq.Count, Reset,
 				Cyan, seq.From, Reset,
 				Cyan, seq.To, Reset)
 		}
 	}
 
+	// Command Chains
+	if len(analysis.CommandChains) > 0 {
+		printSection(w, "COMMAND CHAINS")
+		fmt.Fprintf(w, "  %sCommands you chain on one line (consider making these functions):%s\n\n", Dim, Reset)
+		for i, chain := range analysis.CommandChains {
+			if i >= 8 {
+				break
+			}
+			display := chain.Command
+			if len(display) > 65 {
+				display = display[:65] + "..."
+			}
+			fmt.Fprintf(w, "  %s%dx%s  %s%s%s\n", Magenta, chain.Count, Reset, Dim, display, Reset)
+		}
+	}
+
+	// Long-running commands
+	if len(analysis.LongRunning) > 0 {
+		printSection(w, "LONG-RUNNING COMMANDS")
+		fmt.Fprintf(w, "  %sCommands that often keep you waiting (consider backgrounding or caching):%s\n\n", Dim, Reset)
+		for _, lr := range analysis.LongRunning {
+			fmt.Fprintf(w, "  %s%dx%s  %s%s%s  %savg %s, max %s%s\n",
+				Magenta, lr.LongRuns, Reset,
+				Cyan, lr.Command, Reset,
+				Dim, lr.Average.Round(time.Second), lr.Longest.Round(time.Second), Reset)
+		}
+	}
+
 	// Typos
 	if len(analysis.PossibleTypos) > 0 {
-		printSection("POSSIBLE TYPOS")
+		printSection(w, "POSSIBLE TYPOS")
 		for _, typo := range analysis.PossibleTypos {
-			fmt.Printf("  %s%dx%s  %s'%s'%s → probably meant %s'%s'%s\n",
+			fmt.Fprintf(w, "  %s%dx%s  %s'%s'%s → probably meant %s'%s'%s\n",
 				Yellow, typo.Count, Reset,
 				Dim, typo.Typed, Reset,
 				Green, typo.Intended, Reset)
 		}
 	}
 
-	fmt.Println()
+	fmt.Fprintln(w)
 }
 
-func PrintLLMRecommendations(recommendations string) {
-	printSection("AI RECOMMENDATIONS")
-	fmt.Println()
-	fmt.Println(recommendations)
-	fmt.Println()
+func PrintLLMRecommendations(w io.Writer, recommendations string) {
+	printSection(w, "AI RECOMMENDATIONS")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, recommendations)
+	fmt.Fprintln(w)
 }
 
-func PrintError(msg string) {
-	fmt.Printf("\n%s⚠ %s%s\n", Yellow, msg, Reset)
+func PrintError(w io.Writer, msg string) {
+	fmt.Fprintf(w, "\n%s⚠ %s%s\n", Yellow, msg, Reset)
 }
 
-func PrintInfo(msg string) {
-	fmt.Printf("%s%s%s\n", Dim, msg, Reset)
+func PrintInfo(w io.Writer, msg string) {
+	fmt.Fprintf(w, "%s%s%s\n", Dim, msg, Reset)
 }
 
-func printHeader(title, subtitle string) {
+func printHeader(w io.Writer, title, subtitle string) {
 	width := 60
-	fmt.Println()
-	fmt.Printf("%s%s%s\n", Bold+Cyan, strings.Repeat("─", width), Reset)
-	fmt.Printf("%s  ⚒  %s%s\n", Bold+Cyan, title, Reset)
-	fmt.Printf("%s  %s%s\n", Dim, subtitle, Reset)
-	fmt.Printf("%s%s%s\n", Bold+Cyan, strings.Repeat("─", width), Reset)
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "%s%s%s\n", Bold+Cyan, strings.Repeat("─", width), Reset)
+	fmt.Fprintf(w, "%s  ⚒  %s%s\n", Bold+Cyan, title, Reset)
+	fmt.Fprintf(w, "%s  %s%s\n", Dim, subtitle, Reset)
+	fmt.Fprintf(w, "%s%s%s\n", Bold+Cyan, strings.Repeat("─", width), Reset)
 }
 
-func printSection(title string) {
-	fmt.Printf("\n%s%s ─── %s %s%s\n\n", Bold, Cyan, title, strings.Repeat("─", 40-len(title)), Reset)
+func printSection(w io.Writer, title string) {
+	fmt.Fprintf(w, "\n%s%s ─── %s %s%s\n\n", Bold, Cyan, title, strings.Repeat("─", 40-len(title)), Reset)
 }
@@ -2,9 +2,11 @@ package parser
 
 import (
 	"bufio"
+	"io"
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -13,16 +15,19 @@ type Command struct {
 	Command   string   // First word
 	Args      []string // Remaining words
 	Timestamp int64    // Unix timestamp if available
+	Elapsed   int64    // Seconds it ran, from zsh extended history (with its Repeats, once collapsed); 0 if not recorded
+	Repeats   int      // Times it was entered again straight after, folded in by CollapseRepeats
 }
 
 type HistoryData struct {
 	Commands  []Command
 	ShellType string
 	FilePath  string
+	RawCount  int // Commands as read, before CollapseRepeats
 }
 
-// zsh extended history format: ": timestamp:0;command"
-var zshPattern = regexp.MustCompile(`^: (\d+):\d+;(.+)$`)
+// zsh extended history format: ": timestamp:elapsed;command"
+var zshPattern = regexp.MustCompile(`^: (\d+):(\d+);(.+)$`)
 
 // Parse reads and parses a shell history file
 func Parse(filePath string, shellType string) (*HistoryData, error) {
@@ -42,8 +47,26 @@ func Parse(filePath string, shellType string) (*HistoryData, error) {
 	}
 	defer file.Close()
 
+	data, err := ParseReader(file, shellType)
+	if err != nil {
+		return nil, err
+	}
+	data.FilePath = filePath
+	return data, nil
+}
+
+// ParseReader parses commands from r, one per line, such as history piped
+// in from another machine. With no shell type, zsh-format lines have their
+// timestamps stripped and other lines are taken as they are.
+func ParseReader(r io.Reader, shellType string) (*HistoryData, error) {
+	auto := shellType == ""
+	if auto {
+		shellType = "zsh"
+	}
+	sawZsh := false
+
 	var commands []Command
-	scanner := bufio.NewScanner(file)
+	scanner := bufio.NewScanner(r)
 
 	// Increase buffer size for long lines
 	buf := make([]byte, 0, 64*1024)
@@ -51,32 +74,55 @@ func Parse(filePath string, shellType string) (*HistoryData, error) {
 
 	for scanner.Scan() {
 		line := scanner.Text()
+		if auto && !sawZsh {
+			sawZsh = zshPattern.MatchString(line)
+		}
 		cmd := parseLine(line, shellType)
 		if cmd != nil {
 			commands = append(commands, *cmd)
 		}
 	}
 
+	if auto && !sawZsh {
+		shellType = "bash"
+	}
 	return &HistoryData{
 		Commands:  commands,
 		ShellType: shellType,
-		FilePath:  filePath,
+		RawCount:  len(commands),
 	}, nil
 }
 
+// CollapseRepeats folds each run of the same command entered back to back
+// into one, as zsh's HIST_IGNORE_DUPS would have, so counts reflect distinct
+// invocations rather than a held-down enter key. RawCount is left as it was.
+func (h *HistoryData) CollapseRepeats() {
+	var collapsed []Command
+	for _, cmd := range h.Commands {
+		if n := len(collapsed); n > 0 && collapsed[n-1].Raw == cmd.Raw {
+			collapsed[n-1].Repeats += 1 + cmd.Repeats
+			collapsed[n-1].Elapsed += cmd.Elapsed // Time spent still counts
+			continue
+		}
+		collapsed = append(collapsed, cmd)
+	}
+	h.Commands = collapsed
+}
+
 func parseLine(line string, shellType string) *Command {
 	if line == "" {
 		return nil
 	}
 
 	var raw string
-	var timestamp int64
+	var timestamp, elapsed int64
 
 	if shellType == "zsh" {
 		matches := zshPattern.FindStringSubmatch(line)
 		if matches != nil {
-			// fmt.Sscanf(matches[1], "%d", &timestamp)
-			raw = matches[2]
+			timestamp, _ = strconv.ParseInt(matches[1], 10, 64)
+			elapsed, _ = strconv.ParseInt(matches[2], 10, 64)
+			raw = matches[3]
 		} else {
 			// Plain format (no timestamp)
 			raw = line
@@ -101,6 +147,7 @@ func parseLine(line string, shellType string) *Command {
 		Command:   parts[0],
 		Args:      parts[1:],
 		Timestamp: timestamp,
+		Elapsed:   elapsed,
 	}
 }
 
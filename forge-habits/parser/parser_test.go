@@ -0,0 +1,118 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseReader(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		shellType string
+		wantShell string
+		wantRaw   []string
+	}{
+		{
+			name:      "plain lines",
+			input:     "git status\n\nls -la\n  \ndocker ps\n",
+			wantShell: "bash",
+			wantRaw:   []string{"git status", "ls -la", "docker ps"},
+		},
+		{
+			name:      "zsh extended history",
+			input:     ": 1700000000:0;git status\n: 1700000005:3;make test\n",
+			wantShell: "zsh",
+			wantRaw:   []string{"git status", "make test"},
+		},
+		{
+			name:      "mixed, detected as zsh",
+			input:     "cd ~/src\n: 1700000000:0;git pull\n",
+			wantShell: "zsh",
+			wantRaw:   []string{"cd ~/src", "git pull"},
+		},
+		{
+			name:      "explicit bash keeps lines whole",
+			input:     ": 1700000000:0;git pull\n",
+			shellType: "bash",
+			wantShell: "bash",
+			wantRaw:   []string{": 1700000000:0;git pull"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := ParseReader(strings.NewReader(tt.input), tt.shellType)
+			if err != nil {
+				t.Fatalf("ParseReader() error = %v", err)
+			}
+			if data.ShellType != tt.wantShell {
+				t.Errorf("ShellType = %q, want %q", data.ShellType, tt.wantShell)
+			}
+			if len(data.Commands) != len(tt.wantRaw) {
+				t.Fatalf("got %d commands, want %d: %+v", len(data.Commands), len(tt.wantRaw), data.Commands)
+			}
+			for i, cmd := range data.Commands {
+				if cmd.Raw != tt.wantRaw[i] {
+					t.Errorf("Commands[%d].Raw = %q, want %q", i, cmd.Raw, tt.wantRaw[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCollapseRepeats(t *testing.T) {
+	input := ": 1700000000:0;git status\n" +
+		": 1700000001:0;git status\n" +
+		": 1700000002:0;git status\n" +
+		": 1700000003:0;make test\n" +
+		": 1700000004:0;git status\n" +
+		": 1700000005:0;make test\n" +
+		": 1700000006:0;make test\n"
+
+	data, err := ParseReader(strings.NewReader(input), "")
+	if err != nil {
+		t.Fatalf("ParseReader() error = %v", err)
+	}
+	data.CollapseRepeats()
+
+	// Only back-to-back repeats fold; git status run again later still counts
+	wantRaw := []string{"git status", "make test", "git status", "make test"}
+	wantRepeats := []int{2, 0, 0, 1}
+	if len(data.Commands) != len(wantRaw) {
+		t.Fatalf("CollapseRepeats() left %d commands, want %d", len(data.Commands), len(wantRaw))
+	}
+	for i, cmd := range data.Commands {
+		if cmd.Raw != wantRaw[i] || cmd.Repeats != wantRepeats[i] {
+			t.Errorf("Commands[%d] = %q ×%d repeats, want %q ×%d", i, cmd.Raw, cmd.Repeats, wantRaw[i], wantRepeats[i])
+		}
+	}
+	if data.RawCount != 7 {
+		t.Errorf("RawCount = %d, want 7", data.RawCount)
+	}
+}
+
+func TestParseZshElapsed(t *testing.T) {
+	input := ": 1700000000:0;git status\n" +
+		": 1700000100:95;make build\n" +
+		": 1700000300:80;make build\n" +
+		"ls\n"
+
+	data, err := ParseReader(strings.NewReader(input), "")
+	if err != nil {
+		t.Fatalf("ParseReader() error = %v", err)
+	}
+	wantElapsed := []int64{0, 95, 80, 0}
+	wantTimestamp := []int64{1700000000, 1700000100, 1700000300, 0}
+	for i, cmd := range data.Commands {
+		if cmd.Elapsed != wantElapsed[i] || cmd.Timestamp != wantTimestamp[i] {
+			t.Errorf("Commands[%d] (%q) elapsed %d at %d, want %d at %d", i, cmd.Raw, cmd.Elapsed, cmd.Timestamp, wantElapsed[i], wantTimestamp[i])
+		}
+	}
+
+	// Folded repeats keep the time they took between them
+	data.CollapseRepeats()
+	if cmd := data.Commands[1]; cmd.Raw != "make build" || cmd.Repeats != 1 || cmd.Elapsed != 175 {
+		t.Errorf("collapsed make build = %+v, want 1 repeat and 175s", cmd)
+	}
+}
@@ -0,0 +1,94 @@
+// Package scrub redacts secrets from a shell history file
+package scrub
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"forge-habits/llm"
+)
+
+// Finding is a history line that was redacted
+type Finding struct {
+	Line     int    // 1-based line number in the file
+	Redacted string // The command as it will be written back
+}
+
+// zsh extended history prefix ": timestamp:elapsed;" and bash HISTTIMEFORMAT lines "#timestamp"
+var (
+	zshPrefix     = regexp.MustCompile(`^: \d+:\d+;`)
+	bashTimestamp = regexp.MustCompile(`^#\d+$`)
+)
+
+// Scrub returns data with every secret-bearing command redacted. Only the
+// command text of flagged lines changes; timestamps, continuation
+// backslashes, line endings and every other byte are kept as they were.
+func Scrub(data []byte) ([]byte, []Finding) {
+	lines := strings.Split(string(data), "\n")
+	var findings []Finding
+
+	for i, line := range lines {
+		if bashTimestamp.MatchString(line) {
+			continue
+		}
+
+		prefix := zshPrefix.FindString(line)
+		cmd := line[len(prefix):]
+
+		// A trailing backslash joins the next line into the same entry
+		suffix := ""
+		if strings.HasSuffix(cmd, `\`) {
+			cmd, suffix = cmd[:len(cmd)-1], `\`
+		}
+
+		if !llm.ContainsSensitiveData(cmd) {
+			continue
+		}
+		redacted := redact(cmd)
+		lines[i] = prefix + redacted + suffix
+		findings = append(findings, Finding{Line: i + 1, Redacted: redacted})
+	}
+
+	if len(findings) == 0 {
+		return data, nil
+	}
+	return []byte(strings.Join(lines, "\n")), findings
+}
+
+// redact removes the secret from cmd, or the whole command when the
+// sensitive part can't be pinned down
+func redact(cmd string) string {
+	if sanitized := llm.SanitizeCommand(cmd); sanitized != cmd {
+		return sanitized
+	}
+	return "[REDACTED]"
+}
+
+// Write replaces the file at path with data, keeping its permissions. The
+// new contents are renamed into place so a failed write leaves the original.
+func Write(path string, data []byte) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".scrub-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // No-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
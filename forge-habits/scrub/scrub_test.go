@@ -0,0 +1,95 @@
+package scrub
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestScrubRedactsSecretsAndKeepsFormat(t *testing.T) {
+	history := strings.Join([]string{
+		": 1700000000:0;git status",
+		": 1700000005:0;export API_KEY=sk_live_1234567890abcdefghij",
+		": 1700000010:2;ls -la ~/Projects",
+		": 1700000020:0;mysql -u admin -pSecretPassword123 \\",
+		"  --host db.internal",
+		": 1700000030:0;vim ~/.config/credentials.yml",
+		"",
+	}, "\n")
+
+	out, findings := Scrub([]byte(history))
+	lines := strings.Split(string(out), "\n")
+
+	if len(lines) != 7 || lines[6] != "" {
+		t.Fatalf("Scrub() changed the line structure:\n%s", out)
+	}
+	// Benign lines are untouched, byte for byte
+	for _, i := range []int{0, 2, 4} {
+		if want := strings.Split(history, "\n")[i]; lines[i] != want {
+			t.Errorf("line %d = %q, want unchanged %q", i+1, lines[i], want)
+		}
+	}
+
+	for _, secret := range []string{"sk_live_1234567890abcdefghij", "SecretPassword123"} {
+		if strings.Contains(string(out), secret) {
+			t.Errorf("Scrub() output still contains %q", secret)
+		}
+	}
+	if !strings.HasPrefix(lines[1], ": 1700000005:0;") {
+		t.Errorf("line 2 lost its zsh timestamp: %q", lines[1])
+	}
+	if !strings.HasPrefix(lines[3], ": 1700000020:0;mysql") || !strings.HasSuffix(lines[3], `\`) {
+		t.Errorf("line 4 lost its prefix or continuation: %q", lines[3])
+	}
+	if lines[5] != ": 1700000030:0;[REDACTED]" {
+		t.Errorf("line 6 = %q, want the whole command redacted", lines[5])
+	}
+
+	wantLines := []int{2, 4, 6}
+	if len(findings) != len(wantLines) {
+		t.Fatalf("Scrub() found %d secrets, want %d: %+v", len(findings), len(wantLines), findings)
+	}
+	for i, f := range findings {
+		if f.Line != wantLines[i] {
+			t.Errorf("findings[%d].Line = %d, want %d", i, f.Line, wantLines[i])
+		}
+	}
+}
+
+func TestScrubBashHistory(t *testing.T) {
+	history := "#1700000000\ncd ~/src\n#1700000005\ncurl -H 'Authorization: Bearer abc123def456' https://api.example.com\n"
+
+	out, findings := Scrub([]byte(history))
+	if len(findings) != 1 || findings[0].Line != 4 {
+		t.Fatalf("Scrub() findings = %+v, want one on line 4", findings)
+	}
+	if !strings.HasPrefix(string(out), "#1700000000\ncd ~/src\n#1700000005\ncurl ") || !strings.HasSuffix(string(out), "\n") {
+		t.Errorf("Scrub() changed the surrounding format:\n%s", out)
+	}
+	if strings.Contains(string(out), "abc123def456") {
+		t.Errorf("Scrub() output still contains the token:\n%s", out)
+	}
+
+	// Nothing to scrub returns the input as is
+	clean := []byte("ls\ngit log\n")
+	if out, findings := Scrub(clean); len(findings) != 0 || string(out) != string(clean) {
+		t.Errorf("Scrub(clean) = %q, %v; want input unchanged", out, findings)
+	}
+}
+
+func TestWriteKeepsPermissions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".zsh_history")
+	if err := os.WriteFile(path, []byte("old\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := Write(path, []byte("new\n")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	info, _ := os.Stat(path)
+	if string(data) != "new\n" || info.Mode().Perm() != 0600 {
+		t.Errorf("after Write: %q mode %v, want \"new\\n\" mode 0600", data, info.Mode().Perm())
+	}
+}
@@ -0,0 +1,232 @@
+package shell
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Target is a shell that suggestions can be written for
+type Target string
+
+const (
+	TargetBash Target = "bash"
+	TargetZsh  Target = "zsh"
+	TargetFish Target = "fish"
+	TargetPwsh Target = "pwsh"
+)
+
+// ParseTarget accepts bash, zsh, fish or pwsh (also "powershell")
+func ParseTarget(s string) (Target, error) {
+	switch strings.ToLower(s) {
+	case "bash":
+		return TargetBash, nil
+	case "zsh":
+		return TargetZsh, nil
+	case "fish":
+		return TargetFish, nil
+	case "pwsh", "powershell":
+		return TargetPwsh, nil
+	}
+	return "", fmt.Errorf("unknown shell %q (want bash, zsh, fish or pwsh)", s)
+}
+
+// RCFileFor returns the config file suggestions for target are added to
+func RCFileFor(target Target) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	switch target {
+	case TargetBash:
+		bashProfile := filepath.Join(home, ".bash_profile")
+		if _, err := os.Stat(bashProfile); err == nil {
+			return bashProfile, nil
+		}
+		return filepath.Join(home, ".bashrc"), nil
+	case TargetFish:
+		return filepath.Join(home, ".config", "fish", "config.fish"), nil
+	case TargetPwsh:
+		return filepath.Join(home, ".config", "powershell", "Microsoft.PowerShell_profile.ps1"), nil
+	default:
+		return filepath.Join(home, ".zshrc"), nil
+	}
+}
+
+// emitter writes aliases and functions in one shell's syntax. Bodies come
+// in as bash/zsh, so emitters translate what they can and refuse the rest.
+type emitter interface {
+	alias(name, expansion string) (string, error)
+	function(name, body string) (string, error)
+}
+
+var emitters = map[Target]emitter{
+	TargetBash: posixEmitter{},
+	TargetZsh:  posixEmitter{},
+	TargetFish: fishEmitter{},
+	TargetPwsh: pwshEmitter{},
+}
+
+// Translate rewrites bash/zsh alias or function code for target. Only
+// straight-line commands translate; control flow, shell variables and the
+// like return an error rather than code that might misbehave.
+func Translate(code string, target Target) (string, error) {
+	e, ok := emitters[target]
+	if !ok {
+		return "", fmt.Errorf("unknown shell %q", target)
+	}
+
+	if name, expansion, ok := parseAlias(code); ok {
+		return e.alias(name, expansion)
+	}
+	name, body, ok := parseFunction(code)
+	if !ok {
+		return "", fmt.Errorf("not an alias or function")
+	}
+	return e.function(name, body)
+}
+
+// funcHeader matches "name() {", "name () {", "function name {" and "function name() {"
+var funcHeader = regexp.MustCompile(`^\s*(?:function\s+)?([A-Za-z_][\w-]*)\s*(?:\(\s*\))?\s*\{`)
+
+// parseFunction splits bash/zsh function code into its name and body
+func parseFunction(code string) (string, string, bool) {
+	m := funcHeader.FindStringSubmatchIndex(code)
+	end := strings.LastIndex(code, "}")
+	if m == nil || end < m[1] {
+		return "", "", false
+	}
+	if !strings.HasPrefix(strings.TrimSpace(code), "function") && !strings.Contains(code[:m[1]], "(") {
+		return "", "", false // "name {" alone is not a function
+	}
+
+	var lines []string
+	for _, line := range strings.Split(code[m[1]:end], "\n") {
+		line = strings.TrimSuffix(strings.TrimSpace(line), ";")
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return code[m[2]:m[3]], strings.Join(lines, "\n"), true
+}
+
+// posixEmitter writes bash and zsh, which share alias and function syntax
+type posixEmitter struct{}
+
+func (posixEmitter) alias(name, expansion string) (string, error) {
+	return fmt.Sprintf("alias %s='%s'", name, strings.ReplaceAll(expansion, "'", `'\''`)), nil
+}
+
+func (posixEmitter) function(name, body string) (string, error) {
+	return fmt.Sprintf("%s() {\n%s\n}", name, indent(body, "  ")), nil
+}
+
+// fishEmitter writes fish 3.4+, which has && and $(...) but not bash's control flow
+type fishEmitter struct{}
+
+func (fishEmitter) alias(name, expansion string) (string, error) {
+	if err := checkTranslatable(expansion, "fish", "`", "${"); err != nil {
+		return "", err
+	}
+	escaped := strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(expansion)
+	return fmt.Sprintf("alias %s='%s'", name, escaped), nil
+}
+
+func (fishEmitter) function(name, body string) (string, error) {
+	if err := checkTranslatable(body, "fish", "`", "${", "$?", "$#"); err != nil {
+		return "", err
+	}
+	body = replaceArgs(body, `$argv`, `"$argv"`, func(n int) string {
+		return fmt.Sprintf("$argv[%d]", n)
+	})
+	return fmt.Sprintf("function %s\n%s\nend", name, indent(body, "    ")), nil
+}
+
+// pwshEmitter writes PowerShell 7+, which has && and || between commands
+type pwshEmitter struct{}
+
+// shellVariable matches $NAME, which PowerShell reads as its own variable, not the environment
+var shellVariable = regexp.MustCompile(`\$[A-Za-z_]`)
+
+// pwshUnsupported is bash syntax with a different meaning in PowerShell
+var pwshUnsupported = []string{"`", "${", "$?", "$#", `\"`, "<(", "&>", "~/"}
+
+func (p pwshEmitter) alias(name, expansion string) (string, error) {
+	// Set-Alias can't carry arguments, so aliases become functions that pass theirs on
+	body := expansion
+	if !strings.ContainsAny(expansion, "|;&<>") {
+		body += " @args"
+	}
+	return p.function(name, body)
+}
+
+func (pwshEmitter) function(name, body string) (string, error) {
+	if err := checkTranslatable(body, "pwsh", pwshUnsupported...); err != nil {
+		return "", err
+	}
+	if m := shellVariable.FindString(body); m != "" {
+		return "", fmt.Errorf("pwsh: shell variable %s... has no direct equivalent", m)
+	}
+
+	body = strings.ReplaceAll(body, "/dev/null", "$null")
+	body = replaceArgs(body, `@args`, `"$args"`, func(n int) string {
+		return fmt.Sprintf("$($args[%d])", n-1)
+	})
+
+	// Built-in aliases such as gc and gp would otherwise win over the function
+	return fmt.Sprintf("Remove-Item Alias:%s -Force -ErrorAction SilentlyContinue\nfunction %s {\n%s\n}",
+		name, name, indent(body, "    ")), nil
+}
+
+// shellKeywords start control flow or declarations that aren't translated
+var shellKeywords = map[string]bool{
+	"if": true, "then": true, "elif": true, "else": true, "fi": true,
+	"for": true, "while": true, "until": true, "do": true, "done": true,
+	"case": true, "esac": true, "select": true, "function": true,
+	"export": true, "local": true, "declare": true, "readonly": true, "[[": true,
+}
+
+// assignment matches a bare VAR=value, which fish and pwsh spell differently
+var assignment = regexp.MustCompile(`^[A-Za-z_]\w*=`)
+
+// checkTranslatable refuses bodies using control flow, assignments, heredocs or any of unsupported
+func checkTranslatable(body, shell string, unsupported ...string) error {
+	body = positional.ReplaceAllString(body, "") // $1 and ${1} are translated
+	for _, u := range append(unsupported, "<<") {
+		if strings.Contains(body, u) {
+			return fmt.Errorf("%s: %q can't be translated", shell, u)
+		}
+	}
+	for _, word := range strings.FieldsFunc(body, func(r rune) bool {
+		return r == ' ' || r == '\t' || r == '\n' || r == ';' || r == '|' || r == '&' || r == '(' || r == ')'
+	}) {
+		if shellKeywords[word] {
+			return fmt.Errorf("%s: %q can't be translated", shell, word)
+		}
+		if assignment.MatchString(word) && !strings.HasPrefix(word, "-") {
+			return fmt.Errorf("%s: variable assignment %q can't be translated", shell, word)
+		}
+	}
+	return nil
+}
+
+// positional matches $1-$9 and ${1}-${9}
+var positional = regexp.MustCompile(`\$\{?([1-9])\}?`)
+
+// replaceArgs rewrites "$@"/$@ as all, "$*"/$* as joined and $N via nth
+func replaceArgs(body, all, joined string, nth func(int) string) string {
+	body = strings.NewReplacer(`"$@"`, all, `$@`, all, `"$*"`, joined, `$*`, all).Replace(body)
+	return positional.ReplaceAllStringFunc(body, func(m string) string {
+		n, _ := strconv.Atoi(strings.Trim(m, "${}"))
+		return nth(n)
+	})
+}
+
+// indent prefixes every line of s
+func indent(s, prefix string) string {
+	return prefix + strings.ReplaceAll(s, "\n", "\n"+prefix)
+}
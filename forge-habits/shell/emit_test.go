@@ -0,0 +1,90 @@
+package shell
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTranslate(t *testing.T) {
+	killPort := "kp() {\n  lsof -ti:\"$1\" | xargs kill -9\n}"
+	checkout := `gco() { git checkout "$@"; }`
+	alias := `alias gs='git status --short'`
+	quoted := `alias say='echo '\''hi'\'''`
+
+	tests := []struct {
+		code   string
+		target Target
+		want   string
+	}{
+		{alias, TargetZsh, "alias gs='git status --short'"},
+		{killPort, TargetBash, "kp() {\n  lsof -ti:\"$1\" | xargs kill -9\n}"},
+		{checkout, TargetZsh, "gco() {\n  git checkout \"$@\"\n}"},
+
+		{alias, TargetFish, "alias gs='git status --short'"},
+		{quoted, TargetFish, `alias say='echo \'hi\''`},
+		{killPort, TargetFish, "function kp\n    lsof -ti:\"$argv[1]\" | xargs kill -9\nend"},
+		{checkout, TargetFish, "function gco\n    git checkout $argv\nend"},
+
+		{alias, TargetPwsh, "Remove-Item Alias:gs -Force -ErrorAction SilentlyContinue\nfunction gs {\n    git status --short @args\n}"},
+		{`alias lg='git log | head'`, TargetPwsh, "function lg {\n    git log | head\n}"},
+		{killPort, TargetPwsh, "function kp {\n    lsof -ti:\"$($args[0])\" | xargs kill -9\n}"},
+		{checkout, TargetPwsh, "function gco {\n    git checkout @args\n}"},
+		{`q() { grep -r "${1}" . 2>/dev/null; }`, TargetPwsh, "function q {\n    grep -r \"$($args[0])\" . 2>$null\n}"},
+	}
+
+	for _, tt := range tests {
+		got, err := Translate(tt.code, tt.target)
+		if err != nil {
+			t.Errorf("Translate(%q, %s) error = %v", tt.code, tt.target, err)
+			continue
+		}
+		if !strings.Contains(got, tt.want) {
+			t.Errorf("Translate(%q, %s) =\n%s\nwant it to contain\n%s", tt.code, tt.target, got, tt.want)
+		}
+		if !hasBalancedBlocks(got, tt.target) {
+			t.Errorf("Translate(%q, %s) has unbalanced blocks:\n%s", tt.code, tt.target, got)
+		}
+	}
+}
+
+func TestTranslateRefusesWhatItCannotCarry(t *testing.T) {
+	tests := []struct {
+		code   string
+		target Target
+	}{
+		{"up() {\n  if [ -d .git ]; then git pull; fi\n}", TargetFish},
+		{"up() {\n  for f in *.log; do rm \"$f\"; done\n}", TargetPwsh},
+		{"b() { echo `date`; }", TargetFish},
+		{"h() { cd $HOME/src; }", TargetPwsh},
+		{"e() { export FOO=1; }", TargetFish},
+		{"c() { x=1; echo $x; }", TargetPwsh},
+		{"not code at all", TargetFish},
+	}
+
+	for _, tt := range tests {
+		if got, err := Translate(tt.code, tt.target); err == nil {
+			t.Errorf("Translate(%q, %s) = %q, want an error", tt.code, tt.target, got)
+		}
+	}
+}
+
+// hasBalancedBlocks checks that functions are closed the way target expects
+func hasBalancedBlocks(code string, target Target) bool {
+	switch target {
+	case TargetFish:
+		return strings.Count(code, "function ") == strings.Count(code, "\nend")
+	default:
+		return strings.Count(code, "{") == strings.Count(code, "}")
+	}
+}
+
+func TestParseTarget(t *testing.T) {
+	for in, want := range map[string]Target{"fish": TargetFish, "PowerShell": TargetPwsh, "pwsh": TargetPwsh, "zsh": TargetZsh} {
+		if got, err := ParseTarget(in); err != nil || got != want {
+			t.Errorf("ParseTarget(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseTarget("tcsh"); err == nil {
+		t.Error("ParseTarget(tcsh) error = nil, want an error")
+	}
+}
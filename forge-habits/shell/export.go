@@ -0,0 +1,76 @@
+package shell
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Export describes a standalone snippet of suggestions for a dotfiles repo
+type Export struct {
+	Entries []string
+	Shell   Target
+	Machine string
+	At      time.Time
+}
+
+// ForgeEntries returns the entries in the RC file's forge section, which is
+// everything accepted so far. A missing file or section has none.
+func ForgeEntries(rcPath string) ([]string, error) {
+	data, err := os.ReadFile(rcPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	content := string(data)
+	start := strings.Index(content, forgeHeader)
+	if start == -1 {
+		return nil, nil
+	}
+	end := strings.Index(content[start:], forgeFooter)
+	if end == -1 {
+		end = len(content) - start
+	}
+	return extractForgeEntries(content[start : start+end]), nil
+}
+
+// CurrentTarget returns the shell the user runs, by the rules GetRCFile uses
+func CurrentTarget() Target {
+	if strings.Contains(os.Getenv("SHELL"), "bash") {
+		return TargetBash
+	}
+	return TargetZsh
+}
+
+// String renders the export: a header saying where and when it came from and
+// how to load it, then the entries between the usual forge markers
+func (e Export) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# forge-habits suggestions for %s\n", e.Shell)
+	fmt.Fprintf(&sb, "# Exported %s from %s\n", e.At.Format("2006-01-02 15:04"), e.Machine)
+	fmt.Fprintf(&sb, "# Load it from your %s config with: %s\n\n", e.Shell, sourceLine(e.Shell))
+
+	sb.WriteString(forgeHeader + "\n\n")
+	for _, entry := range e.Entries {
+		sb.WriteString(entry)
+		sb.WriteString("\n\n")
+	}
+	sb.WriteString(forgeFooter + "\n")
+	return sb.String()
+}
+
+// sourceLine is how shell loads another file; the path is only an example
+func sourceLine(shell Target) string {
+	switch shell {
+	case TargetFish:
+		return "source ~/dotfiles/forge-habits.fish"
+	case TargetPwsh:
+		return ". ~/dotfiles/forge-habits.ps1"
+	default:
+		return "source ~/dotfiles/forge-habits." + string(shell)
+	}
+}
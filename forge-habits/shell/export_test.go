@@ -0,0 +1,72 @@
+package shell
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestExportFormat(t *testing.T) {
+	e := Export{
+		Entries: []string{"alias gs='git status'", "kp() {\n  lsof -ti:\"$1\" | xargs kill -9\n}"},
+		Shell:   TargetZsh,
+		Machine: "anvil.local",
+		At:      time.Date(2026, 3, 4, 9, 30, 0, 0, time.UTC),
+	}
+	got := e.String()
+
+	want := `# forge-habits suggestions for zsh
+# Exported 2026-03-04 09:30 from anvil.local
+# Load it from your zsh config with: source ~/dotfiles/forge-habits.zsh
+
+# === Added by forge-habits ===
+
+alias gs='git status'
+
+kp() {
+  lsof -ti:"$1" | xargs kill -9
+}
+
+# === End forge-habits ===
+`
+	if got != want {
+		t.Errorf("Export.String() =\n%s\nwant\n%s", got, want)
+	}
+
+	// The export reads back like a forge section in an RC file
+	path := filepath.Join(t.TempDir(), "forge-habits.zsh")
+	if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := ForgeEntries(path)
+	if err != nil {
+		t.Fatalf("ForgeEntries() error = %v", err)
+	}
+	if !reflect.DeepEqual(entries, e.Entries) {
+		t.Errorf("ForgeEntries() = %q, want %q", entries, e.Entries)
+	}
+}
+
+func TestForgeEntries(t *testing.T) {
+	rc := filepath.Join(t.TempDir(), ".zshrc")
+	content := "alias mine='ls'\n" +
+		"\n" + forgeHeader + "\n# Added on 2026-01-01 10:00\n\nalias gs='git status'\n\n" + forgeFooter + "\n" +
+		"alias after='pwd'\n"
+	if err := os.WriteFile(rc, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ForgeEntries(rc)
+	if err != nil {
+		t.Fatalf("ForgeEntries() error = %v", err)
+	}
+	if want := []string{"alias gs='git status'"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ForgeEntries() = %q, want %q", got, want)
+	}
+
+	if got, err := ForgeEntries(filepath.Join(t.TempDir(), "missing")); err != nil || len(got) != 0 {
+		t.Errorf("ForgeEntries(missing) = %q, %v, want nothing", got, err)
+	}
+}
@@ -0,0 +1,84 @@
+package shell
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// BinDir is where saved scripts go: ~/bin
+func BinDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "bin"), nil
+}
+
+// WriteScript saves code as an executable script called name in dir,
+// creating dir if needed. An existing file is never overwritten.
+func WriteScript(dir, name, code string) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, name)
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0755)
+	if err != nil {
+		if os.IsExist(err) {
+			return "", fmt.Errorf("%s already exists", path)
+		}
+		return "", err
+	}
+	if _, err := f.WriteString(code); err != nil {
+		f.Close()
+		os.Remove(path)
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+	// The umask may have taken the execute bits
+	return path, os.Chmod(path, 0755)
+}
+
+// OnPath reports whether dir is one of the directories in $PATH
+func OnPath(dir string) bool {
+	for _, p := range filepath.SplitList(os.Getenv("PATH")) {
+		if p != "" && sameDir(p, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// PathEntry is the RC line that puts dir on target's PATH
+func PathEntry(dir string, target Target) string {
+	if home, err := os.UserHomeDir(); err == nil {
+		if rel, err := filepath.Rel(home, dir); err == nil && filepath.IsLocal(rel) {
+			if target == TargetFish {
+				dir = "~/" + filepath.ToSlash(rel)
+			} else {
+				dir = "$HOME/" + filepath.ToSlash(rel)
+			}
+		}
+	}
+
+	switch target {
+	case TargetFish:
+		return fmt.Sprintf("fish_add_path %s", dir)
+	case TargetPwsh:
+		return fmt.Sprintf(`$env:PATH = "%s" + [IO.Path]::PathSeparator + $env:PATH`, dir)
+	default:
+		return fmt.Sprintf(`export PATH="%s:$PATH"`, dir)
+	}
+}
+
+// sameDir reports whether a and b are the same directory, by path or on disk
+func sameDir(a, b string) bool {
+	if filepath.Clean(a) == filepath.Clean(b) {
+		return true
+	}
+	ai, aerr := os.Stat(a)
+	bi, berr := os.Stat(b)
+	return aerr == nil && berr == nil && os.SameFile(ai, bi)
+}
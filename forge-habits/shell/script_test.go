@@ -0,0 +1,71 @@
+package shell
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteScript(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "bin")
+	code := "#!/usr/bin/env bash\nps aux | grep node | awk '{print $2}' | xargs echo\n"
+
+	path, err := WriteScript(dir, "pn", code)
+	if err != nil {
+		t.Fatalf("WriteScript() error = %v", err)
+	}
+	if path != filepath.Join(dir, "pn") {
+		t.Errorf("WriteScript() path = %s, want %s", path, filepath.Join(dir, "pn"))
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0755 {
+		t.Errorf("script mode = %v, want 0755", info.Mode().Perm())
+	}
+	if data, _ := os.ReadFile(path); string(data) != code {
+		t.Errorf("script = %q, want %q", data, code)
+	}
+
+	// A second script of the same name leaves the first alone
+	if _, err := WriteScript(dir, "pn", "#!/bin/sh\necho other\n"); err == nil {
+		t.Error("WriteScript() over an existing file succeeded, want an error")
+	}
+	if data, _ := os.ReadFile(path); string(data) != code {
+		t.Errorf("existing script was changed to %q", data)
+	}
+}
+
+func TestOnPath(t *testing.T) {
+	bin := t.TempDir()
+	t.Setenv("PATH", "/usr/bin"+string(os.PathListSeparator)+bin+"/")
+
+	if !OnPath(bin) {
+		t.Errorf("OnPath(%s) = false, want true", bin)
+	}
+	if OnPath(filepath.Join(bin, "other")) {
+		t.Error("OnPath(other) = true, want false")
+	}
+}
+
+func TestPathEntry(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	bin := filepath.Join(home, "bin")
+
+	tests := []struct {
+		target Target
+		want   string
+	}{
+		{TargetZsh, `export PATH="$HOME/bin:$PATH"`},
+		{TargetBash, `export PATH="$HOME/bin:$PATH"`},
+		{TargetFish, "fish_add_path ~/bin"},
+		{TargetPwsh, `$env:PATH = "$HOME/bin" + [IO.Path]::PathSeparator + $env:PATH`},
+	}
+	for _, tt := range tests {
+		if got := PathEntry(bin, tt.target); got != tt.want {
+			t.Errorf("PathEntry(%s) = %q, want %q", tt.target, got, tt.want)
+		}
+	}
+}
@@ -62,6 +62,106 @@ func HasAlias(rcPath, name string) (bool, error) {
 	return false, scanner.Err()
 }
 
+// ExistingAliases returns each alias defined in the RC file mapped to its expansion
+func ExistingAliases(rcPath string) (map[string]string, error) {
+	aliases := map[string]string{}
+
+	file, err := os.Open(rcPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return aliases, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if name, expansion, ok := parseAlias(scanner.Text()); ok {
+			aliases[name] = expansion
+		}
+	}
+
+	return aliases, scanner.Err()
+}
+
+// AliasedAs returns the name of an existing alias that already expands to
+// command, ignoring differences in whitespace
+func AliasedAs(aliases map[string]string, command string) (string, bool) {
+	want := normalizeCommand(command)
+	if want == "" {
+		return "", false
+	}
+
+	// Pick the alphabetically first match so the answer is stable
+	found := ""
+	for name, expansion := range aliases {
+		if normalizeCommand(expansion) == want && (found == "" || name < found) {
+			found = name
+		}
+	}
+	return found, found != ""
+}
+
+// parseAlias parses lines like alias gs='git status' (zsh's -g/-s flags are allowed)
+func parseAlias(line string) (string, string, bool) {
+	fields := strings.TrimSpace(line)
+	if !strings.HasPrefix(fields, "alias ") {
+		return "", "", false
+	}
+	rest := strings.TrimSpace(strings.TrimPrefix(fields, "alias "))
+	for strings.HasPrefix(rest, "-") {
+		_, after, found := strings.Cut(rest, " ")
+		if !found {
+			return "", "", false
+		}
+		rest = strings.TrimSpace(after)
+	}
+
+	name, value, found := strings.Cut(rest, "=")
+	if !found || name == "" || strings.ContainsAny(name, " \t") {
+		return "", "", false
+	}
+	return name, unquote(value), true
+}
+
+// unquote strips shell quoting, and any trailing comment, from an alias value
+func unquote(value string) string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return ""
+	}
+
+	q := value[0]
+	if q != '\'' && q != '"' {
+		value, _, _ = strings.Cut(value, " #")
+		return strings.TrimSpace(value)
+	}
+
+	var sb strings.Builder
+	for i := 1; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case q == '"' && c == '\\' && i+1 < len(value):
+			i++
+			sb.WriteByte(value[i])
+		case c == q && q == '\'' && strings.HasPrefix(value[i:], `'\''`):
+			// '\'' puts a literal quote inside a single-quoted string
+			sb.WriteByte('\'')
+			i += 3
+		case c == q:
+			return sb.String()
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
+
+func normalizeCommand(command string) string {
+	return strings.Join(strings.Fields(command), " ")
+}
+
 // AddToRC adds code to the shell RC file
 func AddToRC(rcPath string, entries []string) error {
 	if len(entries) == 0 {
@@ -147,6 +247,11 @@ func AddToRC(rcPath string, entries []string) error {
 		}
 	}
 
+	// A new shell's config directory (e.g. ~/.config/fish) may not exist yet
+	if err := os.MkdirAll(filepath.Dir(rcPath), 0755); err != nil {
+		return err
+	}
+
 	// Write back with secure permissions
 	return os.WriteFile(rcPath, []byte(finalContent), fileMode)
 }
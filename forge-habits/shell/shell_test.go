@@ -0,0 +1,77 @@
+package shell
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExistingAliases(t *testing.T) {
+	rc := filepath.Join(t.TempDir(), ".zshrc")
+	content := `# my aliases
+alias gs='git status'
+alias ll="ls -la"
+  alias dc=docker-compose   # indented, with a comment
+alias -g G='| grep'
+alias say='echo '\''hi'\'''
+alias esc="echo \"quoted\""
+export PATH=$HOME/bin:$PATH
+gco() { git checkout "$@"; }
+`
+	if err := os.WriteFile(rc, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ExistingAliases(rc)
+	if err != nil {
+		t.Fatalf("ExistingAliases() error = %v", err)
+	}
+	want := map[string]string{
+		"gs":  "git status",
+		"ll":  "ls -la",
+		"dc":  "docker-compose",
+		"G":   "| grep",
+		"say": "echo 'hi'",
+		"esc": `echo "quoted"`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ExistingAliases() = %v, want %v", got, want)
+	}
+
+	missing, err := ExistingAliases(filepath.Join(t.TempDir(), "nope"))
+	if err != nil || len(missing) != 0 {
+		t.Errorf("ExistingAliases(missing) = %v, %v, want empty, nil", missing, err)
+	}
+}
+
+func TestAliasedAs(t *testing.T) {
+	aliases := map[string]string{
+		"gs":     "git status",
+		"gst":    "git status",
+		"k":      "kubectl",
+		"glog":   "git log --oneline  --graph",
+		"search": "grep -rn",
+	}
+
+	tests := []struct {
+		command  string
+		wantName string
+		wantOK   bool
+	}{
+		{"git status", "gs", true},
+		{"  git   status ", "gs", true},
+		{"git log --oneline --graph", "glog", true},
+		{"kubectl", "k", true},
+		{"kubectl get pods", "", false},
+		{"git stat", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		name, ok := AliasedAs(aliases, tt.command)
+		if name != tt.wantName || ok != tt.wantOK {
+			t.Errorf("AliasedAs(%q) = %q, %v, want %q, %v", tt.command, name, ok, tt.wantName, tt.wantOK)
+		}
+	}
+}
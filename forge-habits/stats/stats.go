@@ -0,0 +1,107 @@
+// Package stats keeps a local, never-uploaded tally of suggestion decisions
+package stats
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"forge-shared/datadir"
+)
+
+// Decision is the user's answer to one suggestion
+type Decision struct {
+	Type       string // alias, function
+	Confidence string // high, medium, low
+	Accepted   bool
+}
+
+// Tally counts accepted and rejected suggestions
+type Tally struct {
+	Accepted int `json:"accepted"`
+	Rejected int `json:"rejected"`
+}
+
+// AcceptRate returns the accepted fraction, or 0 if nothing was decided
+func (t Tally) AcceptRate() float64 {
+	total := t.Accepted + t.Rejected
+	if total == 0 {
+		return 0
+	}
+	return float64(t.Accepted) / float64(total)
+}
+
+// Stats is the acceptance history across runs
+type Stats struct {
+	Runs         int              `json:"runs"`
+	FirstRun     time.Time        `json:"first_run"`
+	LastRun      time.Time        `json:"last_run"`
+	Total        Tally            `json:"total"`
+	ByConfidence map[string]Tally `json:"by_confidence"`
+	ByType       map[string]Tally `json:"by_type"`
+}
+
+// Path returns habits-stats.json in the forge data directory
+func Path() string {
+	return datadir.Path("habits-stats.json")
+}
+
+// Load reads stats from path; a missing file is an empty history
+func Load(path string) (*Stats, error) {
+	s := &Stats{ByConfidence: map[string]Tally{}, ByType: map[string]Tally{}}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, err
+	}
+	if s.ByConfidence == nil {
+		s.ByConfidence = map[string]Tally{}
+	}
+	if s.ByType == nil {
+		s.ByType = map[string]Tally{}
+	}
+	return s, nil
+}
+
+// Record adds one run's decisions
+func (s *Stats) Record(decisions []Decision, at time.Time) {
+	s.Runs++
+	if s.FirstRun.IsZero() {
+		s.FirstRun = at
+	}
+	s.LastRun = at
+
+	for _, d := range decisions {
+		s.Total = count(s.Total, d.Accepted)
+		s.ByConfidence[d.Confidence] = count(s.ByConfidence[d.Confidence], d.Accepted)
+		s.ByType[d.Type] = count(s.ByType[d.Type], d.Accepted)
+	}
+}
+
+// Save writes stats to path, creating its directory if needed
+func (s *Stats) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+func count(t Tally, accepted bool) Tally {
+	if accepted {
+		t.Accepted++
+	} else {
+		t.Rejected++
+	}
+	return t
+}
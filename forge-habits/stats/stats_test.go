@@ -0,0 +1,83 @@
+package stats
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRecordAccumulatesAcrossRuns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".forge", "habits-stats.json")
+	day1 := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	day2 := day1.Add(24 * time.Hour)
+
+	runs := [][]Decision{
+		{
+			{Type: "alias", Confidence: "high", Accepted: true},
+			{Type: "alias", Confidence: "high", Accepted: true},
+			{Type: "function", Confidence: "medium", Accepted: false},
+		},
+		{
+			{Type: "alias", Confidence: "medium", Accepted: true},
+			{Type: "function", Confidence: "medium", Accepted: false},
+		},
+	}
+
+	for i, decisions := range runs {
+		s, err := Load(path)
+		if err != nil {
+			t.Fatalf("run %d: Load() error = %v", i+1, err)
+		}
+		s.Record(decisions, []time.Time{day1, day2}[i])
+		if err := s.Save(path); err != nil {
+			t.Fatalf("run %d: Save() error = %v", i+1, err)
+		}
+	}
+
+	s, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if s.Runs != 2 {
+		t.Errorf("Runs = %d, want 2", s.Runs)
+	}
+	if !s.FirstRun.Equal(day1) || !s.LastRun.Equal(day2) {
+		t.Errorf("FirstRun, LastRun = %v, %v, want %v, %v", s.FirstRun, s.LastRun, day1, day2)
+	}
+
+	tallies := []struct {
+		name string
+		got  Tally
+		want Tally
+	}{
+		{"total", s.Total, Tally{Accepted: 3, Rejected: 2}},
+		{"high", s.ByConfidence["high"], Tally{Accepted: 2}},
+		{"medium", s.ByConfidence["medium"], Tally{Accepted: 1, Rejected: 2}},
+		{"alias", s.ByType["alias"], Tally{Accepted: 3}},
+		{"function", s.ByType["function"], Tally{Rejected: 2}},
+	}
+	for _, tt := range tallies {
+		if tt.got != tt.want {
+			t.Errorf("%s = %+v, want %+v", tt.name, tt.got, tt.want)
+		}
+	}
+
+	if got := s.Total.AcceptRate(); got != 0.6 {
+		t.Errorf("Total.AcceptRate() = %v, want 0.6", got)
+	}
+}
+
+func TestPathFollowsForgeDataDir(t *testing.T) {
+	home, xdg, forgeHome := t.TempDir(), t.TempDir(), t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_DATA_HOME", xdg)
+	t.Setenv("FORGE_HOME", forgeHome)
+	if got, want := Path(), filepath.Join(forgeHome, "habits-stats.json"); got != want {
+		t.Errorf("Path() with FORGE_HOME = %s, want %s", got, want)
+	}
+	t.Setenv("FORGE_HOME", "")
+	if got, want := Path(), filepath.Join(xdg, "forge", "habits-stats.json"); got != want {
+		t.Errorf("Path() with XDG_DATA_HOME = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,125 @@
+package suggestions
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"forge-habits/analyzer"
+)
+
+// chainName names a chain after its steps: the first letter of each
+// command, or where every step runs the same command (git add, git commit,
+// git push), that letter followed by the first letter of each subcommand
+func chainName(steps []string) string {
+	var words [][]string
+	for _, step := range steps {
+		words = append(words, strings.Fields(step))
+	}
+
+	same := true
+	for _, w := range words {
+		if len(w) < 2 || w[0] != words[0][0] {
+			same = false
+		}
+	}
+
+	var name strings.Builder
+	if same {
+		name.WriteByte(words[0][0][0])
+	}
+	for _, w := range words {
+		word := w[0]
+		if same {
+			word = w[1]
+		}
+		if c := word[0]; c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' {
+			name.WriteByte(c)
+		}
+		if name.Len() >= 5 {
+			break
+		}
+	}
+	return strings.ToLower(name.String())
+}
+
+// chainParams counts the "$1", "$2"... that ChainPattern put in pattern
+func chainParams(pattern string) int {
+	n := 0
+	for strings.Contains(pattern, fmt.Sprintf(`"$%d"`, n+1)) {
+		n++
+	}
+	return n
+}
+
+// createChainSuggestion suggests a function running the chain pattern, one
+// step per line, its quoted arguments becoming the function's. It returns
+// nil if the function isn't safe to suggest.
+func createChainSuggestion(pattern string, count int) *Suggestion {
+	if containsDangerousPatterns(pattern) {
+		return nil
+	}
+
+	steps, ops := analyzer.SplitChain(pattern)
+	name := chainName(steps)
+	if validateName(name) != nil {
+		name = generateSimpleName(pattern)
+	}
+
+	// One step a line, keeping && and ; apart: after ;, a step runs even if
+	// the one before it failed
+	var body strings.Builder
+	fmt.Fprintf(&body, "%s() {\n  %s", name, steps[0])
+	for i, op := range ops {
+		if op == "&&" {
+			body.WriteString(" &&")
+		}
+		fmt.Fprintf(&body, "\n  %s", steps[i+1])
+	}
+	body.WriteString("\n}")
+	code := body.String()
+
+	if err := ValidateSuggestion(&LLMSuggestion{Name: name, Type: "function", Code: code}); err != nil {
+		log.Printf("Rejected chain suggestion for %q: %v", pattern, err)
+		return nil
+	}
+
+	conf := ConfLow
+	if count >= 20 {
+		conf = ConfHigh
+	} else if count >= 10 {
+		conf = ConfMedium
+	}
+
+	return &Suggestion{
+		Type:        TypeFunction,
+		Name:        name,
+		Usage:       name + strings.Repeat(` "..."`, chainParams(pattern)),
+		Command:     pattern,
+		Code:        code,
+		Description: fmt.Sprintf("%d-command chain used %d times", len(steps), count),
+		Impact:      count,
+		Confidence:  conf,
+	}
+}
+
+// chainSuggestions suggests a function for each chain used at least min times
+func chainSuggestions(chains []analyzer.CommandCount, min int, dismissed *Dismissed) []Suggestion {
+	var funcs []Suggestion
+	for _, cc := range chains {
+		if cc.Count < min || dismissed.Has(cc.Command) {
+			continue
+		}
+		if s := createChainSuggestion(cc.Command, cc.Count); s != nil {
+			funcs = append(funcs, *s)
+		}
+	}
+	return funcs
+}
+
+// isChained reports whether cmd is a use of one of the chained patterns,
+// which its function already covers
+func isChained(cmd string, chained map[string]bool) bool {
+	pattern, ok := analyzer.ChainPattern(cmd)
+	return ok && chained[pattern]
+}
@@ -0,0 +1,62 @@
+package suggestions
+
+import (
+	"testing"
+
+	"forge-habits/analyzer"
+)
+
+func TestChainName(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{`git add . && git commit -m "$1" && git push`, "gacp"},
+		{"make && make install", "mm"},
+		{"cd build; cmake ..; make", "ccm"},
+		{"./configure && make", "m"}, // too short, left to the fallback
+	}
+	for _, tt := range tests {
+		steps, _ := analyzer.SplitChain(tt.pattern)
+		if got := chainName(steps); got != tt.want {
+			t.Errorf("chainName(%q) = %q, want %q", tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestCreateChainSuggestion(t *testing.T) {
+	s := createChainSuggestion(`git add . && git commit -m "$1"; git push origin "$2"`, 25)
+	if s == nil {
+		t.Fatal("createChainSuggestion() = nil")
+	}
+	want := "gacp() {\n  git add . &&\n  git commit -m \"$1\"\n  git push origin \"$2\"\n}"
+	if s.Type != TypeFunction || s.Code != want {
+		t.Errorf("createChainSuggestion() = %s %q, want function %q", s.Type, s.Code, want)
+	}
+	if s.Usage != `gacp "..." "..."` || s.Confidence != ConfHigh {
+		t.Errorf("createChainSuggestion() usage %q, confidence %s", s.Usage, s.Confidence)
+	}
+
+	// Validation still applies
+	if s := createChainSuggestion("cd /tmp && eval $(cat cmd)", 25); s != nil {
+		t.Errorf("createChainSuggestion() = %+v for an eval, want nil", s)
+	}
+}
+
+func TestGenerateWithoutLLMSuggestsChainFunctions(t *testing.T) {
+	pattern := `git add . && git commit -m "$1" && git push`
+	raw := "git add . && git commit -m 'wip' && git push"
+	analysis := &analyzer.Analysis{
+		CommandChains:   []analyzer.CommandCount{{Command: pattern, Count: 30}},
+		AliasCandidates: []analyzer.CommandCount{{Command: raw, Count: 12}},
+	}
+
+	set := GenerateWithoutLLM(analysis, nil)
+	if len(set.HighImpact) != 1 || set.HighImpact[0].Command != pattern || set.HighImpact[0].Type != TypeFunction {
+		t.Fatalf("HighImpact = %+v, want a function for the chain", set.HighImpact)
+	}
+	// The alias for one of its uses isn't offered as well
+	if len(set.Review) != 0 {
+		t.Errorf("Review = %+v, want nothing", set.Review)
+	}
+}
@@ -0,0 +1,73 @@
+package suggestions
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"forge-shared/datadir"
+)
+
+// Dismissed remembers command patterns the user marked "not useful", so
+// they are not suggested again
+type Dismissed struct {
+	Commands map[string]time.Time `json:"commands"` // normalized command -> when dismissed
+}
+
+// DismissedPath returns habits-dismissed.json in the forge data directory
+func DismissedPath() string {
+	return datadir.Path("habits-dismissed.json")
+}
+
+// LoadDismissed reads dismissals from path; a missing file means none
+func LoadDismissed(path string) (*Dismissed, error) {
+	d := &Dismissed{Commands: map[string]time.Time{}}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return d, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, d); err != nil {
+		return nil, err
+	}
+	if d.Commands == nil {
+		d.Commands = map[string]time.Time{}
+	}
+	return d, nil
+}
+
+// Add dismisses command
+func (d *Dismissed) Add(command string, at time.Time) {
+	d.Commands[normalize(command)] = at
+}
+
+// Has reports whether command was dismissed; a nil Dismissed has nothing
+func (d *Dismissed) Has(command string) bool {
+	if d == nil {
+		return false
+	}
+	_, ok := d.Commands[normalize(command)]
+	return ok
+}
+
+// Save writes dismissals to path, creating its directory if needed
+func (d *Dismissed) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// normalize collapses whitespace so "git  status" and "git status" match
+func normalize(command string) string {
+	return strings.Join(strings.Fields(command), " ")
+}
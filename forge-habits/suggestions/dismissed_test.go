@@ -0,0 +1,64 @@
+package suggestions
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"forge-habits/analyzer"
+)
+
+func TestDismissedPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "habits-dismissed.json")
+
+	d, err := LoadDismissed(path)
+	if err != nil {
+		t.Fatalf("LoadDismissed(missing) error = %v", err)
+	}
+	if d.Has("git status") {
+		t.Error("empty Dismissed.Has() = true")
+	}
+
+	d.Add("docker  compose   up -d", time.Now())
+	if err := d.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := LoadDismissed(path)
+	if err != nil {
+		t.Fatalf("LoadDismissed() error = %v", err)
+	}
+	if !loaded.Has("docker compose up -d") {
+		t.Error("reloaded Dismissed lost the dismissal (or whitespace was not normalized)")
+	}
+	if loaded.Has("docker compose down") {
+		t.Error("Has() matched a command that was never dismissed")
+	}
+
+	var none *Dismissed
+	if none.Has("anything") {
+		t.Error("nil Dismissed.Has() = true")
+	}
+}
+
+func TestGenerateWithoutLLMSkipsDismissed(t *testing.T) {
+	analysis := &analyzer.Analysis{
+		AliasCandidates: []analyzer.CommandCount{
+			{Command: "git status --short", Count: 25},
+			{Command: "docker compose up -d", Count: 25},
+		},
+	}
+
+	all := GenerateWithoutLLM(analysis, nil)
+	if len(all.HighImpact) != 2 {
+		t.Fatalf("without dismissals: %d suggestions, want 2: %+v", len(all.HighImpact), all.HighImpact)
+	}
+
+	d := &Dismissed{Commands: map[string]time.Time{}}
+	d.Add("docker compose up -d", time.Now())
+	set := GenerateWithoutLLM(analysis, d)
+
+	if len(set.HighImpact) != 1 || set.HighImpact[0].Command != "git status --short" {
+		t.Errorf("with docker dismissed: %+v, want only git status --short", set.HighImpact)
+	}
+}
@@ -0,0 +1,104 @@
+package suggestions
+
+import (
+	"fmt"
+	"log"
+
+	"forge-habits/analyzer"
+)
+
+// ScriptStages is how many stages a pipeline needs before it's suggested as
+// a script in ~/bin instead of an alias: past that, a one-line alias is hard
+// to read and harder to fix
+const ScriptStages = 4
+
+// Stages counts the commands in a pipeline: one more than the |, ||, && and
+// ; operators outside quotes
+func Stages(cmd string) int {
+	stages := 1
+	var quote byte
+	for i := 0; i < len(cmd); i++ {
+		c := cmd[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\\':
+			i++
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '|' || c == ';':
+			stages++
+			if i+1 < len(cmd) && cmd[i+1] == c {
+				i++ // || and ;; are one operator
+			}
+		case c == '&' && i+1 < len(cmd) && cmd[i+1] == '&':
+			stages++
+			i++
+		}
+	}
+	return stages
+}
+
+// NeedsScript reports whether cmd has enough stages to be better as a script
+func NeedsScript(cmd string) bool {
+	return Stages(cmd) >= ScriptStages
+}
+
+// ScriptCode is the script a pipeline is saved as
+func ScriptCode(cmd string) string {
+	return fmt.Sprintf("#!/usr/bin/env bash\n# Saved by forge-habits\nset -o pipefail\n\n%s\n", cmd)
+}
+
+// createScriptSuggestion suggests saving cmd as a script, or returns nil if
+// it isn't safe to
+func createScriptSuggestion(cmd string, count int) *Suggestion {
+	if containsDangerousPatterns(cmd) {
+		return nil
+	}
+	if err := validateCodeSafety(cmd); err != nil {
+		log.Printf("Rejected script suggestion for %q: %v", cmd, err)
+		return nil
+	}
+
+	name := generateSimpleName(cmd)
+	if err := validateName(name); err != nil {
+		return nil
+	}
+
+	conf := ConfLow
+	if count >= 20 {
+		conf = ConfHigh
+	} else if count >= 10 {
+		conf = ConfMedium
+	}
+
+	return &Suggestion{
+		Type:        TypeScript,
+		Name:        name,
+		Usage:       name,
+		Command:     cmd,
+		Code:        ScriptCode(cmd),
+		Description: fmt.Sprintf("%d-stage pipeline used %d times", Stages(cmd), count),
+		Impact:      count,
+		Confidence:  conf,
+	}
+}
+
+// scriptSuggestions suggests a script for each complex pipeline used at
+// least min times
+func scriptSuggestions(pipelines []analyzer.CommandCount, min int, dismissed *Dismissed) []Suggestion {
+	var scripts []Suggestion
+	for _, pc := range pipelines {
+		if pc.Count < min || !NeedsScript(pc.Command) || dismissed.Has(pc.Command) {
+			continue
+		}
+		if s := createScriptSuggestion(pc.Command, pc.Count); s != nil {
+			scripts = append(scripts, *s)
+		}
+	}
+	return scripts
+}
@@ -0,0 +1,82 @@
+package suggestions
+
+import (
+	"strings"
+	"testing"
+
+	"forge-habits/analyzer"
+)
+
+func TestStages(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		want int
+	}{
+		{"ls", 1},
+		{"ps aux | grep node", 2},
+		{"make && ./run || echo failed", 3},
+		{"cd src; make; make install", 3},
+		{"grep 'a|b' log | sort | uniq -c | sort -rn", 4},
+		{`echo "x && y; z" | wc -c`, 2},
+		{`echo a\|b | cat`, 2},
+		{"sleep 5 & wait", 1}, // backgrounding isn't a stage
+	}
+	for _, tt := range tests {
+		if got := Stages(tt.cmd); got != tt.want {
+			t.Errorf("Stages(%q) = %d, want %d", tt.cmd, got, tt.want)
+		}
+	}
+}
+
+func TestNeedsScript(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		want bool
+	}{
+		{"ps aux | grep node", false},
+		{"cat log | grep ERROR | sort", false}, // three stages, one short of the threshold
+		{"cat log | grep ERROR | sort | uniq -c", true},
+		{"git fetch && git rebase origin/main && go test ./... | tail", true},
+	}
+	for _, tt := range tests {
+		if got := NeedsScript(tt.cmd); got != tt.want {
+			t.Errorf("NeedsScript(%q) = %v, want %v", tt.cmd, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateWithoutLLMSuggestsScripts(t *testing.T) {
+	long := "kubectl get pods -A | grep -v Running | awk '{print $2}' | sort | uniq"
+	short := "docker ps | grep api"
+	analysis := &analyzer.Analysis{
+		PipelineCommands: []analyzer.CommandCount{
+			{Command: long, Count: 25},
+			{Command: short, Count: 25},
+		},
+		AliasCandidates: []analyzer.CommandCount{
+			{Command: long, Count: 25},
+		},
+	}
+
+	set := GenerateWithoutLLM(analysis, nil)
+	if len(set.Scripts) != 1 {
+		t.Fatalf("Scripts = %+v, want one for the long pipeline", set.Scripts)
+	}
+	s := set.Scripts[0]
+	if s.Type != TypeScript || s.Command != long {
+		t.Errorf("script = %+v, want a script for %q", s, long)
+	}
+	if !strings.HasPrefix(s.Code, "#!/usr/bin/env bash\n") || !strings.HasSuffix(s.Code, "\n"+long+"\n") {
+		t.Errorf("script code = %q, want a bash script running the pipeline", s.Code)
+	}
+
+	// The long pipeline isn't offered as an alias as well
+	for _, a := range append(set.HighImpact, set.Review...) {
+		if a.Command == long {
+			t.Errorf("long pipeline also suggested as %s %s", a.Type, a.Name)
+		}
+	}
+	if len(set.HighImpact) != 1 || set.HighImpact[0].Command != short {
+		t.Errorf("HighImpact = %+v, want an alias for %q", set.HighImpact, short)
+	}
+}
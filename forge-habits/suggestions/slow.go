@@ -0,0 +1,68 @@
+package suggestions
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"forge-habits/analyzer"
+)
+
+// slowKinds is advice for kinds of slow command, by words in them. The
+// first kind with a word in the command wins.
+var slowKinds = []struct {
+	words  []string
+	advice string
+}{
+	{[]string{"test", "pytest", "jest", "vitest", "rspec"}, "While working, run just the tests you're changing, or a watch mode, and leave the full suite to CI."},
+	{[]string{"docker build", "docker compose build"}, "Order the Dockerfile so rarely changed layers come first, and BuildKit's cache mounts keep downloads between builds."},
+	{[]string{"build", "make", "cargo", "gradle", "mvn", "bazel", "xcodebuild", "cmake"}, "A build cache such as ccache, sccache or Gradle's build cache can make repeat builds much quicker."},
+	{[]string{"install", "upgrade", "update", "npm ci", "bundle"}, "Installs from a lockfile with a warm package cache skip most of the downloading."},
+}
+
+// slowAdvice picks the advice for cmd's kind, if it has one
+func slowAdvice(cmd string) string {
+	for _, kind := range slowKinds {
+		for _, word := range kind.words {
+			if strings.Contains(cmd, word) {
+				return kind.advice
+			}
+		}
+	}
+	return ""
+}
+
+// formatDuration rounds d to what's worth reading: 45s, 4m, 1h5m
+func formatDuration(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return d.Round(time.Second).String()
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Round(time.Minute).Minutes()))
+	default:
+		d = d.Round(time.Minute)
+		return fmt.Sprintf("%dh%dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+}
+
+// longRunningTips suggests ways to stop waiting on each slow command:
+// backgrounding it, and whatever speeds up its kind of work
+func longRunningTips(long []analyzer.LongRunning) []Suggestion {
+	var tips []Suggestion
+	for _, lr := range long {
+		desc := fmt.Sprintf("'%s' took %s or more %d of %d times (%s on average, %s at most). Run it in the background with '&', or 'nohup %s > out.log 2>&1 &' to outlive the terminal, and carry on.",
+			lr.Command, formatDuration(analyzer.LongRun), lr.LongRuns, lr.Runs, formatDuration(lr.Average), formatDuration(lr.Longest), lr.Command)
+		if advice := slowAdvice(lr.Command); advice != "" {
+			desc += " " + advice
+		}
+		tips = append(tips, Suggestion{
+			Type:        TypeTip,
+			Name:        lr.Command,
+			Command:     lr.Command,
+			Description: desc,
+			Impact:      lr.LongRuns,
+			Confidence:  ConfMedium,
+		})
+	}
+	return tips
+}
@@ -26,6 +26,7 @@ const (
 	TypeAlias    SuggestionType = "alias"
 	TypeFunction SuggestionType = "function"
 	TypeTip      SuggestionType = "tip"
+	TypeScript   SuggestionType = "script" // Saved to ~/bin rather than the RC file
 )
 
 // Suggestion represents an actionable improvement
@@ -45,18 +46,34 @@ type SuggestionSet struct {
 	HighImpact []Suggestion // Auto-add candidates
 	Review     []Suggestion // Need user review
 	Tips       []Suggestion // Just informational
+	Scripts    []Suggestion // Pipelines too long for an alias, to save in ~/bin
 }
 
-// Generate creates actionable suggestions from analysis using LLM
-func Generate(analysis *analyzer.Analysis, client llm.Client) *SuggestionSet {
+// Generate creates actionable suggestions from analysis using LLM, leaving
+// out dismissed patterns
+func Generate(analysis *analyzer.Analysis, client llm.Client, dismissed *Dismissed) *SuggestionSet {
 	set := &SuggestionSet{}
 
+	// Complex pipelines become scripts rather than going to the LLM
+	scripts := scriptSuggestions(analysis.PipelineCommands, 3, dismissed)
+	scripted := make(map[string]bool)
+	for _, s := range scripts {
+		scripted[s.Command] = true
+	}
+
+	// Chains on one line become functions, their quoted arguments parameters
+	chains := chainSuggestions(analysis.CommandChains, 3, dismissed)
+	chained := make(map[string]bool)
+	for _, s := range chains {
+		chained[s.Command] = true
+	}
+
 	// Collect patterns worth analyzing
 	var patterns []PatternInput
 
 	// Long commands used repeatedly
 	for _, ac := range analysis.AliasCandidates {
-		if ac.Count >= 5 {
+		if ac.Count >= 5 && !dismissed.Has(ac.Command) && !scripted[ac.Command] && !isChained(ac.Command, chained) {
 			patterns = append(patterns, PatternInput{
 				Command: ac.Command,
 				Count:   ac.Count,
@@ -67,7 +84,7 @@ func Generate(analysis *analyzer.Analysis, client llm.Client) *SuggestionSet {
 
 	// Pipeline commands
 	for _, pc := range analysis.PipelineCommands {
-		if pc.Count >= 3 {
+		if pc.Count >= 3 && !dismissed.Has(pc.Command) && !NeedsScript(pc.Command) {
 			patterns = append(patterns, PatternInput{
 				Command: pc.Command,
 				Count:   pc.Count,
@@ -87,22 +104,31 @@ func Generate(analysis *analyzer.Analysis, client llm.Client) *SuggestionSet {
 		}
 	}
 
-	if len(patterns) == 0 {
+	if len(patterns) == 0 && len(scripts) == 0 && len(chains) == 0 {
 		return set
 	}
 
 	// Ask LLM to analyze patterns
-	suggestions := analyzePatternsWithLLM(patterns, client)
+	var suggestions []Suggestion
+	if len(patterns) > 0 {
+		suggestions = analyzePatternsWithLLM(patterns, client)
+	}
 
-	// Categorize by confidence
-	seen := make(map[string]bool)
+	// Only high and medium confidence suggestions are offered, so only they
+	// compete for names, along with the scripts that share the same PATH
+	batch := append(scripts, chains...)
 	for _, s := range suggestions {
-		if seen[s.Name] {
+		if dismissed.Has(s.Command) || (s.Confidence != ConfHigh && s.Confidence != ConfMedium) {
 			continue
 		}
-		seen[s.Name] = true
+		batch = append(batch, s)
+	}
 
-		if s.Confidence == ConfHigh {
+	// Categorize by confidence
+	for _, s := range ResolveCollisions(batch) {
+		if s.Type == TypeScript {
+			set.Scripts = append(set.Scripts, s)
+		} else if s.Confidence == ConfHigh {
 			set.HighImpact = append(set.HighImpact, s)
 		} else if s.Confidence == ConfMedium {
 			set.Review = append(set.Review, s)
@@ -115,27 +141,35 @@ func Generate(analysis *analyzer.Analysis, client llm.Client) *SuggestionSet {
 	return set
 }
 
-// GenerateWithoutLLM creates suggestions using heuristics only
-func GenerateWithoutLLM(analysis *analyzer.Analysis) *SuggestionSet {
+// GenerateWithoutLLM creates suggestions using heuristics only, leaving out
+// dismissed patterns
+func GenerateWithoutLLM(analysis *analyzer.Analysis, dismissed *Dismissed) *SuggestionSet {
 	set := &SuggestionSet{}
-	seen := make(map[string]bool)
+	var batch []Suggestion
 
 	addSuggestion := func(s *Suggestion) {
-		if s == nil || seen[s.Name] {
+		if s == nil || dismissed.Has(s.Command) {
 			return
 		}
-		seen[s.Name] = true
+		batch = append(batch, *s)
+	}
 
-		if s.Confidence == ConfHigh {
-			set.HighImpact = append(set.HighImpact, *s)
-		} else {
-			set.Review = append(set.Review, *s)
-		}
+	// Complex pipelines become scripts, the rest aliases
+	scripted := make(map[string]bool)
+	for _, s := range scriptSuggestions(analysis.PipelineCommands, 5, dismissed) {
+		scripted[s.Command] = true
+		batch = append(batch, s)
+	}
+
+	chained := make(map[string]bool)
+	for _, s := range chainSuggestions(analysis.CommandChains, 5, dismissed) {
+		chained[s.Command] = true
+		batch = append(batch, s)
 	}
 
 	// Simple heuristics for common patterns
 	for _, pc := range analysis.PipelineCommands {
-		if pc.Count < 5 {
+		if pc.Count < 5 || NeedsScript(pc.Command) {
 			continue
 		}
 		s := createSimpleSuggestion(pc.Command, pc.Count)
@@ -143,13 +177,23 @@ func GenerateWithoutLLM(analysis *analyzer.Analysis) *SuggestionSet {
 	}
 
 	for _, ac := range analysis.AliasCandidates {
-		if ac.Count < 5 {
+		if ac.Count < 5 || scripted[ac.Command] || isChained(ac.Command, chained) {
 			continue
 		}
 		s := createSimpleSuggestion(ac.Command, ac.Count)
 		addSuggestion(s)
 	}
 
+	for _, s := range ResolveCollisions(batch) {
+		if s.Type == TypeScript {
+			set.Scripts = append(set.Scripts, s)
+		} else if s.Confidence == ConfHigh {
+			set.HighImpact = append(set.HighImpact, s)
+		} else {
+			set.Review = append(set.Review, s)
+		}
+	}
+
 	set.Tips = generateTips(analysis)
 	return set
 }
@@ -446,5 +490,7 @@ func generateTips(analysis *analyzer.Analysis) []Suggestion {
 		}
 	}
 
+	tips = append(tips, longRunningTips(analysis.LongRunning)...)
+
 	return tips
 }
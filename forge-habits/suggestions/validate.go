@@ -2,6 +2,7 @@ package suggestions
 
 import (
 	"fmt"
+	"log"
 	"regexp"
 	"strings"
 )
@@ -87,6 +88,32 @@ func ValidateSuggestion(s *LLMSuggestion) error {
 	return nil
 }
 
+// ResolveCollisions keeps one suggestion per name. ValidateSuggestion checks
+// each suggestion alone, but two in one batch named gb would both be written,
+// and the second would silently shadow the first. The more used one wins,
+// the earlier one on a tie; order is otherwise kept.
+func ResolveCollisions(batch []Suggestion) []Suggestion {
+	winner := make(map[string]int) // name -> index in batch
+	for i, s := range batch {
+		if w, ok := winner[s.Name]; !ok || s.Impact > batch[w].Impact {
+			winner[s.Name] = i
+		}
+	}
+
+	var kept []Suggestion
+	for i, s := range batch {
+		w := winner[s.Name]
+		if w != i {
+			if s.Command != batch[w].Command {
+				log.Printf("Dropped suggestion %q for %q: the name is taken by %q, used more often", s.Name, s.Command, batch[w].Command)
+			}
+			continue
+		}
+		kept = append(kept, s)
+	}
+	return kept
+}
+
 func validateName(name string) error {
 	if name == "" {
 		return &ValidationError{Field: "name", Message: "name cannot be empty"}
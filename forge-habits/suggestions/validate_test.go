@@ -1,6 +1,7 @@
 package suggestions
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -212,3 +213,20 @@ func TestIsSuspicious(t *testing.T) {
 		})
 	}
 }
+
+func TestResolveCollisions(t *testing.T) {
+	batch := []Suggestion{
+		{Name: "gb", Command: "git branch --sort=-committerdate", Impact: 12},
+		{Name: "kp", Command: "lsof -ti:8080 | xargs kill -9", Impact: 9},
+		{Name: "gb", Command: "go build ./...", Impact: 40},
+		{Name: "gb", Command: "git bisect", Impact: 40},
+	}
+
+	got := ResolveCollisions(batch)
+
+	// The most used gb wins, the earlier of two equally used ones, and order is kept
+	want := []Suggestion{batch[1], batch[2]}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ResolveCollisions() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,26 @@
+// Package datadir finds the directory where forge, forge-dust and
+// forge-habits keep their data, so all three agree on it.
+package datadir
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Dir returns the data directory: $FORGE_HOME if set, else
+// $XDG_DATA_HOME/forge if that's set to an absolute path, else ~/.forge
+func Dir() string {
+	if dir := os.Getenv("FORGE_HOME"); dir != "" {
+		return dir
+	}
+	if xdg := os.Getenv("XDG_DATA_HOME"); xdg != "" && filepath.IsAbs(xdg) {
+		return filepath.Join(xdg, "forge")
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".forge")
+}
+
+// Path joins elem onto Dir
+func Path(elem ...string) string {
+	return filepath.Join(append([]string{Dir()}, elem...)...)
+}
@@ -0,0 +1,40 @@
+package datadir
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDir(t *testing.T) {
+	home, xdg := t.TempDir(), t.TempDir()
+	forgeHome := filepath.Join(t.TempDir(), "forge")
+	tests := []struct {
+		name      string
+		forgeHome string
+		xdg       string
+		legacy    bool // ~/.forge already exists
+		want      string
+	}{
+		{"nothing set", "", "", false, filepath.Join(home, ".forge")},
+		{"nothing set with ~/.forge", "", "", true, filepath.Join(home, ".forge")},
+		{"FORGE_HOME", forgeHome, xdg, true, forgeHome},
+		{"XDG_DATA_HOME", "", xdg, false, filepath.Join(xdg, "forge")},
+		{"XDG_DATA_HOME with ~/.forge", "", xdg, true, filepath.Join(xdg, "forge")},
+		{"relative XDG_DATA_HOME", "", "data", false, filepath.Join(home, ".forge")},
+	}
+	for _, tt := range tests {
+		t.Setenv("HOME", home)
+		t.Setenv("FORGE_HOME", tt.forgeHome)
+		t.Setenv("XDG_DATA_HOME", tt.xdg)
+		os.RemoveAll(filepath.Join(home, ".forge"))
+		if tt.legacy {
+			if err := os.Mkdir(filepath.Join(home, ".forge"), 0755); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if got := Dir(); got != tt.want {
+			t.Errorf("%s: Dir() = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
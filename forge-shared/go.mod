@@ -0,0 +1,7 @@
+module forge-shared
+
+go 1.25.5
+
+require golang.org/x/term v0.40.0
+
+require golang.org/x/sys v0.41.0 // indirect
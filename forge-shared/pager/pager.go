@@ -0,0 +1,147 @@
+// Package pager shows long reports through $PAGER, the way git does: only
+// when stdout is a terminal and the report wouldn't fit on it. forge,
+// forge-dust and forge-habits all page their reports with it.
+package pager
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Capture holds stdout while a report is printed
+type Capture struct {
+	stdout *os.File
+	w      *os.File
+	buf    bytes.Buffer
+	done   chan struct{}
+}
+
+// Start captures stdout until Stop, unless disabled or stdout isn't a
+// terminal, in which case output goes straight through as usual
+func Start(disabled bool) *Capture {
+	if disabled || !isTerminal(os.Stdout) {
+		return nil
+	}
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil
+	}
+	c := &Capture{stdout: os.Stdout, w: w, done: make(chan struct{})}
+	go func() {
+		io.Copy(&c.buf, r)
+		r.Close()
+		close(c.done)
+	}()
+	os.Stdout = w
+	return c
+}
+
+// Writer returns where to print the report: into the capture while there
+// is one, otherwise w
+func (c *Capture) Writer(w io.Writer) io.Writer {
+	if c == nil {
+		return w
+	}
+	return c.w
+}
+
+// Stop restores stdout and shows what was captured: through the pager if it
+// is taller than the terminal, directly otherwise
+func (c *Capture) Stop() {
+	if c == nil {
+		return
+	}
+	c.w.Close()
+	<-c.done
+	os.Stdout = c.stdout
+
+	out := c.buf.Bytes()
+	if !ShouldPage(false, true, bytes.Count(out, []byte("\n")), terminalHeight()) || !page(out, c.stdout) {
+		c.stdout.Write(out)
+	}
+}
+
+// ShouldPage decides whether output of lines lines needs a pager on a
+// terminal height lines tall. A report that fits is printed as is.
+func ShouldPage(disabled, terminal bool, lines, height int) bool {
+	return !disabled && terminal && height > 0 && lines >= height
+}
+
+// Command returns the pager to run from $PAGER (less by default) and whether
+// it shows colors. less does when given -R, which LESS=FRX supplies unless
+// the user set LESS themselves; other pagers get plain text.
+func Command(pagerEnv string) (args []string, color bool) {
+	args = strings.Fields(pagerEnv)
+	if len(args) == 0 {
+		args = []string{"less"}
+	}
+	if filepath.Base(args[0]) != "less" {
+		return args, false
+	}
+	if less, ok := os.LookupEnv("LESS"); ok && !strings.Contains(less, "R") && !strings.Contains(less, "r") {
+		args = append(args, "-R")
+	}
+	return args, true
+}
+
+// ansi matches the color escapes the tools print
+var ansi = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// StripColor removes color escapes from out
+func StripColor(out []byte) []byte {
+	return ansi.ReplaceAll(out, nil)
+}
+
+// page runs the pager on out, reporting false if it couldn't be started
+func page(out []byte, stdout *os.File) bool {
+	args, color := Command(os.Getenv("PAGER"))
+	if !color {
+		out = StripColor(out)
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = bytes.NewReader(out)
+	cmd.Stdout = stdout
+	cmd.Stderr = os.Stderr
+	if _, ok := os.LookupEnv("LESS"); !ok {
+		cmd.Env = append(os.Environ(), "LESS=FRX")
+	}
+	if err := cmd.Start(); err != nil {
+		return false
+	}
+	cmd.Wait()
+	return true
+}
+
+// isTerminal reports whether f is a terminal rather than a pipe or file
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// terminalHeight returns the terminal's rows from $LINES or stty, or 0 if
+// neither knows
+func terminalHeight() int {
+	if n, err := strconv.Atoi(os.Getenv("LINES")); err == nil && n > 0 {
+		return n
+	}
+	tty, err := os.Open("/dev/tty")
+	if err != nil {
+		return 0
+	}
+	defer tty.Close()
+	cmd := exec.Command("stty", "size")
+	cmd.Stdin = tty
+	out, err := cmd.Output()
+	if err != nil {
+		return 0
+	}
+	rows, _, _ := strings.Cut(strings.TrimSpace(string(out)), " ")
+	n, _ := strconv.Atoi(rows)
+	return n
+}
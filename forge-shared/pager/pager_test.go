@@ -0,0 +1,64 @@
+package pager
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestShouldPage(t *testing.T) {
+	tests := []struct {
+		name     string
+		disabled bool
+		terminal bool
+		lines    int
+		height   int
+		want     bool
+	}{
+		{"long report on a terminal", false, true, 200, 50, true},
+		{"exactly a screenful", false, true, 50, 50, true},
+		{"fits on screen", false, true, 30, 50, false},
+		{"--no-pager", true, true, 200, 50, false},
+		{"piped", false, false, 200, 50, false},
+		{"unknown height", false, true, 200, 0, false},
+	}
+
+	for _, tt := range tests {
+		if got := ShouldPage(tt.disabled, tt.terminal, tt.lines, tt.height); got != tt.want {
+			t.Errorf("ShouldPage(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCommand(t *testing.T) {
+	tests := []struct {
+		pager     string
+		less      string // $LESS; "-" leaves it unset
+		wantArgs  []string
+		wantColor bool
+	}{
+		{"", "-", []string{"less"}, true},
+		{"/usr/bin/less", "-", []string{"/usr/bin/less"}, true},
+		{"less", "FRX", []string{"less"}, true},
+		{"less", "S", []string{"less", "-R"}, true},
+		{"more", "-", []string{"more"}, false},
+		{"most -s", "-", []string{"most", "-s"}, false},
+	}
+
+	for _, tt := range tests {
+		if tt.less == "-" {
+			t.Setenv("LESS", "") // Restored after the test
+			os.Unsetenv("LESS")
+		} else {
+			t.Setenv("LESS", tt.less)
+		}
+		args, color := Command(tt.pager)
+		if !reflect.DeepEqual(args, tt.wantArgs) || color != tt.wantColor {
+			t.Errorf("Command(%q) with LESS=%q = %q, %v, want %q, %v", tt.pager, tt.less, args, color, tt.wantArgs, tt.wantColor)
+		}
+	}
+
+	if got := string(StripColor([]byte("\x1b[1m\x1b[36mTitle\x1b[0m plain"))); got != "Title plain" {
+		t.Errorf("StripColor() = %q, want %q", got, "Title plain")
+	}
+}
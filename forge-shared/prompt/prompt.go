@@ -0,0 +1,127 @@
+// Package prompt reads the answers forge and forge-habits ask for at their
+// prompts, with line editing on a terminal and plainly from anything else.
+package prompt
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// ErrInterrupted is returned when the user presses Ctrl-C at a prompt.
+// Closed input, or Ctrl-D on an empty line, is io.EOF.
+var ErrInterrupted = errors.New("input interrupted")
+
+// LineReader reads one line of user input at a time
+type LineReader interface {
+	ReadLine() (string, error)
+}
+
+// NewLineReader returns a reader with line editing and history (arrow keys,
+// Ctrl-A/E/U/W) when in is a terminal, and a plain buffered reader otherwise
+func NewLineReader(in *os.File) LineReader {
+	fd := int(in.Fd())
+	if !term.IsTerminal(fd) {
+		return NewPlainReader(in)
+	}
+	return newTermReader(fd, in, os.Stdout)
+}
+
+// NewPlainReader returns a reader of the lines in r, without line editing
+func NewPlainReader(r io.Reader) LineReader {
+	return &plainReader{reader: bufio.NewReader(r)}
+}
+
+// termReader puts the terminal in raw mode only while a line is being read,
+// so the terminal is always restored between prompts and on interrupt
+type termReader struct {
+	fd   int
+	term *term.Terminal
+	keys *keyWatcher
+}
+
+func newTermReader(fd int, in io.Reader, out io.Writer) *termReader {
+	keys := &keyWatcher{Reader: in}
+	rw := struct {
+		io.Reader
+		io.Writer
+	}{keys, out}
+	return &termReader{fd: fd, term: term.NewTerminal(rw, ""), keys: keys}
+}
+
+func (r *termReader) ReadLine() (string, error) {
+	state, err := term.MakeRaw(r.fd)
+	if err != nil {
+		return "", err
+	}
+	defer term.Restore(r.fd, state)
+	return r.read()
+}
+
+// read reads a line in whatever mode the terminal is in. The terminal
+// reports Ctrl-C and Ctrl-D alike as io.EOF, so which it was comes from the
+// keys it read; a pasted line is just a line.
+func (r *termReader) read() (string, error) {
+	line, err := r.term.ReadLine()
+	switch {
+	case err == io.EOF && r.keys.ctrlC:
+		return "", ErrInterrupted
+	case err == term.ErrPasteIndicator:
+		err = nil
+	}
+	return strings.TrimSpace(line), err
+}
+
+// keyWatcher notes whether the last keys read from the terminal had a Ctrl-C
+type keyWatcher struct {
+	io.Reader
+	ctrlC bool
+}
+
+func (k *keyWatcher) Read(p []byte) (int, error) {
+	n, err := k.Reader.Read(p)
+	k.ctrlC = bytes.IndexByte(p[:n], 3) >= 0
+	return n, err
+}
+
+type plainReader struct {
+	reader *bufio.Reader
+}
+
+func (r *plainReader) ReadLine() (string, error) {
+	line, err := r.reader.ReadString('\n')
+	if err == io.EOF && line == "" {
+		return "", io.EOF
+	}
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// IsYes interprets a y/n answer; an empty answer returns defaultYes
+func IsYes(input string, defaultYes bool) bool {
+	switch strings.ToLower(strings.TrimSpace(input)) {
+	case "":
+		return defaultYes
+	case "y", "yes":
+		return true
+	default:
+		return false
+	}
+}
+
+// ParseChoice interprets input as a 1-based menu number between 1 and max
+func ParseChoice(input string, max int) (int, bool) {
+	num, err := strconv.Atoi(strings.TrimSpace(input))
+	if err != nil || num < 1 || num > max {
+		return 0, false
+	}
+	return num, true
+}
@@ -0,0 +1,90 @@
+package prompt
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestIsYes(t *testing.T) {
+	tests := []struct {
+		input      string
+		defaultYes bool
+		want       bool
+	}{
+		{"", true, true},
+		{"", false, false},
+		{"y", false, true},
+		{" YES ", false, true},
+		{"n", true, false},
+		{"no", true, false},
+		{"maybe", true, false},
+	}
+
+	for _, tt := range tests {
+		if got := IsYes(tt.input, tt.defaultYes); got != tt.want {
+			t.Errorf("IsYes(%q, %v) = %v, want %v", tt.input, tt.defaultYes, got, tt.want)
+		}
+	}
+}
+
+func TestParseChoice(t *testing.T) {
+	tests := []struct {
+		input  string
+		max    int
+		want   int
+		wantOK bool
+	}{
+		{"1", 3, 1, true},
+		{" 3 ", 3, 3, true},
+		{"0", 3, 0, false},
+		{"4", 3, 0, false},
+		{"a", 3, 0, false},
+		{"", 3, 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := ParseChoice(tt.input, tt.max)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("ParseChoice(%q, %d) = %d, %v, want %d, %v", tt.input, tt.max, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestPlainReader(t *testing.T) {
+	r := NewPlainReader(strings.NewReader("  d \nlast"))
+
+	for _, want := range []string{"d", "last"} {
+		got, err := r.ReadLine()
+		if err != nil || got != want {
+			t.Errorf("ReadLine() = %q, %v, want %q, nil", got, err, want)
+		}
+	}
+
+	if _, err := r.ReadLine(); err != io.EOF {
+		t.Errorf("ReadLine() at end of input error = %v, want io.EOF", err)
+	}
+}
+
+func TestTermReader(t *testing.T) {
+	tests := []struct {
+		name    string
+		keys    string
+		want    string
+		wantErr error
+	}{
+		{"typed", " d \r", "d", nil},
+		{"pasted", "\x1b[200~/tmp/some dir\r\x1b[201~", "/tmp/some dir", nil},
+		{"ctrl-c", "par\x03", "", ErrInterrupted},
+		{"ctrl-d", "\x04", "", io.EOF},
+		{"closed", "", "", io.EOF},
+	}
+
+	for _, tt := range tests {
+		r := newTermReader(-1, strings.NewReader(tt.keys), io.Discard)
+		got, err := r.read()
+		if got != tt.want || err != tt.wantErr {
+			t.Errorf("%s: read() = %q, %v, want %q, %v", tt.name, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+// Package size parses the sizes given to forge and forge-dust flags, so
+// "--target 20" and "--min-cache-size 20" mean the same amount.
+package size
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Parse parses sizes like "512KB", "20GB" or "1.5TB" (powers of 1024);
+// bare numbers are MB, like forge-dust's --min-size
+func Parse(s string) (int64, error) {
+	units := []struct {
+		suffix string
+		mult   float64
+	}{
+		{"TB", 1 << 40}, {"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1},
+	}
+
+	num, mult := strings.ToUpper(strings.TrimSpace(s)), float64(1<<20)
+	for _, u := range units {
+		if strings.HasSuffix(num, u.suffix) {
+			num, mult = strings.TrimSpace(strings.TrimSuffix(num, u.suffix)), u.mult
+			break
+		}
+	}
+
+	n, err := strconv.ParseFloat(num, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return int64(n * mult), nil
+}
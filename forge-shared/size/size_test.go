@@ -0,0 +1,27 @@
+package size
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int64
+		wantErr bool
+	}{
+		{"20", 20 << 20, false}, // Bare numbers are MB
+		{"512KB", 512 << 10, false},
+		{" 20gb ", 20 << 30, false},
+		{"1.5TB", 3 << 39, false},
+		{"100B", 100, false},
+		{"0", 0, false},
+		{"-1GB", 0, true},
+		{"lots", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("Parse(%q) = %d, %v, want %d, wantErr %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
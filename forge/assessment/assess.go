@@ -1,11 +1,17 @@
 package assessment
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"forge/llm"
+	"forge/messages"
 	"forge/rules"
 )
 
@@ -21,6 +27,16 @@ const (
 	ModeNull          Mode = "null"          // Nothing to do
 )
 
+// ParseMode returns the mode named s, or "" if s isn't one a category can
+// be pinned to
+func ParseMode(s string) Mode {
+	switch m := Mode(strings.ToLower(strings.TrimSpace(s))); m {
+	case ModeAuto, ModeSuggest, ModeGuided, ModeCollaborative, ModeInformative:
+		return m
+	}
+	return ""
+}
+
 // Finding represents a single item found by a tool
 type Finding struct {
 	Category    string            `json:"category"`
@@ -28,10 +44,17 @@ type Finding struct {
 	Size        int64             `json:"size"`
 	Type        string            `json:"type"`
 	AgeDays     int               `json:"age_days,omitempty"`
-	Metadata    map[string]string `json:"metadata,omitempty"`
+	Modified    time.Time         `json:"modified,omitzero"`
+	Confidence  string            `json:"confidence,omitempty"` // this finding's own confidence
+	Active      bool              `json:"active,omitempty"`     // a cache modified within ActiveWindow
+	Metadata    map[string]string `json:"metadata,omitempty"`   // the tool's context, e.g. "contains" for an archive
 	RuleApplied *rules.MergedRule `json:"-"`
 }
 
+// ActiveWindow is how recently a cache must have changed to count as in use.
+// Deleting a cache that was just rebuilt only means rebuilding it again.
+const ActiveWindow = 24 * time.Hour
+
 // CategoryAssessment is the assessment for a category of findings
 type CategoryAssessment struct {
 	Category    string    `json:"category"`
@@ -43,17 +66,56 @@ type CategoryAssessment struct {
 	Mode        Mode      `json:"mode"`
 	Explanation string    `json:"explanation"`
 	Action      string    `json:"suggested_action"`
+	ModeTrace   []string  `json:"mode_trace,omitempty"` // how Mode was reached, for --explain-mode
 }
 
 // SessionAssessment is the overall assessment for a session
 type SessionAssessment struct {
 	OverallMode      Mode                 `json:"overall_mode"`
 	OpeningMessage   string               `json:"opening_message"`
+	Opening          Opening              `json:"opening"` // what OpeningMessage says, as data
 	Categories       []CategoryAssessment `json:"categories"`
 	TotalReclaimable int64                `json:"total_reclaimable"`
 	Flags            []string             `json:"flags_detected"`
+	ModeReason       string               `json:"mode_reason,omitempty"` // how OverallMode was reached
+	Withheld         []string             `json:"withheld,omitempty"`    // categories left out by safe mode or MaxRisk
+	Hidden           []string             `json:"hidden,omitempty"`      // categories the user hid, counted in the total but not presented
+	Kept             []Kept               `json:"kept,omitempty"`        // items found but not proposed, largest first
+	Trash            int64                `json:"trash,omitempty"`       // bytes in the Trash, left to forge-dust --empty-trash
+
+	// Explanations are the LLM's accounts of the categories the user will
+	// be walked through, coming in while the session gets going
+	Explanations *Explanations `json:"-"`
+}
+
+// Kept is an item the scan found that the session won't propose deleting,
+// with the reason it was held back
+type Kept struct {
+	Path     string `json:"path,omitempty"` // empty when the tool listed the category without items
+	Size     int64  `json:"size"`
+	Category string `json:"category"`
+	Reason   string `json:"reason"`
 }
 
+// Opening is the opening message as data, for frontends that lay it out
+// themselves. The rules-based OpeningMessage is rendered from it.
+type Opening struct {
+	Mode          Mode          `json:"mode"`
+	Reclaimable   int64         `json:"total_reclaimable"`
+	Categories    int           `json:"category_count"`
+	TopCategories []TopCategory `json:"top_categories,omitempty"` // largest first
+}
+
+// TopCategory is one of the largest categories an Opening names
+type TopCategory struct {
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+	Mode Mode   `json:"mode"`
+}
+
+// topCategories is how many categories an Opening names
+const topCategories = 3
+
 // ToolOutput is the expected JSON structure from forge tools
 type ToolOutput struct {
 	Tool        string `json:"tool"`
@@ -75,30 +137,48 @@ type ToolOutput struct {
 			SafeAction   string `json:"safe_action"`
 		} `json:"metadata"`
 		Items []struct {
-			Path    string            `json:"path"`
-			Size    int64             `json:"size"`
-			Type    string            `json:"type"`
-			AgeDays int               `json:"age_days,omitempty"`
-			Context map[string]string `json:"context,omitempty"`
+			Path     string            `json:"path"`
+			Size     int64             `json:"size"`
+			Type     string            `json:"type"`
+			AgeDays  int               `json:"age_days,omitempty"`
+			Modified time.Time         `json:"modified,omitzero"`
+			Context  map[string]string `json:"context,omitempty"`
 		} `json:"items"`
 	} `json:"categories"`
 }
 
 // Assessor determines the interaction mode based on findings
 type Assessor struct {
-	Rules  *rules.RuleSet
+	Rules  *rules.RuleSet // nil, or no rule matching, leaves the tool's metadata to decide
 	Client *llm.OllamaClient
+	Safe   bool // only present reversible categories
+
+	// MaxRisk, if set, withholds categories riskier than it, as Safe
+	// withholds irreversible ones
+	MaxRisk rules.Level
+
+	// MaxAutoRisk is the highest risk a category can have and still be
+	// handled automatically; anything riskier is at most suggested
+	MaxAutoRisk rules.Level
+
+	// CategoryModes pins categories, by id or name, to a mode whatever
+	// the rules and flags say; MaxAutoRisk still holds
+	CategoryModes map[string]Mode
 }
 
 // NewAssessor creates a new assessor
 func NewAssessor(rs *rules.RuleSet, client *llm.OllamaClient) *Assessor {
 	return &Assessor{
-		Rules:  rs,
-		Client: client,
+		Rules:       rs,
+		Client:      client,
+		MaxAutoRisk: rules.LevelLow,
 	}
 }
 
-// Assess analyzes tool output and determines interaction mode
+// Assess analyzes tool output and determines interaction mode. Rules only
+// set confidence: where none matches, confidence stays medium and each
+// category's own typical_risk and reversible decide its mode, which is the
+// baseline forge falls back to with no rules and no LLM.
 func (a *Assessor) Assess(output *ToolOutput, flags []string) (*SessionAssessment, error) {
 	assessment := &SessionAssessment{
 		Flags: flags,
@@ -106,9 +186,39 @@ func (a *Assessor) Assess(output *ToolOutput, flags []string) (*SessionAssessmen
 
 	hasQuickFlag := contains(flags, "--quick")
 	hasCarefulFlag := contains(flags, "--careful")
+	now := time.Now()
 
 	// Assess each category
 	for _, cat := range output.Categories {
+		keepAll := func(reason string) {
+			if len(cat.Items) == 0 {
+				assessment.Kept = append(assessment.Kept, Kept{Size: cat.TotalSize, Category: cat.Name, Reason: reason})
+			}
+			for _, item := range cat.Items {
+				assessment.Kept = append(assessment.Kept, Kept{Path: item.Path, Size: item.Size, Category: cat.Name, Reason: reason})
+			}
+		}
+
+		// The Trash is emptied by forge-dust's own guarded flow, never moved
+		// aside as a folder like any other finding
+		if cat.Metadata.SafeAction == "empty_trash" {
+			assessment.Trash += cat.TotalSize
+			assessment.TotalReclaimable += cat.TotalSize
+			keepAll("emptied with 'forge-dust --empty-trash', not deleted as a folder")
+			continue
+		}
+		if reason := a.withholdReason(cat.Metadata.Reversible, cat.Metadata.TypicalRisk); reason != "" {
+			assessment.Withheld = append(assessment.Withheld, cat.Name)
+			keepAll(reason)
+			continue
+		}
+		if a.hidden(cat.ID, cat.Name) {
+			assessment.Hidden = append(assessment.Hidden, cat.Name)
+			assessment.TotalReclaimable += cat.TotalSize
+			keepAll(fmt.Sprintf("category hidden ('forge unhide %s' to show)", cat.Name))
+			continue
+		}
+
 		catAssess := CategoryAssessment{
 			Category:   cat.Name,
 			TotalSize:  cat.TotalSize,
@@ -116,98 +226,316 @@ func (a *Assessor) Assess(output *ToolOutput, flags []string) (*SessionAssessmen
 			Risk:       cat.Metadata.TypicalRisk,
 			Reversible: cat.Metadata.Reversible,
 		}
+		var ruleTrace string
+		active := 0
 
 		// Apply rules to determine confidence
 		for _, item := range cat.Items {
+			// A protected item isn't offered, nor counted as reclaimable
+			if pref, ok := a.neverDelete(item.Path); ok {
+				assessment.Kept = append(assessment.Kept, Kept{Path: item.Path, Size: item.Size, Category: cat.Name, Reason: describeNever(pref)})
+				catAssess.TotalSize -= item.Size
+				continue
+			}
+
 			finding := Finding{
-				Category: cat.Name,
-				Path:     item.Path,
-				Size:     item.Size,
-				Type:     item.Type,
-				AgeDays:  item.AgeDays,
+				Category:   cat.Name,
+				Path:       item.Path,
+				Size:       item.Size,
+				Type:       item.Type,
+				AgeDays:    item.AgeDays,
+				Modified:   item.Modified,
+				Confidence: "medium",
+				Metadata:   item.Context,
 			}
 
 			// Check if we have a rule for this
-			rule := a.Rules.GetRuleFor(item.Path)
+			var rule *rules.MergedRule
+			if a.Rules != nil {
+				rule = a.Rules.GetRuleFor(item.Path)
+			}
 			if rule != nil {
 				finding.RuleApplied = rule
+				finding.Confidence = rule.EffectiveConf
 				catAssess.Confidence = rule.EffectiveConf
+				ruleTrace = fmt.Sprintf("confidence %q from %s matching %s",
+					rule.EffectiveConf, describeRule(rule), filepath.Base(item.Path))
+			}
+			// A cache that just changed is being used; it's worth more than a stale one
+			if cat.Metadata.Reversible && isActive(item.Modified, now) {
+				finding.Active = true
+				finding.Confidence = string(rules.ParseLevel(finding.Confidence).Lower())
+				active++
 			}
 
 			catAssess.Findings = append(catAssess.Findings, finding)
 		}
 
+		if len(cat.Items) > 0 && len(catAssess.Findings) == 0 {
+			continue // Every item is protected
+		}
+
+		catAssess.ModeTrace = append(catAssess.ModeTrace, fmt.Sprintf("inputs: confidence=%s, risk=%s, reversible=%v",
+			catAssess.Confidence, catAssess.Risk, catAssess.Reversible))
+		if ruleTrace != "" {
+			catAssess.ModeTrace = append(catAssess.ModeTrace, ruleTrace)
+		} else {
+			catAssess.ModeTrace = append(catAssess.ModeTrace, "no rule matched; confidence defaults to medium, so risk and reversibility decide")
+		}
+		if active > 0 && active == len(catAssess.Findings) {
+			lowered := string(rules.ParseLevel(catAssess.Confidence).Lower())
+			catAssess.ModeTrace = append(catAssess.ModeTrace, fmt.Sprintf("every item changed in the last %s, so in use: confidence %s → %s",
+				ActiveWindow, catAssess.Confidence, lowered))
+			catAssess.Confidence = lowered
+		} else if active > 0 {
+			catAssess.ModeTrace = append(catAssess.ModeTrace, fmt.Sprintf("%d of %d items changed in the last %s and count as in use",
+				active, len(catAssess.Findings), ActiveWindow))
+		}
+
 		// Determine mode for this category
-		catAssess.Mode = determineMode(catAssess.Confidence, catAssess.Risk, catAssess.Reversible)
+		var reason string
+		catAssess.Mode, reason = determineMode(catAssess.Confidence, catAssess.Risk, catAssess.Reversible)
+		catAssess.ModeTrace = append(catAssess.ModeTrace, fmt.Sprintf("%s: %s", catAssess.Mode, reason))
 
 		// Override with flags
 		if hasQuickFlag {
-			catAssess.Mode = biasTowandAuto(catAssess.Mode)
+			biased := biasTowandAuto(catAssess.Mode)
+			catAssess.ModeTrace = append(catAssess.ModeTrace, fmt.Sprintf("--quick: %s → %s", catAssess.Mode, biased))
+			catAssess.Mode = biased
 		}
 		if hasCarefulFlag {
-			catAssess.Mode = biasTowardCareful(catAssess.Mode)
+			biased := biasTowardCareful(catAssess.Mode)
+			catAssess.ModeTrace = append(catAssess.ModeTrace, fmt.Sprintf("--careful: %s → %s", catAssess.Mode, biased))
+			catAssess.Mode = biased
+		}
+
+		if pinned, ok := a.pinnedMode(cat.ID, cat.Name); ok && pinned != catAssess.Mode {
+			catAssess.ModeTrace = append(catAssess.ModeTrace, fmt.Sprintf("category_modes: %s → %s", catAssess.Mode, pinned))
+			catAssess.Mode = pinned
+		}
+
+		// Checked last, so neither a rule's confidence, --quick nor a pinned mode can get past it
+		if catAssess.Mode == ModeAuto && !a.autoAllowed(catAssess.Risk) {
+			catAssess.ModeTrace = append(catAssess.ModeTrace, fmt.Sprintf("max_auto_risk %s: risk %s is too high for auto, so %s → %s",
+				a.maxAutoRisk(), catAssess.Risk, ModeAuto, ModeSuggest))
+			catAssess.Mode = ModeSuggest
 		}
 
 		catAssess.Explanation = generateExplanation(catAssess)
 		catAssess.Action = suggestAction(catAssess)
 
 		assessment.Categories = append(assessment.Categories, catAssess)
-		assessment.TotalReclaimable += cat.TotalSize
+		assessment.TotalReclaimable += catAssess.TotalSize
 	}
 
+	sort.SliceStable(assessment.Kept, func(i, j int) bool {
+		return assessment.Kept[i].Size > assessment.Kept[j].Size
+	})
+
 	// Determine overall session mode
-	assessment.OverallMode = aggregateMode(assessment.Categories)
-	assessment.OpeningMessage = generateOpeningMessage(assessment)
+	assessment.OverallMode, assessment.ModeReason = aggregateMode(assessment.Categories)
+	if a.Safe && (assessment.OverallMode == ModeGuided || assessment.OverallMode == ModeCollaborative) {
+		assessment.ModeReason = fmt.Sprintf("%s, but safe mode left only reversible categories, so suggest", assessment.ModeReason)
+		assessment.OverallMode = ModeSuggest
+	}
+	assessment.Opening = newOpening(assessment)
+	assessment.OpeningMessage = assessment.Opening.Message()
 
 	return assessment, nil
 }
 
-// AssessWithLLM uses the LLM for more nuanced assessment
-func (a *Assessor) AssessWithLLM(output *ToolOutput, flags []string) (*SessionAssessment, error) {
+// withholdReason says why safe mode or MaxRisk leaves a category out, or
+// returns "" if neither does
+func (a *Assessor) withholdReason(reversible bool, risk string) string {
+	if a.Safe && !reversible {
+		return "not reversible, and safe mode offers only what rebuilds itself"
+	}
+	if a.MaxRisk != "" && !a.MaxRisk.AtLeast(rules.ParseLevel(risk)) {
+		if risk == "" {
+			risk = "unknown"
+		}
+		return fmt.Sprintf("%s risk, above the %s allowed", risk, a.MaxRisk)
+	}
+	return ""
+}
+
+// neverDelete returns the never_delete preference protecting path, if any
+func (a *Assessor) neverDelete(path string) (rules.Preference, bool) {
+	if a.Rules == nil {
+		return rules.Preference{}, false
+	}
+	return a.Rules.NeverDeleteFor(path)
+}
+
+// hidden reports whether the user hid a category, by its id or name
+func (a *Assessor) hidden(id, name string) bool {
+	if a.Rules == nil {
+		return false
+	}
+	return (id != "" && a.Rules.IsHidden(id)) || a.Rules.IsHidden(name)
+}
+
+// pinnedMode looks a category up in CategoryModes by id, then by name,
+// ignoring case
+func (a *Assessor) pinnedMode(id, name string) (Mode, bool) {
+	for _, key := range []string{id, name} {
+		for pinned, mode := range a.CategoryModes {
+			if key != "" && strings.EqualFold(pinned, key) {
+				return mode, true
+			}
+		}
+	}
+	return "", false
+}
+
+// maxAutoRisk is MaxAutoRisk, or low if it isn't a known level
+func (a *Assessor) maxAutoRisk() rules.Level {
+	if level := rules.ParseLevel(string(a.MaxAutoRisk)); level != "" {
+		return level
+	}
+	return rules.LevelLow
+}
+
+// autoAllowed reports whether a category of this risk may be handled
+// automatically. An unknown risk is never low enough.
+func (a *Assessor) autoAllowed(risk string) bool {
+	level := rules.ParseLevel(risk)
+	return level != "" && level.Score() <= a.maxAutoRisk().Score()
+}
+
+// AssessWithLLM uses the LLM for more nuanced assessment: an opening
+// message, waited for, and an explanation of each category that will be
+// walked through or discussed, fetched in the background until ctx ends or
+// the assessment's Explanations are stopped.
+func (a *Assessor) AssessWithLLM(ctx context.Context, output *ToolOutput, flags []string) (*SessionAssessment, error) {
 	// First do rule-based assessment
 	assessment, err := a.Assess(output, flags)
 	if err != nil {
 		return nil, err
 	}
 
-	// If we have mixed or complex findings, consult LLM
-	if assessment.OverallMode == ModeGuided || assessment.OverallMode == ModeCollaborative {
-		llmAssessment, err := a.getLLMAssessment(output, assessment)
-		if err == nil && llmAssessment != "" {
-			// Parse LLM response and enhance assessment
-			assessment.OpeningMessage = llmAssessment
+	// Only mixed or complex findings are worth consulting the LLM on
+	if assessment.OverallMode != ModeGuided && assessment.OverallMode != ModeCollaborative {
+		return assessment, nil
+	}
+
+	// If it doesn't come back, the rule-based message stands
+	if opening, err := a.assessmentPrompt(output, assessment); err == nil {
+		if message, err := a.Client.GenerateContext(ctx, opening); err == nil && message != "" {
+			assessment.OpeningMessage = message
+		}
+	}
+
+	var discussed []CategoryAssessment
+	for _, cat := range assessment.Categories {
+		if cat.Mode == ModeGuided || cat.Mode == ModeCollaborative {
+			discussed = append(discussed, cat)
 		}
 	}
+	assessment.Explanations = fetchExplanations(ctx, a.Client, discussed)
 
 	return assessment, nil
 }
 
-func (a *Assessor) getLLMAssessment(output *ToolOutput, initial *SessionAssessment) (string, error) {
-	prompt := buildAssessmentPrompt(output, initial)
-	return a.Client.Generate(prompt)
+// Explanations fetches the LLM's account of categories in the background,
+// so asking about one is usually instant without the first screen waiting
+// on them all. A nil Explanations has none.
+type Explanations struct {
+	mu     sync.Mutex
+	texts  map[string]string // By category, once it has come back
+	cancel context.CancelFunc
+	done   chan struct{}
 }
 
-func buildAssessmentPrompt(output *ToolOutput, initial *SessionAssessment) string {
-	var sb strings.Builder
+// fetchExplanations starts asking client about each of cats, up to its
+// Concurrency at a time. A call that fails leaves that category to the
+// rule-based text, and the others carry on.
+func fetchExplanations(ctx context.Context, client *llm.OllamaClient, cats []CategoryAssessment) *Explanations {
+	ctx, cancel := context.WithCancel(ctx)
+	e := &Explanations{texts: make(map[string]string), cancel: cancel, done: make(chan struct{})}
+	prompts := make([]string, len(cats))
+	for i, cat := range cats {
+		prompts[i] = CategoryPrompt(cat)
+	}
+	go func() {
+		defer close(e.done)
+		client.GenerateEach(ctx, prompts, func(i int, response string) {
+			e.mu.Lock()
+			e.texts[cats[i].Category] = strings.TrimSpace(response)
+			e.mu.Unlock()
+		})
+	}()
+	return e
+}
 
-	sb.WriteString(`You are the Forge assistant, helping a user clean up their disk.
+// Lookup returns category's explanation, if it has come back yet
+func (e *Explanations) Lookup(category string) (string, bool) {
+	if e == nil {
+		return "", false
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	text, ok := e.texts[category]
+	return text, ok && text != ""
+}
 
-FINDINGS:
-`)
+// Wait blocks until every explanation has come back or failed
+func (e *Explanations) Wait() {
+	if e != nil {
+		<-e.done
+	}
+}
 
-	for _, cat := range output.Categories {
-		sb.WriteString(fmt.Sprintf("\n%s (%d items, %d bytes):\n",
-			cat.Name, cat.ItemCount, cat.TotalSize))
-		sb.WriteString(fmt.Sprintf("  Risk: %s, Reversible: %v\n",
-			cat.Metadata.TypicalRisk, cat.Metadata.Reversible))
+// Stop cancels the calls still going and waits for them to end
+func (e *Explanations) Stop() {
+	if e != nil {
+		e.cancel()
+		<-e.done
+	}
+}
+
+// CategoryPrompt asks the LLM to explain a category to the user
+func CategoryPrompt(cat CategoryAssessment) string {
+	return fmt.Sprintf(`Explain in 2-3 sentences what "%s" files are and whether they're safe to delete. Be concise and helpful. The user is looking at %d files totaling %s.`,
+		cat.Category, len(cat.Findings), formatBytes(cat.TotalSize))
+}
+
+// AssessmentPrompt is the template in llm.PromptDir that replaces the
+// built-in opening-message prompt
+const AssessmentPrompt = "assessment.tmpl"
+
+// PromptData is what an assessment prompt template is rendered with
+type PromptData struct {
+	Output     *ToolOutput
+	Assessment *SessionAssessment
+	Default    string // The built-in prompt, for templates that only add to it
+}
+
+// assessmentPrompt is the opening-message prompt, the user's template if
+// they have one
+func (a *Assessor) assessmentPrompt(output *ToolOutput, initial *SessionAssessment) (string, error) {
+	prompt := buildAssessmentPrompt(output, initial, a.Client.Budget())
+	custom, ok, err := llm.CustomPrompt(AssessmentPrompt, PromptData{Output: output, Assessment: initial, Default: prompt})
+	if err != nil {
+		return "", err
 	}
+	if ok {
+		prompt = custom
+	}
+	return prompt, nil
+}
+
+// buildAssessmentPrompt describes the findings in at most budget characters
+// (0 for no limit); if they don't all fit, the largest categories are kept
+func buildAssessmentPrompt(output *ToolOutput, initial *SessionAssessment, budget int) string {
+	header := `You are the Forge assistant, helping a user clean up their disk.
 
-	sb.WriteString(`
+FINDINGS:
+`
+	footer := fmt.Sprintf(`
 INITIAL ASSESSMENT:
-`)
-	sb.WriteString(fmt.Sprintf("Overall mode: %s\n", initial.OverallMode))
+Overall mode: %s
 
-	sb.WriteString(`
 TASK:
 Write a brief, friendly opening message (2-3 sentences) that:
 1. Summarizes what was found
@@ -215,79 +543,83 @@ Write a brief, friendly opening message (2-3 sentences) that:
 3. Makes the user feel in control
 
 Be concise. No markdown formatting.
-`)
+`, initial.OverallMode)
 
+	var sections []llm.Section
+	for _, cat := range output.Categories {
+		sections = append(sections, llm.Section{
+			Text: fmt.Sprintf("\n%s (%d items, %d bytes):\n  Risk: %s, Reversible: %v\n",
+				cat.Name, cat.ItemCount, cat.TotalSize, cat.Metadata.TypicalRisk, cat.Metadata.Reversible),
+			Priority: int(cat.TotalSize),
+		})
+	}
+	if budget > 0 {
+		budget = max(budget-len(header)-len(footer)-len(sampledNote), 1)
+	}
+	kept, dropped := llm.Fit(sections, budget)
+
+	var sb strings.Builder
+	sb.WriteString(header)
+	for _, text := range kept {
+		sb.WriteString(text)
+	}
+	if dropped > 0 {
+		sb.WriteString(fmt.Sprintf(sampledNote, len(kept), len(sections)))
+	}
+	sb.WriteString(footer)
 	return sb.String()
 }
 
-func determineMode(confidence, risk string, reversible bool) Mode {
+// sampledNote tells the LLM that the findings were cut to fit the prompt
+const sampledNote = "\n(Only the %d largest of %d categories are listed, to keep this prompt short.)\n"
+
+// isActive reports whether modified falls within ActiveWindow of now
+func isActive(modified, now time.Time) bool {
+	return !modified.IsZero() && now.Sub(modified) < ActiveWindow
+}
+
+// determineMode picks a category's mode and returns the decisive reason
+func determineMode(confidence, risk string, reversible bool) (Mode, string) {
 	// High confidence + low risk = more automatic
 	// Low confidence + high risk = more careful
 
-	confScore := confidenceScore(confidence)
-	riskScore := riskScore(risk)
+	confScore := rules.ParseLevel(confidence).Score()
+	riskScore := rules.ParseLevel(risk).Score()
 
-	if riskScore == 3 { // high risk
+	if riskScore >= 3 { // high risk
 		if confScore >= 2 {
-			return ModeGuided
+			return ModeGuided, "high risk, confidence at least medium"
 		}
-		return ModeInformative
+		return ModeInformative, "high risk with low confidence"
 	}
 
 	if riskScore == 2 { // medium risk
-		if confScore == 3 {
-			return ModeSuggest
+		if confScore >= 3 {
+			return ModeSuggest, "medium risk with high confidence"
 		}
 		if confScore == 2 {
-			return ModeGuided
+			return ModeGuided, "medium risk with medium confidence"
 		}
-		return ModeCollaborative
+		return ModeCollaborative, fmt.Sprintf("medium risk with %s confidence", rules.Level(confidence).Label())
 	}
 
 	// low risk
 	if confScore >= 2 && reversible {
-		return ModeSuggest
-	}
-	if confScore == 3 {
-		return ModeAuto
+		return ModeSuggest, "low risk and reversible, confidence at least medium"
 	}
-
-	return ModeGuided
-}
-
-func confidenceScore(conf string) int {
-	switch conf {
-	case "very_high":
-		return 4
-	case "high":
-		return 3
-	case "medium":
-		return 2
-	case "low":
-		return 1
-	default:
-		return 2
+	if confScore >= 3 {
+		return ModeAuto, "low risk with high confidence"
 	}
-}
 
-func riskScore(risk string) int {
-	switch risk {
-	case "high":
-		return 3
-	case "medium":
-		return 2
-	case "low":
-		return 1
-	default:
-		return 2
-	}
+	return ModeGuided, fmt.Sprintf("low risk but %s confidence and not reversible", rules.Level(confidence).Label())
 }
 
-func aggregateMode(categories []CategoryAssessment) Mode {
+// aggregateMode combines category modes and returns the decisive reason
+func aggregateMode(categories []CategoryAssessment) (Mode, string) {
 	// Rule 1: Any high-risk pulls toward careful
 	for _, cat := range categories {
-		if cat.Risk == "high" {
-			return ModeGuided
+		if rules.ParseLevel(cat.Risk).AtLeast(rules.LevelHigh) {
+			return ModeGuided, fmt.Sprintf("high-risk category %q pulls the session to guided", cat.Category)
 		}
 	}
 
@@ -302,12 +634,14 @@ func aggregateMode(categories []CategoryAssessment) Mode {
 			}
 		}
 		if allSame {
-			return firstMode
+			return firstMode, fmt.Sprintf("all categories agree on %s", firstMode)
 		}
+
+		// Rule 3: Mixed = guided
+		return ModeGuided, "categories disagree on mode, so the session is guided"
 	}
 
-	// Rule 3: Mixed = guided
-	return ModeGuided
+	return ModeNull, "no categories found, so there is nothing to do"
 }
 
 func biasTowandAuto(m Mode) Mode {
@@ -358,22 +692,40 @@ func suggestAction(cat CategoryAssessment) string {
 	}
 }
 
-func generateOpeningMessage(a *SessionAssessment) string {
-	switch a.OverallMode {
+// newOpening gathers what the opening message says about a
+func newOpening(a *SessionAssessment) Opening {
+	o := Opening{
+		Mode:        a.OverallMode,
+		Reclaimable: a.TotalReclaimable,
+		Categories:  len(a.Categories),
+	}
+	for _, cat := range a.Categories {
+		o.TopCategories = append(o.TopCategories, TopCategory{Name: cat.Category, Size: cat.TotalSize, Mode: cat.Mode})
+	}
+	sort.SliceStable(o.TopCategories, func(i, j int) bool {
+		return o.TopCategories[i].Size > o.TopCategories[j].Size
+	})
+	if len(o.TopCategories) > topCategories {
+		o.TopCategories = o.TopCategories[:topCategories]
+	}
+	return o
+}
+
+// Message renders the opening in the current message pack
+func (o Opening) Message() string {
+	switch o.Mode {
 	case ModeAuto:
-		return "Found pure slag ready to burn off."
+		return messages.Get("opening.auto")
 	case ModeSuggest:
-		return fmt.Sprintf("Found %s of raw material that could be smelted down.",
-			formatBytes(a.TotalReclaimable))
+		return messages.Getf("opening.suggest", formatBytes(o.Reclaimable))
 	case ModeGuided:
-		return fmt.Sprintf("Found %d ore deposits to inspect. Let's examine each one.",
-			len(a.Categories))
+		return messages.Getf("opening.guided", o.Categories)
 	case ModeCollaborative:
-		return "Found some unusual materials. Best we look at these together before firing up the furnace."
+		return messages.Get("opening.collaborative")
 	case ModeInformative:
-		return "Laid out the findings on the anvil. The hammer's yours."
+		return messages.Get("opening.informative")
 	default:
-		return "Forge inspection complete. The workshop is clean."
+		return messages.Get("opening.done")
 	}
 }
 
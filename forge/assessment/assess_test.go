@@ -0,0 +1,510 @@
+package assessment
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"forge/llm"
+	"forge/rules"
+)
+
+// toolOutput parses a ToolOutput fixture
+func toolOutput(t *testing.T, data string) *ToolOutput {
+	t.Helper()
+	out, err := ParseToolOutput([]byte(data))
+	if err != nil {
+		t.Fatalf("ParseToolOutput() error = %v", err)
+	}
+	return out
+}
+
+const mixedOutput = `{
+  "tool": "forge-dust",
+  "categories": [
+    {"id": "cache_directories", "name": "Cache Directories", "total_size": 5000,
+     "metadata": {"typical_risk": "low", "reversible": true},
+     "items": [{"path": "/home/u/proj/node_modules", "size": 5000, "type": "node_modules"}]},
+    {"id": "large_files", "name": "Large Files", "total_size": 9000,
+     "metadata": {"typical_risk": "high", "reversible": false},
+     "items": [{"path": "/home/u/Movies/trip.mov", "size": 9000, "type": "large_file"}]}
+  ]
+}`
+
+func TestExplainModesNamesDecisiveFactor(t *testing.T) {
+	rs := &rules.RuleSet{}
+	a, err := NewAssessor(rs, nil).Assess(toolOutput(t, mixedOutput), []string{"--quick"})
+	if err != nil {
+		t.Fatalf("Assess() error = %v", err)
+	}
+
+	explanation := ExplainModes(a)
+
+	wants := []string{
+		`high-risk category "Large Files" pulls the session to guided`,
+		"inputs: confidence=medium, risk=low, reversible=true",
+		"low risk and reversible",
+		"--quick: suggest → auto",
+		"high risk, confidence at least medium",
+	}
+	for _, want := range wants {
+		if !strings.Contains(explanation, want) {
+			t.Errorf("ExplainModes() missing %q in:\n%s", want, explanation)
+		}
+	}
+}
+
+func TestSafeModeDropsIrreversibleCategories(t *testing.T) {
+	assessor := NewAssessor(&rules.RuleSet{}, nil)
+	assessor.Safe = true
+	a, err := assessor.Assess(toolOutput(t, mixedOutput), nil)
+	if err != nil {
+		t.Fatalf("Assess() error = %v", err)
+	}
+
+	if len(a.Categories) != 1 || a.Categories[0].Category != "Cache Directories" {
+		t.Fatalf("Categories = %v, want only Cache Directories", a.Categories)
+	}
+	if len(a.Withheld) != 1 || a.Withheld[0] != "Large Files" {
+		t.Errorf("Withheld = %v, want [Large Files]", a.Withheld)
+	}
+	if a.TotalReclaimable != 5000 {
+		t.Errorf("TotalReclaimable = %d, want 5000", a.TotalReclaimable)
+	}
+	if a.OverallMode == ModeGuided || a.OverallMode == ModeCollaborative {
+		t.Errorf("OverallMode = %s, want auto or suggest", a.OverallMode)
+	}
+}
+
+func TestRecentlyModifiedCacheIsDowngraded(t *testing.T) {
+	recent := time.Now().Add(-10 * time.Minute).Format(time.RFC3339)
+	stale := time.Now().Add(-120 * 24 * time.Hour).Format(time.RFC3339)
+	out := toolOutput(t, `{
+  "tool": "forge-dust",
+  "categories": [
+    {"id": "cache_directories", "name": "Cache Directories", "total_size": 8000,
+     "metadata": {"typical_risk": "low", "reversible": true},
+     "items": [
+       {"path": "/home/u/app/node_modules", "size": 5000, "type": "node_modules", "modified": "`+recent+`"},
+       {"path": "/home/u/old/node_modules", "size": 3000, "type": "node_modules", "modified": "`+stale+`"}
+     ]}
+  ]
+}`)
+
+	a, err := NewAssessor(&rules.RuleSet{}, nil).Assess(out, nil)
+	if err != nil {
+		t.Fatalf("Assess() error = %v", err)
+	}
+
+	findings := a.Categories[0].Findings
+	active, old := findings[0], findings[1]
+	if !active.Active || old.Active {
+		t.Fatalf("Active = %v, %v; want true for the cache touched minutes ago only", active.Active, old.Active)
+	}
+	if rules.ParseLevel(active.Confidence).Score() >= rules.ParseLevel(old.Confidence).Score() {
+		t.Errorf("confidence active=%s, stale=%s; want the active cache lower", active.Confidence, old.Confidence)
+	}
+
+	// --target skips the active cache even though it is the largest
+	sel := SelectForTarget(a.Categories, 1000)
+	if len(sel.Findings) != 1 || sel.Findings[0].Path != old.Path {
+		t.Errorf("SelectForTarget() = %+v, want only %s", sel.Findings, old.Path)
+	}
+}
+
+func TestVeryHighConfidenceCountsAsHigh(t *testing.T) {
+	tests := []struct {
+		confidence, risk string
+		reversible       bool
+		want             Mode
+	}{
+		{"very_high", "medium", false, ModeSuggest},
+		{"high", "medium", false, ModeSuggest},
+		{"very_high", "low", false, ModeAuto},
+		{"very_high", "very_high", false, ModeGuided}, // very high risk is still high risk
+	}
+
+	for _, tt := range tests {
+		if got, _ := determineMode(tt.confidence, tt.risk, tt.reversible); got != tt.want {
+			t.Errorf("determineMode(%q, %q, %v) = %v, want %v", tt.confidence, tt.risk, tt.reversible, got, tt.want)
+		}
+	}
+}
+
+func TestMaxAutoRiskKeepsMediumRiskOutOfAuto(t *testing.T) {
+	out := toolOutput(t, `{
+  "tool": "forge-dust",
+  "categories": [
+    {"id": "downloads", "name": "Downloads", "total_size": 7000,
+     "metadata": {"typical_risk": "medium", "reversible": false},
+     "items": [{"path": "/home/u/Downloads/setup.dmg", "size": 7000, "type": "download"}]}
+  ]
+}`)
+	rs := &rules.RuleSet{Merged: map[string]rules.MergedRule{
+		"installers": {Rule: rules.Rule{Patterns: []string{"*.dmg"}}, EffectiveConf: "very_high"},
+	}}
+
+	// High confidence and --quick would otherwise make it suggest → auto
+	a, err := NewAssessor(rs, nil).Assess(out, []string{"--quick"})
+	if err != nil {
+		t.Fatalf("Assess() error = %v", err)
+	}
+	cat := a.Categories[0]
+	if cat.Mode == ModeAuto || a.OverallMode == ModeAuto {
+		t.Errorf("medium-risk category mode = %v (session %v), want it kept out of auto:\n%s",
+			cat.Mode, a.OverallMode, strings.Join(cat.ModeTrace, "\n"))
+	}
+
+	// Raising the limit lets it through
+	assessor := NewAssessor(rs, nil)
+	assessor.MaxAutoRisk = rules.LevelMedium
+	if a, _ := assessor.Assess(out, []string{"--quick"}); a.Categories[0].Mode != ModeAuto {
+		t.Errorf("with max_auto_risk medium, mode = %v, want %v", a.Categories[0].Mode, ModeAuto)
+	}
+}
+
+func TestCategoryModesOverrideComputedMode(t *testing.T) {
+	assessor := NewAssessor(&rules.RuleSet{}, nil)
+	assessor.CategoryModes = map[string]Mode{
+		"cache_directories": ModeAuto,   // by id; computed suggest
+		"large files":       ModeGuided, // by name, any case; computed guided already
+	}
+	a, err := assessor.Assess(toolOutput(t, mixedOutput), []string{"--careful"})
+	if err != nil {
+		t.Fatalf("Assess() error = %v", err)
+	}
+	if got := a.Categories[0].Mode; got != ModeAuto {
+		t.Errorf("pinned Cache Directories mode = %s, want auto over --careful:\n%s",
+			got, strings.Join(a.Categories[0].ModeTrace, "\n"))
+	}
+
+	// Pinning a high-risk category to auto still stops at max_auto_risk
+	assessor.CategoryModes = map[string]Mode{"large_files": ModeAuto}
+	a, _ = assessor.Assess(toolOutput(t, mixedOutput), nil)
+	if got := a.Categories[1].Mode; got != ModeSuggest {
+		t.Errorf("Large Files pinned to auto = %s, want suggest under max_auto_risk low:\n%s",
+			got, strings.Join(a.Categories[1].ModeTrace, "\n"))
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	tests := map[string]Mode{"auto": ModeAuto, " Guided ": ModeGuided, "null": "", "fast": ""}
+	for in, want := range tests {
+		if got := ParseMode(in); got != want {
+			t.Errorf("ParseMode(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestAssessWithoutRulesUsesMetadata(t *testing.T) {
+	out := toolOutput(t, `{
+  "tool": "forge-dust",
+  "categories": [
+    {"id": "caches", "name": "Caches", "total_size": 100,
+     "metadata": {"typical_risk": "low", "reversible": true},
+     "items": [{"path": "/home/u/.cache/pip", "size": 100}]},
+    {"id": "logs", "name": "Logs", "total_size": 100,
+     "metadata": {"typical_risk": "low", "reversible": false},
+     "items": [{"path": "/home/u/app.log", "size": 100}]},
+    {"id": "downloads", "name": "Downloads", "total_size": 100,
+     "metadata": {"typical_risk": "medium", "reversible": false},
+     "items": [{"path": "/home/u/Downloads/a.zip", "size": 100}]},
+    {"id": "documents", "name": "Documents", "total_size": 100,
+     "metadata": {"typical_risk": "high", "reversible": false},
+     "items": [{"path": "/home/u/Documents/thesis.pdf", "size": 100}]}
+  ]
+}`)
+
+	want := map[string]Mode{
+		"Caches":    ModeSuggest,
+		"Logs":      ModeGuided,
+		"Downloads": ModeGuided,
+		"Documents": ModeGuided,
+	}
+	for _, rs := range []*rules.RuleSet{nil, {}} {
+		a, err := NewAssessor(rs, nil).Assess(out, nil)
+		if err != nil {
+			t.Fatalf("Assess() error = %v", err)
+		}
+		for _, cat := range a.Categories {
+			if cat.Mode != want[cat.Category] {
+				t.Errorf("Assess() without rules: %s mode = %s, want %s", cat.Category, cat.Mode, want[cat.Category])
+			}
+			if cat.Confidence != "medium" {
+				t.Errorf("Assess() without rules: %s confidence = %s, want medium", cat.Category, cat.Confidence)
+			}
+		}
+		if a.OverallMode != ModeGuided {
+			t.Errorf("Assess() without rules: overall mode = %s, want guided", a.OverallMode)
+		}
+
+		// Reversible, low-risk categories still go automatic with --quick
+		quick, _ := NewAssessor(rs, nil).Assess(out, []string{"--quick"})
+		if got := quick.Categories[0].Mode; got != ModeAuto {
+			t.Errorf("Assess(--quick) without rules: Caches mode = %s, want auto", got)
+		}
+	}
+}
+
+func TestOpeningMatchesMessage(t *testing.T) {
+	a, err := NewAssessor(&rules.RuleSet{}, nil).Assess(toolOutput(t, mixedOutput), nil)
+	if err != nil {
+		t.Fatalf("Assess() error = %v", err)
+	}
+	o := a.Opening
+	if o.Mode != a.OverallMode || o.Reclaimable != 14000 || o.Categories != 2 {
+		t.Errorf("Opening = %+v, want mode %s, 14000 bytes, 2 categories", o, a.OverallMode)
+	}
+	if len(o.TopCategories) != 2 || o.TopCategories[0].Name != "Large Files" || o.TopCategories[0].Size != 9000 {
+		t.Errorf("TopCategories = %+v, want Large Files first", o.TopCategories)
+	}
+	if !strings.Contains(a.OpeningMessage, "Found 2 ") {
+		t.Errorf("OpeningMessage = %q, want it to count the %d categories", a.OpeningMessage, o.Categories)
+	}
+
+	// A suggest opening names the total instead
+	o.Mode = ModeSuggest
+	if msg := o.Message(); !strings.Contains(msg, formatBytes(o.Reclaimable)) {
+		t.Errorf("Message() = %q, want the %s total", msg, formatBytes(o.Reclaimable))
+	}
+}
+
+func TestAssessmentPromptFitsBudget(t *testing.T) {
+	var cats []string
+	for n := range 1000 {
+		cats = append(cats, fmt.Sprintf(`{"id": "c%d", "name": "Category %d", "total_size": %d,
+     "metadata": {"typical_risk": "low", "reversible": true}}`, n, n, n*1000))
+	}
+	out := toolOutput(t, `{"tool": "forge-dust", "categories": [`+strings.Join(cats, ",")+`]}`)
+
+	prompt := buildAssessmentPrompt(out, &SessionAssessment{OverallMode: ModeSuggest}, 3000)
+	if len(prompt) > 3000 {
+		t.Errorf("buildAssessmentPrompt() is %d characters, want at most 3000", len(prompt))
+	}
+	if !strings.Contains(prompt, "Category 999 ") || strings.Contains(prompt, "Category 1 ") {
+		t.Errorf("buildAssessmentPrompt() should keep the largest categories:\n%s", prompt)
+	}
+	if !strings.Contains(prompt, "largest of 1000 categories") {
+		t.Errorf("buildAssessmentPrompt() doesn't say it was sampled:\n%s", prompt)
+	}
+}
+
+func TestAssessWithLLMExplainsEachCategory(t *testing.T) {
+	t.Setenv("HOME", t.TempDir()) // No custom prompt templates
+
+	// Categories 0-5 are high risk, so each is discussed; 6 is handled alone
+	var cats []string
+	for n := range 7 {
+		risk := "high"
+		if n == 6 {
+			risk = "low"
+		}
+		cats = append(cats, fmt.Sprintf(`{"id": "c%d", "name": "Category %d", "total_size": %d,
+     "metadata": {"typical_risk": %q, "reversible": false},
+     "items": [{"path": "/data/f%d", "size": %d, "type": "large_file"}]}`, n, n, 1000+n, risk, n, 1000+n))
+	}
+	out := toolOutput(t, `{"tool": "forge-dust", "categories": [`+strings.Join(cats, ",")+`]}`)
+
+	// Explanations wait for release, and then later categories answer
+	// sooner, so calls finish out of order; category 3's fails
+	category := regexp.MustCompile(`"Category (\d+)"`)
+	var release chan struct{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct{ Prompt string }
+		json.NewDecoder(r.Body).Decode(&req)
+		response := "Welcome to the forge."
+		if m := category.FindStringSubmatch(req.Prompt); m != nil {
+			<-release
+			n, _ := strconv.Atoi(m[1])
+			if n == 3 {
+				http.Error(w, `{"error":"boom"}`, http.StatusInternalServerError)
+				return
+			}
+			time.Sleep(time.Duration(6-n) * 5 * time.Millisecond)
+			response = "About category " + m[1] + ".\n"
+		}
+		json.NewEncoder(w).Encode(map[string]any{"response": response, "done": true})
+	}))
+	defer server.Close()
+
+	client := llm.NewClient("qwen3:8b")
+	client.BaseURL = server.URL
+	client.Concurrency = 3
+
+	var first []string
+	for run := range 3 {
+		release = make(chan struct{})
+		a, err := NewAssessor(nil, client).AssessWithLLM(context.Background(), out, nil)
+		if err != nil {
+			t.Fatalf("AssessWithLLM() error = %v", err)
+		}
+		if a.OpeningMessage != "Welcome to the forge." {
+			t.Errorf("OpeningMessage = %q, want the LLM's", a.OpeningMessage)
+		}
+		// Returned without waiting for the explanations
+		if text, ok := a.Explanations.Lookup("Category 0"); ok {
+			t.Errorf("run %d: Category 0 explained as %q before the LLM answered", run, text)
+		}
+		close(release)
+		a.Explanations.Wait()
+
+		var got []string
+		for _, cat := range a.Categories {
+			n := strings.TrimPrefix(cat.Category, "Category ")
+			want := "About category " + n + "."
+			if cat.Mode == ModeAuto || cat.Mode == ModeSuggest || n == "3" {
+				want = ""
+			}
+			text, _ := a.Explanations.Lookup(cat.Category)
+			if text != want {
+				t.Errorf("run %d: %s explanation = %q, want %q", run, cat.Category, text, want)
+			}
+			got = append(got, cat.Category+": "+text)
+		}
+		if first == nil {
+			first = got
+		} else if strings.Join(got, "\n") != strings.Join(first, "\n") {
+			t.Errorf("run %d gave %q, want the same as run 0: %q", run, got, first)
+		}
+	}
+}
+
+func TestHiddenCategoryIsCountedNotPresented(t *testing.T) {
+	rs := &rules.RuleSet{}
+	rs.Hide("LARGE_FILES")
+	a, err := NewAssessor(rs, nil).Assess(toolOutput(t, mixedOutput), nil)
+	if err != nil {
+		t.Fatalf("Assess() error = %v", err)
+	}
+
+	for _, cat := range a.Categories {
+		if cat.Category == "Large Files" {
+			t.Errorf("Categories include hidden Large Files")
+		}
+	}
+	for _, top := range a.Opening.TopCategories {
+		if top.Name == "Large Files" {
+			t.Errorf("Opening names hidden Large Files")
+		}
+	}
+	if len(a.Hidden) != 1 || a.Hidden[0] != "Large Files" {
+		t.Errorf("Hidden = %v, want [Large Files]", a.Hidden)
+	}
+	if a.TotalReclaimable != 14000 {
+		t.Errorf("TotalReclaimable = %d, want 14000 (hidden categories still count)", a.TotalReclaimable)
+	}
+
+	if !rs.Unhide("large_files") {
+		t.Fatal("Unhide(large_files) = false, want true")
+	}
+	a, _ = NewAssessor(rs, nil).Assess(toolOutput(t, mixedOutput), nil)
+	if len(a.Categories) != 2 || len(a.Hidden) != 0 {
+		t.Errorf("after Unhide: %d categories, hidden %v; want 2 and none", len(a.Categories), a.Hidden)
+	}
+}
+
+func TestTrashIsLeftToEmptyTrash(t *testing.T) {
+	out := toolOutput(t, `{
+  "tool": "forge-dust",
+  "categories": [
+    {"id": "cache_directories", "name": "Cache Directories", "total_size": 5000,
+     "metadata": {"typical_risk": "low", "reversible": true},
+     "items": [{"path": "/home/u/app/node_modules", "size": 5000, "type": "node_modules"}]},
+    {"id": "trash", "name": "Trash", "total_size": 9000,
+     "metadata": {"typical_risk": "low", "reversible": false, "safe_action": "empty_trash"},
+     "items": [{"path": "/home/u/.Trash", "size": 9000, "type": "trash"}]}
+  ]
+}`)
+	a, err := NewAssessor(&rules.RuleSet{}, nil).Assess(out, nil)
+	if err != nil {
+		t.Fatalf("Assess() error = %v", err)
+	}
+
+	if len(a.Categories) != 1 || a.Categories[0].Category != "Cache Directories" {
+		t.Errorf("Categories = %v, want only Cache Directories: the Trash folder is never a finding", a.Categories)
+	}
+	if a.Trash != 9000 || a.TotalReclaimable != 14000 {
+		t.Errorf("Trash = %d, TotalReclaimable = %d; want 9000 and 14000", a.Trash, a.TotalReclaimable)
+	}
+	if len(a.Kept) != 1 || a.Kept[0].Path != "/home/u/.Trash" || !strings.Contains(a.Kept[0].Reason, "--empty-trash") {
+		t.Errorf("Kept = %+v, want the Trash, pointing to --empty-trash", a.Kept)
+	}
+}
+
+func TestKeptReasonNamesGoverningRule(t *testing.T) {
+	out := toolOutput(t, `{
+  "tool": "forge-dust",
+  "categories": [
+    {"id": "cache_directories", "name": "Cache Directories", "total_size": 5000,
+     "metadata": {"typical_risk": "low", "reversible": true},
+     "items": [{"path": "/home/u/proj/node_modules", "size": 5000, "type": "node_modules"}]},
+    {"id": "downloads", "name": "Downloads", "total_size": 7000,
+     "metadata": {"typical_risk": "medium", "reversible": true},
+     "items": [{"path": "/home/u/Downloads/talk.mov", "size": 4000, "type": "download"},
+               {"path": "/home/u/Downloads/setup.dmg", "size": 3000, "type": "download"}]},
+    {"id": "old_files", "name": "Old Files", "total_size": 6000,
+     "metadata": {"typical_risk": "medium", "reversible": true},
+     "items": [{"path": "/home/u/Documents/draft.doc", "size": 6000, "type": "old_file"}]},
+    {"id": "large_files", "name": "Large Files", "total_size": 9000,
+     "metadata": {"typical_risk": "high", "reversible": false},
+     "items": [{"path": "/home/u/Movies/trip.mov", "size": 9000, "type": "large_file"}]}
+  ]
+}`)
+	rs := &rules.RuleSet{}
+	rs.Preferences.NeverDelete = []rules.Preference{
+		{Pattern: "*.key"},
+		{Pattern: "*.mov", Location: "/home/u/Downloads"},
+	}
+	rs.Hide("old_files")
+	assessor := NewAssessor(rs, nil)
+	assessor.Safe = true
+	a, err := assessor.Assess(out, nil)
+	if err != nil {
+		t.Fatalf("Assess() error = %v", err)
+	}
+
+	want := []Kept{
+		{"/home/u/Movies/trip.mov", 9000, "Large Files", "not reversible, and safe mode offers only what rebuilds itself"},
+		{"/home/u/Documents/draft.doc", 6000, "Old Files", "category hidden ('forge unhide Old Files' to show)"},
+		{"/home/u/Downloads/talk.mov", 4000, "Downloads", `never_delete preference "*.mov" in /home/u/Downloads`},
+	}
+	if len(a.Kept) != len(want) {
+		t.Fatalf("Kept = %+v, want %+v", a.Kept, want)
+	}
+	for i := range want {
+		if a.Kept[i] != want[i] {
+			t.Errorf("Kept[%d] = %+v, want %+v", i, a.Kept[i], want[i])
+		}
+	}
+
+	// Kept out of what's offered, and out of the totals
+	for _, cat := range a.Categories {
+		for _, f := range cat.Findings {
+			if f.Path == "/home/u/Downloads/talk.mov" {
+				t.Errorf("%s offers the protected %s", cat.Category, f.Path)
+			}
+		}
+		if cat.Category == "Downloads" && cat.TotalSize != 3000 {
+			t.Errorf("Downloads TotalSize = %d, want 3000 without the protected file", cat.TotalSize)
+		}
+	}
+	if a.TotalReclaimable != 5000+3000+6000 {
+		t.Errorf("TotalReclaimable = %d, want %d", a.TotalReclaimable, 5000+3000+6000)
+	}
+
+	assessor = NewAssessor(&rules.RuleSet{}, nil)
+	assessor.MaxRisk = rules.LevelLow
+	if a, _ = assessor.Assess(out, nil); len(a.Kept) == 0 || a.Kept[0].Reason != "high risk, above the low allowed" {
+		t.Errorf("with MaxRisk low, Kept = %+v, want trip.mov first for its high risk", a.Kept)
+	}
+	if report := KeptReport(a); !strings.Contains(report, "/home/u/Movies/trip.mov") || !strings.Contains(report, "high risk, above the low allowed") {
+		t.Errorf("KeptReport() missing trip.mov and its reason:\n%s", report)
+	}
+}
@@ -0,0 +1,100 @@
+package assessment
+
+import (
+	"fmt"
+	"strings"
+
+	"forge/rules"
+)
+
+// ExplainModes renders how each category's mode was chosen and how the
+// category modes were combined into the session mode
+func ExplainModes(a *SessionAssessment) string {
+	var sb strings.Builder
+
+	sb.WriteString("Why these modes:\n")
+	for _, cat := range a.Categories {
+		sb.WriteString(fmt.Sprintf("\n  %s → %s\n", cat.Category, cat.Mode))
+		for _, step := range cat.ModeTrace {
+			sb.WriteString(fmt.Sprintf("    • %s\n", step))
+		}
+	}
+
+	if len(a.Flags) > 0 {
+		sb.WriteString(fmt.Sprintf("\n  Flags: %s\n", strings.Join(a.Flags, " ")))
+	}
+	sb.WriteString(fmt.Sprintf("\n  Session → %s: %s\n", a.OverallMode, a.ModeReason))
+
+	return sb.String()
+}
+
+// describeRule names where a merged rule's settings came from
+func describeRule(rule *rules.MergedRule) string {
+	switch {
+	case rule.Source == "preference":
+		return fmt.Sprintf("preference (%s)", rule.EffectiveAction)
+	case rule.CalibratedConf != "" || rule.CalibratedAct != "":
+		return fmt.Sprintf("calibrated rule %v", rule.Patterns)
+	default:
+		return fmt.Sprintf("base rule %v", rule.Patterns)
+	}
+}
+
+// describeNever names the never_delete preference keeping an item
+func describeNever(pref rules.Preference) string {
+	s := fmt.Sprintf("never_delete preference %q", pref.Pattern)
+	if pref.Location != "" {
+		s += " in " + pref.Location
+	}
+	return s
+}
+
+// keptShown is how many kept items KeptReport lists
+const keptShown = 10
+
+// KeptReport renders the largest items the session held back and the
+// reason for each, for --show-kept
+func KeptReport(a *SessionAssessment) string {
+	var sb strings.Builder
+
+	sb.WriteString("Kept, and why:\n\n")
+	if len(a.Kept) == 0 {
+		sb.WriteString("  Nothing was held back.\n")
+		return sb.String()
+	}
+
+	for i, k := range a.Kept {
+		if i == keptShown {
+			sb.WriteString(fmt.Sprintf("  ... and %d smaller\n", len(a.Kept)-keptShown))
+			break
+		}
+		what := k.Path
+		if what == "" {
+			what = k.Category
+		}
+		sb.WriteString(fmt.Sprintf("  %10s  %s\n", formatBytes(k.Size), what))
+		sb.WriteString(fmt.Sprintf("  %10s  %s\n", "", k.Reason))
+	}
+
+	return sb.String()
+}
+
+// Preview renders the assessment as a read-only summary, for --preview
+func Preview(a *SessionAssessment) string {
+	var sb strings.Builder
+
+	sb.WriteString("Preview (nothing will be changed):\n\n")
+	if len(a.Categories) == 0 {
+		sb.WriteString("  Nothing significant found.\n")
+		return sb.String()
+	}
+
+	for _, cat := range a.Categories {
+		sb.WriteString(fmt.Sprintf("  %-28s %10s  %-13s %s\n",
+			cat.Category, formatBytes(cat.TotalSize), cat.Mode, cat.Action))
+	}
+	sb.WriteString(fmt.Sprintf("\n  Session mode: %s\n", a.OverallMode))
+	sb.WriteString(fmt.Sprintf("  Reclaimable:  %s\n", formatBytes(a.TotalReclaimable)))
+
+	return sb.String()
+}
@@ -0,0 +1,75 @@
+package assessment
+
+import (
+	"sort"
+
+	"forge/rules"
+)
+
+// Selection is the set of findings chosen to free a target amount of space
+type Selection struct {
+	Findings  []Finding
+	Total     int64
+	Target    int64
+	Shortfall int64 // how far Total falls short of Target, 0 if met
+}
+
+// SelectForTarget picks findings to free at least target bytes from the
+// categories that are low risk and reversible, the ones that rebuild
+// themselves; the rest are never picked, and nor are never-delete findings
+// or caches in use. It takes the largest findings, but finishes with the
+// smallest one that covers what's left so it doesn't overshoot needlessly.
+func SelectForTarget(categories []CategoryAssessment, target int64) Selection {
+	sel := Selection{Target: target}
+
+	var items []Finding
+	for _, cat := range categories {
+		if cat.RebuildsItself() {
+			items = append(items, cat.Batchable()...)
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].Size > items[j].Size })
+
+	for i, f := range items {
+		remaining := target - sel.Total
+		if f.Size >= remaining {
+			// Sizes are descending, so the last item still covering the rest is the tightest fit
+			best := i
+			for j := i + 1; j < len(items) && items[j].Size >= remaining; j++ {
+				best = j
+			}
+			sel.Findings = append(sel.Findings, items[best])
+			sel.Total += items[best].Size
+			break
+		}
+		sel.Findings = append(sel.Findings, f)
+		sel.Total += f.Size
+	}
+
+	if sel.Total < target {
+		sel.Shortfall = target - sel.Total
+	}
+	return sel
+}
+
+// RebuildsItself reports whether the category is low risk and reversible,
+// the only kind picked for a target or cleaned without looking at it
+func (c CategoryAssessment) RebuildsItself() bool {
+	return c.Reversible && rules.ParseLevel(c.Risk) == rules.LevelLow
+}
+
+// Batchable returns the findings that may be deleted along with the rest
+// of the category, leaving out never-delete findings and caches in use
+func (c CategoryAssessment) Batchable() []Finding {
+	var findings []Finding
+	for _, f := range c.Findings {
+		if f.RuleApplied != nil && f.RuleApplied.EffectiveAction == "never_delete" {
+			continue
+		}
+		if f.Active {
+			continue // Would only be rebuilt straight away
+		}
+		findings = append(findings, f)
+	}
+	return findings
+}
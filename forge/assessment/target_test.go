@@ -0,0 +1,88 @@
+package assessment
+
+import (
+	"testing"
+
+	"forge/rules"
+)
+
+const gb = int64(1) << 30
+
+func category(name, risk string, reversible bool, sizes ...int64) CategoryAssessment {
+	cat := CategoryAssessment{Category: name, Risk: risk, Reversible: reversible}
+	for i, size := range sizes {
+		cat.Findings = append(cat.Findings, Finding{
+			Category: name,
+			Path:     "/" + name + "/" + string(rune('a'+i)),
+			Size:     size,
+		})
+		cat.TotalSize += size
+	}
+	return cat
+}
+
+func paths(findings []Finding) []string {
+	var out []string
+	for _, f := range findings {
+		out = append(out, f.Path)
+	}
+	return out
+}
+
+func TestSelectForTarget(t *testing.T) {
+	cats := []CategoryAssessment{
+		category("cache", "low", true, 5*gb, 4*gb, 1*gb),
+		category("global", "low", true, 3*gb),
+		category("downloads", "low", false, 20*gb),
+		category("large", "medium", false, 30*gb, 8*gb),
+		category("system", "high", true, 100*gb),
+	}
+
+	tests := []struct {
+		name      string
+		target    int64
+		want      []string
+		shortfall int64
+	}{
+		// 4GB alone covers it; no need to take the 5GB cache
+		{"tightest single fit", 4 * gb, []string{"/cache/b"}, 0},
+		// 5GB, then 3GB covers the remaining 2GB more tightly than 4GB
+		{"largest first, then tightest fit", 7 * gb, []string{"/cache/a", "/global/a"}, 0},
+		// Downloads, large files and system caches are never used, even when the target can't be met
+		{"shortfall", 20 * gb, []string{"/cache/a", "/cache/b", "/global/a", "/cache/c"}, 7 * gb},
+	}
+
+	for _, tt := range tests {
+		sel := SelectForTarget(cats, tt.target)
+		got := paths(sel.Findings)
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: picked %v, want %v", tt.name, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s: picked %v, want %v", tt.name, got, tt.want)
+				break
+			}
+		}
+		if sel.Shortfall != tt.shortfall {
+			t.Errorf("%s: Shortfall = %d, want %d", tt.name, sel.Shortfall, tt.shortfall)
+		}
+		if sel.Shortfall == 0 && sel.Total < tt.target {
+			t.Errorf("%s: Total = %d, below target %d with no shortfall", tt.name, sel.Total, tt.target)
+		}
+	}
+}
+
+func TestSelectForTargetSkipsNeverDelete(t *testing.T) {
+	cat := category("cache", "low", true, 6*gb, 2*gb)
+	cat.Findings[0].RuleApplied = &rules.MergedRule{EffectiveAction: "never_delete"}
+
+	sel := SelectForTarget([]CategoryAssessment{cat}, 5*gb)
+	if got := paths(sel.Findings); len(got) != 1 || got[0] != "/cache/b" {
+		t.Errorf("picked %v, want only /cache/b", got)
+	}
+	if sel.Shortfall != 3*gb {
+		t.Errorf("Shortfall = %d, want %d", sel.Shortfall, 3*gb)
+	}
+}
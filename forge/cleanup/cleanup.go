@@ -0,0 +1,348 @@
+// Package cleanup deletes accepted findings, journaling each one as it goes
+// so a cleanup that's interrupted, or retried after a crash, continues where
+// it stopped and still knows what was already freed.
+package cleanup
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"forge/assessment"
+	"forge/rules"
+)
+
+// Dir is where the journals of unfinished cleanups are kept
+func Dir() string {
+	return filepath.Join(rules.ForgeDir(), "cleanup")
+}
+
+// Entry is one finished deletion in a journal, or the undoing of one
+type Entry struct {
+	Path   string    `json:"path"`
+	Freed  int64     `json:"freed"`
+	At     time.Time `json:"at"`
+	Undone bool      `json:"undone,omitempty"`
+}
+
+// Journal records finished deletions, one JSON line each, synced to disk
+// before the next one starts
+type Journal struct {
+	path string
+	file *os.File
+	done map[string]Entry
+}
+
+// OpenJournal opens the journal named for a cleanup, usually its session's
+// ID, reading back whatever an earlier attempt finished
+func OpenJournal(name string) (*Journal, error) {
+	if err := os.MkdirAll(Dir(), 0755); err != nil {
+		return nil, err
+	}
+	j := &Journal{path: filepath.Join(Dir(), name+".jsonl"), done: make(map[string]Entry)}
+
+	data, err := os.ReadFile(j.path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		var e Entry
+		// A crash mid-write leaves a torn last line; that deletion is retried
+		if json.Unmarshal(line, &e) != nil || e.Path == "" {
+			continue
+		}
+		if e.Undone {
+			delete(j.done, e.Path)
+		} else {
+			j.done[e.Path] = e
+		}
+	}
+
+	f, err := os.OpenFile(j.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	// Start past the torn line, so the next entry isn't glued onto it
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		if _, err := f.Write([]byte("\n")); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	j.file = f
+	return j, nil
+}
+
+// Done returns the entry for path if an earlier attempt already deleted it.
+// A nil journal has nothing done.
+func (j *Journal) Done(path string) (Entry, bool) {
+	if j == nil {
+		return Entry{}, false
+	}
+	e, ok := j.done[path]
+	return e, ok
+}
+
+// Mark records path as deleted, freeing freed bytes. A nil journal keeps
+// no record.
+func (j *Journal) Mark(path string, freed int64) error {
+	if j == nil {
+		return nil
+	}
+	e := Entry{Path: path, Freed: freed, At: time.Now()}
+	if err := j.write(e); err != nil {
+		return err
+	}
+	j.done[path] = e
+	return nil
+}
+
+// Unmark records that path's deletion was undone, so it's deleted afresh
+// if asked again. A nil journal keeps no record.
+func (j *Journal) Unmark(path string) error {
+	if j == nil {
+		return nil
+	}
+	if err := j.write(Entry{Path: path, At: time.Now(), Undone: true}); err != nil {
+		return err
+	}
+	delete(j.done, path)
+	return nil
+}
+
+// write appends e to the journal and syncs it to disk
+func (j *Journal) write(e Entry) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if _, err := j.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return j.file.Sync()
+}
+
+// Close closes the journal, keeping it for a later attempt to resume from
+func (j *Journal) Close() error {
+	if j == nil {
+		return nil
+	}
+	return j.file.Close()
+}
+
+// Finish closes and removes the journal, once every deletion is done
+func (j *Journal) Finish() error {
+	if j == nil {
+		return nil
+	}
+	if err := j.file.Close(); err != nil {
+		return err
+	}
+	if err := os.Remove(j.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// staleAfter is how long a journal goes unwritten before Recover takes its
+// session to have stopped, rather than to be still running alongside
+const staleAfter = 24 * time.Hour
+
+// Interrupted is a cleanup an earlier session left unfinished: it crashed,
+// or was killed, partway through deleting
+type Interrupted struct {
+	Name  string // The journal's name, usually its session's ID
+	Items int    // Deleted before it stopped
+	Freed int64
+}
+
+// Recover reads back the journals left by sessions that stopped partway,
+// other than current's, and removes them: what they deleted is gone either
+// way, so they're reported once and forgotten. Their quarantines, which
+// nothing can undo from any more, are emptied.
+func Recover(current string) ([]Interrupted, error) {
+	if err := emptyStale(current); err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(Dir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var found []Interrupted
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".jsonl")
+		info, err := entry.Info()
+		if !ok || name == current || err != nil || time.Since(info.ModTime()) < staleAfter {
+			continue
+		}
+		j, err := OpenJournal(name)
+		if err != nil {
+			return found, err
+		}
+		in := Interrupted{Name: name, Items: len(j.done)}
+		for _, e := range j.done {
+			in.Freed += e.Freed
+		}
+		if err := j.Finish(); err != nil {
+			return found, err
+		}
+		found = append(found, in)
+	}
+	return found, nil
+}
+
+// Result is what became of one finding
+type Result struct {
+	Path    string
+	Freed   int64
+	Resumed bool  // An earlier attempt had already deleted it
+	Fixed   bool  // Its permissions were repaired to delete it
+	Err     error // Set if it couldn't be deleted; it's retried next time
+}
+
+// ProtectedError refuses to delete a path a never_delete preference covers
+type ProtectedError struct {
+	Path    string // the protected path: the finding, or something inside it
+	Pattern string // the preference's pattern
+}
+
+func (e *ProtectedError) Error() string {
+	return fmt.Sprintf("%s is protected by never_delete %q", e.Path, e.Pattern)
+}
+
+// Delete removes each finding with remove (removeExisting if nil), skipping
+// those the journal says are done and marking each as it finishes. Right
+// before each removal it checks rs's never_delete preferences, whatever
+// the assessment said, and refuses any finding they cover. A finding of the
+// user's own refused for lack of permission is offered to fix, if fix is
+// set, and on its say-so repaired and removed again. It stops at the first
+// journal error, since carrying on would lose track of what was deleted.
+func Delete(findings []assessment.Finding, j *Journal, rs *rules.RuleSet, remove func(string) error, fix func(path string) bool) ([]Result, error) {
+	if remove == nil {
+		remove = removeExisting
+	}
+
+	var results []Result
+	for _, f := range findings {
+		if e, ok := j.Done(f.Path); ok {
+			results = append(results, Result{Path: f.Path, Freed: e.Freed, Resumed: true})
+			continue
+		}
+		if err := protect(rs, f.Path); err != nil {
+			results = append(results, Result{Path: f.Path, Err: err})
+			continue
+		}
+
+		var fixed bool
+		err := remove(f.Path)
+		if err != nil && fix != nil && canFix(f.Path, err, ownedByUser) && fix(f.Path) {
+			if err = repairPermissions(f.Path, ownedByUser); err == nil {
+				fixed = true
+				err = remove(f.Path)
+			}
+		}
+		if err != nil {
+			results = append(results, Result{Path: f.Path, Fixed: fixed, Err: err})
+			continue
+		}
+		results = append(results, Result{Path: f.Path, Freed: f.Size, Fixed: fixed})
+		if err := j.Mark(f.Path, f.Size); err != nil {
+			return results, err
+		}
+	}
+	return results, nil
+}
+
+// removeExisting removes path and everything under it. A path that's
+// already gone is an error, not a deletion: it frees nothing.
+func removeExisting(path string) error {
+	if _, err := os.Lstat(path); err != nil {
+		return err
+	}
+	return os.RemoveAll(path)
+}
+
+// protect returns a ProtectedError if a never_delete preference in rs
+// covers path, a directory it's in or, for a directory, anything under it
+func protect(rs *rules.RuleSet, path string) error {
+	if rs == nil || len(rs.Preferences.NeverDelete) == 0 {
+		return nil
+	}
+	if pref, ok := rs.Protected(path); ok {
+		return &ProtectedError{Path: path, Pattern: pref.Pattern}
+	}
+	var protected error
+	filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || p == path {
+			return nil
+		}
+		if pref, ok := rs.NeverDeleteFor(p); ok {
+			protected = &ProtectedError{Path: p, Pattern: pref.Pattern}
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	return protected
+}
+
+// canFix reports whether removing path failed only for want of permissions
+// the user can grant: refused with EACCES or EPERM on something under path
+// that the user owns, in a directory the user owns too
+func canFix(path string, err error, owned func(string) bool) bool {
+	if !errors.Is(err, fs.ErrPermission) {
+		return false
+	}
+	failed := path
+	var pe *fs.PathError
+	if errors.As(err, &pe) && pe.Path != "" {
+		failed = pe.Path
+	}
+	if rel, err := filepath.Rel(path, failed); err != nil || !filepath.IsLocal(rel) {
+		return false
+	}
+	return owned(failed) && owned(filepath.Dir(failed))
+}
+
+// repairPermissions gives the user what removing path needs: write
+// permission on its directory, and full access to every directory of theirs
+// under path, as read-only files go once their directory is writable. A
+// directory that owned says isn't the user's is left alone, so one shared
+// with others is never opened up.
+func repairPermissions(path string, owned func(string) bool) error {
+	parent := filepath.Dir(path)
+	if !owned(parent) {
+		return &fs.PathError{Op: "chmod", Path: parent, Err: fs.ErrPermission}
+	}
+	if err := addUserPerms(parent, 0200); err != nil {
+		return err
+	}
+	return filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		// Called before a directory is read, so a locked one is opened up in time
+		if d == nil || !d.IsDir() || !owned(p) {
+			return nil
+		}
+		return addUserPerms(p, 0700)
+	})
+}
+
+// addUserPerms adds perm, the owner's bits of a mode, to dir's
+func addUserPerms(dir string, perm fs.FileMode) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+	if info.Mode().Perm()&perm == perm {
+		return nil
+	}
+	return os.Chmod(dir, info.Mode().Perm()|perm)
+}
@@ -0,0 +1,313 @@
+package cleanup
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"forge/assessment"
+	"forge/rules"
+)
+
+func TestResumeSkipsDeleted(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	findings := []assessment.Finding{
+		{Path: "/cache/a", Size: 100},
+		{Path: "/cache/b", Size: 200},
+		{Path: "/cache/c", Size: 300},
+	}
+
+	// The first attempt is cut short at c
+	j, err := OpenJournal("sess_1")
+	if err != nil {
+		t.Fatalf("OpenJournal() error = %v", err)
+	}
+	interrupted := errors.New("interrupted")
+	if _, err := Delete(findings, j, nil, func(path string) error {
+		if path == "/cache/c" {
+			return interrupted
+		}
+		return nil
+	}, nil); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	j.Close()
+
+	j, err = OpenJournal("sess_1")
+	if err != nil {
+		t.Fatalf("OpenJournal() again error = %v", err)
+	}
+	var removed []string
+	results, err := Delete(findings, j, nil, func(path string) error {
+		removed = append(removed, path)
+		return nil
+	}, nil)
+	if err != nil {
+		t.Fatalf("Delete() resumed error = %v", err)
+	}
+	if want := []string{"/cache/c"}; !reflect.DeepEqual(removed, want) {
+		t.Errorf("resumed Delete() removed %q, want only %q", removed, want)
+	}
+
+	var freed int64
+	for i, r := range results {
+		freed += r.Freed
+		if wantResumed := i < 2; r.Resumed != wantResumed || r.Err != nil {
+			t.Errorf("result %s = %+v, want Resumed %v and no error", r.Path, r, wantResumed)
+		}
+	}
+	if freed != 600 {
+		t.Errorf("freed %d bytes across both attempts, want 600", freed)
+	}
+
+	if err := j.Finish(); err != nil {
+		t.Fatalf("Finish() error = %v", err)
+	}
+	if _, err := os.Stat(j.path); !os.IsNotExist(err) {
+		t.Errorf("Finish() left the journal behind (stat error = %v)", err)
+	}
+}
+
+func TestTornJournalLineIsRetried(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	j, err := OpenJournal("sess_2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := j.Mark("/cache/a", 100); err != nil {
+		t.Fatal(err)
+	}
+	j.file.WriteString(`{"path":"/cache/b","fr`)
+	j.Close()
+
+	j, err = OpenJournal("sess_2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := j.Done("/cache/a"); !ok {
+		t.Error("Done(/cache/a) = false, want true")
+	}
+	if _, ok := j.Done("/cache/b"); ok {
+		t.Error("Done(/cache/b) = true from a torn line, want false")
+	}
+
+	// The retry's entry survives the torn line before it
+	if err := j.Mark("/cache/b", 200); err != nil {
+		t.Fatal(err)
+	}
+	j.Close()
+	j, err = OpenJournal("sess_2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.Close()
+	if _, ok := j.Done("/cache/b"); !ok {
+		t.Error("Done(/cache/b) after the retry = false, want true")
+	}
+}
+
+func TestRecoverReportsStoppedCleanups(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	for _, name := range []string{"sess_old", "sess_recent", "sess_now"} {
+		j, err := OpenJournal(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		j.Mark("/cache/a", 100)
+		j.Mark("/cache/b", 200)
+		j.Close()
+	}
+	old := time.Now().Add(-2 * staleAfter)
+	if err := os.Chtimes(filepath.Join(Dir(), "sess_old.jsonl"), old, old); err != nil {
+		t.Fatal(err)
+	}
+	os.Chtimes(filepath.Join(Dir(), "sess_now.jsonl"), old, old)
+
+	got, err := Recover("sess_now")
+	if err != nil {
+		t.Fatalf("Recover() error = %v", err)
+	}
+	// A recent journal may belong to a session still running
+	if want := []Interrupted{{Name: "sess_old", Items: 2, Freed: 300}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Recover() = %+v, want %+v", got, want)
+	}
+	for name, want := range map[string]bool{"sess_old": false, "sess_recent": true, "sess_now": true} {
+		if _, err := os.Stat(filepath.Join(Dir(), name+".jsonl")); (err == nil) != want {
+			t.Errorf("%s journal kept = %v, want %v", name, err == nil, want)
+		}
+	}
+}
+
+func TestCanFix(t *testing.T) {
+	denied := func(path string) error {
+		return &fs.PathError{Op: "unlinkat", Path: path, Err: fs.ErrPermission}
+	}
+	mine := func(string) bool { return true }
+	notMine := func(path string) bool { return path != "/cache/a/locked" }
+	tests := []struct {
+		name  string
+		err   error
+		owned func(string) bool
+		want  bool
+	}{
+		{"denied inside, all mine", denied("/cache/a/locked"), mine, true},
+		{"denied on the finding itself", denied("/cache/a"), mine, true},
+		{"denied without a path", fmt.Errorf("remove: %w", fs.ErrPermission), mine, true},
+		{"denied on someone else's", denied("/cache/a/locked"), notMine, false},
+		{"denied outside the finding", denied("/etc/hosts"), mine, false},
+		{"not a permission error", &fs.PathError{Op: "unlinkat", Path: "/cache/a", Err: fs.ErrNotExist}, mine, false},
+		{"plain error", errors.New("busy"), mine, false},
+	}
+	for _, tt := range tests {
+		if got := canFix("/cache/a", tt.err, tt.owned); got != tt.want {
+			t.Errorf("%s: canFix(%v) = %v, want %v", tt.name, tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestDeleteRetriesAfterFixingPermissions(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := filepath.Join(t.TempDir(), "cache")
+	locked := filepath.Join(dir, "locked")
+	if err := os.MkdirAll(locked, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(locked, 0500); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chmod(locked, 0755) })
+	findings := []assessment.Finding{{Path: dir, Size: 100}}
+
+	// Refused until the locked directory is writable again, as it is for
+	// anyone but root
+	remove := func(path string) error {
+		if info, err := os.Stat(locked); err == nil && info.Mode().Perm()&0200 == 0 {
+			return &fs.PathError{Op: "unlinkat", Path: locked, Err: fs.ErrPermission}
+		}
+		return os.RemoveAll(path)
+	}
+
+	for _, tt := range []struct {
+		name      string
+		allow     bool
+		wantFixed bool
+	}{
+		{"declined", false, false},
+		{"accepted", true, true},
+	} {
+		j, err := OpenJournal("sess_" + tt.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var asked []string
+		results, err := Delete(findings, j, nil, remove, func(path string) bool {
+			asked = append(asked, path)
+			return tt.allow
+		})
+		j.Close()
+		if err != nil {
+			t.Fatalf("%s: Delete() error = %v", tt.name, err)
+		}
+		if want := []string{dir}; !reflect.DeepEqual(asked, want) {
+			t.Errorf("%s: asked to fix %q, want %q", tt.name, asked, want)
+		}
+		r := results[0]
+		if r.Fixed != tt.wantFixed || (r.Err == nil) != tt.allow {
+			t.Errorf("%s: result = %+v, want Fixed %v and deleted %v", tt.name, r, tt.wantFixed, tt.allow)
+		}
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("%s still there after the fix (stat error = %v)", dir, err)
+	}
+}
+
+func TestRepairPermissionsOnlyOpensWhatIsTheUsers(t *testing.T) {
+	mine := func(string) bool { return true }
+	notMine := func(string) bool { return false }
+	tests := []struct {
+		name     string
+		owned    func(string) bool
+		wantMode fs.FileMode
+		wantErr  bool
+	}{
+		// Unlinking needs write permission on the directory and nothing more
+		{"the user's", mine, 0755, false},
+		{"someone else's", notMine, 0555, true},
+	}
+	for _, tt := range tests {
+		parent := filepath.Join(t.TempDir(), "shared")
+		path := filepath.Join(parent, "cache")
+		if err := os.MkdirAll(path, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chmod(parent, 0555); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { os.Chmod(parent, 0755) })
+
+		err := repairPermissions(path, tt.owned)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: repairPermissions() error = %v, want error %v", tt.name, err, tt.wantErr)
+		}
+		if info, err := os.Stat(parent); err != nil || info.Mode().Perm() != tt.wantMode {
+			t.Errorf("%s: directory mode = %v, want %v", tt.name, info.Mode().Perm(), tt.wantMode)
+		}
+	}
+}
+
+func TestDeleteRefusesProtectedPaths(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	root := t.TempDir()
+	for _, p := range []string{"dups/Photos/beach.jpg", "dups/copy/beach.jpg", "keys/id.key", "cache/blob"} {
+		path := filepath.Join(root, p)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("data"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rs := &rules.RuleSet{}
+	rs.Preferences.NeverDelete = []rules.Preference{{Pattern: "Photos"}, {Pattern: "*.key"}}
+
+	// Selected as duplicates, in a directory, or whole: the assessment
+	// would have let each through
+	findings := []assessment.Finding{
+		{Path: filepath.Join(root, "dups/Photos/beach.jpg"), Size: 4},
+		{Path: filepath.Join(root, "keys"), Size: 4},
+		{Path: filepath.Join(root, "dups/copy/beach.jpg"), Size: 4},
+		{Path: filepath.Join(root, "cache"), Size: 4},
+	}
+	j, err := OpenJournal("sess_protected")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.Close()
+	results, err := Delete(findings, j, rs, nil, nil)
+	if err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	wantProtected := map[string]string{
+		findings[0].Path: "Photos",
+		findings[1].Path: "*.key",
+	}
+	for _, r := range results {
+		var pe *ProtectedError
+		pattern, protected := wantProtected[r.Path]
+		if got := errors.As(r.Err, &pe); got != protected || (got && pe.Pattern != pattern) {
+			t.Errorf("Delete(%s) error = %v, want protected %v by %q", r.Path, r.Err, protected, pattern)
+		}
+		if _, err := os.Stat(r.Path); os.IsNotExist(err) == protected {
+			t.Errorf("after Delete, %s exists = %v, want %v", r.Path, !os.IsNotExist(err), protected)
+		}
+		if _, ok := j.Done(r.Path); ok == protected {
+			t.Errorf("journal Done(%s) = %v, want %v", r.Path, ok, !protected)
+		}
+	}
+}
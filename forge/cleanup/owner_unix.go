@@ -0,0 +1,18 @@
+//go:build unix
+
+package cleanup
+
+import (
+	"os"
+	"syscall"
+)
+
+// ownedByUser reports whether path belongs to the user running forge
+func ownedByUser(path string) bool {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return false
+	}
+	st, ok := info.Sys().(*syscall.Stat_t)
+	return ok && int(st.Uid) == os.Getuid()
+}
@@ -0,0 +1,131 @@
+package cleanup
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+
+	"forge/rules"
+)
+
+// QuarantineDir is where each session's deleted paths are held until it ends
+func QuarantineDir() string {
+	return filepath.Join(rules.ForgeDir(), "quarantine")
+}
+
+// Quarantine holds what a session deletes until the session is over, so a
+// deletion can still be undone. Paths are moved in with a rename, which
+// only works on the forge directory's own volume: anything elsewhere is
+// removed outright, and can't be brought back.
+type Quarantine struct {
+	dir  string
+	held map[string]string // Original path to where it's held
+	n    int
+}
+
+// OpenQuarantine makes the quarantine named for a session, usually its ID
+func OpenQuarantine(name string) (*Quarantine, error) {
+	dir := filepath.Join(QuarantineDir(), name)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &Quarantine{dir: dir, held: make(map[string]string)}, nil
+}
+
+// Remove moves path into the quarantine, or removes it outright if it's on
+// another volume. Like removeExisting, a path that's already gone is an
+// error. A nil quarantine removes everything outright.
+func (q *Quarantine) Remove(path string) error {
+	if q == nil {
+		return removeExisting(path)
+	}
+	if _, err := os.Lstat(path); err != nil {
+		return err
+	}
+	q.n++
+	held := filepath.Join(q.dir, strconv.Itoa(q.n)+"-"+filepath.Base(path))
+	err := os.Rename(path, held)
+	if errors.Is(err, syscall.EXDEV) {
+		return os.RemoveAll(path)
+	}
+	if err != nil {
+		return err
+	}
+	q.held[path] = held
+	return nil
+}
+
+// Holds reports whether path is in the quarantine, to be restored
+func (q *Quarantine) Holds(path string) bool {
+	if q == nil {
+		return false
+	}
+	_, ok := q.held[path]
+	return ok
+}
+
+// Restore moves path back where it was deleted from. It won't overwrite
+// anything that has taken its place since.
+func (q *Quarantine) Restore(path string) error {
+	if !q.Holds(path) {
+		return fmt.Errorf("%s was removed outright", path)
+	}
+	held := q.held[path]
+	if _, err := os.Lstat(path); err == nil {
+		return &fs.PathError{Op: "restore", Path: path, Err: fs.ErrExist}
+	}
+	if err := os.Rename(held, path); err != nil {
+		return err
+	}
+	delete(q.held, path)
+	return nil
+}
+
+// Empty removes everything in the quarantine for good, and the quarantine
+// with it. A nil quarantine has nothing to empty.
+func (q *Quarantine) Empty() error {
+	if q == nil {
+		return nil
+	}
+	q.held = make(map[string]string)
+	return removeHeld(q.dir)
+}
+
+// removeHeld removes a quarantine directory. What's in it was the user's to
+// delete when it went in, so locked directories inside are opened up.
+func removeHeld(dir string) error {
+	if err := os.RemoveAll(dir); err == nil || !errors.Is(err, fs.ErrPermission) {
+		return err
+	}
+	if err := repairPermissions(dir, ownedByUser); err != nil {
+		return err
+	}
+	return os.RemoveAll(dir)
+}
+
+// emptyStale removes the quarantines of sessions other than current's that
+// have gone untouched for staleAfter: they stopped without emptying them
+func emptyStale(current string) error {
+	entries, err := os.ReadDir(QuarantineDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if entry.Name() == current || err != nil || time.Since(info.ModTime()) < staleAfter {
+			continue
+		}
+		if err := removeHeld(filepath.Join(QuarantineDir(), entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
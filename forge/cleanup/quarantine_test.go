@@ -0,0 +1,110 @@
+package cleanup
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestQuarantineRestores(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	q, err := OpenQuarantine("sess_q")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	kept, taken := filepath.Join(dir, "kept"), filepath.Join(dir, "taken")
+	for _, p := range []string{kept, taken} {
+		if err := os.WriteFile(p, []byte(p), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := q.Remove(p); err != nil {
+			t.Fatalf("Remove(%s) error = %v", p, err)
+		}
+		if _, err := os.Lstat(p); !os.IsNotExist(err) {
+			t.Errorf("%s still in place after Remove", p)
+		}
+	}
+	if err := q.Remove(filepath.Join(dir, "missing")); !os.IsNotExist(err) {
+		t.Errorf("Remove(missing) error = %v, want not exist", err)
+	}
+
+	if err := q.Restore(kept); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	if data, err := os.ReadFile(kept); err != nil || string(data) != kept {
+		t.Errorf("restored %s = %q, %v", kept, data, err)
+	}
+	if q.Holds(kept) {
+		t.Errorf("still holds %s after restoring it", kept)
+	}
+
+	// Something new in its place isn't overwritten
+	os.WriteFile(taken, []byte("new"), 0644)
+	if err := q.Restore(taken); !errors.Is(err, fs.ErrExist) {
+		t.Errorf("Restore() over a new file error = %v, want exists", err)
+	}
+
+	if err := q.Empty(); err != nil {
+		t.Fatalf("Empty() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(QuarantineDir(), "sess_q")); !os.IsNotExist(err) {
+		t.Errorf("quarantine still there after Empty: %v", err)
+	}
+	if data, _ := os.ReadFile(taken); string(data) != "new" {
+		t.Errorf("%s = %q after Empty, want the new file", taken, data)
+	}
+}
+
+func TestUnmarkedPathIsDeletedAgain(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	j, err := OpenJournal("sess_undo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	j.Mark("/cache/a", 100)
+	j.Mark("/cache/b", 200)
+	if err := j.Unmark("/cache/a"); err != nil {
+		t.Fatalf("Unmark() error = %v", err)
+	}
+	j.Close()
+
+	j, err = OpenJournal("sess_undo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.Close()
+	if _, ok := j.Done("/cache/a"); ok {
+		t.Errorf("undone /cache/a read back as deleted")
+	}
+	if _, ok := j.Done("/cache/b"); !ok {
+		t.Errorf("/cache/b not read back as deleted")
+	}
+}
+
+func TestRecoverEmptiesStaleQuarantines(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	for _, name := range []string{"sess_old", "sess_recent", "sess_now"} {
+		if _, err := OpenQuarantine(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old := time.Now().Add(-2 * staleAfter)
+	for _, name := range []string{"sess_old", "sess_now"} {
+		if err := os.Chtimes(filepath.Join(QuarantineDir(), name), old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := Recover("sess_now"); err != nil {
+		t.Fatalf("Recover() error = %v", err)
+	}
+	for name, want := range map[string]bool{"sess_old": false, "sess_recent": true, "sess_now": true} {
+		if _, err := os.Stat(filepath.Join(QuarantineDir(), name)); (err == nil) != want {
+			t.Errorf("%s quarantine kept = %v, want %v", name, err == nil, want)
+		}
+	}
+}
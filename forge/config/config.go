@@ -0,0 +1,172 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"forge/llm"
+	"forge/rules"
+)
+
+// Config holds user settings from ~/.forge/config.yaml
+type Config struct {
+	Safe          bool                  `yaml:"safe"`          // always run as if --safe was passed
+	Quiet         bool                  `yaml:"quiet"`         // always run as if --quiet was passed
+	MaxAutoRisk   string                `yaml:"max_auto_risk"` // riskiest category forge may clean without asking
+	Model         string                `yaml:"model"`         // Ollama model; shorthand for a one-model llm.models
+	LLM           LLMConfig             `yaml:"llm"`
+	Messages      string                `yaml:"messages"`       // message pack: forge (default), plain, or one in ~/.forge/messages
+	RateEvery     int                   `yaml:"rate_every"`     // ask for a 1-5 rating every Nth session; 0 means 5, -1 never
+	CategoryModes map[string]string     `yaml:"category_modes"` // category id or name -> the mode it always gets
+	Tools         map[string]ToolConfig `yaml:"tools"`
+}
+
+// LLMConfig holds the Ollama settings
+type LLMConfig struct {
+	Models       []string `yaml:"models"`        // fallback chain: the first one installed is used
+	PromptBudget int      `yaml:"prompt_budget"` // characters a built-in prompt may take; 0 is the default, -1 no limit
+	Concurrency  int      `yaml:"concurrency"`   // LLM calls made at once while assessing; 0 is the default
+}
+
+// ToolConfig holds settings for a single tool, keyed by its short name (dust, habits)
+type ToolConfig struct {
+	DefaultFlags []string `yaml:"default_flags"`
+}
+
+// conflictingFlags lists flags that cancel each other out, so a user-supplied
+// flag also removes its opposite from the defaults
+var conflictingFlags = map[string]string{
+	"quick":   "careful",
+	"careful": "quick",
+}
+
+// Path returns the location of the config file
+func Path() string {
+	return filepath.Join(rules.ForgeDir(), "config.yaml")
+}
+
+// Load reads the config file, returning an empty config if none exists
+func Load() (*Config, error) {
+	cfg := &Config{}
+
+	data, err := os.ReadFile(Path())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return cfg, err
+	}
+
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return &Config{}, err
+	}
+
+	return cfg, nil
+}
+
+// DefaultRateEvery is how often a session is rated when rate_every is unset
+const DefaultRateEvery = 5
+
+// RateInterval returns how many sessions apart to ask for a rating, or 0
+// to never ask
+func (c *Config) RateInterval() int {
+	switch {
+	case c.RateEvery < 0:
+		return 0
+	case c.RateEvery == 0:
+		return DefaultRateEvery
+	default:
+		return c.RateEvery
+	}
+}
+
+// AutoRiskLimit returns max_auto_risk as a level. It's low when unset, and
+// also when misspelled, since a typo must not loosen the limit.
+func (c *Config) AutoRiskLimit() rules.Level {
+	if level := rules.ParseLevel(c.MaxAutoRisk); level != "" {
+		return level
+	}
+	return rules.LevelLow
+}
+
+// Models returns the configured fallback chain of Ollama models, preferred
+// first, or just the default
+func (c *Config) Models() []string {
+	switch {
+	case len(c.LLM.Models) > 0:
+		return c.LLM.Models
+	case c.Model != "":
+		return []string{c.Model}
+	default:
+		return []string{llm.DefaultModel}
+	}
+}
+
+// Client returns an Ollama client for the models, falling back along them,
+// with the configured prompt budget and concurrency
+func (c *Config) Client(models []string) *llm.OllamaClient {
+	client := llm.NewFallbackClient(models)
+	client.PromptBudget = c.LLM.PromptBudget
+	client.Concurrency = c.LLM.Concurrency
+	return client
+}
+
+// ModelName returns the preferred configured model
+func (c *Config) ModelName() string {
+	return c.Models()[0]
+}
+
+// DefaultFlags returns the configured default flags for a tool
+func (c *Config) DefaultFlags(tool string) []string {
+	return c.Tools[strings.TrimPrefix(tool, "forge-")].DefaultFlags
+}
+
+// MergeFlags prepends default flags to the user's flags. A default is dropped
+// when the user passes the same flag (in any form: --f, -f, --f=value) or a
+// conflicting one (--careful drops a default --quick). A default flag's
+// separate value argument ("--min-size 50") is dropped along with it.
+func MergeFlags(defaults, user []string) []string {
+	userNames := make(map[string]bool)
+	for _, arg := range user {
+		if name := flagName(arg); name != "" {
+			userNames[name] = true
+			if opposite, ok := conflictingFlags[name]; ok {
+				userNames[opposite] = true
+			}
+		}
+	}
+
+	var merged []string
+	dropping := false
+	for _, arg := range defaults {
+		name := flagName(arg)
+		if name == "" {
+			// A value belongs to the flag before it
+			if !dropping {
+				merged = append(merged, arg)
+			}
+			continue
+		}
+		dropping = userNames[name]
+		if !dropping {
+			merged = append(merged, arg)
+		}
+	}
+
+	return append(merged, user...)
+}
+
+// flagName returns the bare name of a flag argument, or "" for a value
+func flagName(arg string) string {
+	if !strings.HasPrefix(arg, "-") || arg == "-" || arg == "--" {
+		return ""
+	}
+	name := strings.TrimLeft(arg, "-")
+	if i := strings.Index(name, "="); i >= 0 {
+		name = name[:i]
+	}
+	return name
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"forge/llm"
+	"forge/rules"
+)
+
+func TestMergeFlags(t *testing.T) {
+	tests := []struct {
+		name     string
+		defaults []string
+		user     []string
+		want     []string
+	}{
+		{"no defaults", nil, []string{"--quick"}, []string{"--quick"}},
+		{"defaults only", []string{"--quick"}, nil, []string{"--quick"}},
+		{"defaults come first", []string{"--quick"}, []string{"--path", "/tmp"}, []string{"--quick", "--path", "/tmp"}},
+		{"user repeats flag", []string{"--quick"}, []string{"--quick"}, []string{"--quick"}},
+		{"user disables bool", []string{"--quick"}, []string{"--quick=false"}, []string{"--quick=false"}},
+		{"single dash matches", []string{"--quick"}, []string{"-quick"}, []string{"-quick"}},
+		{"user overrides value", []string{"--min-size", "50", "--quick"}, []string{"--min-size=200"}, []string{"--quick", "--min-size=200"}},
+		{"user overrides equals form", []string{"--min-size=50"}, []string{"--min-size", "200"}, []string{"--min-size", "200"}},
+		{"conflicting flag wins", []string{"--quick", "--duplicates"}, []string{"--careful"}, []string{"--duplicates", "--careful"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MergeFlags(tt.defaults, tt.user)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MergeFlags(%v, %v) = %v, want %v", tt.defaults, tt.user, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestModelName(t *testing.T) {
+	if got := (&Config{}).ModelName(); got != llm.DefaultModel {
+		t.Errorf("ModelName() with no model = %q, want %q", got, llm.DefaultModel)
+	}
+	if got := (&Config{Model: "qwen3:8b"}).ModelName(); got != "qwen3:8b" {
+		t.Errorf("ModelName() = %q, want qwen3:8b", got)
+	}
+}
+
+func TestModelsChain(t *testing.T) {
+	tests := []struct {
+		cfg  Config
+		want []string
+	}{
+		{Config{}, []string{llm.DefaultModel}},
+		{Config{Model: "qwen3:8b"}, []string{"qwen3:8b"}},
+		{Config{Model: "ignored", LLM: LLMConfig{Models: []string{"a", "b"}}}, []string{"a", "b"}},
+	}
+
+	for _, tt := range tests {
+		if got := tt.cfg.Models(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Models() for %+v = %v, want %v", tt.cfg, got, tt.want)
+		}
+	}
+}
+
+func TestAutoRiskLimit(t *testing.T) {
+	tests := []struct {
+		value string
+		want  rules.Level
+	}{
+		{"", rules.LevelLow},
+		{"medium", rules.LevelMedium},
+		{"Very High", rules.LevelVeryHigh},
+		{"mediun", rules.LevelLow},
+	}
+
+	for _, tt := range tests {
+		cfg := Config{MaxAutoRisk: tt.value}
+		if got := cfg.AutoRiskLimit(); got != tt.want {
+			t.Errorf("AutoRiskLimit() with max_auto_risk %q = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
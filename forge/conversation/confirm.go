@@ -0,0 +1,99 @@
+package conversation
+
+import (
+	"fmt"
+	"sort"
+
+	"forge-shared/prompt"
+	"forge/assessment"
+)
+
+// confirmShown is how many paths a batch confirmation lists before it
+// shows only the largest and smallest
+const confirmShown = 20
+
+// BatchSummary is everything a batch cleanup would delete, so it can be
+// confirmed path by path rather than by category
+type BatchSummary struct {
+	Findings []assessment.Finding // Largest first, each path once
+	Total    int64
+}
+
+// NewBatchSummary gathers the findings of cats, counting a path found in
+// more than one category once
+func NewBatchSummary(cats []assessment.CategoryAssessment) BatchSummary {
+	var b BatchSummary
+	seen := make(map[string]bool)
+	for _, cat := range cats {
+		for _, f := range cat.Findings {
+			if seen[f.Path] {
+				continue
+			}
+			seen[f.Path] = true
+			b.Findings = append(b.Findings, f)
+			b.Total += f.Size
+		}
+	}
+	sort.SliceStable(b.Findings, func(i, j int) bool {
+		return b.Findings[i].Size > b.Findings[j].Size
+	})
+	return b
+}
+
+// Lines lists the paths, up to max of them: past that, the largest and
+// smallest halves with a count of those left out between them
+func (b BatchSummary) Lines(max int) []string {
+	line := func(f assessment.Finding) string {
+		return fmt.Sprintf("%8s  %s", formatBytes(f.Size), f.Path)
+	}
+
+	var lines []string
+	if len(b.Findings) <= max {
+		for _, f := range b.Findings {
+			lines = append(lines, line(f))
+		}
+		return lines
+	}
+
+	head, tail := (max+1)/2, max/2
+	var hidden int64
+	for _, f := range b.Findings[head : len(b.Findings)-tail] {
+		hidden += f.Size
+	}
+	for _, f := range b.Findings[:head] {
+		lines = append(lines, line(f))
+	}
+	lines = append(lines, fmt.Sprintf("%8s  ... %d more paths, %s ...", "", len(b.Findings)-head-tail, formatBytes(hidden)))
+	for _, f := range b.Findings[len(b.Findings)-tail:] {
+		lines = append(lines, line(f))
+	}
+	return lines
+}
+
+// confirmBatch lists what a batch would delete and asks before going ahead,
+// unless --yes said not to ask. A batch with no paths to list, as when the
+// tool reported categories without items, is confirmed on the question
+// alone. Closed input, or Ctrl-C, is ErrAborted.
+func (l *Loop) confirmBatch(b BatchSummary, question string) (bool, error) {
+	if len(b.Findings) > 0 {
+		paths := "paths"
+		if len(b.Findings) == 1 {
+			paths = "path"
+		}
+		fmt.Fprintf(l.out(), "\n%s%d %s, %s in all:%s\n\n", Bold, len(b.Findings), paths, formatBytes(b.Total), Reset)
+		for _, line := range b.Lines(confirmShown) {
+			fmt.Fprintf(l.out(), "  %s\n", line)
+		}
+	}
+
+	if l.Yes {
+		fmt.Fprintf(l.out(), "\n%s%s Yes (--yes)%s\n", Dim, question, Reset)
+		return true, nil
+	}
+	fmt.Fprintf(l.out(), "\n%s %s[Y/n]%s ", question, Dim, Reset)
+	line, err := l.readLine()
+	if err != nil {
+		return false, err
+	}
+	return prompt.IsYes(line, true), nil
+}
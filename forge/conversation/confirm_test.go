@@ -0,0 +1,147 @@
+package conversation
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"forge-shared/prompt"
+	"forge/assessment"
+	"forge/session"
+)
+
+func TestNewBatchSummary(t *testing.T) {
+	cats := []assessment.CategoryAssessment{
+		{Category: "Cache Directories", Findings: []assessment.Finding{
+			{Path: "/p/app/node_modules", Size: 300},
+			{Path: "/p/web/.next", Size: 50},
+		}},
+		{Category: "Large Files", Findings: []assessment.Finding{
+			{Path: "/p/backup.img", Size: 900},
+			{Path: "/p/app/node_modules", Size: 300}, // Reported twice, deleted once
+		}},
+	}
+
+	b := NewBatchSummary(cats)
+	if b.Total != 1250 {
+		t.Errorf("Total = %d, want 1250", b.Total)
+	}
+	var paths []string
+	for _, f := range b.Findings {
+		paths = append(paths, f.Path)
+	}
+	if want := "/p/backup.img /p/app/node_modules /p/web/.next"; strings.Join(paths, " ") != want {
+		t.Errorf("Findings = %v, want %s, largest first", paths, want)
+	}
+	if lines := b.Lines(confirmShown); len(lines) != 3 || !strings.HasSuffix(lines[0], "  /p/backup.img") {
+		t.Errorf("Lines() = %q, want every path", lines)
+	}
+}
+
+func TestBatchSummaryShowsTopAndBottom(t *testing.T) {
+	var findings []assessment.Finding
+	for i := range 30 {
+		findings = append(findings, assessment.Finding{Path: fmt.Sprintf("/p/f%02d", i), Size: int64(30-i) * 1024})
+	}
+	b := NewBatchSummary([]assessment.CategoryAssessment{{Findings: findings}})
+
+	lines := b.Lines(20)
+	if len(lines) != 21 {
+		t.Fatalf("Lines(20) has %d lines, want 20 paths and a count of the rest:\n%s", len(lines), strings.Join(lines, "\n"))
+	}
+	if !strings.HasSuffix(lines[0], "/p/f00") || !strings.HasSuffix(lines[9], "/p/f09") {
+		t.Errorf("Lines(20) doesn't open with the 10 largest:\n%s", strings.Join(lines, "\n"))
+	}
+	// f10-f19 are left out: 20 KB down to 11 KB
+	if want := "... 10 more paths, 155.0 KB ..."; !strings.Contains(lines[10], want) {
+		t.Errorf("Lines(20)[10] = %q, want %q", lines[10], want)
+	}
+	if !strings.HasSuffix(lines[11], "/p/f20") || !strings.HasSuffix(lines[20], "/p/f29") {
+		t.Errorf("Lines(20) doesn't close with the 10 smallest:\n%s", strings.Join(lines, "\n"))
+	}
+}
+
+func TestSuggestModeListsPathsBeforeCleaning(t *testing.T) {
+	assess := &assessment.SessionAssessment{
+		OverallMode: assessment.ModeSuggest,
+		Categories: []assessment.CategoryAssessment{
+			{Category: "Cache Directories", TotalSize: 2 << 20, Confidence: "high", Risk: "low", Findings: []assessment.Finding{
+				{Path: "/p/app/node_modules", Size: 2 << 20},
+			}},
+		},
+	}
+
+	run := func(yes bool, answer string) (string, *session.Session) {
+		sess := session.NewSession("forge-dust")
+		l := NewLoop(assess, sess, nil)
+		l.Yes = yes
+		l.reader = prompt.NewPlainReader(strings.NewReader(answer))
+		out := captureStdout(t, func() {
+			if err := l.Run(); err != nil {
+				t.Errorf("Run() error = %v", err)
+			}
+		})
+		return out, sess
+	}
+
+	out, sess := run(false, "n\n")
+	if !strings.Contains(out, "1 path, 2.0 MB in all") || !strings.Contains(out, "/p/app/node_modules") {
+		t.Errorf("suggest mode didn't list the paths:\n%s", out)
+	}
+	if got := sess.Interactions[0].UserResponse; got != "reject" {
+		t.Errorf("declined: UserResponse = %q, want reject", got)
+	}
+
+	// --yes goes ahead without reading an answer
+	out, sess = run(true, "")
+	if !strings.Contains(out, "/p/app/node_modules") || !strings.Contains(out, "(--yes)") {
+		t.Errorf("--yes output:\n%s", out)
+	}
+	if got := sess.Interactions[0].UserResponse; got != "accept" {
+		t.Errorf("--yes: UserResponse = %q, want accept", got)
+	}
+}
+
+func TestTargetModeConfirmsLikeAnyBatch(t *testing.T) {
+	t.Setenv("FORGE_HOME", t.TempDir())
+
+	run := func(yes bool, answer string) (string, []assessment.Finding) {
+		findings := makeFindings(t, t.TempDir(), 10, 20)
+		assess := &assessment.SessionAssessment{
+			OverallMode: assessment.ModeSuggest,
+			Categories: []assessment.CategoryAssessment{{
+				Category: "caches", TotalSize: 30, Findings: findings,
+				Risk: "low", Reversible: true, Action: "delete",
+			}},
+		}
+		l := NewLoop(assess, session.NewSession("forge-dust"), nil)
+		l.Target, l.Yes = 30, yes
+		l.reader = prompt.NewPlainReader(strings.NewReader(answer))
+		out := captureStdout(t, func() {
+			if err := l.Run(); err != nil {
+				t.Errorf("Run() error = %v", err)
+			}
+		})
+		return out, findings
+	}
+
+	out, findings := run(false, "n\n")
+	if !strings.Contains(out, "2 paths, 30 B in all") || !strings.Contains(out, findings[0].Path) {
+		t.Errorf("target mode didn't list the paths:\n%s", out)
+	}
+	if _, err := os.Stat(findings[0].Path); err != nil {
+		t.Errorf("declined, yet %s is gone", findings[0].Path)
+	}
+
+	// --yes goes ahead without reading an answer
+	out, findings = run(true, "")
+	if !strings.Contains(out, "(--yes)") {
+		t.Errorf("--yes output:\n%s", out)
+	}
+	for _, f := range findings {
+		if _, err := os.Stat(f.Path); !os.IsNotExist(err) {
+			t.Errorf("--yes: %s kept", f.Path)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package conversation
+
+import (
+	"errors"
+	"fmt"
+
+	"forge-shared/prompt"
+	"forge/assessment"
+	"forge/cleanup"
+	"forge/events"
+	"forge/messages"
+	"forge/session"
+)
+
+// clean deletes findings and records i, the user's answer, with what they
+// freed, reporting whether every one was deleted. Every path the loop
+// deletes goes through here, so cleanup.Delete's never_delete check has
+// the last word however the loop got there.
+func (l *Loop) clean(i session.Interaction, findings []assessment.Finding) (freed int64, ok bool) {
+	results := l.delete(findings)
+	ok = len(results) == len(findings)
+	for _, r := range results {
+		var pe *cleanup.ProtectedError
+		switch {
+		case errors.As(r.Err, &pe) && i.Item == r.Path:
+			i.UserResponse = "protected" // A lone item the user asked for
+			ok = false
+		case r.Err != nil:
+			ok = false
+			i.PermissionsFixed = i.PermissionsFixed || r.Fixed
+		case !r.Resumed:
+			i.PermissionsFixed = i.PermissionsFixed || r.Fixed // Resumed ones were deleted already this session, and counted then
+			i.BytesFreed += r.Freed
+		}
+	}
+	l.record(i)
+	var deleted []cleanup.Result
+	for _, r := range results {
+		if r.Err == nil && !r.Resumed {
+			deleted = append(deleted, r)
+			l.Events.Emit(events.Event{Type: events.Deleted, Category: i.Category, Path: r.Path, Size: r.Freed})
+		}
+	}
+	if len(deleted) > 0 {
+		l.deletions = append(l.deletions, deletion{at: len(l.Session.Interactions) - 1, results: deleted})
+	}
+	return i.BytesFreed, ok
+}
+
+// deletion is what one answer deleted, for undo to bring back
+type deletion struct {
+	at       int              // its interaction in the session
+	results  []cleanup.Result // the paths it deleted, less those already restored
+	restored int64            // bytes restored so far
+	partial  bool             // some paths were removed outright, and stay gone
+}
+
+// delete removes findings with cleanup.Delete, journaled under the
+// session's ID, and says which it couldn't remove
+func (l *Loop) delete(findings []assessment.Finding) []cleanup.Result {
+	if len(findings) == 0 {
+		return nil
+	}
+	if l.cleanupErr != nil {
+		fmt.Fprintf(l.out(), "  %s%s%s\n", Yellow, messages.Getf("delete.stopped", l.cleanupErr), Reset)
+		return nil
+	}
+
+	remove := l.remove
+	if remove == nil {
+		remove = l.openQuarantine().Remove
+	}
+	results, err := cleanup.Delete(findings, l.openJournal(), l.Rules, remove, l.confirmFix)
+	for _, r := range results {
+		var pe *cleanup.ProtectedError
+		switch {
+		case errors.As(r.Err, &pe):
+			fmt.Fprintf(l.out(), "  %s%s%s\n", Yellow, messages.Getf("delete.protected", shortenPath(pe.Path, 50), pe.Pattern), Reset)
+		case r.Err != nil:
+			fmt.Fprintf(l.out(), "  %s%s%s\n", Yellow, messages.Getf("delete.failed", shortenPath(r.Path, 50), r.Err), Reset)
+		}
+	}
+	if err != nil {
+		// Without the journal, what's deleted would go unrecorded
+		l.cleanupErr = err
+		fmt.Fprintf(l.out(), "  %s%s%s\n", Yellow, messages.Getf("delete.stopped", err), Reset)
+	}
+	return results
+}
+
+// confirmFix asks before opening up the permissions of path, the user's
+// own, so it can be deleted. Closed input, or Ctrl-C, is a no.
+func (l *Loop) confirmFix(path string) bool {
+	fmt.Fprintf(l.out(), "\n  %s%s%s %s[y/N]%s ", Yellow, messages.Getf("delete.fix", shortenPath(path, 50)), Reset, Dim, Reset)
+	line, err := l.reader.ReadLine()
+	if err != nil {
+		fmt.Fprintln(l.out())
+		return false
+	}
+	return prompt.IsYes(line, false)
+}
+
+// openJournal opens the session's cleanup journal on its first deletion.
+// If it can't be opened, deletions go ahead without one.
+func (l *Loop) openJournal() *cleanup.Journal {
+	if l.journal == nil && !l.noJournal {
+		j, err := cleanup.OpenJournal(l.Session.ID)
+		if err != nil {
+			l.noJournal = true
+			fmt.Fprintf(l.out(), "  %s%s%s\n", Dim, messages.Getf("delete.unjournaled", err), Reset)
+			return nil
+		}
+		l.journal = j
+	}
+	return l.journal
+}
+
+// openQuarantine makes the session's quarantine on its first deletion. If
+// it can't be made, deletions are outright and can't be undone.
+func (l *Loop) openQuarantine() *cleanup.Quarantine {
+	if l.quarantine == nil && !l.noQuarantine {
+		q, err := cleanup.OpenQuarantine(l.Session.ID)
+		if err != nil {
+			l.noQuarantine = true
+			fmt.Fprintf(l.out(), "  %s%s%s\n", Dim, messages.Getf("delete.unquarantined", err), Reset)
+			return nil
+		}
+		l.quarantine = q
+	}
+	return l.quarantine
+}
+
+// finishCleanup empties the quarantine once the loop is over, past undoing,
+// and removes the session's journal, or keeps it if the journal itself
+// failed partway
+func (l *Loop) finishCleanup() {
+	if err := l.quarantine.Empty(); err != nil {
+		fmt.Fprintf(l.out(), "%s%s%s\n", Yellow, messages.Getf("delete.unemptied", cleanup.QuarantineDir(), err), Reset)
+	}
+	if l.cleanupErr != nil {
+		l.journal.Close()
+		return
+	}
+	if err := l.journal.Finish(); err != nil {
+		fmt.Fprintf(l.out(), "%s%s%s\n", Yellow, messages.Getf("delete.stopped", err), Reset)
+	}
+}
@@ -1,16 +1,23 @@
 package conversation
 
 import (
-	"bufio"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
-	"strconv"
 	"strings"
+	"time"
 
+	"forge-shared/prompt"
 	"forge/assessment"
+	"forge/cleanup"
+	"forge/events"
 	"forge/llm"
+	"forge/messages"
+	"forge/rules"
+	"forge/scan"
 	"forge/session"
 )
 
@@ -26,12 +33,29 @@ const (
 	Magenta = "\033[35m"
 )
 
+// ErrAborted is returned by Loop.Run when the user quits mid-conversation
+var ErrAborted = errors.New("conversation aborted")
+
 // Loop handles the interactive conversation with the user
 type Loop struct {
 	Assessment *assessment.SessionAssessment
 	Session    *session.Session
 	Client     *llm.OllamaClient
-	reader     *bufio.Reader
+	Target     int64           // bytes to free; when set, propose just enough safe findings
+	Yes        bool            // go ahead with batch cleanups without asking, from --yes
+	Compact    bool            // one dense line per category in guided mode, from --compact
+	Events     *events.Emitter // where --events reports what's shown and answered; nil reports nothing
+	Rules      *rules.RuleSet  // never_delete is checked again by cleanup.Delete; nil protects nothing
+	Out        io.Writer       // where the conversation is shown; nil is stdout. Answers are read from the terminal.
+	reader     prompt.LineReader
+
+	remove       func(string) error  // deletes a path; nil moves it into the quarantine
+	journal      *cleanup.Journal    // the session's cleanup journal, once something's deleted
+	noJournal    bool                // the journal couldn't be opened, so deletions go without
+	cleanupErr   error               // the journal failed, so nothing more is deleted
+	quarantine   *cleanup.Quarantine // holds what's deleted until the session ends, for undo
+	noQuarantine bool                // the quarantine couldn't be made, so deletions are outright
+	deletions    []deletion          // what each answer deleted, latest last, for undo
 }
 
 // NewLoop creates a new conversation loop
@@ -40,15 +64,36 @@ func NewLoop(assess *assessment.SessionAssessment, sess *session.Session, client
 		Assessment: assess,
 		Session:    sess,
 		Client:     client,
-		reader:     bufio.NewReader(os.Stdin),
+		reader:     prompt.NewLineReader(os.Stdin),
+	}
+}
+
+// out is where the conversation is shown
+func (l *Loop) out() io.Writer {
+	if l.Out == nil {
+		return os.Stdout
 	}
+	return l.Out
 }
 
 // Run executes the conversation loop
-func (l *Loop) Run() error {
+func (l *Loop) Run() (err error) {
+	// An interrupted prompt returns ErrAborted up to here, and the caller
+	// still saves the session
+	defer func() {
+		if errors.Is(err, ErrAborted) {
+			fmt.Fprintf(l.out(), "\n%s%s%s\n", Dim, messages.Get("quit"), Reset)
+		}
+	}()
+	defer l.finishCleanup()
+
 	// Display opening
 	l.printHeader()
-	fmt.Printf("\n%s%s%s\n\n", Dim, l.Assessment.OpeningMessage, Reset)
+	fmt.Fprintf(l.out(), "\n%s%s%s\n\n", Dim, l.Assessment.OpeningMessage, Reset)
+
+	if l.Target > 0 {
+		return l.runTargetMode()
+	}
 
 	// Route based on mode
 	switch l.Assessment.OverallMode {
@@ -63,110 +108,169 @@ func (l *Loop) Run() error {
 	case assessment.ModeInformative:
 		return l.runInformativeMode()
 	default:
-		fmt.Println("Nothing significant found.")
+		fmt.Fprintln(l.out(), "Nothing significant found.")
 		return nil
 	}
 }
 
 func (l *Loop) printHeader() {
-	fmt.Println()
-	fmt.Printf("%s%s────────────────────────────────────────────────────────────%s\n", Bold, Cyan, Reset)
-	fmt.Printf("%s  ⚒  FORGE%s\n", Bold+Cyan, Reset)
-	fmt.Printf("%s────────────────────────────────────────────────────────────%s\n", Bold+Cyan, Reset)
+	fmt.Fprintln(l.out())
+	fmt.Fprintf(l.out(), "%s%s────────────────────────────────────────────────────────────%s\n", Bold, Cyan, Reset)
+	fmt.Fprintf(l.out(), "%s  ⚒  FORGE%s\n", Bold+Cyan, Reset)
+	fmt.Fprintf(l.out(), "%s────────────────────────────────────────────────────────────%s\n", Bold+Cyan, Reset)
 }
 
 func (l *Loop) runAutoMode() error {
-	fmt.Printf("%s⚡ Burning off the slag...%s\n\n", Green, Reset)
+	fmt.Fprintf(l.out(), "%s%s%s\n\n", Green, messages.Get("auto.start"), Reset)
 
-	for _, cat := range l.Assessment.Categories {
+	for _, cat := range l.batchable(l.Assessment.Categories) {
 		if cat.Mode == assessment.ModeAuto {
-			fmt.Printf("  %s✓%s %s (%s)\n", Green, Reset, cat.Category, formatBytes(cat.TotalSize))
-
-			// Record interaction
-			l.Session.AddInteraction(session.Interaction{
+			l.present(cat.Category, "", cat.TotalSize, "auto_delete")
+			freed, _ := l.clean(session.Interaction{
 				Category:     cat.Category,
 				TotalSize:    cat.TotalSize,
 				Suggestion:   "auto_delete",
 				Confidence:   cat.Confidence,
 				UserResponse: "auto_accepted",
-				BytesFreed:   cat.TotalSize,
-			})
+			}, cat.Findings)
+			fmt.Fprintf(l.out(), "  %s✓%s %s (%s)\n", Green, Reset, cat.Category, formatBytes(freed))
 		}
 	}
 
-	fmt.Printf("\n%sForged and finished.%s\n", Green, Reset)
+	fmt.Fprintf(l.out(), "\n%s%s%s\n", Green, messages.Get("done"), Reset)
 	return nil
 }
 
 func (l *Loop) runSuggestMode() error {
+	cats := l.batchable(l.Assessment.Categories)
 	totalSize := int64(0)
-	for _, cat := range l.Assessment.Categories {
+	for _, cat := range cats {
 		totalSize += cat.TotalSize
 	}
 
-	fmt.Printf("Found %s%s%s of raw material to reclaim:\n\n", Bold, formatBytes(totalSize), Reset)
+	fmt.Fprintln(l.out(), messages.Getf("suggest.found", Bold+formatBytes(totalSize)+Reset))
+	fmt.Fprintln(l.out())
 
-	for _, cat := range l.Assessment.Categories {
-		icon := "🟢"
-		if cat.Risk == "medium" {
-			icon = "🟡"
-		}
-		fmt.Printf("  %s %s (%s)\n", icon, cat.Category, formatBytes(cat.TotalSize))
+	for _, cat := range cats {
+		fmt.Fprintf(l.out(), "  %s %s (%s)\n", levelIcons(cat), cat.Category, formatBytes(cat.TotalSize))
 	}
+	fmt.Fprintf(l.out(), "\n  %s%s%s\n", Dim, rules.Legend(), Reset)
 
-	fmt.Printf("\nClean all? %s[Y/n]%s ", Dim, Reset)
-
-	response := l.readLine()
-	accepted := response == "" || strings.ToLower(response) == "y" || strings.ToLower(response) == "yes"
-
-	userResp := "accept"
-	if !accepted {
-		userResp = "reject"
+	for _, cat := range cats {
+		l.present(cat.Category, "", cat.TotalSize, "suggest_delete")
+	}
+	accepted, err := l.confirmBatch(NewBatchSummary(cats), "Clean all?")
+	if err != nil {
+		return err
 	}
 
-	for _, cat := range l.Assessment.Categories {
-		l.Session.AddInteraction(session.Interaction{
+	if accepted {
+		fmt.Fprintf(l.out(), "\n%s%s%s\n", Green, messages.Get("clean.start"), Reset)
+	}
+	for _, cat := range cats {
+		i := session.Interaction{
 			Category:     cat.Category,
 			TotalSize:    cat.TotalSize,
 			Suggestion:   "suggest_delete",
 			Confidence:   cat.Confidence,
-			UserResponse: userResp,
-		})
+			UserResponse: "reject",
+		}
+		if !accepted {
+			l.record(i)
+			continue
+		}
+		i.UserResponse = "accept"
+		l.clean(i, cat.Findings)
 	}
 
 	if accepted {
-		fmt.Printf("\n%s✓ Firing up the crucible...%s\n", Green, Reset)
-		// TODO: Actually execute cleanup
-		fmt.Printf("%sForged and finished.%s\n", Green, Reset)
+		fmt.Fprintf(l.out(), "%s%s%s\n", Green, messages.Get("done"), Reset)
 	} else {
-		fmt.Println("\nThe metal cools. Nothing changed.")
+		fmt.Fprintln(l.out(), "\n"+messages.Get("clean.declined"))
+	}
+
+	return nil
+}
+
+func (l *Loop) runTargetMode() error {
+	sel := assessment.SelectForTarget(l.Assessment.Categories, l.Target)
+	if len(sel.Findings) == 0 {
+		fmt.Fprintln(l.out(), messages.Getf("target.none", formatBytes(l.Target)))
+		return nil
+	}
+
+	if sel.Shortfall > 0 {
+		fmt.Fprintf(l.out(), "Only %s%s%s of the %s target can be reclaimed safely (%s short).\n",
+			Bold, formatBytes(sel.Total), Reset, formatBytes(l.Target), formatBytes(sel.Shortfall))
+	} else {
+		fmt.Fprintf(l.out(), "These %d items free %s%s%s, meeting the %s target.\n",
+			len(sel.Findings), Bold, formatBytes(sel.Total), Reset, formatBytes(l.Target))
+	}
+
+	for _, f := range sel.Findings {
+		l.present(f.Category, f.Path, f.Size, "target_delete")
+	}
+
+	// Listed path by path and confirmed like any other batch, so --yes holds
+	accepted, err := l.confirmBatch(NewBatchSummary([]assessment.CategoryAssessment{{Findings: sel.Findings}}), "Clean these?")
+	if err != nil {
+		return err
+	}
+
+	if accepted {
+		fmt.Fprintf(l.out(), "\n%s%s%s\n", Green, messages.Get("clean.start"), Reset)
+	}
+	for _, f := range sel.Findings {
+		i := session.Interaction{
+			Category:     f.Category,
+			Item:         f.Path,
+			TotalSize:    f.Size,
+			Suggestion:   "target_delete",
+			UserResponse: "reject",
+		}
+		if !accepted {
+			l.record(i)
+			continue
+		}
+		i.UserResponse = "accept"
+		l.clean(i, []assessment.Finding{f})
+	}
+
+	if accepted {
+		fmt.Fprintf(l.out(), "%s%s%s\n", Green, messages.Get("done"), Reset)
+	} else {
+		fmt.Fprintln(l.out(), "\n"+messages.Get("clean.declined"))
 	}
 
 	return nil
 }
 
 func (l *Loop) runGuidedMode() error {
-	fmt.Printf("Found %s%d ore deposits%s to inspect:\n\n", Bold, len(l.Assessment.Categories), Reset)
+	fmt.Fprintln(l.out(), messages.Getf("guided.found", fmt.Sprint(Bold, len(l.Assessment.Categories), Reset)))
+	fmt.Fprintln(l.out())
 
 	for i, cat := range l.Assessment.Categories {
-		icon := "🟢"
-		if cat.Risk == "medium" {
-			icon = "🟡"
-		} else if cat.Risk == "high" {
-			icon = "🔴"
+		if l.Compact {
+			fmt.Fprintf(l.out(), "  %s[%2d]%s %s\n", Cyan, i+1, Reset, compactLine(cat))
+			continue
 		}
-		fmt.Printf("  %s[%d]%s %s %s (%s)\n", Cyan, i+1, Reset, icon, cat.Category, formatBytes(cat.TotalSize))
+		fmt.Fprintf(l.out(), "  %s[%d]%s %s %s (%s)\n", Cyan, i+1, Reset, levelIcons(cat), cat.Category, formatBytes(cat.TotalSize))
 	}
+	fmt.Fprintf(l.out(), "\n  %s%s%s\n", Dim, rules.Legend(), Reset)
 
-	fmt.Printf("\n  %s[a]%s Clean all safe items\n", Cyan, Reset)
-	fmt.Printf("  %s[q]%s Quit\n", Cyan, Reset)
+	fmt.Fprintf(l.out(), "\n  %s[a]%s Clean all safe items\n", Cyan, Reset)
+	fmt.Fprintf(l.out(), "  %s[u]%s Undo the last deletion\n", Cyan, Reset)
+	fmt.Fprintf(l.out(), "  %s[q]%s Quit\n", Cyan, Reset)
 
 	for {
-		fmt.Printf("\n%s→%s Pick a category (1-%d), or action: ", Cyan, Reset, len(l.Assessment.Categories))
-		input := l.readLine()
+		fmt.Fprintf(l.out(), "\n%s→%s Pick a category (1-%d), or action: ", Cyan, Reset, len(l.Assessment.Categories))
+		input, err := l.readLine()
+		if err != nil {
+			return err
+		}
 
 		if input == "q" || input == "quit" {
-			fmt.Println("Banking the fire. Until next time.")
+			fmt.Fprintln(l.out(), messages.Get("quit"))
 			return nil
 		}
 
@@ -174,23 +278,32 @@ func (l *Loop) runGuidedMode() error {
 			return l.cleanAllSafe()
 		}
 
+		if input == "u" || input == "undo" {
+			l.undoLast()
+			continue
+		}
+
 		// Try to parse as category number
-		num, err := strconv.Atoi(input)
-		if err == nil && num >= 1 && num <= len(l.Assessment.Categories) {
+		if num, ok := prompt.ParseChoice(input, len(l.Assessment.Categories)); ok {
 			if err := l.exploreCat(num - 1); err != nil {
 				return err
 			}
 			continue
 		}
 
-		fmt.Printf("%sInvalid choice. Try again.%s\n", Yellow, Reset)
+		fmt.Fprintf(l.out(), "%sInvalid choice. Try again.%s\n", Yellow, Reset)
 	}
 }
 
 func (l *Loop) exploreCat(idx int) error {
 	cat := l.Assessment.Categories[idx]
+	l.present(cat.Category, "", cat.TotalSize, cat.Action)
 
-	fmt.Printf("\n%s── %s (%s) ──%s\n\n", Bold+Cyan, cat.Category, formatBytes(cat.TotalSize), Reset)
+	fmt.Fprintf(l.out(), "\n%s── %s (%s) ──%s\n\n", Bold+Cyan, cat.Category, formatBytes(cat.TotalSize), Reset)
+	// The compact listing left this out
+	if l.Compact && cat.Explanation != "" {
+		fmt.Fprintf(l.out(), "  %s%s%s\n\n", Dim, cat.Explanation, Reset)
+	}
 
 	// Group files by type for better understanding
 	groups := groupFilesByType(cat.Findings)
@@ -199,7 +312,8 @@ func (l *Loop) exploreCat(idx int) error {
 	fileNum := 1
 	fileMap := make(map[int]assessment.Finding)
 
-	for groupName, files := range groups {
+	for _, groupName := range groupOrder {
+		files := groups[groupName]
 		if len(files) == 0 {
 			continue
 		}
@@ -209,7 +323,7 @@ func (l *Loop) exploreCat(idx int) error {
 			groupSize += f.Size
 		}
 
-		fmt.Printf("  %s%s%s %s(%s)%s\n", Bold, groupName, Reset, Dim, formatBytes(groupSize), Reset)
+		fmt.Fprintf(l.out(), "  %s%s%s %s(%s)%s\n", Bold, groupName, Reset, Dim, formatBytes(groupSize), Reset)
 
 		for _, f := range files {
 			fileMap[fileNum] = f
@@ -223,81 +337,123 @@ func (l *Loop) exploreCat(idx int) error {
 				displayName = filename[:42] + "..."
 			}
 
-			fmt.Printf("    %s[%2d]%s %s%8s%s  %s\n",
+			fmt.Fprintf(l.out(), "    %s[%2d]%s %s%8s%s  %s\n",
 				Cyan, fileNum, Reset,
 				Yellow, formatBytes(f.Size), Reset,
 				displayName)
-			fmt.Printf("         %sin %s%s\n", Dim, parentDir, Reset)
+			fmt.Fprintf(l.out(), "         %sin %s%s\n", Dim, parentDir, Reset)
 
 			fileNum++
 			if fileNum > 20 {
 				remaining := len(cat.Findings) - 20
 				if remaining > 0 {
-					fmt.Printf("\n    %s... and %d more files%s\n", Dim, remaining, Reset)
+					fmt.Fprintf(l.out(), "\n    %s... and %d more files%s\n", Dim, remaining, Reset)
 				}
 				break
 			}
 		}
-		fmt.Println()
+		fmt.Fprintln(l.out())
 	}
 
 	// Interactive loop for this category
 	for {
-		fmt.Printf("  %s[1-%d]%s Inspect file  %s[d]%s Delete all  %s[s]%s Skip  %s[b]%s Back\n",
+		fmt.Fprintf(l.out(), "  %s[1-%d]%s Inspect file  %s[d]%s Delete all  %s[s]%s Skip  %s[u]%s Undo  %s[b]%s Back\n",
 			Cyan, len(fileMap), Reset,
 			Green, Reset,
 			Yellow, Reset,
+			Cyan, Reset,
 			Dim, Reset)
-		fmt.Printf("\n%s→%s ", Cyan, Reset)
+		fmt.Fprintf(l.out(), "\n%s→%s ", Cyan, Reset)
 
-		input := l.readLine()
+		input, err := l.readLine()
+		if err != nil {
+			return err
+		}
 
 		// Check if it's a number (file selection)
-		if num, err := strconv.Atoi(input); err == nil && num >= 1 && num <= len(fileMap) {
-			l.inspectFile(fileMap[num])
+		if num, ok := prompt.ParseChoice(input, len(fileMap)); ok {
+			if err := l.inspectFile(fileMap[num]); err != nil {
+				return err
+			}
 			continue
 		}
 
-		var userResp string
+		i := session.Interaction{
+			Category:   cat.Category,
+			TotalSize:  cat.TotalSize,
+			Suggestion: cat.Action,
+			Confidence: cat.Confidence,
+		}
 		switch strings.ToLower(input) {
 		case "d", "delete":
-			userResp = "accept"
-			fmt.Printf("\n%s✓ Into the furnace%s\n", Green, Reset)
+			i.UserResponse = "accept"
+			var findings []assessment.Finding
+			for _, c := range l.batchable([]assessment.CategoryAssessment{cat}) {
+				findings = c.Findings
+			}
+			if _, ok := l.clean(i, findings); ok {
+				fmt.Fprintf(l.out(), "\n%s%s%s\n", Green, messages.Get("category.deleted"), Reset)
+			}
 		case "s", "skip":
-			userResp = "reject"
-			fmt.Println("\nSet aside for now.")
+			i.UserResponse = "reject"
+			l.record(i)
+			fmt.Fprintln(l.out(), "\n"+messages.Get("category.skipped"))
+		case "u", "undo":
+			l.undoLast()
+			continue
 		case "b", "back", "q":
 			return nil
 		default:
-			fmt.Printf("%sType a number to examine, or pick an action.%s\n", Dim, Reset)
+			fmt.Fprintf(l.out(), "%sType a number to examine, or pick an action.%s\n", Dim, Reset)
 			continue
 		}
 
-		l.Session.AddInteraction(session.Interaction{
-			Category:     cat.Category,
-			TotalSize:    cat.TotalSize,
-			Suggestion:   cat.Action,
-			Confidence:   cat.Confidence,
-			UserResponse: userResp,
-		})
-
 		return nil
 	}
 }
 
-// groupFilesByType organizes files into meaningful groups
+// compactName is how wide the category name is in a compact line
+const compactName = 28
+
+// compactLine shows a category on one line: its icons, name, size, how many
+// items it has and its mode, in columns that line up from one to the next
+func compactLine(cat assessment.CategoryAssessment) string {
+	name := cat.Category
+	if runes := []rune(name); len(runes) > compactName {
+		name = string(runes[:compactName-3]) + "..."
+	}
+	items := "items"
+	if len(cat.Findings) == 1 {
+		items = "item"
+	}
+	return fmt.Sprintf("%s %-*s %9s %6d %-5s  %s", levelIcons(cat), compactName, name, formatBytes(cat.TotalSize), len(cat.Findings), items, cat.Mode)
+}
+
+// levelIcons shows a category's risk and confidence, as keyed by rules.Legend
+func levelIcons(cat assessment.CategoryAssessment) string {
+	return rules.ParseLevel(cat.Risk).RiskIcon() + " " + rules.ParseLevel(cat.Confidence).ConfidenceIcon()
+}
+
+// groupOrder lists groupFilesByType's groups in the order they're shown
+var groupOrder = []string{
+	"🐳 Docker & Containers",
+	"🤖 AI/ML Models",
+	groupVideos,
+	groupArchives,
+	groupDiskImages,
+	"📁 Application Data",
+	"📄 Other",
+}
+
+// groupFilesByType organizes files into meaningful groups. Among the first
+// sniffLimit findings, files whose names don't match are placed by content.
 func groupFilesByType(findings []assessment.Finding) map[string][]assessment.Finding {
-	groups := map[string][]assessment.Finding{
-		"🐳 Docker & Containers": {},
-		"🤖 AI/ML Models":        {},
-		"🎬 Videos":              {},
-		"📦 Archives":            {},
-		"💾 Disk Images":         {},
-		"📁 Application Data":    {},
-		"📄 Other":               {},
+	groups := make(map[string][]assessment.Finding, len(groupOrder))
+	for _, name := range groupOrder {
+		groups[name] = []assessment.Finding{}
 	}
 
-	for _, f := range findings {
+	for i, f := range findings {
 		path := strings.ToLower(f.Path)
 		filename := strings.ToLower(filepath.Base(f.Path))
 		ext := strings.ToLower(filepath.Ext(f.Path))
@@ -311,80 +467,189 @@ func groupFilesByType(findings []assessment.Finding) map[string][]assessment.Fin
 			groups["🤖 AI/ML Models"] = append(groups["🤖 AI/ML Models"], f)
 		case ext == ".mp4" || ext == ".mov" || ext == ".avi" || ext == ".mkv" ||
 			ext == ".wmv" || ext == ".m4v" || ext == ".webm":
-			groups["🎬 Videos"] = append(groups["🎬 Videos"], f)
+			groups[groupVideos] = append(groups[groupVideos], f)
 		case ext == ".zip" || ext == ".tar" || ext == ".gz" || ext == ".7z" ||
 			ext == ".rar" || ext == ".tar.gz" || ext == ".tgz":
-			groups["📦 Archives"] = append(groups["📦 Archives"], f)
+			groups[groupArchives] = append(groups[groupArchives], f)
 		case ext == ".dmg" || ext == ".iso" || ext == ".img" || ext == ".raw":
-			groups["💾 Disk Images"] = append(groups["💾 Disk Images"], f)
+			groups[groupDiskImages] = append(groups[groupDiskImages], f)
 		case strings.Contains(path, "application support") || strings.Contains(path, "library"):
 			groups["📁 Application Data"] = append(groups["📁 Application Data"], f)
 		default:
-			groups["📄 Other"] = append(groups["📄 Other"], f)
+			// Extensionless or misnamed: the first bytes may still tell
+			group := ""
+			if i < sniffLimit {
+				group = sniffGroup(f.Path)
+			}
+			if group == "" {
+				group = "📄 Other"
+			}
+			groups[group] = append(groups[group], f)
 		}
 	}
 
 	return groups
 }
 
+// breakdownSize is how many children the size breakdown lists
+const breakdownSize = 10
+
+// explainSize shows the largest entries inside dir, letting the user drill
+// further into subdirectories
+func (l *Loop) explainSize(dir string) error {
+	// A recent forge scan already has the sizes; otherwise walk just this folder
+	listing, ok := scan.Lookup(dir)
+	if !ok {
+		fmt.Fprintf(l.out(), "\n%sSizing %s...%s", Dim, filepath.Base(dir), Reset)
+		var err error
+		listing, err = scan.Walk(dir)
+		fmt.Fprintf(l.out(), "\r\033[K")
+		if err != nil {
+			fmt.Fprintf(l.out(), "  %sCouldn't read %s: %v%s\n", Yellow, dir, err, Reset)
+			return nil
+		}
+	}
+
+	for {
+		b, ok := listing.Breakdown(dir, breakdownSize)
+		if !ok || len(b.Top) == 0 {
+			fmt.Fprintf(l.out(), "  %s%s is empty%s\n", Dim, dir, Reset)
+			return nil
+		}
+
+		fmt.Fprintf(l.out(), "\n  %s%s%s %s(%s)%s\n\n", Bold, dir, Reset, Dim, formatBytes(b.Dir.Size), Reset)
+		for i, e := range b.Top {
+			name := filepath.Base(e.Path)
+			if e.IsDir {
+				name += "/"
+			}
+			fmt.Fprintf(l.out(), "    %s[%2d]%s %s%8s%s  %s%s%s %s\n",
+				Cyan, i+1, Reset,
+				Yellow, formatBytes(e.Size), Reset,
+				Dim, sizeBar(e.Size, b.Dir.Size), Reset,
+				name)
+		}
+		if b.RestCount > 0 {
+			fmt.Fprintf(l.out(), "         %s%8s  ... and %d smaller entries%s\n", Dim, formatBytes(b.Rest), b.RestCount, Reset)
+		}
+
+		fmt.Fprintf(l.out(), "\n  %s[1-%d]%s Drill into a folder  %s[u]%s Up  %s[b]%s Back\n", Cyan, len(b.Top), Reset, Cyan, Reset, Dim, Reset)
+		fmt.Fprintf(l.out(), "\n%s→%s ", Cyan, Reset)
+
+		input, err := l.readLine()
+		if err != nil {
+			return err
+		}
+		if num, ok := prompt.ParseChoice(input, len(b.Top)); ok {
+			if e := b.Top[num-1]; e.IsDir {
+				dir = e.Path
+			} else {
+				fmt.Fprintf(l.out(), "  %s%s is a file%s\n", Dim, filepath.Base(e.Path), Reset)
+			}
+			continue
+		}
+		switch strings.ToLower(input) {
+		case "u", "up":
+			if parent := filepath.Dir(dir); parent != dir && strings.HasPrefix(parent, listing.Root) {
+				dir = parent
+			}
+		case "b", "back", "q", "":
+			return nil
+		}
+	}
+}
+
+// sizeBar draws size as a share of total, ten characters wide
+func sizeBar(size, total int64) string {
+	filled := 0
+	if total > 0 {
+		filled = int(size * 10 / total)
+	}
+	return strings.Repeat("█", filled) + strings.Repeat("░", 10-filled)
+}
+
 // inspectFile shows detailed info about a specific file and asks LLM for context
-func (l *Loop) inspectFile(f assessment.Finding) {
-	fmt.Printf("\n%s────────────────────────────────────────────────%s\n", Cyan, Reset)
-	fmt.Printf("  %sFile:%s %s\n", Bold, Reset, filepath.Base(f.Path))
-	fmt.Printf("  %sSize:%s %s\n", Bold, Reset, formatBytes(f.Size))
-	fmt.Printf("  %sPath:%s %s\n", Bold, Reset, f.Path)
+func (l *Loop) inspectFile(f assessment.Finding) error {
+	fmt.Fprintf(l.out(), "\n%s────────────────────────────────────────────────%s\n", Cyan, Reset)
+	fmt.Fprintf(l.out(), "  %sFile:%s %s\n", Bold, Reset, filepath.Base(f.Path))
+	fmt.Fprintf(l.out(), "  %sSize:%s %s\n", Bold, Reset, formatBytes(f.Size))
+	fmt.Fprintf(l.out(), "  %sPath:%s %s\n", Bold, Reset, f.Path)
 	if f.AgeDays > 0 {
-		fmt.Printf("  %sAge:%s %s\n", Bold, Reset, formatAgeDays(f.AgeDays))
+		fmt.Fprintf(l.out(), "  %sAge:%s %s\n", Bold, Reset, formatAgeDays(f.AgeDays))
 	}
-	fmt.Printf("%s────────────────────────────────────────────────%s\n", Cyan, Reset)
+	if f.Active {
+		fmt.Fprintf(l.out(), "  %sIn use:%s changed %s ago, so it would just be rebuilt\n", Bold, Reset, time.Since(f.Modified).Round(time.Minute))
+	}
+	contains := f.Metadata["contains"]
+	if contains != "" {
+		fmt.Fprintf(l.out(), "  %sContains:%s %s\n", Bold, Reset, contains)
+	}
+	fmt.Fprintf(l.out(), "%s────────────────────────────────────────────────%s\n", Cyan, Reset)
 
 	// Ask LLM for context
-	fmt.Printf("\n%sAnalyzing...%s", Dim, Reset)
+	fmt.Fprintf(l.out(), "\n%sAnalyzing...%s", Dim, Reset)
 
 	prompt := fmt.Sprintf(`What is this file and is it safe to delete? Be specific and concise (2-3 sentences).
 
 File: %s
 Size: %s
 Full path: %s
-
+%s
 Consider: Is this user data that can't be recovered? Is it a cache/temp file? Is it from a specific application?`,
-		filepath.Base(f.Path), formatBytes(f.Size), f.Path)
+		filepath.Base(f.Path), formatBytes(f.Size), f.Path, archiveLine(contains))
 
 	explanation, err := l.Client.Generate(prompt)
 	if err != nil {
-		fmt.Printf("\r%s                    %s\n", Reset, Reset)
-		fmt.Printf("  %sCouldn't analyze - check if Ollama is running%s\n", Yellow, Reset)
+		fmt.Fprintf(l.out(), "\r%s                    %s\n", Reset, Reset)
+		fmt.Fprintf(l.out(), "  %sCouldn't analyze - check if Ollama is running%s\n", Yellow, Reset)
 	} else {
-		fmt.Printf("\r%s                    %s\n", Reset, Reset)
+		fmt.Fprintf(l.out(), "\r%s                    %s\n", Reset, Reset)
 		// Clean up and display the explanation
 		explanation = strings.TrimSpace(explanation)
-		fmt.Printf("  %s%s%s\n", Dim, explanation, Reset)
+		fmt.Fprintf(l.out(), "  %s%s%s\n", Dim, explanation, Reset)
 	}
 
-	fmt.Printf("\n  %s[d]%s Delete  %s[k]%s Keep  %s[o]%s Open folder  %s[b]%s Back\n",
-		Red, Reset, Green, Reset, Cyan, Reset, Dim, Reset)
-	fmt.Printf("\n%s→%s ", Cyan, Reset)
+	info, err := os.Stat(f.Path)
+	isDir := err == nil && info.IsDir()
+
+	l.present("individual_file", f.Path, f.Size, "delete")
+	fmt.Fprintf(l.out(), "\n  %s[d]%s Delete  %s[k]%s Keep  %s[o]%s Open folder", Red, Reset, Green, Reset, Cyan, Reset)
+	if isDir {
+		fmt.Fprintf(l.out(), "  %s[w]%s Why this size?", Cyan, Reset)
+	}
+	fmt.Fprintf(l.out(), "  %s[b]%s Back\n", Dim, Reset)
+	fmt.Fprintf(l.out(), "\n%s→%s ", Cyan, Reset)
 
-	input := l.readLine()
+	input, err := l.readLine()
+	if err != nil {
+		return err
+	}
 
 	switch strings.ToLower(input) {
+	case "w", "why":
+		if isDir {
+			if err := l.explainSize(f.Path); err != nil {
+				return err
+			}
+		}
 	case "d", "delete":
-		fmt.Printf("%s✓ Marked for the crucible%s\n", Green, Reset)
-		l.Session.AddInteraction(session.Interaction{
+		if _, ok := l.clean(session.Interaction{
 			Category:     "individual_file",
 			Item:         f.Path,
 			TotalSize:    f.Size,
 			Suggestion:   "delete",
 			UserResponse: "accept",
-		})
+		}, []assessment.Finding{f}); ok {
+			fmt.Fprintf(l.out(), "%s%s%s\n", Green, messages.Get("file.deleted"), Reset)
+		}
 	case "o", "open":
 		// Open the folder in Finder
 		dir := filepath.Dir(f.Path)
 		exec.Command("open", dir).Run()
-		fmt.Printf("%sOpened in Finder%s\n", Dim, Reset)
+		fmt.Fprintf(l.out(), "%sOpened in Finder%s\n", Dim, Reset)
 	case "k", "keep":
-		fmt.Printf("%s✓ Preserved%s\n", Green, Reset)
-		l.Session.AddInteraction(session.Interaction{
+		fmt.Fprintf(l.out(), "%s%s%s\n", Green, messages.Get("file.kept"), Reset)
+		l.record(session.Interaction{
 			Category:     "individual_file",
 			Item:         f.Path,
 			TotalSize:    f.Size,
@@ -392,7 +657,8 @@ Consider: Is this user data that can't be recovered? Is it a cache/temp file? Is
 			UserResponse: "reject",
 		})
 	}
-	fmt.Println()
+	fmt.Fprintln(l.out())
+	return nil
 }
 
 func formatAgeDays(days int) string {
@@ -411,84 +677,154 @@ func formatAgeDays(days int) string {
 }
 
 func (l *Loop) explainCategory(cat assessment.CategoryAssessment) {
-	prompt := fmt.Sprintf(`Explain in 2-3 sentences what "%s" files are and whether they're safe to delete. Be concise and helpful. The user is looking at %d files totaling %s.`,
-		cat.Category, len(cat.Findings), formatBytes(cat.TotalSize))
+	// Usually fetched already, in the background
+	if text, ok := l.Assessment.Explanations.Lookup(cat.Category); ok {
+		fmt.Fprintf(l.out(), "\n%s%s%s\n", Dim, text, Reset)
+		return
+	}
+	prompt := assessment.CategoryPrompt(cat)
 
-	fmt.Printf("\n%sThinking...%s\n", Dim, Reset)
+	fmt.Fprintf(l.out(), "\n%sThinking...%s\n", Dim, Reset)
 
 	explanation, err := l.Client.Generate(prompt)
 	if err != nil {
-		fmt.Printf("\n%s%s%s\n", Dim, cat.Explanation, Reset)
+		fmt.Fprintf(l.out(), "\n%s%s%s\n", Dim, cat.Explanation, Reset)
 		return
 	}
 
-	fmt.Printf("\n%s%s%s\n", Dim, explanation, Reset)
+	fmt.Fprintf(l.out(), "\n%s%s%s\n", Dim, explanation, Reset)
 }
 
+// cleanAllSafe cleans, on one confirmation, the categories that rebuild
+// themselves, the same ones --target picks from. Never-delete findings and
+// caches in use stay behind.
 func (l *Loop) cleanAllSafe() error {
-	fmt.Printf("\n%sSmelting the pure ore...%s\n\n", Green, Reset)
-
+	var rebuilding []assessment.CategoryAssessment
 	for _, cat := range l.Assessment.Categories {
-		if cat.Risk != "high" {
-			fmt.Printf("  %s✓%s %s (%s)\n", Green, Reset, cat.Category, formatBytes(cat.TotalSize))
+		if cat.RebuildsItself() {
+			rebuilding = append(rebuilding, cat)
+		}
+	}
+	safe := l.batchable(rebuilding)
+	if len(safe) == 0 {
+		fmt.Fprintln(l.out(), messages.Get("safe.none"))
+		return nil
+	}
 
-			l.Session.AddInteraction(session.Interaction{
+	for _, cat := range safe {
+		l.present(cat.Category, "", cat.TotalSize, "clean_all_safe")
+	}
+	accepted, err := l.confirmBatch(NewBatchSummary(safe), "Clean these?")
+	if err != nil {
+		return err
+	}
+	if !accepted {
+		for _, cat := range safe {
+			l.record(session.Interaction{
 				Category:     cat.Category,
 				TotalSize:    cat.TotalSize,
 				Suggestion:   "clean_all_safe",
 				Confidence:   cat.Confidence,
-				UserResponse: "accept",
-				BytesFreed:   cat.TotalSize,
+				UserResponse: "reject",
 			})
 		}
+		fmt.Fprintln(l.out(), "\n"+messages.Get("clean.declined"))
+		return nil
+	}
+
+	fmt.Fprintf(l.out(), "\n%s%s%s\n\n", Green, messages.Get("safe.start"), Reset)
+
+	for _, cat := range safe {
+		freed, _ := l.clean(session.Interaction{
+			Category:     cat.Category,
+			TotalSize:    cat.TotalSize,
+			Suggestion:   "clean_all_safe",
+			Confidence:   cat.Confidence,
+			UserResponse: "accept",
+		}, cat.Findings)
+		fmt.Fprintf(l.out(), "  %s✓%s %s (%s)\n", Green, Reset, cat.Category, formatBytes(freed))
 	}
 
-	fmt.Printf("\n%sForged and finished.%s\n", Green, Reset)
+	fmt.Fprintf(l.out(), "\n%s%s%s\n", Green, messages.Get("done"), Reset)
 	return nil
 }
 
+// batchable trims cats to the findings a batch deletes, leaving out caches
+// in use and saying so. A category left with nothing is dropped.
+func (l *Loop) batchable(cats []assessment.CategoryAssessment) []assessment.CategoryAssessment {
+	var out []assessment.CategoryAssessment
+	active := 0
+	for _, cat := range cats {
+		findings := cat.Batchable()
+		for _, f := range cat.Findings {
+			if f.Active {
+				active++
+			}
+		}
+		if len(findings) < len(cat.Findings) {
+			if len(findings) == 0 {
+				continue
+			}
+			cat.Findings, cat.TotalSize = findings, 0
+			for _, f := range findings {
+				cat.TotalSize += f.Size
+			}
+		}
+		out = append(out, cat)
+	}
+	if active > 0 {
+		fmt.Fprintf(l.out(), "%s%s%s\n\n", Dim, messages.Getf("batch.active", active), Reset)
+	}
+	return out
+}
+
 func (l *Loop) runCollaborativeMode() error {
-	fmt.Printf("Found some unusual alloys that need your eye.\n\n")
+	fmt.Fprintf(l.out(), "%s\n\n", messages.Get("collaborative.intro"))
 
 	for _, cat := range l.Assessment.Categories {
-		if cat.Risk == "high" || cat.Confidence == "low" {
-			fmt.Printf("%s── %s ──%s\n\n", Bold+Cyan, cat.Category, Reset)
+		if rules.ParseLevel(cat.Risk).AtLeast(rules.LevelHigh) || !rules.ParseLevel(cat.Confidence).AtLeast(rules.LevelMedium) {
+			fmt.Fprintf(l.out(), "%s── %s ──%s\n\n", Bold+Cyan, cat.Category, Reset)
 
 			for _, finding := range cat.Findings {
-				fmt.Printf("  %s\n", shortenPath(finding.Path, 60))
-				fmt.Printf("  Size: %s\n\n", formatBytes(finding.Size))
+				fmt.Fprintf(l.out(), "  %s\n", shortenPath(finding.Path, 60))
+				fmt.Fprintf(l.out(), "  Size: %s\n\n", formatBytes(finding.Size))
 
-				fmt.Printf("  What would you like to do?\n")
-				fmt.Printf("  %s[d]%s Delete  %s[k]%s Keep  %s[?]%s Tell me more\n\n",
+				l.present(cat.Category, finding.Path, finding.Size, "discuss")
+				fmt.Fprintf(l.out(), "  What would you like to do?\n")
+				fmt.Fprintf(l.out(), "  %s[d]%s Delete  %s[k]%s Keep  %s[?]%s Tell me more\n\n",
 					Red, Reset, Green, Reset, Cyan, Reset)
-				fmt.Printf("%s→%s ", Cyan, Reset)
+				fmt.Fprintf(l.out(), "%s→%s ", Cyan, Reset)
 
-				input := l.readLine()
+				input, err := l.readLine()
+				if err != nil {
+					return err
+				}
 
-				var userResp string
+				i := session.Interaction{
+					Category:   cat.Category,
+					Item:       finding.Path,
+					TotalSize:  finding.Size,
+					Suggestion: "discuss",
+					Confidence: cat.Confidence,
+				}
 				switch strings.ToLower(input) {
 				case "d", "delete":
-					userResp = "accept"
-					fmt.Printf("%s✓ Into the crucible%s\n\n", Green, Reset)
+					i.UserResponse = "accept"
+					if _, ok := l.clean(i, []assessment.Finding{finding}); ok {
+						fmt.Fprintf(l.out(), "%s%s%s\n\n", Green, messages.Get("collaborative.deleted"), Reset)
+					}
+					continue
 				case "k", "keep":
-					userResp = "reject"
-					fmt.Printf("%s✓ Set aside%s\n\n", Green, Reset)
+					i.UserResponse = "reject"
+					fmt.Fprintf(l.out(), "%s%s%s\n\n", Green, messages.Get("collaborative.kept"), Reset)
 				case "?":
-					userResp = "explain"
+					i.UserResponse = "explain"
 					l.explainFile(finding)
 				default:
-					userResp = "skip"
-					fmt.Println("Passing over.\n")
+					i.UserResponse = "skip"
+					fmt.Fprintf(l.out(), "%s\n\n", messages.Get("collaborative.skipped"))
 				}
-
-				l.Session.AddInteraction(session.Interaction{
-					Category:     cat.Category,
-					Item:         finding.Path,
-					TotalSize:    finding.Size,
-					Suggestion:   "discuss",
-					Confidence:   cat.Confidence,
-					UserResponse: userResp,
-				})
+				l.record(i)
 			}
 		}
 	}
@@ -506,33 +842,34 @@ Type: %s
 Give a brief (2-3 sentence) explanation of what this file likely is and whether it's safe to delete. Be helpful but cautious.`,
 		finding.Path, formatBytes(finding.Size), finding.Type)
 
-	fmt.Printf("\n%sThinking...%s\n", Dim, Reset)
+	fmt.Fprintf(l.out(), "\n%sThinking...%s\n", Dim, Reset)
 
 	explanation, err := l.Client.Generate(prompt)
 	if err != nil {
-		fmt.Printf("\n%sI'm not sure about this file.%s\n\n", Dim, Reset)
+		fmt.Fprintf(l.out(), "\n%sI'm not sure about this file.%s\n\n", Dim, Reset)
 		return
 	}
 
-	fmt.Printf("\n%s%s%s\n\n", Dim, explanation, Reset)
+	fmt.Fprintf(l.out(), "\n%s%s%s\n\n", Dim, explanation, Reset)
 }
 
 func (l *Loop) runInformativeMode() error {
-	fmt.Printf("Laid out the materials for your inspection.\n\n")
+	fmt.Fprintf(l.out(), "%s\n\n", messages.Get("informative.intro"))
 
 	for _, cat := range l.Assessment.Categories {
-		fmt.Printf("%s── %s (%s) ──%s\n\n", Bold+Cyan, cat.Category, formatBytes(cat.TotalSize), Reset)
+		l.present(cat.Category, "", cat.TotalSize, "inform_only")
+		fmt.Fprintf(l.out(), "%s── %s (%s) ──%s\n\n", Bold+Cyan, cat.Category, formatBytes(cat.TotalSize), Reset)
 
 		for i, finding := range cat.Findings {
 			if i >= 10 {
-				fmt.Printf("  %s... and %d more%s\n", Dim, len(cat.Findings)-10, Reset)
+				fmt.Fprintf(l.out(), "  %s... and %d more%s\n", Dim, len(cat.Findings)-10, Reset)
 				break
 			}
-			fmt.Printf("  %s (%s)\n", shortenPath(finding.Path, 50), formatBytes(finding.Size))
+			fmt.Fprintf(l.out(), "  %s (%s)\n", shortenPath(finding.Path, 50), formatBytes(finding.Size))
 		}
-		fmt.Println()
+		fmt.Fprintln(l.out())
 
-		l.Session.AddInteraction(session.Interaction{
+		l.record(session.Interaction{
 			Category:     cat.Category,
 			TotalSize:    cat.TotalSize,
 			Suggestion:   "inform_only",
@@ -541,13 +878,112 @@ func (l *Loop) runInformativeMode() error {
 		})
 	}
 
-	fmt.Printf("%sThe forge stands ready. Return when you're prepared to work the metal.%s\n", Dim, Reset)
+	fmt.Fprintf(l.out(), "%s%s%s\n", Dim, messages.Get("informative.outro"), Reset)
 	return nil
 }
 
-func (l *Loop) readLine() string {
-	line, _ := l.reader.ReadString('\n')
-	return strings.TrimSpace(line)
+// undoLast takes back the most recent deletion this session, moving what
+// it deleted back out of the quarantine. Paths that were removed outright
+// are reported and stay gone; the deletion is dropped once nothing in it
+// is left to restore.
+func (l *Loop) undoLast() {
+	if len(l.deletions) == 0 {
+		fmt.Fprintf(l.out(), "%s%s%s\n", Dim, messages.Get("undo.none"), Reset)
+		return
+	}
+	d := l.deletions[len(l.deletions)-1]
+	var held []cleanup.Result
+	for _, r := range d.results {
+		if l.quarantine.Holds(r.Path) {
+			held = append(held, r)
+			continue
+		}
+		d.partial = true
+		fmt.Fprintf(l.out(), "%s%s%s\n", Yellow, messages.Getf("undo.outright", shortenPath(r.Path, 50)), Reset)
+	}
+
+	var left []cleanup.Result
+	for _, r := range held {
+		if err := l.quarantine.Restore(r.Path); err != nil {
+			fmt.Fprintf(l.out(), "%s%s%s\n", Yellow, messages.Getf("undo.failed", shortenPath(r.Path, 50), err), Reset)
+			left = append(left, r)
+			continue
+		}
+		d.restored += r.Freed
+		if err := l.journal.Unmark(r.Path); err != nil && l.cleanupErr == nil {
+			l.cleanupErr = err
+			fmt.Fprintf(l.out(), "%s%s%s\n", Yellow, messages.Getf("delete.stopped", err), Reset)
+		}
+		l.Events.Emit(events.Event{Type: events.Undone, Category: l.Session.Interactions[d.at].Category, Path: r.Path, Size: r.Freed})
+	}
+	if len(left) > 0 {
+		// Kept as a deletion until the rest come back too, with another u
+		d.results = left
+		l.deletions[len(l.deletions)-1] = d
+		return
+	}
+	l.deletions = l.deletions[:len(l.deletions)-1]
+
+	i := l.Session.Interactions[d.at]
+	what := i.Item
+	if what == "" {
+		what = i.Category
+	}
+	switch {
+	case !d.partial:
+		l.Session.Undo(d.at)
+		fmt.Fprintf(l.out(), "%s%s%s\n", Green, messages.Getf("undo.done", what), Reset)
+	case d.restored > 0:
+		// The answer stands, since some of what it deleted is gone for good
+		l.Session.UndoPart(d.at, d.restored)
+		fmt.Fprintf(l.out(), "%s%s%s\n", Green, messages.Getf("undo.partial", what), Reset)
+	}
+}
+
+// present reports that the user is being shown a suggestion for a
+// category, or for one path in it
+func (l *Loop) present(category, path string, size int64, suggestion string) {
+	l.Events.Emit(events.Event{Type: events.SuggestionPresented, Category: category, Path: path, Size: size, Suggestion: suggestion})
+}
+
+// record adds i to the session and reports the response, and the deletion
+// if one was accepted
+func (l *Loop) record(i session.Interaction) {
+	l.Session.AddInteraction(i)
+	l.Events.Emit(events.Event{Type: events.UserResponse, Category: i.Category, Path: i.Item, Size: i.TotalSize,
+		Suggestion: i.Suggestion, Response: i.UserResponse})
+}
+
+// AskRating asks for a 1-5 rating of the session just run. Anything else,
+// including Enter or Ctrl-C, skips it, and ok is false.
+func (l *Loop) AskRating() (rating int, ok bool) {
+	fmt.Fprintf(l.out(), "\n%s%s%s ", Dim, messages.Get("rating.ask"), Reset)
+	line, err := l.reader.ReadLine()
+	if err != nil {
+		fmt.Fprintln(l.out())
+		return 0, false
+	}
+	return prompt.ParseChoice(line, 5)
+}
+
+// archiveLine tells the LLM what an archive holds, where "also on disk"
+// means that entry was already extracted next to it
+func archiveLine(contains string) string {
+	if contains == "" {
+		return ""
+	}
+	return fmt.Sprintf("Archive contents (top level): %s\n", contains)
+}
+
+// readLine reads the user's answer to a prompt. Ctrl-C or closed input is
+// ErrAborted, to be returned up to Run; the terminal has already been
+// restored.
+func (l *Loop) readLine() (string, error) {
+	line, err := l.reader.ReadLine()
+	if err != nil {
+		return "", ErrAborted
+	}
+	return line, nil
 }
 
 func formatBytes(b int64) string {
@@ -0,0 +1,573 @@
+package conversation
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"forge-shared/prompt"
+	"forge/assessment"
+	"forge/cleanup"
+	"forge/events"
+	"forge/messages"
+	"forge/rules"
+	"forge/session"
+)
+
+// captureStdout returns what fn prints
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	done := make(chan []byte)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- data
+	}()
+	fn()
+	w.Close()
+	return string(<-done)
+}
+
+func TestPlainMessagesInSuggestFlow(t *testing.T) {
+	assess := &assessment.SessionAssessment{
+		OverallMode: assessment.ModeSuggest,
+		Categories: []assessment.CategoryAssessment{
+			{Category: "node_modules", TotalSize: 1 << 30, Confidence: "high", Risk: "low"},
+		},
+	}
+
+	run := func(answer string) string {
+		l := NewLoop(assess, session.NewSession("forge-dust"), nil)
+		l.reader = prompt.NewPlainReader(strings.NewReader(answer))
+		return captureStdout(t, func() {
+			if err := l.Run(); err != nil {
+				t.Errorf("Run() error = %v", err)
+			}
+		})
+	}
+
+	forge := run("y\n") + run("n\n")
+	for _, want := range []string{"raw material", "Firing up the crucible", "Forged and finished.", "The metal cools."} {
+		if !strings.Contains(forge, want) {
+			t.Errorf("default output lacks %q:\n%s", want, forge)
+		}
+	}
+
+	messages.Use(messages.Plain)
+	t.Cleanup(func() { messages.Use(nil) })
+
+	plain := run("y\n") + run("n\n")
+	for _, want := range []string{"Found \033[1m1.0 GB\033[0m to reclaim:", "✓ Cleaning...", "Done.", "Nothing changed."} {
+		if !strings.Contains(plain, want) {
+			t.Errorf("--plain output lacks %q:\n%s", want, plain)
+		}
+	}
+	for _, themed := range []string{"raw material", "crucible", "Forged", "metal"} {
+		if strings.Contains(plain, themed) {
+			t.Errorf("--plain output still says %q:\n%s", themed, plain)
+		}
+	}
+}
+
+// makeFindings writes a file of each size under dir, as findings
+func makeFindings(t *testing.T, dir string, sizes ...int) []assessment.Finding {
+	t.Helper()
+	var findings []assessment.Finding
+	for i, size := range sizes {
+		path := filepath.Join(dir, fmt.Sprintf("f%d", i))
+		if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+			t.Fatal(err)
+		}
+		findings = append(findings, assessment.Finding{Path: path, Size: int64(size)})
+	}
+	return findings
+}
+
+func TestAcceptedCategoryIsDeleted(t *testing.T) {
+	t.Setenv("FORGE_HOME", t.TempDir())
+	findings := makeFindings(t, t.TempDir(), 100, 200)
+	gone := filepath.Join(t.TempDir(), "gone")
+	assess := &assessment.SessionAssessment{
+		OverallMode: assessment.ModeGuided,
+		Categories: []assessment.CategoryAssessment{
+			{Category: "caches", TotalSize: 300, Action: "delete", Findings: findings},
+			{Category: "stale", TotalSize: 50, Action: "delete", Findings: []assessment.Finding{{Path: gone, Size: 50}}},
+		},
+	}
+	s := session.NewSession("forge-dust")
+	l := NewLoop(assess, s, nil)
+	var stream bytes.Buffer
+	l.Events = events.New(&stream)
+	l.reader = prompt.NewPlainReader(strings.NewReader("1\nd\n2\nd\nq\n"))
+	out := captureStdout(t, func() {
+		if err := l.Run(); err != nil {
+			t.Errorf("Run() error = %v", err)
+		}
+	})
+
+	for _, f := range findings {
+		if _, err := os.Lstat(f.Path); !os.IsNotExist(err) {
+			t.Errorf("%s still there after the category was deleted", f.Path)
+		}
+	}
+	// Already gone when the loop got to it: reported, and nothing counted as freed
+	if !strings.Contains(out, "Couldn't melt down") || !strings.Contains(out, "no such file") {
+		t.Errorf("output doesn't say %s couldn't be deleted:\n%s", gone, out)
+	}
+	if got := []int64{s.Interactions[0].BytesFreed, s.Interactions[1].BytesFreed}; got[0] != 300 || got[1] != 0 {
+		t.Errorf("BytesFreed = %v, want [300 0]", got)
+	}
+	if s.Outcome.TotalFreed != 300 {
+		t.Errorf("TotalFreed = %d, want 300", s.Outcome.TotalFreed)
+	}
+	var deleted []string
+	for _, line := range strings.Split(strings.TrimSpace(stream.String()), "\n") {
+		var e events.Event
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatalf("event %q: %v", line, err)
+		}
+		if e.Type == events.Deleted {
+			deleted = append(deleted, fmt.Sprintf("%s %d", e.Path, e.Size))
+		}
+	}
+	if want := []string{findings[0].Path + " 100", findings[1].Path + " 200"}; !slices.Equal(deleted, want) {
+		t.Errorf("deleted events = %v, want %v", deleted, want)
+	}
+	if entries, _ := os.ReadDir(cleanup.Dir()); len(entries) != 0 {
+		t.Errorf("journal left behind after the session: %v", entries)
+	}
+}
+
+func TestProtectedPathsSurviveEveryDeletionPath(t *testing.T) {
+	t.Setenv("FORGE_HOME", t.TempDir())
+	rs := &rules.RuleSet{}
+	rs.Preferences.NeverDelete = []rules.Preference{{Pattern: "keep-*"}}
+
+	tests := []struct {
+		name   string
+		mode   assessment.Mode
+		target int64
+		input  string
+	}{
+		{"auto", assessment.ModeAuto, 0, ""},
+		{"suggest clean all", assessment.ModeSuggest, 0, "y\n"},
+		{"guided clean all safe", assessment.ModeGuided, 0, "a\ny\n"},
+		{"guided category", assessment.ModeGuided, 0, "1\nd\nq\n"},
+		{"target", assessment.ModeSuggest, 1 << 20, "y\n"},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		protected := filepath.Join(dir, "keep-me")
+		if err := os.WriteFile(protected, []byte("data"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		findings := append(makeFindings(t, dir, 10), assessment.Finding{Path: protected, Size: 4})
+		assess := &assessment.SessionAssessment{
+			OverallMode: tt.mode,
+			Categories: []assessment.CategoryAssessment{{
+				Category: "caches", TotalSize: 14, Findings: findings,
+				Mode: tt.mode, Risk: "low", Reversible: true, Action: "delete",
+			}},
+		}
+		s := session.NewSession("forge-dust")
+		l := NewLoop(assess, s, nil)
+		l.Rules, l.Target = rs, tt.target
+		l.reader = prompt.NewPlainReader(strings.NewReader(tt.input))
+		out := captureStdout(t, func() {
+			if err := l.Run(); err != nil {
+				t.Errorf("%s: Run() error = %v", tt.name, err)
+			}
+		})
+
+		if _, err := os.Stat(protected); err != nil {
+			t.Errorf("%s: protected file deleted", tt.name)
+		}
+		if _, err := os.Stat(findings[0].Path); !os.IsNotExist(err) {
+			t.Errorf("%s: unprotected file kept", tt.name)
+		}
+		if !strings.Contains(out, `never_delete "keep-*" guards`) {
+			t.Errorf("%s: output doesn't name the preference:\n%s", tt.name, out)
+		}
+		if s.Outcome.TotalFreed != 10 {
+			t.Errorf("%s: TotalFreed = %d, want only the unprotected 10", tt.name, s.Outcome.TotalFreed)
+		}
+	}
+}
+
+func TestCleanAllSafeTakesOnlyWhatRebuildsItself(t *testing.T) {
+	t.Setenv("FORGE_HOME", t.TempDir())
+	caches := makeFindings(t, t.TempDir(), 10, 20)
+	caches[1].Active = true
+	large := makeFindings(t, t.TempDir(), 30)
+	downloads := makeFindings(t, t.TempDir(), 40)
+	assess := &assessment.SessionAssessment{
+		OverallMode: assessment.ModeGuided,
+		Categories: []assessment.CategoryAssessment{
+			{Category: "caches", TotalSize: 30, Risk: "low", Reversible: true, Findings: caches},
+			{Category: "large", TotalSize: 30, Risk: "medium", Reversible: true, Findings: large},
+			{Category: "downloads", TotalSize: 40, Risk: "low", Reversible: false, Findings: downloads},
+		},
+	}
+	s := session.NewSession("forge-dust")
+	l := NewLoop(assess, s, nil)
+	l.reader = prompt.NewPlainReader(strings.NewReader("a\ny\n"))
+	captureStdout(t, func() {
+		if err := l.Run(); err != nil {
+			t.Errorf("Run() error = %v", err)
+		}
+	})
+
+	if _, err := os.Stat(caches[0].Path); !os.IsNotExist(err) {
+		t.Errorf("idle cache kept after [a]")
+	}
+	for _, f := range []assessment.Finding{caches[1], large[0], downloads[0]} {
+		if _, err := os.Stat(f.Path); err != nil {
+			t.Errorf("%s deleted by [a], want it kept: active cache, medium risk or irreversible", f.Path)
+		}
+	}
+	if s.Outcome.TotalFreed != 10 {
+		t.Errorf("TotalFreed = %d, want 10", s.Outcome.TotalFreed)
+	}
+}
+
+func TestCachesInUseSurviveEveryBatch(t *testing.T) {
+	t.Setenv("FORGE_HOME", t.TempDir())
+	tests := []struct {
+		name  string
+		mode  assessment.Mode
+		input string
+	}{
+		{"auto", assessment.ModeAuto, ""},
+		{"suggest clean all", assessment.ModeSuggest, "y\n"},
+		{"guided clean all safe", assessment.ModeGuided, "a\ny\n"},
+		{"guided delete all", assessment.ModeGuided, "1\nd\nq\n"},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		warm, cold := filepath.Join(dir, "app", "node_modules"), filepath.Join(dir, "old", "node_modules")
+		for _, path := range []string{warm, cold} {
+			if err := os.MkdirAll(path, 0755); err != nil {
+				t.Fatal(err)
+			}
+		}
+		stale := time.Now().Add(-120 * 24 * time.Hour).Format(time.RFC3339)
+		out, err := assessment.ParseToolOutput([]byte(`{
+  "tool": "forge-dust",
+  "categories": [
+    {"id": "cache_directories", "name": "Cache Directories", "total_size": 8000,
+     "metadata": {"typical_risk": "low", "reversible": true},
+     "items": [
+       {"path": "` + warm + `", "size": 5000, "type": "node_modules", "modified": "` + time.Now().Format(time.RFC3339) + `"},
+       {"path": "` + cold + `", "size": 3000, "type": "node_modules", "modified": "` + stale + `"}
+     ]}
+  ]
+}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assess, err := assessment.NewAssessor(&rules.RuleSet{}, nil).Assess(out, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assess.OverallMode, assess.Categories[0].Mode = tt.mode, tt.mode
+
+		s := session.NewSession("forge-dust")
+		l := NewLoop(assess, s, nil)
+		l.reader = prompt.NewPlainReader(strings.NewReader(tt.input))
+		captureStdout(t, func() {
+			if err := l.Run(); err != nil {
+				t.Errorf("%s: Run() error = %v", tt.name, err)
+			}
+		})
+
+		if _, err := os.Stat(warm); err != nil {
+			t.Errorf("%s: cache in use deleted", tt.name)
+		}
+		if _, err := os.Stat(cold); !os.IsNotExist(err) {
+			t.Errorf("%s: stale cache kept", tt.name)
+		}
+	}
+}
+
+func TestLockedFindingIsFixedOnlyWhenTheUserSaysSo(t *testing.T) {
+	t.Setenv("FORGE_HOME", t.TempDir())
+
+	for _, tt := range []struct {
+		answer    string
+		wantFixed bool
+	}{
+		{"n", false},
+		{"y", true},
+	} {
+		dir := filepath.Join(t.TempDir(), "cache")
+		locked := filepath.Join(dir, "locked")
+		if err := os.MkdirAll(locked, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chmod(locked, 0500); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { os.Chmod(locked, 0755) })
+
+		assess := &assessment.SessionAssessment{
+			OverallMode: assessment.ModeGuided,
+			Categories: []assessment.CategoryAssessment{
+				{Category: "caches", TotalSize: 100, Action: "delete", Findings: []assessment.Finding{{Path: dir, Size: 100}}},
+			},
+		}
+		s := session.NewSession("forge-dust")
+		l := NewLoop(assess, s, nil)
+		// Refused until the locked directory is writable again, as it is for
+		// anyone but root
+		l.remove = func(path string) error {
+			if info, err := os.Stat(locked); err == nil && info.Mode().Perm()&0200 == 0 {
+				return &fs.PathError{Op: "unlinkat", Path: locked, Err: fs.ErrPermission}
+			}
+			return os.RemoveAll(path)
+		}
+		l.reader = prompt.NewPlainReader(strings.NewReader("1\nd\n" + tt.answer + "\nq\n"))
+		out := captureStdout(t, func() {
+			if err := l.Run(); err != nil {
+				t.Errorf("%s: Run() error = %v", tt.answer, err)
+			}
+		})
+
+		if !strings.Contains(out, "locked against the hammer") {
+			t.Errorf("%s: never asked before fixing permissions:\n%s", tt.answer, out)
+		}
+		_, err := os.Lstat(dir)
+		if deleted := os.IsNotExist(err); deleted != tt.wantFixed {
+			t.Errorf("%s: deleted = %v, want %v", tt.answer, deleted, tt.wantFixed)
+		}
+		if got := s.Interactions[0].PermissionsFixed; got != tt.wantFixed {
+			t.Errorf("%s: PermissionsFixed = %v, want %v", tt.answer, got, tt.wantFixed)
+		}
+	}
+}
+
+func TestUndoTakesBackLastDeletion(t *testing.T) {
+	t.Setenv("FORGE_HOME", t.TempDir())
+	dir := t.TempDir()
+	modules := makeFindings(t, dir, 300, 200)
+	caches := []assessment.Finding{{Path: filepath.Join(dir, "cache"), Size: 100}}
+	if err := os.MkdirAll(filepath.Join(caches[0].Path, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(caches[0].Path, "sub", "blob"), make([]byte, 100), 0644); err != nil {
+		t.Fatal(err)
+	}
+	assess := &assessment.SessionAssessment{
+		OverallMode: assessment.ModeGuided,
+		Categories: []assessment.CategoryAssessment{
+			{Category: "node_modules", TotalSize: 500, Action: "delete", Findings: modules},
+			{Category: "caches", TotalSize: 100, Action: "delete", Findings: caches},
+		},
+	}
+	s := session.NewSession("forge-dust")
+	l := NewLoop(assess, s, nil)
+	// Delete both and undo the caches from the list, then undo node_modules
+	// from inside a category, where there's then nothing left to undo
+	l.reader = prompt.NewPlainReader(strings.NewReader("1\nd\n2\nd\nu\n2\nu\nu\nb\nq\n"))
+	out := captureStdout(t, func() {
+		if err := l.Run(); err != nil {
+			t.Errorf("Run() error = %v", err)
+		}
+	})
+
+	for _, want := range []string{"Pulled caches back out", "Pulled node_modules back out", "Nothing has gone into the fire yet."} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output lacks %q:\n%s", want, out)
+		}
+	}
+
+	if len(s.Interactions) != 2 {
+		t.Fatalf("got %d interactions, want 2", len(s.Interactions))
+	}
+	for _, i := range s.Interactions {
+		if i.UserResponse != "reject" || i.UserComment != "undone" || i.BytesFreed != 0 {
+			t.Errorf("%s = %q %q freed %d, want rejected, undone, nothing freed", i.Category, i.UserResponse, i.UserComment, i.BytesFreed)
+		}
+	}
+	want := session.Outcome{ItemsKept: 2, Regrets: 2}
+	if s.Outcome != want {
+		t.Errorf("Outcome = %+v, want %+v", s.Outcome, want)
+	}
+
+	for _, f := range append(modules, caches...) {
+		if _, err := os.Lstat(f.Path); err != nil {
+			t.Errorf("%s not restored: %v", f.Path, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(caches[0].Path, "sub", "blob")); err != nil {
+		t.Errorf("restored directory lost its contents: %v", err)
+	}
+	if entries, _ := os.ReadDir(cleanup.QuarantineDir()); len(entries) != 0 {
+		t.Errorf("quarantine left behind after the session: %v", entries)
+	}
+}
+
+func TestUndoAfterOutrightDeletion(t *testing.T) {
+	t.Setenv("FORGE_HOME", t.TempDir())
+	findings := makeFindings(t, t.TempDir(), 100)
+	assess := &assessment.SessionAssessment{
+		OverallMode: assessment.ModeGuided,
+		Categories:  []assessment.CategoryAssessment{{Category: "caches", TotalSize: 100, Action: "delete", Findings: findings}},
+	}
+	s := session.NewSession("forge-dust")
+	l := NewLoop(assess, s, nil)
+	l.remove = os.RemoveAll // As off the forge directory's volume, where there's no quarantine
+	l.reader = prompt.NewPlainReader(strings.NewReader("1\nd\nu\nq\n"))
+	out := captureStdout(t, func() {
+		if err := l.Run(); err != nil {
+			t.Errorf("Run() error = %v", err)
+		}
+	})
+
+	if !strings.Contains(out, "no pulling it back") {
+		t.Errorf("output doesn't say the deletion can't be undone:\n%s", out)
+	}
+	if i := s.Interactions[0]; i.UserResponse != "accept" || i.BytesFreed != 100 {
+		t.Errorf("interaction = %q freed %d, want still accepted, 100 freed", i.UserResponse, i.BytesFreed)
+	}
+}
+
+func TestUndoRestoresWhatIsHeldFromAMixedDeletion(t *testing.T) {
+	t.Setenv("FORGE_HOME", t.TempDir())
+	findings := makeFindings(t, t.TempDir(), 100, 200)
+	held, outright := findings[0].Path, findings[1].Path
+	assess := &assessment.SessionAssessment{
+		OverallMode: assessment.ModeGuided,
+		Categories:  []assessment.CategoryAssessment{{Category: "caches", TotalSize: 300, Action: "delete", Findings: findings}},
+	}
+	s := session.NewSession("forge-dust")
+	l := NewLoop(assess, s, nil)
+	l.remove = func(path string) error {
+		if path == outright {
+			return os.RemoveAll(path) // As off the forge directory's volume
+		}
+		return l.openQuarantine().Remove(path)
+	}
+	l.reader = prompt.NewPlainReader(strings.NewReader("1\nd\nu\nu\nq\n"))
+	out := captureStdout(t, func() {
+		if err := l.Run(); err != nil {
+			t.Errorf("Run() error = %v", err)
+		}
+	})
+
+	if _, err := os.Stat(held); err != nil {
+		t.Errorf("quarantined %s not restored: %v", held, err)
+	}
+	if !strings.Contains(out, "no pulling it back") {
+		t.Errorf("output doesn't say %s can't be undone:\n%s", outright, out)
+	}
+	if !strings.Contains(out, messages.Get("undo.none")) {
+		t.Errorf("second undo found something left to restore:\n%s", out)
+	}
+	if i := s.Interactions[0]; i.UserResponse != "accept" || i.BytesFreed != 200 {
+		t.Errorf("interaction = %q freed %d, want still accepted, 200 freed", i.UserResponse, i.BytesFreed)
+	}
+	if s.Outcome.TotalFreed != 200 {
+		t.Errorf("TotalFreed = %d, want 200", s.Outcome.TotalFreed)
+	}
+}
+
+func TestUndoUpdatesTotals(t *testing.T) {
+	s := session.NewSession("forge-dust")
+	s.AddInteraction(session.Interaction{Category: "a", UserResponse: "accept", BytesFreed: 100})
+	s.AddInteraction(session.Interaction{Category: "b", UserResponse: "reject"})
+	s.AddInteraction(session.Interaction{Category: "c", UserResponse: "auto_accepted", BytesFreed: 50, ItemsAffected: 3})
+	if want := (session.Outcome{TotalFreed: 150, ItemsDeleted: 4, ItemsKept: 1}); s.Outcome != want {
+		t.Fatalf("Outcome = %+v, want %+v", s.Outcome, want)
+	}
+
+	if _, ok := s.Undo(1); ok {
+		t.Errorf("Undo(1) took back a rejection")
+	}
+	i, ok := s.Undo(2)
+	if !ok || i.Category != "c" || i.UserResponse != "auto_accepted" {
+		t.Fatalf("Undo(2) = %+v, %v, want c as it was", i, ok)
+	}
+	if want := (session.Outcome{TotalFreed: 100, ItemsDeleted: 1, ItemsKept: 4, Regrets: 1}); s.Outcome != want {
+		t.Errorf("Outcome = %+v, want %+v", s.Outcome, want)
+	}
+	if got := s.Interactions[2]; got.UserResponse != "reject" || got.UserComment != "undone" {
+		t.Errorf("undone interaction = %+v", got)
+	}
+}
+
+func TestClosedInputAbortsFromAnyPrompt(t *testing.T) {
+	t.Setenv("FORGE_HOME", t.TempDir())
+	tests := []struct {
+		input string
+		kept  int // interactions recorded before the input ran out
+	}{
+		{"", 0},       // the category list
+		{"1\n", 0},    // inside a category
+		{"a\n", 0},    // confirming every safe category
+		{"1\ns\n", 1}, // after skipping one
+	}
+	for _, tt := range tests {
+		assess := &assessment.SessionAssessment{
+			OverallMode: assessment.ModeGuided,
+			Categories: []assessment.CategoryAssessment{
+				{Category: "caches", TotalSize: 100, Risk: "low", Reversible: true, Action: "delete",
+					Findings: []assessment.Finding{{Path: "/nowhere/caches", Size: 100}}},
+				{Category: "logs", TotalSize: 50, Risk: "low", Reversible: true, Action: "delete",
+					Findings: []assessment.Finding{{Path: "/nowhere/logs", Size: 50}}},
+			},
+		}
+		s := session.NewSession("forge-dust")
+		l := NewLoop(assess, s, nil)
+		l.reader = prompt.NewPlainReader(strings.NewReader(tt.input))
+		var err error
+		out := captureStdout(t, func() { err = l.Run() })
+
+		if !errors.Is(err, ErrAborted) {
+			t.Errorf("input %q: Run() error = %v, want ErrAborted", tt.input, err)
+		}
+		if !strings.Contains(out, messages.Get("quit")) {
+			t.Errorf("input %q: output doesn't say it stopped:\n%s", tt.input, out)
+		}
+		if len(s.Interactions) != tt.kept {
+			t.Errorf("input %q: %d interactions, want %d", tt.input, len(s.Interactions), tt.kept)
+		}
+	}
+}
+
+func TestCompactLine(t *testing.T) {
+	findings := make([]assessment.Finding, 3)
+	tests := []struct {
+		cat  assessment.CategoryAssessment
+		want string
+	}{
+		{
+			assessment.CategoryAssessment{Category: "node_modules", Findings: findings, TotalSize: 3 << 30, Risk: "low", Confidence: "high", Mode: assessment.ModeSuggest},
+			"🟢 ◕ node_modules                    3.0 GB      3 items  suggest",
+		},
+		{
+			assessment.CategoryAssessment{Category: "Large files in Downloads and Desktop", Findings: findings[:1], TotalSize: 512, Risk: "high", Confidence: "low", Mode: assessment.ModeGuided},
+			"🔴 ◔ Large files in Downloads ...     512 B      1 item   guided",
+		},
+	}
+	for _, tt := range tests {
+		if got := compactLine(tt.cat); got != tt.want {
+			t.Errorf("compactLine(%q) =\n%q, want\n%q", tt.cat.Category, got, tt.want)
+		}
+	}
+}
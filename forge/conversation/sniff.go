@@ -0,0 +1,86 @@
+package conversation
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Groups that a file's contents can place it in
+const (
+	groupVideos     = "🎬 Videos"
+	groupArchives   = "📦 Archives"
+	groupDiskImages = "💾 Disk Images"
+)
+
+// sniffLimit caps how many findings have their contents read: the category
+// view only shows the first 20
+const sniffLimit = 20
+
+// Magic numbers that http.DetectContentType doesn't know
+var signatures = []struct {
+	offset int
+	magic  []byte
+	group  string
+}{
+	{0, []byte("7z\xBC\xAF\x27\x1C"), groupArchives},
+	{0, []byte("\xFD7zXZ\x00"), groupArchives},
+	{0, []byte("BZh"), groupArchives},
+	{0, []byte("\x28\xB5\x2F\xFD"), groupArchives}, // zstd
+	{257, []byte("ustar"), groupArchives},          // tar
+	{4, []byte("ftyp"), groupVideos},               // mp4, mov, m4v
+	{0, []byte("QFI\xFB"), groupDiskImages},        // qcow2
+	{0, []byte("vhdxfile"), groupDiskImages},
+	{0, []byte("KDMV"), groupDiskImages}, // vmdk
+}
+
+// sniffGroup reads the start of path to place a file whose name doesn't say
+// what it is. It returns "" when the contents don't say either.
+func sniffGroup(path string) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return ""
+	}
+	head = head[:n]
+
+	for _, sig := range signatures {
+		end := sig.offset + len(sig.magic)
+		if end <= len(head) && bytes.Equal(head[sig.offset:end], sig.magic) {
+			return sig.group
+		}
+	}
+
+	switch ct := http.DetectContentType(head); {
+	case strings.HasPrefix(ct, "video/"):
+		return groupVideos
+	case ct == "application/zip" || ct == "application/x-gzip" || ct == "application/x-rar-compressed":
+		return groupArchives
+	}
+
+	// ISO 9660 and DMG mark themselves away from the start
+	if at(file, 0x8001, []byte("CD001")) {
+		return groupDiskImages
+	}
+	if info, err := file.Stat(); err == nil && info.Size() >= 512 && at(file, info.Size()-512, []byte("koly")) {
+		return groupDiskImages
+	}
+	return ""
+}
+
+// at reports whether file holds magic at offset
+func at(file *os.File, offset int64, magic []byte) bool {
+	buf := make([]byte, len(magic))
+	if _, err := file.ReadAt(buf, offset); err != nil {
+		return false
+	}
+	return bytes.Equal(buf, magic)
+}
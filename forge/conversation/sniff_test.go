@@ -0,0 +1,92 @@
+package conversation
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"forge/assessment"
+)
+
+// header returns size zero bytes with magic written at offset
+func header(size, offset int, magic string) []byte {
+	data := make([]byte, size)
+	copy(data[offset:], magic)
+	return data
+}
+
+func TestSniffGroup(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"zip", header(64, 0, "PK\x03\x04"), groupArchives},
+		{"gzip", header(64, 0, "\x1F\x8B\x08"), groupArchives},
+		{"7z", header(64, 0, "7z\xBC\xAF\x27\x1C"), groupArchives},
+		{"tar", header(1024, 257, "ustar"), groupArchives},
+		{"mov", header(64, 0, "\x00\x00\x00\x14ftypqt  "), groupVideos},
+		{"mkv", header(64, 0, "\x1A\x45\xDF\xA3"), groupVideos},
+		{"qcow2", header(64, 0, "QFI\xFB"), groupDiskImages},
+		{"iso", header(0x9000, 0x8001, "CD001"), groupDiskImages},
+		{"dmg", header(4096, 4096-512, "koly"), groupDiskImages},
+		{"text", []byte("just some notes\n"), ""},
+		{"empty", nil, ""},
+	}
+
+	dir := t.TempDir()
+	for _, tt := range tests {
+		path := filepath.Join(dir, tt.name)
+		if err := os.WriteFile(path, tt.data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if got := sniffGroup(path); got != tt.want {
+			t.Errorf("sniffGroup(%s) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	if got := sniffGroup(filepath.Join(dir, "missing")); got != "" {
+		t.Errorf("sniffGroup(missing) = %q, want \"\"", got)
+	}
+}
+
+func TestGroupFilesByTypeSniffsUnknownNames(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, data []byte) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	backup := write("backup", header(64, 0, "\x1F\x8B\x08"))
+	misnamed := write("clip.bin", header(64, 0, "\x00\x00\x00\x18ftypmp42"))
+	notes := write("notes", []byte("plain text"))
+	named := write("movie.mp4", []byte("not really a video"))
+
+	findings := []assessment.Finding{{Path: backup}, {Path: misnamed}, {Path: notes}, {Path: named}}
+	groups := groupFilesByType(findings)
+
+	want := map[string]string{backup: groupArchives, misnamed: groupVideos, notes: "📄 Other", named: groupVideos}
+	for path, group := range want {
+		found := false
+		for _, f := range groups[group] {
+			found = found || f.Path == path
+		}
+		if !found {
+			t.Errorf("%s not in %q", filepath.Base(path), group)
+		}
+	}
+
+	// Past the display limit, files aren't opened
+	var many []assessment.Finding
+	for i := 0; i < sniffLimit; i++ {
+		many = append(many, assessment.Finding{Path: write(fmt.Sprintf("plain%d", i), []byte("text"))})
+	}
+	many = append(many, assessment.Finding{Path: backup})
+	if got := groupFilesByType(many); len(got[groupArchives]) != 0 {
+		t.Errorf("finding %d was sniffed, want only the first %d", sniffLimit+1, sniffLimit)
+	}
+}
@@ -0,0 +1,88 @@
+// Package events reports what a forge run does as it happens, one JSON
+// object per line, for GUIs and logging pipelines that follow along while
+// the usual UI runs.
+package events
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// Type names what happened
+type Type string
+
+const (
+	ScanStarted         Type = "scan_started"         // the tool started scanning
+	Progress            Type = "progress"             // the scan is still running
+	CategoryFound       Type = "category_found"       // a category was assessed and will be presented
+	SuggestionPresented Type = "suggestion_presented" // the user was shown something to act on
+	UserResponse        Type = "user_response"        // the user answered, or auto mode did for them
+	Deleted             Type = "deleted"              // a path was deleted, freeing Size
+	Undone              Type = "undone"               // the last deletion was taken back
+	Finished            Type = "finished"             // the run ended, with its exit code
+)
+
+// Event is one line of the stream. Fields that don't apply to its type are
+// left out.
+type Event struct {
+	Type       Type      `json:"type"`
+	Time       time.Time `json:"time"`
+	Tool       string    `json:"tool,omitempty"`
+	Category   string    `json:"category,omitempty"`
+	Path       string    `json:"path,omitempty"`
+	Size       int64     `json:"size,omitempty"`
+	Items      int       `json:"items,omitempty"`
+	Mode       string    `json:"mode,omitempty"`
+	Suggestion string    `json:"suggestion,omitempty"`
+	Response   string    `json:"response,omitempty"`
+	ElapsedMs  int64     `json:"elapsed_ms,omitempty"`
+	Freed      int64     `json:"freed,omitempty"`     // Finished: the bytes of every Deleted event not Undone
+	ExitCode   *int      `json:"exit_code,omitempty"` // Finished only, so 0 still shows
+}
+
+// Emitter writes events to a stream. A nil Emitter writes nothing, so
+// callers needn't check whether --events was given.
+type Emitter struct {
+	mu    sync.Mutex
+	enc   *json.Encoder
+	freed int64
+	now   func() time.Time
+}
+
+// New returns an emitter writing to w
+func New(w io.Writer) *Emitter {
+	return &Emitter{enc: json.NewEncoder(w), now: time.Now}
+}
+
+// Emit writes ev, stamping it with the time if it has none. A failed write
+// is dropped: the stream is a record of the run, not part of it.
+func (e *Emitter) Emit(ev Event) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if ev.Time.IsZero() {
+		ev.Time = e.now()
+	}
+	switch ev.Type {
+	case Deleted:
+		e.freed += ev.Size
+	case Undone:
+		e.freed -= ev.Size
+	}
+	e.enc.Encode(ev)
+}
+
+// Finish writes the Finished event for a run ending with code
+func (e *Emitter) Finish(code int) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	freed := e.freed
+	e.mu.Unlock()
+	e.Emit(Event{Type: Finished, Freed: freed, ExitCode: &code})
+}
@@ -0,0 +1,38 @@
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestFinishTotalsDeleted(t *testing.T) {
+	var buf bytes.Buffer
+	e := New(&buf)
+	e.Emit(Event{Type: Deleted, Category: "Cache Directories", Size: 300})
+	e.Emit(Event{Type: UserResponse, Category: "Downloads", Size: 5000, Response: "reject"})
+	e.Emit(Event{Type: Deleted, Path: "/home/u/a.dmg", Size: 200})
+	e.Finish(0)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("got %d lines, want 4:\n%s", len(lines), buf.String())
+	}
+	var last Event
+	if err := json.Unmarshal([]byte(lines[3]), &last); err != nil {
+		t.Fatal(err)
+	}
+	if last.Type != Finished || last.Freed != 500 || last.ExitCode == nil || *last.ExitCode != 0 {
+		t.Errorf("last event = %s, want finished with freed 500 and exit_code 0", lines[3])
+	}
+	if !strings.Contains(lines[3], `"exit_code":0`) {
+		t.Errorf("finished event %s leaves out exit code 0", lines[3])
+	}
+}
+
+func TestNilEmitterWritesNothing(t *testing.T) {
+	var e *Emitter
+	e.Emit(Event{Type: Progress})
+	e.Finish(1)
+}
@@ -2,4 +2,12 @@ module forge
 
 go 1.25.5
 
-require gopkg.in/yaml.v3 v3.0.1
+require (
+	forge-shared v0.0.0
+	golang.org/x/term v0.40.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require golang.org/x/sys v0.41.0 // indirect
+
+replace forge-shared => ../forge-shared
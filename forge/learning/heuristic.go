@@ -0,0 +1,182 @@
+package learning
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+
+	"forge/rules"
+	"forge/session"
+)
+
+// Decision rates at which ReflectHeuristic proposes a change
+const (
+	raiseAcceptRate = 0.9
+	lowerRejectRate = 0.7
+)
+
+// Ratings that make reflection cautious: with enough rated sessions
+// averaging this low, nothing is made more automatic and, in
+// ReflectHeuristic, rules the user pushes back on half the time lose
+// confidence
+const (
+	minRatings         = 3
+	lowSatisfaction    = 2.5
+	cautiousRejectRate = 0.5
+)
+
+// tally counts decisions on items matching one rule pattern
+type tally struct {
+	rule     string
+	pattern  string
+	accepted int
+	rejected int
+	decided  int
+}
+
+// ReflectHeuristic proposes calibrations from sessions by counting, without
+// the LLM. A pattern whose items were nearly always accepted gains a level
+// of confidence, and one mostly rejected loses one, unless the sessions'
+// ratings are low, when forge only grows more careful.
+func (l *Learner) ReflectHeuristic(sessions []*session.Session) (*ReflectionResult, error) {
+	if len(sessions) < minSessions {
+		return nil, fmt.Errorf("not enough sessions for reflection (need %d, have %d)", minSessions, len(sessions))
+	}
+
+	result := &ReflectionResult{}
+	result.AnalysisSummary.SessionsAnalyzed = len(sessions)
+
+	tallies := map[string]*tally{}
+	accepted, decided := 0, 0
+	for _, s := range sessions {
+		result.AnalysisSummary.TotalInteractions += len(s.Interactions)
+		for _, i := range s.Interactions {
+			ok, counted := decision(i.UserResponse)
+			if !counted {
+				continue
+			}
+			decided++
+			if ok {
+				accepted++
+			}
+
+			rule, pattern := l.patternFor(i.Item)
+			if pattern == "" {
+				continue
+			}
+			t := tallies[pattern]
+			if t == nil {
+				t = &tally{rule: rule, pattern: pattern}
+				tallies[pattern] = t
+			}
+			t.decided++
+			if ok {
+				t.accepted++
+			} else if i.UserResponse == "reject" {
+				t.rejected++
+			}
+		}
+	}
+	if decided > 0 {
+		result.AnalysisSummary.OverallAcceptanceRate = float64(accepted) / float64(decided)
+	}
+	result.noteRatings(sessions)
+	cautious := result.Cautious()
+	if cautious {
+		result.Insights = fmt.Sprintf("Recent sessions were rated %.1f/5 on average, so nothing is made more automatic for now.",
+			result.AnalysisSummary.AverageSatisfaction)
+	}
+
+	patterns := make([]string, 0, len(tallies))
+	for pattern := range tallies {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+
+	for _, pattern := range patterns {
+		if cal, ok := l.propose(tallies[pattern], cautious); ok {
+			result.Calibrations = append(result.Calibrations, cal)
+		}
+	}
+	return result, nil
+}
+
+// decision reports whether a response accepted the suggestion, and whether
+// it was a decision at all, the way Session.AcceptanceRate counts them
+func decision(response string) (accepted, counted bool) {
+	switch response {
+	case "accept", "auto_accepted", "modify":
+		return true, true
+	case "reject", "skip":
+		return false, true
+	}
+	return false, false
+}
+
+// patternFor finds the merged rule and pattern an item's name matches,
+// checking rules in name order so the answer doesn't vary between runs
+func (l *Learner) patternFor(item string) (rule, pattern string) {
+	if item == "" {
+		return "", ""
+	}
+	for _, name := range slices.Sorted(maps.Keys(l.Rules.Merged)) {
+		for _, p := range l.Rules.Merged[name].Patterns {
+			if rules.MatchPath(item, p, "") {
+				return name, p
+			}
+		}
+	}
+	return "", ""
+}
+
+// propose turns a tally into a calibration, if the rates call for one. A
+// cautious proposal never raises confidence and lowers it sooner.
+func (l *Learner) propose(t *tally, cautious bool) (ProposedCalibration, bool) {
+	var cal ProposedCalibration
+	if t.decided < minObservations {
+		return cal, false
+	}
+
+	merged := l.Rules.Merged[t.rule]
+	current := rules.Level(merged.EffectiveConf)
+	acceptRate := float64(t.accepted) / float64(t.decided)
+	rejectRate := float64(t.rejected) / float64(t.decided)
+
+	cal.RuleID = t.rule
+	cal.Pattern = t.pattern
+	cal.CurrentConfidence = merged.EffectiveConf
+	cal.CurrentAction = merged.EffectiveAction
+	cal.Evidence.Observations = t.decided
+	cal.Evidence.AcceptRate = acceptRate
+	cal.Evidence.RejectRate = rejectRate
+
+	lowerAt := lowerRejectRate
+	if cautious {
+		lowerAt = cautiousRejectRate
+	}
+
+	switch {
+	case acceptRate >= raiseAcceptRate && !cautious:
+		cal.ProposedConfidence = string(current.Higher())
+		if cal.ProposedConfidence == string(rules.LevelVeryHigh) && merged.EffectiveAction == "suggest_delete" {
+			cal.ProposedAction = "auto_delete"
+		}
+		cal.Rationale = fmt.Sprintf("accepted %d of %d times", t.accepted, t.decided)
+		cal.ConfidenceInProposal = acceptRate
+	case rejectRate >= lowerAt:
+		cal.ProposedConfidence = string(current.Lower())
+		if merged.EffectiveAction == "auto_delete" {
+			cal.ProposedAction = "suggest_delete"
+		}
+		cal.Rationale = fmt.Sprintf("rejected %d of %d times", t.rejected, t.decided)
+		cal.ConfidenceInProposal = rejectRate
+	default:
+		return cal, false
+	}
+
+	if cal.ProposedConfidence == cal.CurrentConfidence && cal.ProposedAction == "" {
+		return cal, false
+	}
+	return cal, true
+}
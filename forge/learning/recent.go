@@ -0,0 +1,97 @@
+package learning
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"forge/rules"
+)
+
+// Event is one change to how forge behaves: a calibration it learned or a
+// preference the user gave it
+type Event struct {
+	At   time.Time
+	What string // Completes "I learned to ...", e.g. "auto-delete *.dmg in Downloads"
+}
+
+// RecentEvents returns what was learned after since, newest first.
+// Preferences only record the day they were added, so any from since's day
+// count as recent.
+func RecentEvents(rs *rules.RuleSet, since time.Time) []Event {
+	var events []Event
+
+	for _, cal := range rs.Calibrations.Adjustments {
+		at, err := time.Parse(time.RFC3339, cal.LearnedAt)
+		if err != nil || !at.After(since) {
+			continue
+		}
+		events = append(events, Event{At: at, What: describeCalibration(cal)})
+	}
+
+	day := time.Date(since.Year(), since.Month(), since.Day(), 0, 0, 0, 0, time.Local)
+	prefs := []struct {
+		verb  string
+		prefs []rules.Preference
+	}{
+		{"always delete", rs.Preferences.AlwaysDelete},
+		{"never delete", rs.Preferences.NeverDelete},
+		{"always ask about", rs.Preferences.AlwaysAsk},
+	}
+	for _, p := range prefs {
+		for _, pref := range p.prefs {
+			at, err := time.ParseInLocation("2006-01-02", pref.Added, time.Local)
+			if err != nil || at.Before(day) {
+				continue
+			}
+			events = append(events, Event{At: at, What: p.verb + " " + where(pref.Pattern, pref.Location)})
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].At.After(events[j].At)
+	})
+	return events
+}
+
+// LearningNote sums up events in one line for the end of a run, or returns
+// "" if nothing was learned
+func LearningNote(events []Event) string {
+	if len(events) == 0 {
+		return ""
+	}
+	note := "I learned to " + events[0].What
+	if more := len(events) - 1; more > 0 {
+		note += fmt.Sprintf(" (and %d more; see 'forge review')", more)
+	}
+	return note
+}
+
+// describeCalibration says what a calibration changed, as "I learned to" would
+func describeCalibration(cal rules.Calibration) string {
+	target := where(cal.Pattern, cal.Location)
+	if cal.Calibrated.Action == "" || cal.Calibrated.Action == cal.Original.Action {
+		return fmt.Sprintf("rate %s as %s confidence", target, strings.ReplaceAll(cal.Calibrated.Confidence, "_", " "))
+	}
+	switch cal.Calibrated.Action {
+	case "auto_delete":
+		return "auto-delete " + target
+	case "suggest_delete":
+		return "suggest deleting " + target
+	case "never_delete":
+		return "never delete " + target
+	case "inform_only":
+		return "only mention " + target
+	default:
+		return fmt.Sprintf("treat %s as %s", target, strings.ReplaceAll(cal.Calibrated.Action, "_", " "))
+	}
+}
+
+// where is a pattern and, if it has one, its location
+func where(pattern, location string) string {
+	if location == "" {
+		return pattern
+	}
+	return pattern + " in " + location
+}
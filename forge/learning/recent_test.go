@@ -0,0 +1,52 @@
+package learning
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"forge/rules"
+)
+
+func TestRecentEvents(t *testing.T) {
+	lastRun := time.Date(2026, 3, 10, 15, 0, 0, 0, time.Local)
+	calibration := func(pattern, location, action, learnedAt string) rules.Calibration {
+		var cal rules.Calibration
+		cal.Pattern, cal.Location, cal.LearnedAt = pattern, location, learnedAt
+		cal.Original.Action, cal.Original.Confidence = "suggest_delete", "high"
+		cal.Calibrated.Action, cal.Calibrated.Confidence = action, "very_high"
+		return cal
+	}
+
+	rs := &rules.RuleSet{}
+	rs.Calibrations.Adjustments = []rules.Calibration{
+		calibration("*.iso", "", "auto_delete", lastRun.Add(-48*time.Hour).Format(time.RFC3339)), // before the last run
+		calibration("*.dmg", "Downloads", "auto_delete", lastRun.Add(2*time.Hour).Format(time.RFC3339)),
+		calibration("node_modules", "", "suggest_delete", lastRun.Add(time.Hour).Format(time.RFC3339)),
+		calibration("*.zip", "", "auto_delete", "not a time"),
+	}
+	rs.Preferences.NeverDelete = []rules.Preference{
+		{Pattern: "*.mov", Added: "2026-03-11"},
+		{Pattern: "*.psd", Added: "2026-03-01"},
+	}
+
+	var got []string
+	for _, e := range RecentEvents(rs, lastRun) {
+		got = append(got, e.What)
+	}
+	want := []string{
+		"never delete *.mov",
+		"auto-delete *.dmg in Downloads",
+		"rate node_modules as very high confidence",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("RecentEvents() = %q, want %q", got, want)
+	}
+
+	if note := LearningNote(RecentEvents(rs, lastRun)); note != "I learned to never delete *.mov (and 2 more; see 'forge review')" {
+		t.Errorf("LearningNote() = %q", note)
+	}
+	if note := LearningNote(nil); note != "" {
+		t.Errorf("LearningNote(nil) = %q, want nothing", note)
+	}
+}
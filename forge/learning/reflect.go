@@ -3,13 +3,16 @@ package learning
 import (
 	"encoding/json"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
 	"forge/llm"
 	"forge/rules"
+	"forge/scan"
 	"forge/session"
 )
 
@@ -19,12 +22,37 @@ type ReflectionResult struct {
 		SessionsAnalyzed      int     `json:"sessions_analyzed"`
 		TotalInteractions     int     `json:"total_interactions"`
 		OverallAcceptanceRate float64 `json:"overall_acceptance_rate"`
+		AverageSatisfaction   float64 `json:"average_satisfaction,omitempty"` // of the rated sessions, 1-5
+		RatedSessions         int     `json:"rated_sessions,omitempty"`
 	} `json:"analysis_summary"`
 	Calibrations []ProposedCalibration `json:"calibrations"`
 	NewRules     []ProposedRule        `json:"new_rules"`
 	Insights     string                `json:"insights"`
 }
 
+// noteRatings fills in how the sessions reflected on were rated, whatever
+// the LLM said about it
+func (r *ReflectionResult) noteRatings(sessions []*session.Session) {
+	ratings, sum := 0, 0
+	for _, s := range sessions {
+		if rating := s.Outcome.UserSatisfaction; rating != nil {
+			ratings++
+			sum += *rating
+		}
+	}
+	r.AnalysisSummary.RatedSessions = ratings
+	r.AnalysisSummary.AverageSatisfaction = 0
+	if ratings > 0 {
+		r.AnalysisSummary.AverageSatisfaction = float64(sum) / float64(ratings)
+	}
+}
+
+// Cautious reports whether enough sessions were rated low that nothing
+// should be made more automatic
+func (r *ReflectionResult) Cautious() bool {
+	return r.AnalysisSummary.RatedSessions >= minRatings && r.AnalysisSummary.AverageSatisfaction <= lowSatisfaction
+}
+
 // ProposedCalibration is a suggested adjustment to a rule
 type ProposedCalibration struct {
 	RuleID             string  `json:"rule_id"`
@@ -54,6 +82,35 @@ type ProposedRule struct {
 	Rationale string `json:"rationale"`
 }
 
+// Change describes how applying a calibration would alter the ruleset
+type Change struct {
+	Rules            []string // merged rules containing the pattern, if any
+	Pattern          string
+	Location         string
+	BeforeConfidence string
+	AfterConfidence  string
+	BeforeAction     string
+	AfterAction      string
+	Observations     int
+	AcceptRate       float64
+	Rationale        string
+}
+
+// Loosens reports whether the change trusts a rule more: a higher
+// confidence, or deleting without asking
+func (c Change) Loosens() bool {
+	if rules.ParseLevel(c.AfterConfidence).Score() > rules.ParseLevel(c.BeforeConfidence).Score() {
+		return true
+	}
+	return c.AfterAction == "auto_delete" && c.BeforeAction != "auto_delete"
+}
+
+// minSessions is how many sessions reflection needs before it says anything
+const minSessions = 5
+
+// minObservations is how often a pattern must come up before it's calibrated
+const minObservations = 5
+
 // Learner handles the reflection and learning process
 type Learner struct {
 	Rules  *rules.RuleSet
@@ -84,13 +141,20 @@ func (l *Learner) Reflect() (*ReflectionResult, error) {
 	if err != nil {
 		return nil, err
 	}
+	return l.ReflectOn(sessions)
+}
 
-	if len(sessions) < 5 {
-		return nil, fmt.Errorf("not enough sessions for reflection (need 5, have %d)", len(sessions))
+// ReflectOn asks the LLM what sessions suggest about the rules
+func (l *Learner) ReflectOn(sessions []*session.Session) (*ReflectionResult, error) {
+	if len(sessions) < minSessions {
+		return nil, fmt.Errorf("not enough sessions for reflection (need %d, have %d)", minSessions, len(sessions))
 	}
 
 	// Build prompt
-	prompt := l.buildReflectionPrompt(sessions)
+	prompt, err := l.reflectionPrompt(sessions)
+	if err != nil {
+		return nil, err
+	}
 
 	// Get LLM analysis
 	response, err := l.Client.Generate(prompt)
@@ -106,42 +170,84 @@ func (l *Learner) Reflect() (*ReflectionResult, error) {
 			Insights: response,
 		}, nil
 	}
+	result.noteRatings(sessions)
 
 	return result, nil
 }
 
-// ApplyCalibrations applies proposed calibrations that meet the threshold
-func (l *Learner) ApplyCalibrations(result *ReflectionResult) ([]string, error) {
-	var applied []string
+// PreviewCalibrations returns the changes ApplyCalibrations would make,
+// using the same thresholds, without modifying or saving the ruleset. While
+// the result is Cautious, changes that loosen a rule are left out.
+func (l *Learner) PreviewCalibrations(result *ReflectionResult) []Change {
+	var changes []Change
 
 	for _, cal := range result.Calibrations {
-		// Only apply if confidence is high enough
-		if cal.ConfidenceInProposal < 0.7 {
+		if !meetsThreshold(cal) {
 			continue
 		}
 
-		// Only apply if enough observations
-		if cal.Evidence.Observations < 5 {
+		change := Change{
+			Rules:            l.Rules.RulesMatching(cal.Pattern),
+			Pattern:          cal.Pattern,
+			Location:         cal.Location,
+			BeforeConfidence: cal.CurrentConfidence,
+			BeforeAction:     cal.CurrentAction,
+			Observations:     cal.Evidence.Observations,
+			AcceptRate:       cal.Evidence.AcceptRate,
+			Rationale:        cal.Rationale,
+		}
+
+		// Prefer what the ruleset actually says over what the LLM believes
+		if len(change.Rules) > 0 {
+			merged := l.Rules.Merged[change.Rules[0]]
+			change.BeforeConfidence = merged.EffectiveConf
+			change.BeforeAction = merged.EffectiveAction
+		}
+
+		// Empty proposals leave the current value in place, as merge does
+		change.AfterConfidence = change.BeforeConfidence
+		// The LLM may spell levels its own way ("Very High"); rules use the canonical
+		// form, and a proposal that isn't a level at all is ignored
+		if level := rules.ParseLevel(cal.ProposedConfidence); level != "" {
+			change.AfterConfidence = string(level)
+		}
+		change.AfterAction = change.BeforeAction
+		if cal.ProposedAction != "" {
+			change.AfterAction = cal.ProposedAction
+		}
+
+		if result.Cautious() && change.Loosens() {
 			continue
 		}
+		changes = append(changes, change)
+	}
 
+	return changes
+}
+
+// ApplyCalibrations applies proposed calibrations that meet the threshold,
+// merging each into any calibration already kept for its pattern
+func (l *Learner) ApplyCalibrations(result *ReflectionResult) ([]string, error) {
+	var applied []string
+
+	for _, change := range l.PreviewCalibrations(result) {
 		// Create calibration entry
 		newCal := rules.Calibration{
-			ID:       fmt.Sprintf("cal_%d", time.Now().Unix()),
-			Pattern:  cal.Pattern,
-			Location: cal.Location,
-			Reason:   cal.Rationale,
+			ID:        fmt.Sprintf("cal_%d", time.Now().Unix()),
+			Pattern:   change.Pattern,
+			Location:  change.Location,
+			Reason:    change.Rationale,
 			LearnedAt: time.Now().Format(time.RFC3339),
 		}
-		newCal.Original.Confidence = cal.CurrentConfidence
-		newCal.Original.Action = cal.CurrentAction
-		newCal.Calibrated.Confidence = cal.ProposedConfidence
-		newCal.Calibrated.Action = cal.ProposedAction
-		newCal.Evidence.Observations = cal.Evidence.Observations
-		newCal.Evidence.AcceptRate = cal.Evidence.AcceptRate
-
-		l.Rules.Calibrations.Adjustments = append(l.Rules.Calibrations.Adjustments, newCal)
-		applied = append(applied, cal.Pattern)
+		newCal.Original.Confidence = change.BeforeConfidence
+		newCal.Original.Action = change.BeforeAction
+		newCal.Calibrated.Confidence = change.AfterConfidence
+		newCal.Calibrated.Action = change.AfterAction
+		newCal.Evidence.Observations = change.Observations
+		newCal.Evidence.AcceptRate = change.AcceptRate
+
+		l.Rules.Calibrations.Add(newCal)
+		applied = append(applied, change.Pattern)
 	}
 
 	// Update metadata
@@ -160,6 +266,42 @@ func (l *Learner) ApplyCalibrations(result *ReflectionResult) ([]string, error)
 	return applied, nil
 }
 
+// meetsThreshold reports whether a proposed calibration has enough support to apply
+func meetsThreshold(cal ProposedCalibration) bool {
+	// Only apply if confidence is high enough
+	if cal.ConfidenceInProposal < 0.7 {
+		return false
+	}
+
+	// Only apply if enough observations
+	return cal.Evidence.Observations >= minObservations
+}
+
+// ReflectionPrompt is the template in llm.PromptDir that replaces the
+// built-in reflection prompt
+const ReflectionPrompt = "reflection.tmpl"
+
+// PromptData is what a reflection prompt template is rendered with
+type PromptData struct {
+	Sessions []*session.Session // Newest first
+	Rules    *rules.RuleSet
+	Default  string // The built-in prompt, for templates that only add to it
+}
+
+// reflectionPrompt is the user's reflection template if they have one, and
+// the built-in prompt otherwise
+func (l *Learner) reflectionPrompt(sessions []*session.Session) (string, error) {
+	prompt := l.buildReflectionPrompt(sessions)
+	custom, ok, err := llm.CustomPrompt(ReflectionPrompt, PromptData{Sessions: sessions, Rules: l.Rules, Default: prompt})
+	if err != nil || !ok {
+		return prompt, err
+	}
+	return custom, nil
+}
+
+// buildReflectionPrompt describes the rules and sessions within the
+// client's prompt budget; if the sessions don't all fit, those with the most
+// interactions are kept, and the newest of equals
 func (l *Learner) buildReflectionPrompt(sessions []*session.Session) string {
 	var sb strings.Builder
 
@@ -169,7 +311,8 @@ CURRENT RULES:
 `)
 
 	// Add current rules
-	for name, rule := range l.Rules.Base.Categories {
+	for _, name := range slices.Sorted(maps.Keys(l.Rules.Base.Categories)) {
+		rule := l.Rules.Base.Categories[name]
 		sb.WriteString(fmt.Sprintf("- %s: confidence=%s, risk=%s, action=%s\n",
 			name, rule.Confidence, rule.Risk, rule.DefaultAction))
 	}
@@ -177,15 +320,42 @@ CURRENT RULES:
 	sb.WriteString("\nRECENT SESSIONS:\n")
 
 	// Add session summaries
+	var sections []llm.Section
 	for _, s := range sessions {
-		sb.WriteString(fmt.Sprintf("\nSession %s (%s):\n", s.ID, s.Tool))
+		var text strings.Builder
+		if rating := s.Outcome.UserSatisfaction; rating != nil {
+			text.WriteString(fmt.Sprintf("\nSession %s (%s, rated %d/5 by the user):\n", s.ID, s.Tool, *rating))
+		} else {
+			text.WriteString(fmt.Sprintf("\nSession %s (%s):\n", s.ID, s.Tool))
+		}
 		for _, i := range s.Interactions {
-			sb.WriteString(fmt.Sprintf("  - %s: suggested=%s, response=%s\n",
+			text.WriteString(fmt.Sprintf("  - %s: suggested=%s, response=%s\n",
 				i.Category, i.Suggestion, i.UserResponse))
 		}
+		sections = append(sections, llm.Section{Text: text.String(), Priority: len(s.Interactions)})
+	}
+
+	budget := l.Client.Budget()
+	if budget > 0 {
+		budget = max(budget-sb.Len()-len(reflectionTasks)-len(sampledSessions), 1)
 	}
+	kept, dropped := llm.Fit(sections, budget)
+	for _, text := range kept {
+		sb.WriteString(text)
+	}
+	if dropped > 0 {
+		sb.WriteString(fmt.Sprintf(sampledSessions, len(kept), len(sections)))
+	}
+
+	sb.WriteString(reflectionTasks)
+	return sb.String()
+}
+
+// sampledSessions tells the LLM that sessions were left out to fit the prompt
+const sampledSessions = "\n(Sampled: %d of %d sessions are listed, those with the most interactions, to keep this prompt short. Counts below come from these alone.)\n"
 
-	sb.WriteString(`
+// reflectionTasks ends the reflection prompt
+const reflectionTasks = `
 
 ANALYSIS TASKS:
 
@@ -193,6 +363,7 @@ ANALYSIS TASKS:
 2. Identify rules where user behavior diverges from expectations (>20% difference)
 3. Look for contextual patterns (same type, different behavior by location)
 4. Propose specific calibration adjustments
+5. Where sessions are rated 1-2 out of 5, the user was unhappy: prefer more cautious confidence and actions
 
 OUTPUT as JSON:
 {
@@ -223,10 +394,7 @@ OUTPUT as JSON:
 CONSTRAINTS:
 - Only propose calibrations with >= 5 observations
 - Be conservative - when uncertain, don't change
-`)
-
-	return sb.String()
-}
+`
 
 func parseReflectionResponse(response string) (*ReflectionResult, error) {
 	// Try to extract JSON from response
@@ -264,6 +432,45 @@ func logLearning(applied []string, result *ReflectionResult) {
 	f.WriteString(entry)
 }
 
+// PreferencePreview describes what a new preference would cover on disk right now
+type PreferencePreview struct {
+	Matches           int
+	TotalSize         int64
+	NeedsConfirmation bool // surprisingly broad for an always_delete preference
+}
+
+// Thresholds above which an always_delete preference must be confirmed
+const (
+	confirmMatches = 1000
+	confirmBytes   = 10 << 30 // 10 GB
+)
+
+// PreviewPreference counts what pattern and location match in listing,
+// using the same matcher the ruleset applies
+func (l *Learner) PreviewPreference(prefType, pattern, location string, listing *scan.Result) PreferencePreview {
+	sizes := make(map[string]int64, len(listing.Entries))
+	for _, e := range listing.Entries {
+		sizes[e.Path] = e.Size
+	}
+
+	var preview PreferencePreview
+	for _, path := range rules.MatchPaths(listing.Paths(), pattern, location) {
+		preview.Matches++
+		preview.TotalSize += sizes[path]
+	}
+	preview.NeedsConfirmation = needsConfirmation(prefType, preview.Matches, preview.TotalSize)
+	return preview
+}
+
+// needsConfirmation reports whether adding a preference this broad should be confirmed.
+// Only always_delete can cause deletions, so the others never need it.
+func needsConfirmation(prefType string, matches int, totalSize int64) bool {
+	if prefType != "always_delete" {
+		return false
+	}
+	return matches >= confirmMatches || totalSize >= confirmBytes
+}
+
 // AddPreference adds an explicit user preference
 func (l *Learner) AddPreference(prefType, pattern, location, reason string) error {
 	pref := rules.Preference{
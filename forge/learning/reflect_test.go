@@ -0,0 +1,371 @@
+package learning
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"forge/llm"
+	"forge/rules"
+	"forge/scan"
+	"forge/session"
+)
+
+func proposal(pattern, conf, action string, observations int, certainty float64) ProposedCalibration {
+	p := ProposedCalibration{
+		Pattern:              pattern,
+		CurrentConfidence:    "high",
+		ProposedConfidence:   conf,
+		CurrentAction:        "suggest_delete",
+		ProposedAction:       action,
+		ConfidenceInProposal: certainty,
+		Rationale:            "test",
+	}
+	p.Evidence.Observations = observations
+	p.Evidence.AcceptRate = 0.9
+	return p
+}
+
+func TestPreviewMatchesApply(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	rs, err := rules.Load()
+	if err != nil {
+		t.Fatalf("rules.Load() error = %v", err)
+	}
+	l := NewLearner(rs, nil)
+
+	result := &ReflectionResult{
+		Calibrations: []ProposedCalibration{
+			proposal("node_modules", "very_high", "auto_delete", 12, 0.9),
+			proposal("*.dmg", "", "auto_delete", 8, 0.8),
+			proposal("target", "low", "ask_first", 3, 0.9), // too few observations
+			proposal("*.mov", "low", "ask_first", 20, 0.5), // not confident enough
+		},
+	}
+
+	preview := l.PreviewCalibrations(result)
+	if len(rs.Calibrations.Adjustments) != 0 {
+		t.Fatalf("PreviewCalibrations() modified the ruleset")
+	}
+
+	if len(preview) != 2 {
+		t.Fatalf("PreviewCalibrations() returned %d changes, want 2", len(preview))
+	}
+	if got := preview[1]; got.BeforeConfidence != "medium" || got.AfterConfidence != "medium" {
+		t.Errorf("*.dmg confidence = %s → %s, want medium → medium (from installers rule)",
+			got.BeforeConfidence, got.AfterConfidence)
+	}
+
+	applied, err := l.ApplyCalibrations(result)
+	if err != nil {
+		t.Fatalf("ApplyCalibrations() error = %v", err)
+	}
+	if len(applied) != len(preview) {
+		t.Fatalf("applied %d calibrations, preview showed %d", len(applied), len(preview))
+	}
+
+	for i, change := range preview {
+		cal := rs.Calibrations.Adjustments[i]
+		if cal.Pattern != change.Pattern ||
+			cal.Original.Confidence != change.BeforeConfidence ||
+			cal.Original.Action != change.BeforeAction ||
+			cal.Calibrated.Confidence != change.AfterConfidence ||
+			cal.Calibrated.Action != change.AfterAction {
+			t.Errorf("applied %+v does not match preview %+v", cal, change)
+		}
+	}
+}
+
+func TestApplyingTwiceMergesCalibration(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	rs, err := rules.Load()
+	if err != nil {
+		t.Fatalf("rules.Load() error = %v", err)
+	}
+	l := NewLearner(rs, nil)
+	result := &ReflectionResult{
+		Calibrations: []ProposedCalibration{proposal("node_modules", "very_high", "auto_delete", 12, 0.9)},
+	}
+
+	for range 2 {
+		if _, err := l.ApplyCalibrations(result); err != nil {
+			t.Fatalf("ApplyCalibrations() error = %v", err)
+		}
+	}
+
+	reloaded, err := rules.Load()
+	if err != nil {
+		t.Fatalf("rules.Load() error = %v", err)
+	}
+	if n := len(reloaded.Calibrations.Adjustments); n != 1 {
+		t.Fatalf("applying the same calibration twice kept %d entries, want 1", n)
+	}
+	cal := reloaded.Calibrations.Adjustments[0]
+	if cal.Evidence.Observations != 24 {
+		t.Errorf("merged observations = %d, want 24", cal.Evidence.Observations)
+	}
+	if cal.Original.Confidence != "high" || cal.Calibrated.Confidence != "very_high" {
+		t.Errorf("merged calibration = %s → %s, want high → very_high",
+			cal.Original.Confidence, cal.Calibrated.Confidence)
+	}
+}
+
+func TestNeedsConfirmation(t *testing.T) {
+	tests := []struct {
+		prefType  string
+		matches   int
+		totalSize int64
+		want      bool
+	}{
+		{"always_delete", 0, 0, false},
+		{"always_delete", 12, 3 << 20, false},
+		{"always_delete", 999, 1 << 30, false},
+		{"always_delete", 1000, 1 << 20, true},
+		{"always_delete", 3, 12 << 30, true},
+		{"never_delete", 50000, 100 << 30, false},
+		{"always_ask", 50000, 100 << 30, false},
+	}
+
+	for _, tt := range tests {
+		if got := needsConfirmation(tt.prefType, tt.matches, tt.totalSize); got != tt.want {
+			t.Errorf("needsConfirmation(%q, %d, %d) = %v, want %v", tt.prefType, tt.matches, tt.totalSize, got, tt.want)
+		}
+	}
+}
+
+func TestPreviewPreferenceCountsAndWarns(t *testing.T) {
+	listing := &scan.Result{Root: "/home/u"}
+	for i := 0; i < 1500; i++ {
+		listing.Entries = append(listing.Entries, scan.Entry{Path: fmt.Sprintf("/home/u/logs/app%d.log", i), Size: 100})
+	}
+	listing.Entries = append(listing.Entries, scan.Entry{Path: "/home/u/notes.txt", Size: 100})
+
+	learner := NewLearner(&rules.RuleSet{}, nil)
+
+	got := learner.PreviewPreference("always_delete", "*.log", "", listing)
+	if got.Matches != 1500 || got.TotalSize != 150000 || !got.NeedsConfirmation {
+		t.Errorf("PreviewPreference(*.log) = %+v, want 1500 matches, 150000 bytes, confirmation", got)
+	}
+
+	got = learner.PreviewPreference("always_delete", "*.txt", "", listing)
+	if got.Matches != 1 || got.NeedsConfirmation {
+		t.Errorf("PreviewPreference(*.txt) = %+v, want 1 match without confirmation", got)
+	}
+}
+
+func TestDiffReflectsAppliedCalibration(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	rs, err := rules.Load()
+	if err != nil {
+		t.Fatalf("rules.Load() error = %v", err)
+	}
+	if diff := rs.Diff(); len(diff) != 0 {
+		t.Fatalf("Diff() on base rules = %+v, want none", diff)
+	}
+
+	l := NewLearner(rs, nil)
+	result := &ReflectionResult{
+		Calibrations: []ProposedCalibration{
+			proposal("node_modules", "very_high", "auto_delete", 12, 0.9),
+			proposal("*.dmg", "", "auto_delete", 8, 0.8),
+		},
+	}
+	if _, err := l.ApplyCalibrations(result); err != nil {
+		t.Fatalf("ApplyCalibrations() error = %v", err)
+	}
+
+	// Reload to check what was saved, not just what's in memory
+	rs, err = rules.Load()
+	if err != nil {
+		t.Fatalf("rules.Load() error = %v", err)
+	}
+
+	want := []rules.RuleChange{
+		{
+			Rule:                "installers",
+			BaseConfidence:      "medium",
+			EffectiveConfidence: "medium",
+			BaseAction:          "suggest_delete",
+			EffectiveAction:     "auto_delete",
+			Pattern:             "*.dmg",
+			Reason:              "test",
+		},
+		{
+			Rule:                "node_modules",
+			BaseConfidence:      "high",
+			EffectiveConfidence: "very_high",
+			BaseAction:          "suggest_delete",
+			EffectiveAction:     "auto_delete",
+			Pattern:             "node_modules",
+			Reason:              "test",
+		},
+	}
+
+	got := rs.Diff()
+	if len(got) != len(want) {
+		t.Fatalf("Diff() = %+v, want %d changes", got, len(want))
+	}
+	for i := range want {
+		if got[i].LearnedAt == "" {
+			t.Errorf("Diff()[%d].LearnedAt is empty", i)
+		}
+		got[i].LearnedAt = ""
+		if got[i] != want[i] {
+			t.Errorf("Diff()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestCustomReflectionPrompt(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	rs, err := rules.Load()
+	if err != nil {
+		t.Fatalf("rules.Load() error = %v", err)
+	}
+	l := NewLearner(rs, nil)
+	sessions := []*session.Session{
+		{ID: "2026-09-02_1000", Tool: "forge-dust"},
+		{ID: "2026-09-01_1000", Tool: "forge-habits"},
+	}
+
+	builtin, err := l.reflectionPrompt(sessions)
+	if err != nil || builtin != l.buildReflectionPrompt(sessions) {
+		t.Fatalf("reflectionPrompt() without a template = %q, %v, want the built-in prompt", builtin, err)
+	}
+
+	if err := os.MkdirAll(llm.PromptDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	tmpl := "Answer in French.\n{{range .Sessions}}{{.ID}} {{.Tool}}\n{{end}}" +
+		"node_modules: {{(index .Rules.Merged \"node_modules\").EffectiveConf}}\n{{.Default}}"
+	if err := os.WriteFile(filepath.Join(llm.PromptDir(), ReflectionPrompt), []byte(tmpl), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := l.reflectionPrompt(sessions)
+	if err != nil {
+		t.Fatalf("reflectionPrompt() error = %v", err)
+	}
+	want := "Answer in French.\n2026-09-02_1000 forge-dust\n2026-09-01_1000 forge-habits\nnode_modules: high\n" + builtin
+	if got != want {
+		t.Errorf("reflectionPrompt() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestLowSatisfactionMakesReflectionCautious(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	sessions, err := session.LoadDir(filepath.Join("..", "testdata", "simulate"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs, err := rules.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	learner := NewLearner(rs, nil)
+
+	changes := func() []string {
+		t.Helper()
+		result, err := learner.ReflectHeuristic(sessions)
+		if err != nil {
+			t.Fatalf("ReflectHeuristic() error = %v", err)
+		}
+		var got []string
+		for _, c := range result.Calibrations {
+			got = append(got, c.Pattern+" "+c.ProposedConfidence)
+		}
+		return got
+	}
+
+	if got := changes(); len(got) != 2 {
+		t.Fatalf("ReflectHeuristic() unrated = %q, want the node_modules raise and the *.dmg lower", got)
+	}
+
+	for i, s := range sessions {
+		rating := 1 + i%2
+		s.Outcome.UserSatisfaction = &rating
+	}
+	got := changes()
+	if want := []string{"*.dmg low"}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("ReflectHeuristic() rated 1-2 = %q, want only %q", got, want)
+	}
+}
+
+func TestLowSatisfactionHoldsBackLooseningCalibrations(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	rs, err := rules.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := NewLearner(rs, nil)
+
+	// As the LLM might propose them, whatever the ratings
+	result := &ReflectionResult{
+		Calibrations: []ProposedCalibration{
+			proposal("node_modules", "very_high", "", 12, 0.9),
+			proposal("*.dmg", "", "auto_delete", 8, 0.8),
+			proposal("*.mov", "low", "ask_first", 20, 0.9),
+		},
+	}
+	rating := 2
+	var sessions []*session.Session
+	for range minRatings {
+		s := &session.Session{}
+		s.Outcome.UserSatisfaction = &rating
+		sessions = append(sessions, s)
+	}
+	result.noteRatings(sessions)
+	if !result.Cautious() {
+		t.Fatalf("Cautious() = false with %d sessions rated %d", minRatings, rating)
+	}
+
+	applied, err := l.ApplyCalibrations(result)
+	if err != nil {
+		t.Fatalf("ApplyCalibrations() error = %v", err)
+	}
+	if want := []string{"*.mov"}; fmt.Sprint(applied) != fmt.Sprint(want) {
+		t.Errorf("ApplyCalibrations() rated low applied %q, want only %q", applied, want)
+	}
+}
+
+func TestReflectionPromptFitsBudget(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	rs, err := rules.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sessions []*session.Session
+	for n := range 500 {
+		s := &session.Session{ID: fmt.Sprintf("sess_%03d", n), Tool: "forge-dust"}
+		for range 1 + n%7 {
+			s.AddInteraction(session.Interaction{Category: "node_modules", Suggestion: "suggest_delete", UserResponse: "accept"})
+		}
+		sessions = append(sessions, s)
+	}
+
+	client := llm.NewClient("small:1b")
+	client.PromptBudget = 4000
+	prompt := NewLearner(rs, client).buildReflectionPrompt(sessions)
+	if len(prompt) > 4000 {
+		t.Errorf("buildReflectionPrompt() is %d characters, want at most 4000", len(prompt))
+	}
+	if !strings.Contains(prompt, "Sampled:") || !strings.Contains(prompt, "OUTPUT as JSON") {
+		t.Errorf("buildReflectionPrompt() lacks the sampling note or the tasks:\n%s", prompt)
+	}
+	// The busiest sessions are the ones kept
+	if !strings.Contains(prompt, "sess_006") || strings.Contains(prompt, "sess_000") {
+		t.Errorf("buildReflectionPrompt() kept the wrong sessions:\n%s", prompt)
+	}
+
+	client.PromptBudget = -1
+	if prompt := NewLearner(rs, client).buildReflectionPrompt(sessions); strings.Contains(prompt, "Sampled:") {
+		t.Error("buildReflectionPrompt() with no budget sampled the sessions")
+	}
+}
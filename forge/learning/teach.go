@@ -0,0 +1,68 @@
+package learning
+
+import (
+	"strings"
+
+	"forge/rules"
+)
+
+// Topic is a common kind of clutter `forge teach` asks about up front
+type Topic struct {
+	Name     string   // How the question names it
+	Patterns []string // Each becomes a preference with the same answer
+	Location string   // Where the patterns apply; empty means anywhere
+}
+
+// TeachTopics are asked in order by `forge teach`
+var TeachTopics = []Topic{
+	{Name: "node_modules folders in your projects", Patterns: []string{"node_modules"}},
+	{Name: "Installers (.dmg, .pkg) in Downloads", Patterns: []string{"*.dmg", "*.pkg"}, Location: "~/Downloads"},
+	{Name: "Archives (.zip) in Downloads", Patterns: []string{"*.zip"}, Location: "~/Downloads"},
+	{Name: "Videos (.mov, .mp4) in Movies", Patterns: []string{"*.mov", "*.mp4"}, Location: "~/Movies"},
+	{Name: "Python caches (__pycache__, .pytest_cache)", Patterns: []string{"__pycache__", ".pytest_cache"}},
+	{Name: "Rust build output (target)", Patterns: []string{"target"}},
+	{Name: "Xcode DerivedData", Patterns: []string{"DerivedData"}},
+}
+
+// ParseTeachAnswer turns an answer to a teach question into a preference
+// type. Skipping returns "" and ok; anything unrecognized returns !ok.
+func ParseTeachAnswer(input string) (prefType string, ok bool) {
+	switch strings.ToLower(strings.TrimSpace(input)) {
+	case "a", "always":
+		return "always_delete", true
+	case "n", "never":
+		return "never_delete", true
+	case "k", "ask":
+		return "always_ask", true
+	case "", "s", "skip":
+		return "", true
+	default:
+		return "", false
+	}
+}
+
+// Taught reports whether the user already has a preference for any of the
+// topic's patterns there, so `forge teach` can leave it alone
+func (l *Learner) Taught(topic Topic) bool {
+	prefs := l.Rules.Preferences
+	for _, list := range [][]rules.Preference{prefs.AlwaysDelete, prefs.NeverDelete, prefs.AlwaysAsk} {
+		for _, pref := range list {
+			for _, pattern := range topic.Patterns {
+				if pref.Pattern == pattern && pref.Location == topic.Location {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// Teach records prefType for each of the topic's patterns
+func (l *Learner) Teach(topic Topic, prefType string) error {
+	for _, pattern := range topic.Patterns {
+		if err := l.AddPreference(prefType, pattern, topic.Location, "Taught with forge teach"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package learning
+
+import (
+	"reflect"
+	"testing"
+
+	"forge/rules"
+)
+
+func TestTeachAnswersBecomePreferences(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	rs, err := rules.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := NewLearner(rs, nil)
+
+	installers := Topic{Name: "Installers", Patterns: []string{"*.dmg", "*.pkg"}, Location: "~/Downloads"}
+	videos := Topic{Name: "Videos", Patterns: []string{"*.mov"}, Location: "~/Movies"}
+	caches := Topic{Name: "Caches", Patterns: []string{"__pycache__"}}
+	answers := []struct {
+		topic Topic
+		input string
+	}{
+		{installers, "a"},
+		{videos, "Never"},
+		{caches, "k"},
+	}
+	for _, a := range answers {
+		prefType, ok := ParseTeachAnswer(a.input)
+		if !ok {
+			t.Fatalf("ParseTeachAnswer(%q) not ok", a.input)
+		}
+		if err := l.Teach(a.topic, prefType); err != nil {
+			t.Fatalf("Teach(%s) error = %v", a.topic.Name, err)
+		}
+	}
+
+	got := func(prefs []rules.Preference) []string {
+		var out []string
+		for _, p := range prefs {
+			out = append(out, p.Pattern+"@"+p.Location)
+		}
+		return out
+	}
+	// What was saved, reloaded from disk
+	saved, err := rules.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"*.dmg@~/Downloads", "*.pkg@~/Downloads"}; !reflect.DeepEqual(got(saved.Preferences.AlwaysDelete), want) {
+		t.Errorf("always_delete = %v, want %v", got(saved.Preferences.AlwaysDelete), want)
+	}
+	if want := []string{"*.mov@~/Movies"}; !reflect.DeepEqual(got(saved.Preferences.NeverDelete), want) {
+		t.Errorf("never_delete = %v, want %v", got(saved.Preferences.NeverDelete), want)
+	}
+	if want := []string{"__pycache__@"}; !reflect.DeepEqual(got(saved.Preferences.AlwaysAsk), want) {
+		t.Errorf("always_ask = %v, want %v", got(saved.Preferences.AlwaysAsk), want)
+	}
+
+	// Answered topics are skipped next time; the same pattern elsewhere isn't answered
+	for _, topic := range []Topic{installers, videos, caches} {
+		if !l.Taught(topic) {
+			t.Errorf("Taught(%s) = false after answering", topic.Name)
+		}
+	}
+	if l.Taught(Topic{Patterns: []string{"*.mov"}, Location: "~/Desktop"}) {
+		t.Error("Taught(*.mov in ~/Desktop) = true, want only ~/Movies answered")
+	}
+
+	for _, input := range []string{"", "s", "skip"} {
+		if prefType, ok := ParseTeachAnswer(input); !ok || prefType != "" {
+			t.Errorf("ParseTeachAnswer(%q) = %q, %v, want a skip", input, prefType, ok)
+		}
+	}
+	if _, ok := ParseTeachAnswer("sometimes"); ok {
+		t.Error("ParseTeachAnswer(sometimes) ok, want it rejected")
+	}
+}
@@ -0,0 +1,64 @@
+package llm
+
+import "sort"
+
+// DefaultPromptBudget is how many characters a built-in prompt may take when
+// the config doesn't say: about 3,000 tokens, leaving room in a 4k context
+// for the answer
+const DefaultPromptBudget = 12000
+
+// Budget is how many characters a prompt for this client may take, or 0 for
+// no limit. A nil client gets the default.
+func (c *OllamaClient) Budget() int {
+	switch {
+	case c == nil || c.PromptBudget == 0:
+		return DefaultPromptBudget
+	case c.PromptBudget < 0:
+		return 0
+	default:
+		return c.PromptBudget
+	}
+}
+
+// Section is a part of a prompt that can be left out when space is short
+type Section struct {
+	Text     string
+	Priority int // Higher is kept first; ties keep the earlier section
+}
+
+// Fit keeps the highest-priority sections whose text fits in budget
+// characters, returned in their original order, and says how many were left
+// out. A budget of 0 or less keeps everything.
+func Fit(sections []Section, budget int) (kept []string, dropped int) {
+	if budget <= 0 {
+		for _, s := range sections {
+			kept = append(kept, s.Text)
+		}
+		return kept, 0
+	}
+
+	order := make([]int, len(sections))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return sections[order[i]].Priority > sections[order[j]].Priority
+	})
+
+	keep := make([]bool, len(sections))
+	used := 0
+	for _, i := range order {
+		if used+len(sections[i].Text) > budget {
+			dropped++
+			continue
+		}
+		used += len(sections[i].Text)
+		keep[i] = true
+	}
+	for i, s := range sections {
+		if keep[i] {
+			kept = append(kept, s.Text)
+		}
+	}
+	return kept, dropped
+}
@@ -0,0 +1,50 @@
+package llm
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFit(t *testing.T) {
+	sections := []Section{
+		{Text: "aaaa", Priority: 1},
+		{Text: "bbbb", Priority: 3},
+		{Text: "cccc", Priority: 2},
+		{Text: "dddd", Priority: 3},
+	}
+	tests := []struct {
+		budget      int
+		want        []string
+		wantDropped int
+	}{
+		{0, []string{"aaaa", "bbbb", "cccc", "dddd"}, 0},
+		{16, []string{"aaaa", "bbbb", "cccc", "dddd"}, 0},
+		{12, []string{"bbbb", "cccc", "dddd"}, 1},
+		{9, []string{"bbbb", "dddd"}, 2},
+		{3, nil, 4},
+	}
+	for _, tt := range tests {
+		got, dropped := Fit(sections, tt.budget)
+		if !reflect.DeepEqual(got, tt.want) || dropped != tt.wantDropped {
+			t.Errorf("Fit(%d) = %q, %d; want %q, %d", tt.budget, got, dropped, tt.want, tt.wantDropped)
+		}
+	}
+}
+
+func TestBudget(t *testing.T) {
+	var none *OllamaClient
+	tests := []struct {
+		client *OllamaClient
+		want   int
+	}{
+		{none, DefaultPromptBudget},
+		{&OllamaClient{}, DefaultPromptBudget},
+		{&OllamaClient{PromptBudget: 4000}, 4000},
+		{&OllamaClient{PromptBudget: -1}, 0},
+	}
+	for _, tt := range tests {
+		if got := tt.client.Budget(); got != tt.want {
+			t.Errorf("Budget() with %+v = %d, want %d", tt.client, got, tt.want)
+		}
+	}
+}
@@ -2,10 +2,13 @@ package llm
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"sync"
 	"time"
 )
 
@@ -13,7 +16,19 @@ import (
 type OllamaClient struct {
 	BaseURL string
 	Model   string
+	Models  []string // Fallback chain, preferred first; Model is picked from it on first use
 	Timeout time.Duration
+
+	// PromptBudget is how many characters a built-in prompt may take, about
+	// four a token; 0 means DefaultPromptBudget, and negative no limit
+	PromptBudget int
+
+	// Concurrency is how many calls GenerateEach has in flight at once; 0
+	// means DefaultConcurrency
+	Concurrency int
+
+	chooseOnce sync.Once
+	mu         sync.Mutex // Guards Model once calls run concurrently
 }
 
 type generateRequest struct {
@@ -36,10 +51,94 @@ func NewClient(model string) *OllamaClient {
 	}
 }
 
-// Generate sends a prompt to Ollama and returns the response
+// NewFallbackClient creates a client that uses the first of models Ollama
+// has installed, and moves down the list if that model returns an error
+func NewFallbackClient(models []string) *OllamaClient {
+	if len(models) == 0 {
+		models = []string{DefaultModel}
+	}
+	c := NewClient(models[0])
+	c.Models = models
+	return c
+}
+
+// chooseModel settles Model from the fallback chain, once per client, so
+// the whole run uses the same model
+func (c *OllamaClient) chooseModel() {
+	c.chooseOnce.Do(func() {
+		if len(c.Models) < 2 {
+			return
+		}
+		installed, err := c.ListModels()
+		if err != nil {
+			return // Generate will report the real problem
+		}
+		if model, ok := SelectModel(c.Models, installed); ok {
+			c.Model = model
+		}
+	})
+}
+
+// Generate sends a prompt to Ollama and returns the response. If the model
+// fails, the later models in the fallback chain are tried in turn, and the
+// first that answers is kept for the rest of the run.
 func (c *OllamaClient) Generate(prompt string) (string, error) {
+	return c.GenerateContext(context.Background(), prompt)
+}
+
+// GenerateContext is Generate, giving up when ctx is done
+func (c *OllamaClient) GenerateContext(ctx context.Context, prompt string) (string, error) {
+	c.chooseModel()
+
+	current := c.model()
+	response, err := c.generate(ctx, current, prompt)
+	var status *statusError
+	if err == nil || !errors.As(err, &status) {
+		return response, err // Fallbacks can't help if Ollama itself is unreachable
+	}
+
+	for _, model := range c.fallbacksAfter(current) {
+		if fallback, ferr := c.generate(ctx, model, prompt); ferr == nil {
+			c.mu.Lock()
+			c.Model = model
+			c.mu.Unlock()
+			return fallback, nil
+		}
+	}
+	return "", err
+}
+
+// model is the model calls go to now
+func (c *OllamaClient) model() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.Model
+}
+
+// fallbacksAfter returns the models in the chain that come after model
+func (c *OllamaClient) fallbacksAfter(model string) []string {
+	for i, m := range c.Models {
+		if m == model {
+			return c.Models[i+1:]
+		}
+	}
+	return nil
+}
+
+// statusError is Ollama answering with something other than 200, e.g. for
+// a model it doesn't have
+type statusError struct {
+	code int
+	body string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("Ollama returned status %d: %s", e.code, e.body)
+}
+
+func (c *OllamaClient) generate(ctx context.Context, model, prompt string) (string, error) {
 	reqBody := generateRequest{
-		Model:  c.Model,
+		Model:  model,
 		Prompt: prompt,
 		Stream: false,
 	}
@@ -49,8 +148,14 @@ func (c *OllamaClient) Generate(prompt string) (string, error) {
 		return "", fmt.Errorf("failed to marshal request: %w", err)
 	}
 
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.BaseURL+"/api/generate", bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return "", fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
 	client := &http.Client{Timeout: c.Timeout}
-	resp, err := client.Post(c.BaseURL+"/api/generate", "application/json", bytes.NewBuffer(jsonBody))
+	resp, err := client.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("failed to call Ollama: %w", err)
 	}
@@ -58,7 +163,7 @@ func (c *OllamaClient) Generate(prompt string) (string, error) {
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("Ollama returned status %d: %s", resp.StatusCode, string(body))
+		return "", &statusError{code: resp.StatusCode, body: string(body)}
 	}
 
 	var result generateResponse
@@ -69,6 +174,23 @@ func (c *OllamaClient) Generate(prompt string) (string, error) {
 	return result.Response, nil
 }
 
+// pingPrompt asks for as short an answer as a model will give
+const pingPrompt = "Reply with the single word OK."
+
+// SlowLatency is how long a Ping can take before the model counts as slow
+const SlowLatency = 10 * time.Second
+
+// Ping times a trivial generation, about the least any LLM call will take.
+// It asks the model Generate would use, without falling back.
+func (c *OllamaClient) Ping() (time.Duration, error) {
+	c.chooseModel()
+	start := time.Now()
+	if _, err := c.generate(context.Background(), c.model(), pingPrompt); err != nil {
+		return 0, err
+	}
+	return time.Since(start), nil
+}
+
 // IsAvailable checks if Ollama is running
 func (c *OllamaClient) IsAvailable() bool {
 	client := &http.Client{Timeout: 2 * time.Second}
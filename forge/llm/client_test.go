@@ -0,0 +1,41 @@
+package llm
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPingTimesGeneration(t *testing.T) {
+	const delay = 50 * time.Millisecond
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(delay)
+		fmt.Fprint(w, `{"response":"OK","done":true}`)
+	}))
+	defer server.Close()
+
+	c := NewClient("qwen3:8b")
+	c.BaseURL = server.URL
+	latency, err := c.Ping()
+	if err != nil {
+		t.Fatalf("Ping() error = %v", err)
+	}
+	if latency < delay || latency > delay+2*time.Second {
+		t.Errorf("Ping() = %s, want about %s", latency, delay)
+	}
+}
+
+func TestPingReportsFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"model not found"}`, http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	c := NewClient("missing:1b")
+	c.BaseURL = server.URL
+	if latency, err := c.Ping(); err == nil {
+		t.Errorf("Ping() = %s, nil; want an error", latency)
+	}
+}
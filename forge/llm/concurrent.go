@@ -0,0 +1,75 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// DefaultConcurrency is how many calls GenerateEach makes at once when the
+// config doesn't say. Ollama queues what it can't run in parallel, so a
+// few more than it runs costs nothing.
+const DefaultConcurrency = 4
+
+// concurrency is how many calls this client may have in flight
+func (c *OllamaClient) concurrency() int {
+	if c.Concurrency > 0 {
+		return c.Concurrency
+	}
+	return DefaultConcurrency
+}
+
+// GenerateEach answers independent prompts, at most Concurrency at a time,
+// handing each response to done with its prompt's index as it comes back.
+// done is called from the goroutine that made the call, so it must be safe
+// to call concurrently. A failed call doesn't stop the others: the errors
+// are returned together once every call is over, along with ctx's if it
+// ends before every call has started.
+func (c *OllamaClient) GenerateEach(ctx context.Context, prompts []string, done func(i int, response string)) error {
+	slots := make(chan struct{}, c.concurrency())
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	fail := func(err error) {
+		mu.Lock()
+		errs = append(errs, err)
+		mu.Unlock()
+	}
+
+	for i, prompt := range prompts {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if err := ctx.Err(); err != nil {
+			fail(err)
+			break
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			response, err := c.GenerateContext(ctx, prompt)
+			if err != nil {
+				fail(err)
+				return
+			}
+			done(i, response)
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// GenerateAll is GenerateEach, returning each response at its prompt's
+// index whatever order they finish in; a failed call's is empty
+func (c *OllamaClient) GenerateAll(ctx context.Context, prompts []string) ([]string, error) {
+	responses := make([]string, len(prompts))
+	err := c.GenerateEach(ctx, prompts, func(i int, response string) {
+		responses[i] = response // Each index is written once, by one goroutine
+	})
+	return responses, err
+}
@@ -0,0 +1,105 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// echoServer answers each prompt with "re: <prompt>", the later prompts
+// sooner, so calls finish in the reverse of the order they were made
+func echoServer(t *testing.T, inFlight, peak *atomic.Int32) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+		}
+
+		var req generateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var i int
+		fmt.Sscanf(req.Prompt, "prompt %d", &i)
+		time.Sleep(time.Duration(10-i) * 5 * time.Millisecond)
+
+		json.NewEncoder(w).Encode(generateResponse{Response: "re: " + req.Prompt, Done: true})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestGenerateAllKeepsPromptOrder(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	c := NewClient("qwen3:8b")
+	c.BaseURL = echoServer(t, &inFlight, &peak).URL
+	c.Concurrency = 3
+
+	var prompts []string
+	for i := range 10 {
+		prompts = append(prompts, fmt.Sprintf("prompt %d", i))
+	}
+	responses, err := c.GenerateAll(context.Background(), prompts)
+	if err != nil {
+		t.Fatalf("GenerateAll() error = %v", err)
+	}
+	for i, prompt := range prompts {
+		if want := "re: " + prompt; responses[i] != want {
+			t.Errorf("responses[%d] = %q, want %q", i, responses[i], want)
+		}
+	}
+	if got := peak.Load(); got > 3 {
+		t.Errorf("%d calls in flight at once, want at most 3", got)
+	}
+}
+
+func TestGenerateAllKeepsGoingPastAFailure(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		var req generateRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if strings.Contains(req.Prompt, "bad") {
+			http.Error(w, `{"error":"boom"}`, http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(generateResponse{Response: "ok", Done: true})
+	}))
+	defer server.Close()
+
+	c := NewClient("qwen3:8b")
+	c.BaseURL = server.URL
+	c.Concurrency = 1
+
+	responses, err := c.GenerateAll(context.Background(), []string{"good", "bad", "still", "asked"})
+	if err == nil {
+		t.Fatal("GenerateAll() error = nil, want the failed call's error")
+	}
+	if want := []string{"ok", "", "ok", "ok"}; strings.Join(responses, ",") != strings.Join(want, ",") {
+		t.Errorf("responses = %q, want %q", responses, want)
+	}
+	if got := calls.Load(); got != 4 {
+		t.Errorf("%d calls made, want all 4", got)
+	}
+}
+
+func TestGenerateAllHonorsCancellation(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	c := NewClient("qwen3:8b")
+	c.BaseURL = echoServer(t, &inFlight, &peak).URL
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.GenerateAll(ctx, []string{"prompt 1", "prompt 2"}); !errors.Is(err, context.Canceled) {
+		t.Errorf("GenerateAll() on a cancelled context = %v, want %v", err, context.Canceled)
+	}
+}
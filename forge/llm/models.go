@@ -0,0 +1,90 @@
+package llm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultModel is used when config.yaml doesn't name one
+const DefaultModel = "kimi-k2-thinking:cloud"
+
+// ErrNoOllama means the ollama command isn't installed or isn't on PATH
+var ErrNoOllama = errors.New("ollama is not installed (get it from https://ollama.com/download)")
+
+// Model is a model installed in Ollama
+type Model struct {
+	Name       string    `json:"name"`
+	Size       int64     `json:"size"`
+	ModifiedAt time.Time `json:"modified_at"`
+}
+
+// ParseTags reads the model list from an /api/tags response, sorted by name
+func ParseTags(r io.Reader) ([]Model, error) {
+	var tags struct {
+		Models []Model `json:"models"`
+	}
+	if err := json.NewDecoder(r).Decode(&tags); err != nil {
+		return nil, fmt.Errorf("failed to decode model list: %w", err)
+	}
+	sort.Slice(tags.Models, func(i, j int) bool { return tags.Models[i].Name < tags.Models[j].Name })
+	return tags.Models, nil
+}
+
+// ParseModels splits a comma-separated --model value into a fallback chain
+func ParseModels(s string) []string {
+	var models []string
+	for _, m := range strings.Split(s, ",") {
+		if m = strings.TrimSpace(m); m != "" {
+			models = append(models, m)
+		}
+	}
+	return models
+}
+
+// SelectModel returns the first of candidates that is installed. A name
+// without a tag matches its :latest, as it does for ollama itself.
+func SelectModel(candidates []string, installed []Model) (string, bool) {
+	have := make(map[string]bool, len(installed))
+	for _, m := range installed {
+		have[m.Name] = true
+	}
+	for _, c := range candidates {
+		if have[c] || (!strings.Contains(c, ":") && have[c+":latest"]) {
+			return c, true
+		}
+	}
+	return "", false
+}
+
+// ListModels asks Ollama which models are installed
+func (c *OllamaClient) ListModels() ([]Model, error) {
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Get(c.BaseURL + "/api/tags")
+	if err != nil {
+		return nil, fmt.Errorf("failed to call Ollama: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("Ollama returned status %d: %s", resp.StatusCode, string(body))
+	}
+	return ParseTags(resp.Body)
+}
+
+// PullCommand builds the ollama pull for model. Pulling goes through the CLI
+// rather than the API so the user sees ollama's own progress bars.
+func PullCommand(model string) (*exec.Cmd, error) {
+	path, err := exec.LookPath("ollama")
+	if err != nil {
+		return nil, ErrNoOllama
+	}
+	return exec.Command(path, "pull", model), nil
+}
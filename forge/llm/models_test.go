@@ -0,0 +1,131 @@
+package llm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseTags(t *testing.T) {
+	body := `{"models":[
+		{"name":"qwen3:8b","model":"qwen3:8b","modified_at":"2026-01-02T10:00:00Z","size":5225388164,"details":{"family":"qwen3"}},
+		{"name":"kimi-k2-thinking:cloud","model":"kimi-k2-thinking:cloud","modified_at":"2025-12-01T09:30:00-05:00","size":384}
+	]}`
+
+	models, err := ParseTags(strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("ParseTags() error = %v", err)
+	}
+	if len(models) != 2 {
+		t.Fatalf("ParseTags() = %d models, want 2", len(models))
+	}
+	if models[0].Name != "kimi-k2-thinking:cloud" || models[1].Name != "qwen3:8b" {
+		t.Errorf("ParseTags() names = %s, %s; want sorted by name", models[0].Name, models[1].Name)
+	}
+	if models[1].Size != 5225388164 || models[1].ModifiedAt.Year() != 2026 {
+		t.Errorf("ParseTags()[1] = %+v, want size 5225388164 modified in 2026", models[1])
+	}
+
+	if models, err := ParseTags(strings.NewReader(`{"models":[]}`)); err != nil || len(models) != 0 {
+		t.Errorf("ParseTags(empty) = %v, %v; want no models", models, err)
+	}
+	if _, err := ParseTags(strings.NewReader("<html>")); err == nil {
+		t.Error("ParseTags(garbage) error = nil, want an error")
+	}
+}
+
+func TestPullCommand(t *testing.T) {
+	bin := t.TempDir()
+	ollama := filepath.Join(bin, "ollama")
+	if err := os.WriteFile(ollama, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin)
+
+	cmd, err := PullCommand("qwen3:8b")
+	if err != nil {
+		t.Fatalf("PullCommand() error = %v", err)
+	}
+	if cmd.Path != ollama {
+		t.Errorf("PullCommand().Path = %s, want %s", cmd.Path, ollama)
+	}
+	if want := []string{ollama, "pull", "qwen3:8b"}; !reflect.DeepEqual(cmd.Args, want) {
+		t.Errorf("PullCommand().Args = %v, want %v", cmd.Args, want)
+	}
+
+	// Without ollama on PATH
+	t.Setenv("PATH", t.TempDir())
+	if _, err := PullCommand("qwen3:8b"); !errors.Is(err, ErrNoOllama) {
+		t.Errorf("PullCommand() without ollama error = %v, want ErrNoOllama", err)
+	}
+}
+
+func TestSelectModel(t *testing.T) {
+	installed := []Model{{Name: "llama3.2:latest"}, {Name: "qwen3:8b"}}
+	tests := []struct {
+		candidates []string
+		want       string
+		ok         bool
+	}{
+		{[]string{"kimi-k2-thinking:cloud", "qwen3:8b"}, "qwen3:8b", true}, // A absent, B present
+		{[]string{"qwen3:8b", "llama3.2"}, "qwen3:8b", true},               // order wins
+		{[]string{"llama3.2"}, "llama3.2", true},                           // untagged means :latest
+		{[]string{"llama3.2:1b"}, "", false},
+		{nil, "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := SelectModel(tt.candidates, installed)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("SelectModel(%v) = %q, %v; want %q, %v", tt.candidates, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParseModels(t *testing.T) {
+	if got, want := ParseModels(" a:1b, b ,,c"), []string{"a:1b", "b", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseModels() = %v, want %v", got, want)
+	}
+}
+
+func TestFallbackClientPicksInstalledModel(t *testing.T) {
+	var asked []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tags":
+			fmt.Fprint(w, `{"models":[{"name":"b:7b"},{"name":"c:3b"}]}`)
+		case "/api/generate":
+			var req generateRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			asked = append(asked, req.Model)
+			if req.Model == "b:7b" {
+				http.Error(w, `{"error":"model crashed"}`, http.StatusInternalServerError)
+				return
+			}
+			fmt.Fprintf(w, `{"response":"from %s","done":true}`, req.Model)
+		}
+	}))
+	defer server.Close()
+
+	c := NewFallbackClient([]string{"a:70b", "b:7b", "c:3b"})
+	c.BaseURL = server.URL
+
+	// a is absent so b is chosen; b errors, so c answers and is kept
+	got, err := c.Generate("hi")
+	if err != nil || got != "from c:3b" {
+		t.Fatalf("Generate() = %q, %v; want the answer from c:3b", got, err)
+	}
+	if _, err := c.Generate("again"); err != nil {
+		t.Fatalf("second Generate() error = %v", err)
+	}
+	if want := []string{"b:7b", "c:3b", "c:3b"}; !reflect.DeepEqual(asked, want) {
+		t.Errorf("models asked = %v, want %v", asked, want)
+	}
+}
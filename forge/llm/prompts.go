@@ -0,0 +1,61 @@
+package llm
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"forge/rules"
+)
+
+// PromptDir returns ~/.forge/prompts, where templates that replace the
+// built-in prompts are kept
+func PromptDir() string {
+	return filepath.Join(rules.ForgeDir(), "prompts")
+}
+
+// CustomPrompt renders the template called name in PromptDir with data.
+// ok is false when there's no such template, and the built-in prompt applies.
+func CustomPrompt(name string, data any) (prompt string, ok bool, err error) {
+	path := filepath.Join(PromptDir(), name)
+	text, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+
+	tmpl, err := template.New(name).Funcs(promptFuncs).Parse(string(text))
+	if err != nil {
+		return "", false, fmt.Errorf("prompt template %s: %w", path, err)
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", false, fmt.Errorf("prompt template %s: %w", path, err)
+	}
+	return sb.String(), true, nil
+}
+
+// promptFuncs are available to prompt templates
+var promptFuncs = template.FuncMap{
+	"size": formatBytes,
+}
+
+// formatBytes renders bytes for a prompt, e.g. "1.5 GB"
+func formatBytes(b int64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%d B", b)
+	}
+	div, exp := int64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(b)/float64(div), "KMGTPE"[exp])
+}
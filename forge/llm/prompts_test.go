@@ -0,0 +1,42 @@
+package llm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCustomPrompt(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	if _, ok, err := CustomPrompt("missing.tmpl", nil); ok || err != nil {
+		t.Errorf("CustomPrompt(missing) = ok %v, error %v, want neither", ok, err)
+	}
+
+	if err := os.MkdirAll(PromptDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	write := func(name, text string) {
+		if err := os.WriteFile(filepath.Join(PromptDir(), name), []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("custom.tmpl", "Free {{size .Bytes}} from {{.Where}}.")
+	write("broken.tmpl", "{{.Where")
+
+	data := struct {
+		Bytes int64
+		Where string
+	}{3 << 30, "~/Downloads"}
+	got, ok, err := CustomPrompt("custom.tmpl", data)
+	if err != nil || !ok {
+		t.Fatalf("CustomPrompt(custom) = ok %v, error %v", ok, err)
+	}
+	if want := "Free 3.0 GB from ~/Downloads."; got != want {
+		t.Errorf("CustomPrompt(custom) = %q, want %q", got, want)
+	}
+
+	if _, _, err := CustomPrompt("broken.tmpl", data); err == nil {
+		t.Error("CustomPrompt(broken) error = nil, want the parse error")
+	}
+}
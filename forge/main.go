@@ -1,78 +1,206 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
+	"maps"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 
+	"forge-shared/pager"
+	"forge-shared/prompt"
+	"forge-shared/size"
 	"forge/assessment"
+	"forge/cleanup"
+	"forge/config"
 	"forge/conversation"
+	"forge/events"
 	"forge/learning"
 	"forge/llm"
+	"forge/messages"
 	"forge/rules"
+	"forge/scan"
 	"forge/session"
+
+	"golang.org/x/term"
 )
 
 var version = "0.1.0"
 
+// Exit codes, matching forge-dust. When several apply, the first in this
+// list (after generic errors) wins.
+const (
+	exitOK             = 0 // Completed
+	exitError          = 1 // Generic error
+	exitNothingToDo    = 2 // Nothing worth acting on was found
+	exitLLMUnavailable = 3 // Completed without the LLM
+	exitPartialScan    = 4 // The tool could not read everything
+	exitAborted        = 5 // User aborted
+)
+
 func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run is forge given args, without the program name, returning the exit
+// code rather than exiting so it can be driven from tests. Everything is
+// printed to stdout and stderr; prompts still read the terminal.
+func run(args []string, stdout, stderr io.Writer) int {
+	// --plain goes with any subcommand, so it's taken out before they see their flags
+	var plain bool
+	args, plain = takeFlag(args, "--plain")
+	useMessages(stderr, plain)
+	// Help saves nothing in ~/.forge, so needn't create it; the wrapped
+	// tools check it in runToolWith
+	if len(args) > 0 && !slices.Contains([]string{"dust", "clean", "habits", "version", "help", "--help", "-h"}, args[0]) {
+		useStorage(stderr)
+	}
+
 	// Subcommands
-	if len(os.Args) > 1 {
-		switch os.Args[1] {
+	if len(args) > 0 {
+		switch args[0] {
 		case "dust":
-			runTool("forge-dust", os.Args[2:])
-			return
+			return runTool(stdout, stderr, "forge-dust", args[1:])
+		case "clean":
+			return runClean(stdout, stderr, args[1:])
 		case "habits":
-			runTool("forge-habits", os.Args[2:])
-			return
+			return runTool(stdout, stderr, "forge-habits", args[1:])
+		case "assess":
+			return runAssess(stdout, stderr, args[1:])
 		case "review":
-			runReview()
-			return
+			return runReview(stdout, slices.Contains(args[1:], "--no-pager"))
 		case "learn":
-			runLearn()
-			return
+			return runLearn(stdout, stderr, len(args) > 1 && args[1] == "--dry-run")
+		case "teach":
+			return runTeach(stdout, stderr)
+		case "simulate":
+			// Development aid, left out of the help
+			if len(args) > 1 {
+				return runSimulate(stdout, stderr, args[1], slices.Contains(args[2:], "--llm"))
+			}
+			fmt.Fprintln(stdout, "Usage: forge simulate <fixture-dir> [--llm]")
+			return exitError
 		case "always":
-			if len(os.Args) > 2 {
-				runAlways(os.Args[2])
-			} else {
-				fmt.Println("Usage: forge always <pattern>")
+			if len(args) > 1 {
+				return runAlways(stdout, stderr, parsePatternArgs(args[1:]))
 			}
-			return
+			fmt.Fprintln(stdout, "Usage: forge always <pattern> [--location <dir>] [--yes]")
+			return exitError
 		case "never":
-			if len(os.Args) > 2 {
-				runNever(os.Args[2])
-			} else {
-				fmt.Println("Usage: forge never <pattern>")
+			if len(args) > 1 {
+				return runNever(stdout, stderr, parsePatternArgs(args[1:]))
 			}
-			return
+			fmt.Fprintln(stdout, "Usage: forge never <pattern> [--location <dir>]")
+			return exitError
 		case "forget":
-			if len(os.Args) > 2 {
-				runForget(os.Args[2])
-			} else {
-				fmt.Println("Usage: forge forget <pattern>")
+			if len(args) > 1 {
+				return runForget(stdout, args[1])
 			}
-			return
+			fmt.Fprintln(stdout, "Usage: forge forget <pattern>")
+			return exitError
+		case "hide", "unhide":
+			if len(args) > 1 {
+				return runHide(stdout, stderr, args[0], strings.Join(args[1:], " "))
+			}
+			fmt.Fprintf(stdout, "Usage: forge %s <category>\n", args[0])
+			return exitError
 		case "reset":
-			runReset(len(os.Args) > 2 && os.Args[2] == "--all")
-			return
+			return runReset(stdout, stderr, len(args) > 1 && args[1] == "--all")
 		case "rules":
-			runShowRules()
-			return
+			if len(args) > 1 && args[1] == "test" {
+				return runRulesTest(stdout, stderr, args[2:])
+			}
+			if len(args) > 1 && args[1] == "--diff" {
+				return runRulesDiff(stdout, stderr)
+			}
+			if len(args) > 1 && args[1] == "compact" {
+				return runRulesCompact(stdout, stderr)
+			}
+			if len(args) > 1 && (args[1] == "disable" || args[1] == "enable") {
+				if len(args) > 2 {
+					return runRulesToggle(stdout, stderr, args[1], args[2])
+				}
+				fmt.Fprintf(stdout, "Usage: forge rules %s <category>\n", args[1])
+				return exitError
+			}
+			return runShowRules(stdout, stderr)
 		case "sessions":
-			runShowSessions()
-			return
+			if len(args) > 1 && args[1] == "show" {
+				if len(args) > 2 {
+					return runShowSession(stdout, stderr, args[2], slices.Contains(args[3:], "--no-pager"))
+				}
+				fmt.Fprintln(stdout, "Usage: forge sessions show <id>")
+				return exitError
+			}
+			if len(args) > 1 && args[1] == "export" {
+				return runExportSession(stdout, stderr, args[2:])
+			}
+			return runShowSessions(stdout, stderr)
+		case "model":
+			return runModel(stdout, stderr, args[1:])
 		case "version":
-			fmt.Printf("forge v%s\n", version)
-			return
+			fmt.Fprintf(stdout, "forge v%s\n", version)
+			return exitOK
 		case "help", "--help", "-h":
-			printHelp()
-			return
+			printHelp(stdout)
+			return exitOK
 		}
 	}
 
 	// No subcommand - show help
-	printHelp()
+	printHelp(stdout)
+	return exitOK
+}
+
+// takeFlag removes every flag from args, reporting whether there were any
+func takeFlag(args []string, flag string) ([]string, bool) {
+	rest := slices.DeleteFunc(slices.Clone(args), func(arg string) bool { return arg == flag })
+	return rest, len(rest) < len(args)
+}
+
+// useStorage checks ~/.forge can be written, warning once to w if it can't
+// that this run's sessions and learning won't be kept
+func useStorage(w io.Writer) {
+	if err := rules.CheckStorage(); err != nil {
+		fmt.Fprintf(w, "Warning: can't write to %s (%v).\nSessions and anything learned this run won't be saved.\n", rules.ForgeDir(), err)
+	}
+}
+
+// useMessages switches to plain language if asked, and otherwise to the
+// message pack the config names, or the one for the locale if there is one
+func useMessages(stderr io.Writer, plain bool) {
+	if plain {
+		messages.Use(messages.Plain)
+		return
+	}
+
+	cfg, _ := config.Load()
+	name := cfg.Messages
+	if name == "" {
+		name = messages.Locale()
+	}
+	if name == "" {
+		return
+	}
+
+	pack, err := messages.Load(name)
+	if err != nil {
+		// A locale without a pack is normal; a configured pack that's missing isn't
+		if cfg.Messages != "" {
+			fmt.Fprintf(stderr, "Warning: message pack %q: %v\n", name, err)
+		}
+		return
+	}
+	messages.Use(pack)
 }
 
 // ANSI codes for output
@@ -83,348 +211,1503 @@ const (
 	Cyan    = "\033[36m"
 	Green   = "\033[32m"
 	Yellow  = "\033[33m"
+	Red     = "\033[31m"
 )
 
-func runTool(tool string, args []string) {
+func runTool(stdout, stderr io.Writer, tool string, args []string) int {
+	return runToolWith(stdout, stderr, tool, args, runOptions{})
+}
+
+// cleanFlags are the forge-dust flags forge clean scans with: caches are
+// found without descending into every hidden directory
+var cleanFlags = []string{"--quick"}
+
+// runClean is forge clean: a quick scan, then only the reversible, low-risk
+// categories (caches that rebuild themselves), listed path by path and
+// cleaned with one confirmation
+func runClean(stdout, stderr io.Writer, args []string) int {
+	return runToolWith(stdout, stderr, "forge-dust", append(slices.Clone(cleanFlags), args...), runOptions{clean: true})
+}
+
+// runToolWith runs tool, its options parsed from args and config on top of
+// preset's
+func runToolWith(stdout, stderr io.Writer, tool string, args []string, preset runOptions) (code int) {
+	// The session and anything learned are saved in ~/.forge
+	useStorage(stderr)
+
 	// Load rules
 	rs, err := rules.Load()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: could not load rules: %v\n", err)
+		fmt.Fprintf(stderr, "Warning: could not load rules: %v\n", err)
 		rs = &rules.RuleSet{}
 	}
 
-	// Initialize LLM client
-	client := llm.NewClient("kimi-k2-thinking:cloud")
+	// Apply per-tool default flags from config; the user's flags win on conflict
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(stderr, "Warning: could not load config: %v\n", err)
+	}
+	args = config.MergeFlags(cfg.DefaultFlags(tool), args)
 
-	// Check for --no-llm flag
-	noLLM := false
-	var filteredArgs []string
-	for _, arg := range args {
-		if arg == "--no-llm" {
-			noLLM = true
-		} else {
-			filteredArgs = append(filteredArgs, arg)
-		}
+	// Separate forge's own flags from the ones passed through to the tool
+	opts, filteredArgs, err := parseRunOptions(args)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitError
+	}
+	var closeEvents func()
+	if opts.events, closeEvents, err = openEvents(stdout, opts.eventsPath); err != nil {
+		fmt.Fprintf(stderr, "Error: --events: %v\n", err)
+		return exitError
 	}
+	defer func() {
+		opts.events.Finish(code)
+		closeEvents()
+	}()
+	opts.clean = preset.clean
+	opts.safe = opts.safe || cfg.Safe
+	opts.quiet = opts.quiet || cfg.Quiet
+	opts.setAutoRisk(stderr, cfg)
+	opts.setCategoryModes(stderr, cfg)
+	opts.rateEvery = cfg.RateInterval()
 
-	// Show pre-run messaging
-	toolDesc := getToolDescription(tool)
-	fmt.Println()
-	fmt.Printf("%s%s────────────────────────────────────────────────────────────%s\n", Bold, Cyan, Reset)
-	fmt.Printf("%s  ⚒  FORGE%s\n", Bold+Cyan, Reset)
-	fmt.Printf("%s────────────────────────────────────────────────────────────%s\n", Bold+Cyan, Reset)
-	fmt.Println()
-	fmt.Printf("%s%s%s\n", Dim, toolDesc, Reset)
-	fmt.Println()
-	fmt.Printf("%sNote: macOS may prompt for folder access.%s\n", Dim, Reset)
-	fmt.Printf("%sGrant access to allow scanning protected directories.%s\n\n", Dim, Reset)
+	// Initialize LLM client
+	client := cfg.Client(opts.modelChain(cfg))
+	opts.checkLLM(stdout, client)
 
-	// Show spinner while running
-	done := make(chan bool)
-	go showSpinner("Scanning", done)
+	// Show pre-run messaging, unless stdout is for the JSON assessment
+	if !opts.jsonOut {
+		toolDesc := getToolDescription(tool)
+		printBanner(stdout)
+		fmt.Fprintf(stdout, "%s%s%s\n", Dim, toolDesc, Reset)
+		fmt.Fprintln(stdout)
+		fmt.Fprintf(stdout, "%sNote: macOS may prompt for folder access.%s\n", Dim, Reset)
+		fmt.Fprintf(stdout, "%sGrant access to allow scanning protected directories.%s\n\n", Dim, Reset)
+	}
 
 	// Run the tool with --json flag
+	stopSpinner := startSpinner(stdout, chooseSpinner(opts.jsonOut, opts.quiet, stdout == os.Stdout && term.IsTerminal(int(os.Stdout.Fd()))), "Scanning")
 	toolArgs := append(filteredArgs, "--json")
 	cmd := exec.Command(tool, toolArgs...)
+	opts.events.Emit(events.Event{Type: events.ScanStarted, Tool: tool})
+	stopProgress := reportProgress(opts.events, time.Second)
 	output, err := cmd.Output()
+	stopProgress()
+	stopSpinner()
 
-	// Stop spinner
-	done <- true
-	fmt.Print("\r\033[K") // Clear the spinner line
+	// Informational exit codes still come with usable JSON
+	if exitErr, ok := err.(*exec.ExitError); ok && len(output) > 0 {
+		switch exitErr.ExitCode() {
+		case exitNothingToDo:
+			err = nil
+		case exitPartialScan:
+			opts.partial = true
+			err = nil
+		}
+	}
 
+	if err != nil && opts.jsonOut {
+		fmt.Fprintf(stderr, "Error: %s gave no JSON to assess: %v\n", tool, err)
+		return exitError
+	}
 	if err != nil {
 		// Tool might not support --json yet, fall back to normal execution
-		fmt.Printf("%sRunning %s...%s\n", Dim, tool, Reset)
+		fmt.Fprintf(stdout, "%sRunning %s...%s\n", Dim, tool, Reset)
 		cmd := exec.Command(tool, filteredArgs...)
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
+		cmd.Stdout = stdout
+		cmd.Stderr = stderr
 		cmd.Stdin = os.Stdin
-		cmd.Run()
-		return
+		if err := cmd.Run(); err != nil {
+			if exitErr, ok := err.(*exec.ExitError); ok {
+				return exitErr.ExitCode()
+			}
+			fmt.Fprintf(stderr, "Error running %s: %v\n", tool, err)
+			return exitError
+		}
+		return exitOK
 	}
 
-	// Parse tool output
-	toolOutput, err := assessment.ParseToolOutput(output)
+	return converse(stdout, stderr, tool, output, args, rs, client, opts)
+}
+
+// runAssess assesses saved tool output (from a --json run) without rescanning
+func runAssess(stdout, stderr io.Writer, args []string) (code int) {
+	var input string
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--input" && i+1 < len(args) {
+			i++
+			input = args[i]
+			continue
+		}
+		rest = append(rest, args[i])
+	}
+	if input == "" {
+		fmt.Fprintln(stdout, "Usage: forge assess --input <file.json> [--preview|--json] [--no-llm] [--quick|--careful]")
+		return exitError
+	}
+
+	var output []byte
+	var err error
+	if input == "-" {
+		output, err = io.ReadAll(os.Stdin)
+	} else {
+		output, err = os.ReadFile(input)
+	}
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error parsing tool output: %v\n", err)
-		return
+		fmt.Fprintf(stderr, "Error reading %s: %v\n", input, err)
+		return exitError
 	}
 
-	// Create session
-	sess := session.NewSession(tool)
+	rs, err := rules.Load()
+	if err != nil {
+		fmt.Fprintf(stderr, "Warning: could not load rules: %v\n", err)
+		rs = &rules.RuleSet{}
+	}
 
-	// Create assessor
-	assessor := assessment.NewAssessor(rs, client)
+	opts, flags, err := parseRunOptions(rest)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitError
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(stderr, "Warning: could not load config: %v\n", err)
+	}
+	var closeEvents func()
+	if opts.events, closeEvents, err = openEvents(stdout, opts.eventsPath); err != nil {
+		fmt.Fprintf(stderr, "Error: --events: %v\n", err)
+		return exitError
+	}
+	defer func() {
+		opts.events.Finish(code)
+		closeEvents()
+	}()
+	opts.safe = opts.safe || cfg.Safe
+	opts.setAutoRisk(stderr, cfg)
+	opts.setCategoryModes(stderr, cfg)
+	opts.rateEvery = cfg.RateInterval()
+	client := cfg.Client(opts.modelChain(cfg))
+	opts.checkLLM(stdout, client)
+
+	if !opts.jsonOut {
+		printBanner(stdout)
+		fmt.Fprintf(stdout, "%sAssessing saved output from %s%s\n\n", Dim, input, Reset)
+	}
+
+	return converse(stdout, stderr, "", output, flags, rs, client, opts)
+}
+
+// runOptions are forge's own flags plus what was learned while running the tool
+type runOptions struct {
+	noLLM          bool
+	explainMode    bool
+	preview        bool  // show the assessment and stop before the conversation
+	target         int64 // bytes to free with --target; 0 means no target
+	safe           bool  // only reversible categories, from --safe or config
+	models         []string // --model fallback chain, overriding config
+	maxAutoRisk    rules.Level // max_auto_risk from config
+	llmUnavailable bool // Ollama didn't answer, so the run continues without it
+	partial        bool // the tool could not read everything
+	rateEvery      int  // ask for a rating every Nth session; 0 never
+	categoryModes  map[string]assessment.Mode // category_modes from config
+	verbose        bool // report the LLM's latency before the run
+	jsonOut        bool // print the assessment as JSON and stop, like --preview
+	yes            bool // clean accepted batches without the path-by-path confirmation
+	quiet          bool // a plain "Scanning..." instead of the themed spinner, from --quiet or config
+	clean          bool // forge clean: only reversible, low-risk categories, confirmed as one batch
+	compact        bool // one line per category when guided, from --compact
+	showKept       bool // end by listing large items that weren't proposed, and why
+	eventsPath     string          // --events: where to stream NDJSON events, "-" for stdout
+	events         *events.Emitter // the --events stream; nil without one
+}
+
+// parseRunOptions separates forge's own flags from the ones passed through to the tool
+func parseRunOptions(args []string) (runOptions, []string, error) {
+	var opts runOptions
+	var filtered []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--no-llm":
+			opts.noLLM = true
+		case arg == "--explain-mode":
+			opts.explainMode = true
+		case arg == "--preview":
+			opts.preview = true
+		case arg == "--safe":
+			opts.safe = true
+		case arg == "--verbose":
+			opts.verbose = true
+		case arg == "--json":
+			opts.jsonOut = true
+		case arg == "--yes":
+			opts.yes = true
+		case arg == "--quiet":
+			opts.quiet = true
+		case arg == "--compact":
+			opts.compact = true
+		case arg == "--show-kept":
+			opts.showKept = true
+		case arg == "--model" || strings.HasPrefix(arg, "--model="):
+			value, ok := strings.CutPrefix(arg, "--model=")
+			if !ok {
+				if i+1 >= len(args) {
+					return opts, nil, fmt.Errorf("--model needs a model name, e.g. --model qwen3:8b,llama3.2")
+				}
+				i++
+				value = args[i]
+			}
+			if opts.models = llm.ParseModels(value); len(opts.models) == 0 {
+				return opts, nil, fmt.Errorf("--model needs a model name, e.g. --model qwen3:8b,llama3.2")
+			}
+			// The tool takes one model for its own LLM calls: the first choice
+			filtered = append(filtered, "--model", opts.models[0])
+		case arg == "--events" || strings.HasPrefix(arg, "--events="):
+			value, ok := strings.CutPrefix(arg, "--events=")
+			if !ok {
+				if i+1 >= len(args) {
+					return opts, nil, fmt.Errorf("--events needs a file, e.g. --events run.ndjson, or - for stdout")
+				}
+				i++
+				value = args[i]
+			}
+			if value == "" {
+				return opts, nil, fmt.Errorf("--events needs a file, e.g. --events run.ndjson, or - for stdout")
+			}
+			opts.eventsPath = value
+		case arg == "--target" || strings.HasPrefix(arg, "--target="):
+			value, ok := strings.CutPrefix(arg, "--target=")
+			if !ok {
+				if i+1 >= len(args) {
+					return opts, nil, fmt.Errorf("--target needs a size, e.g. --target 20GB")
+				}
+				i++
+				value = args[i]
+			}
+			target, err := size.Parse(value)
+			if err != nil || target == 0 {
+				return opts, nil, fmt.Errorf("--target: invalid size %q", value)
+			}
+			opts.target = target
+		default:
+			filtered = append(filtered, arg)
+		}
+	}
+	if opts.jsonOut && opts.eventsPath == "-" {
+		return opts, nil, fmt.Errorf("--events - and --json would both write to stdout; give --events a file")
+	}
+	return opts, filtered, nil
+}
+
+// openEvents starts the --events stream at path, "-" meaning stdout. With no
+// path the emitter is nil, which reports nothing.
+func openEvents(stdout io.Writer, path string) (*events.Emitter, func(), error) {
+	switch path {
+	case "":
+		return nil, func() {}, nil
+	case "-":
+		return events.New(stdout), func() {}, nil
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return events.New(f), func() { f.Close() }, nil
+}
+
+// reportProgress emits a progress event every interval until stopped, so
+// whoever follows the stream knows the scan is still going
+func reportProgress(e *events.Emitter, interval time.Duration) (stop func()) {
+	if e == nil {
+		return func() {}
+	}
+	start := time.Now()
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		tick := time.NewTicker(interval)
+		defer tick.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-tick.C:
+				e.Emit(events.Event{Type: events.Progress, ElapsedMs: time.Since(start).Milliseconds()})
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// setAutoRisk takes max_auto_risk from config, warning if it isn't a level
+func (o *runOptions) setAutoRisk(stderr io.Writer, cfg *config.Config) {
+	o.maxAutoRisk = cfg.AutoRiskLimit()
+	if cfg.MaxAutoRisk != "" && rules.ParseLevel(cfg.MaxAutoRisk) == "" {
+		fmt.Fprintf(stderr, "Warning: max_auto_risk %q isn't low, medium, high or very_high; using low\n", cfg.MaxAutoRisk)
+	}
+}
+
+// setCategoryModes takes category_modes from config, warning about and
+// skipping any that don't name a mode
+func (o *runOptions) setCategoryModes(stderr io.Writer, cfg *config.Config) {
+	for _, category := range slices.Sorted(maps.Keys(cfg.CategoryModes)) {
+		value := cfg.CategoryModes[category]
+		mode := assessment.ParseMode(value)
+		if mode == "" {
+			fmt.Fprintf(stderr, "Warning: category_modes %s: %q isn't auto, suggest, guided, collaborative or informative; ignoring it\n", category, value)
+			continue
+		}
+		if o.categoryModes == nil {
+			o.categoryModes = make(map[string]assessment.Mode)
+		}
+		o.categoryModes[category] = mode
+	}
+}
+
+// modelChain returns the models given with --model, or else the configured ones
+func (o runOptions) modelChain(cfg *config.Config) []string {
+	if len(o.models) > 0 {
+		return o.models
+	}
+	return cfg.Models()
+}
+
+// checkLLM falls back to no-LLM mode rather than waiting on timeouts, but
+// remembers so the exit code can say so
+func (o *runOptions) checkLLM(stdout io.Writer, client *llm.OllamaClient) {
+	if !o.noLLM && !client.IsAvailable() {
+		o.llmUnavailable = true
+		o.noLLM = true
+	}
+	if o.verbose && !o.noLLM {
+		if latency, err := client.Ping(); err != nil {
+			fmt.Fprintf(stdout, "%sLLM: %s didn't answer: %v%s\n", Dim, client.Model, err, Reset)
+		} else {
+			printLatency(stdout, client.Model, latency)
+		}
+	}
+}
 
-	// Assess findings
+// printLatency reports how long model took to answer a trivial prompt,
+// warning when that's slow enough to drag out a guided session
+func printLatency(w io.Writer, model string, latency time.Duration) {
+	fmt.Fprintf(w, "%sLLM: %s answered in %s%s\n", Dim, model, latency.Round(10*time.Millisecond), Reset)
+	if latency >= llm.SlowLatency {
+		fmt.Fprintf(w, "%sLLM responses may be slow; consider --no-llm%s\n", Yellow, Reset)
+	}
+}
+
+// assessOutput parses a tool's JSON output and assesses it against the rules
+func assessOutput(output []byte, args []string, rs *rules.RuleSet, client *llm.OllamaClient, opts runOptions) (*assessment.ToolOutput, *assessment.SessionAssessment, error) {
+	toolOutput, err := assessment.ParseToolOutput(output)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parsing tool output: %w", err)
+	}
+
+	assessor := assessment.NewAssessor(rs, client)
+	assessor.Safe = opts.safe || opts.clean
+	if opts.clean {
+		assessor.MaxRisk = rules.LevelLow
+	}
+	assessor.MaxAutoRisk = opts.maxAutoRisk
+	assessor.CategoryModes = opts.categoryModes
 	var assess *assessment.SessionAssessment
-	if noLLM {
+	if opts.noLLM {
 		assess, err = assessor.Assess(toolOutput, args)
 	} else {
-		assess, err = assessor.AssessWithLLM(toolOutput, args)
+		assess, err = assessor.AssessWithLLM(context.Background(), toolOutput, args)
 	}
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error assessing: %v\n", err)
-		return
+		return nil, nil, fmt.Errorf("assessing: %w", err)
+	}
+	return toolOutput, assess, nil
+}
+
+// converse assesses tool output and either previews it or runs the
+// conversation loop, then records the session
+func converse(stdout, stderr io.Writer, tool string, output []byte, args []string, rs *rules.RuleSet, client *llm.OllamaClient, opts runOptions) int {
+	if opts.llmUnavailable && !opts.jsonOut {
+		fmt.Fprintf(stdout, "%sOllama isn't reachable; continuing without the LLM.%s\n", Dim, Reset)
+	}
+
+	toolOutput, assess, err := assessOutput(output, args, rs, client, opts)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error %v\n", err)
+		return exitError
+	}
+	defer assess.Explanations.Stop()
+	if tool == "" {
+		tool = toolOutput.Tool
+	}
+	for _, cat := range assess.Categories {
+		opts.events.Emit(events.Event{Type: events.CategoryFound, Category: cat.Category, Size: cat.TotalSize,
+			Items: len(cat.Findings), Mode: string(cat.Mode)})
+	}
+
+	// Everything a frontend needs is in the JSON, so nothing else is printed
+	if opts.jsonOut {
+		data, err := json.MarshalIndent(assess, "", "  ")
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return exitError
+		}
+		fmt.Fprintln(stdout, string(data))
+		return outcomeCode(assess, nil, opts.partial, opts.llmUnavailable)
+	}
+
+	if len(assess.Withheld) > 0 && opts.clean {
+		fmt.Fprintf(stdout, "%sforge clean: left out %s (not reversible and low-risk; 'forge dust' offers them).%s\n", Dim, strings.Join(assess.Withheld, ", "), Reset)
+	} else if len(assess.Withheld) > 0 {
+		fmt.Fprintf(stdout, "%sSafe mode: left out %s (not reversible).%s\n", Dim, strings.Join(assess.Withheld, ", "), Reset)
+	}
+	if len(assess.Hidden) > 0 {
+		fmt.Fprintf(stdout, "%sHidden: %s ('forge unhide <category>' to show).%s\n", Dim, strings.Join(assess.Hidden, ", "), Reset)
+	}
+	if assess.Trash > 0 {
+		fmt.Fprintf(stdout, "%sThe Trash holds %s; 'forge-dust --empty-trash' empties it.%s\n", Dim, formatBytes(assess.Trash), Reset)
+	}
+
+	if opts.explainMode {
+		fmt.Fprintln(stdout)
+		fmt.Fprint(stdout, assessment.ExplainModes(assess))
+	}
+
+	if opts.preview {
+		fmt.Fprintln(stdout)
+		fmt.Fprint(stdout, assessment.Preview(assess))
+		if opts.showKept {
+			fmt.Fprintln(stdout)
+			fmt.Fprint(stdout, assessment.KeptReport(assess))
+		}
+		return outcomeCode(assess, nil, opts.partial, opts.llmUnavailable)
+	}
+
+	// Anything learned since the previous run gets a line at the end
+	var lastRun time.Time
+	if recent, err := session.LoadRecentSessions(1); err == nil && len(recent) > 0 {
+		lastRun = recent[0].Timestamp
+	}
+
+	// forge clean never cleans unseen: everything left is listed and confirmed at once
+	if opts.clean && len(assess.Categories) > 0 {
+		assess.OverallMode = assessment.ModeSuggest
+	}
+
+	// Create session
+	sess := session.NewSession(tool)
+
+	// A session killed partway through deleting left its journal behind
+	stopped, err := cleanup.Recover(sess.ID)
+	if err != nil {
+		fmt.Fprintf(stderr, "Warning: could not read earlier cleanup journals: %v\n", err)
+	}
+	for _, c := range stopped {
+		fmt.Fprintf(stdout, "%sAn earlier cleanup (%s) stopped partway, after deleting %d items (%s).%s\n", Dim, c.Name, c.Items, formatBytes(c.Freed), Reset)
 	}
 
 	// Run conversation loop
 	loop := conversation.NewLoop(assess, sess, client)
-	if err := loop.Run(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	loop.Target = opts.target
+	loop.Yes = opts.yes
+	loop.Compact = opts.compact
+	loop.Events = opts.events
+	loop.Rules = rs
+	loop.Out = stdout
+	loopErr := loop.Run()
+	if loopErr != nil && !errors.Is(loopErr, conversation.ErrAborted) {
+		fmt.Fprintf(stderr, "Error: %v\n", loopErr)
+	}
+	if loopErr == nil && len(sess.Interactions) > 0 && dueForRating(session.CountSessions()+1, opts.rateEvery) {
+		if rating, ok := loop.AskRating(); ok {
+			sess.Outcome.UserSatisfaction = &rating
+		}
 	}
 
 	// Save session
 	sess.Finish()
-	if err := sess.Save(); err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: could not save session: %v\n", err)
+	if err := sess.Save(); err != nil && !errors.Is(err, rules.ErrEphemeral) {
+		fmt.Fprintf(stderr, "Warning: could not save session: %v\n", err)
 	}
 
 	// Check if we should reflect
 	learner := learning.NewLearner(rs, client)
-	if learner.ShouldReflect() && !noLLM {
-		fmt.Println("\n⚙ Running learning reflection...")
+	if learner.ShouldReflect() && !opts.noLLM {
+		fmt.Fprintln(stdout, "\n⚙ Running learning reflection...")
 		result, err := learner.Reflect()
 		if err == nil {
 			applied, _ := learner.ApplyCalibrations(result)
 			if len(applied) > 0 {
-				fmt.Printf("Learned %d new patterns from your usage.\n", len(applied))
+				fmt.Fprintf(stdout, "Learned %d new patterns from your usage.\n", len(applied))
 			}
 		}
 	}
+	if note := learning.LearningNote(learning.RecentEvents(rs, lastRun)); note != "" {
+		fmt.Fprintf(stdout, "\n%s⚙ %s%s\n", Dim, note, Reset)
+	}
+	if opts.showKept {
+		fmt.Fprintln(stdout)
+		fmt.Fprint(stdout, assessment.KeptReport(assess))
+	}
+
+	return outcomeCode(assess, loopErr, opts.partial, opts.llmUnavailable)
+}
+
+// dueForRating reports whether session number n (counting from 1) should
+// end by asking for a rating, which happens every nth session
+func dueForRating(n, every int) bool {
+	return every > 0 && n%every == 0
+}
+
+// printBanner shows the forge header before a run
+func printBanner(w io.Writer) {
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "%s%s────────────────────────────────────────────────────────────%s\n", Bold, Cyan, Reset)
+	fmt.Fprintf(w, "%s  ⚒  FORGE%s\n", Bold+Cyan, Reset)
+	fmt.Fprintf(w, "%s────────────────────────────────────────────────────────────%s\n", Bold+Cyan, Reset)
+	fmt.Fprintln(w)
+}
+
+// outcomeCode maps a finished tool run to its exit code
+func outcomeCode(assess *assessment.SessionAssessment, loopErr error, partial, llmUnavailable bool) int {
+	switch {
+	case errors.Is(loopErr, conversation.ErrAborted):
+		return exitAborted
+	case loopErr != nil:
+		return exitError
+	case partial:
+		return exitPartialScan
+	case len(assess.Categories) == 0:
+		return exitNothingToDo
+	case llmUnavailable:
+		return exitLLMUnavailable
+	default:
+		return exitOK
+	}
 }
 
-func runReview() {
+func runReview(stdout io.Writer, noPager bool) int {
 	rs, _ := rules.Load()
-	client := llm.NewClient("kimi-k2-thinking:cloud")
+	client := configuredClient()
 	learner := learning.NewLearner(rs, client)
 
-	fmt.Println(learner.GetLearningSummary())
+	report := pager.Start(noPager || stdout != os.Stdout)
+	fmt.Fprintln(report.Writer(stdout), learner.GetLearningSummary())
+	report.Stop()
+	return exitOK
 }
 
-func runLearn() {
+func runLearn(stdout, stderr io.Writer, dryRun bool) int {
 	rs, err := rules.Load()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error loading rules: %v\n", err)
-		return
+		fmt.Fprintf(stderr, "Error loading rules: %v\n", err)
+		return exitError
 	}
 
-	client := llm.NewClient("kimi-k2-thinking:cloud")
+	client := configuredClient()
 	learner := learning.NewLearner(rs, client)
 
-	fmt.Println("Running learning reflection...")
+	fmt.Fprintln(stdout, "Running learning reflection...")
 
 	result, err := learner.Reflect()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		return
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitLLMUnavailable
 	}
 
-	fmt.Printf("\nAnalyzed %d sessions, %d total interactions\n",
+	fmt.Fprintf(stdout, "\nAnalyzed %d sessions, %d total interactions\n",
 		result.AnalysisSummary.SessionsAnalyzed,
 		result.AnalysisSummary.TotalInteractions)
-	fmt.Printf("Overall acceptance rate: %.0f%%\n\n",
+	fmt.Fprintf(stdout, "Overall acceptance rate: %.0f%%\n\n",
 		result.AnalysisSummary.OverallAcceptanceRate*100)
 
+	if dryRun {
+		printChanges(stdout, learner.PreviewCalibrations(result))
+		if result.Insights != "" {
+			fmt.Fprintf(stdout, "\nInsights:\n%s\n", result.Insights)
+		}
+		return exitOK
+	}
+
 	if len(result.Calibrations) > 0 {
-		fmt.Println("Proposed calibrations:")
+		fmt.Fprintln(stdout, "Proposed calibrations:")
 		for _, cal := range result.Calibrations {
-			fmt.Printf("  • %s: %s → %s (%.0f%% confidence)\n",
+			fmt.Fprintf(stdout, "  • %s: %s → %s (%.0f%% confidence)\n",
 				cal.Pattern, cal.CurrentAction, cal.ProposedAction,
 				cal.ConfidenceInProposal*100)
 		}
 
-		fmt.Print("\nApply these calibrations? [Y/n] ")
-		var input string
-		fmt.Scanln(&input)
+		fmt.Fprint(stdout, "\nApply these calibrations? [Y/n] ")
+		input, err := prompt.NewLineReader(os.Stdin).ReadLine()
 
-		if input == "" || input == "y" || input == "Y" {
+		if err == nil && prompt.IsYes(input, true) {
+			before := rs.Diff()
 			applied, err := learner.ApplyCalibrations(result)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-				return
+				fmt.Fprintf(stderr, "Error: %v\n", err)
+				return exitError
+			}
+			fmt.Fprintf(stdout, "Applied %d calibrations.\n", len(applied))
+
+			if changed := newRuleChanges(before, rs.Diff()); len(changed) > 0 {
+				fmt.Fprintln(stdout, "\nWhat changed:")
+				printRuleDiff(stdout, changed)
 			}
-			fmt.Printf("Applied %d calibrations.\n", len(applied))
 		}
 	} else {
-		fmt.Println("No calibrations needed at this time.")
+		fmt.Fprintln(stdout, "No calibrations needed at this time.")
 	}
 
 	if result.Insights != "" {
-		fmt.Printf("\nInsights:\n%s\n", result.Insights)
+		fmt.Fprintf(stdout, "\nInsights:\n%s\n", result.Insights)
 	}
+
+	return exitOK
 }
 
-func runAlways(pattern string) {
-	rs, _ := rules.Load()
-	client := llm.NewClient("kimi-k2-thinking:cloud")
+// printChanges shows the before/after of each rule a reflection would change
+func printChanges(w io.Writer, changes []learning.Change) {
+	if len(changes) == 0 {
+		fmt.Fprintln(w, "Dry run: no calibrations meet the threshold, nothing would change.")
+		return
+	}
+
+	fmt.Fprintln(w, "Dry run: these changes would be applied (nothing written):")
+	for _, c := range changes {
+		target := c.Pattern
+		if len(c.Rules) > 0 {
+			target = fmt.Sprintf("%s [%s]", c.Pattern, strings.Join(c.Rules, ", "))
+		}
+		if c.Location != "" {
+			target += " in " + c.Location
+		}
+		fmt.Fprintf(w, "\n  • %s\n", target)
+		fmt.Fprintf(w, "      confidence: %s → %s\n", rules.Level(c.BeforeConfidence).Label(), rules.Level(c.AfterConfidence).Label())
+		fmt.Fprintf(w, "      action:     %s → %s\n", c.BeforeAction, c.AfterAction)
+		fmt.Fprintf(w, "      evidence:   %d observations, %.0f%% accepted\n", c.Observations, c.AcceptRate*100)
+		if c.Rationale != "" {
+			fmt.Fprintf(w, "      why:        %s\n", c.Rationale)
+		}
+	}
+}
+
+// runSimulate shows what reflecting on the sessions in dir would change,
+// without reading real sessions or writing anything
+func runSimulate(stdout, stderr io.Writer, dir string, useLLM bool) int {
+	rs, err := rules.Load()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error loading rules: %v\n", err)
+		return exitError
+	}
+
+	var client *llm.OllamaClient
+	if useLLM {
+		client = configuredClient()
+	}
 	learner := learning.NewLearner(rs, client)
 
-	if err := learner.AddPreference("always_delete", pattern, "", "User specified"); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		return
+	result, err := simulate(learner, dir, useLLM)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		if useLLM {
+			return exitLLMUnavailable
+		}
+		return exitError
+	}
+
+	fmt.Fprintf(stdout, "Simulated %d sessions, %d total interactions\n",
+		result.AnalysisSummary.SessionsAnalyzed,
+		result.AnalysisSummary.TotalInteractions)
+	fmt.Fprintf(stdout, "Overall acceptance rate: %.0f%%\n\n",
+		result.AnalysisSummary.OverallAcceptanceRate*100)
+	printChanges(stdout, learner.PreviewCalibrations(result))
+	if result.Insights != "" {
+		fmt.Fprintf(stdout, "\nInsights:\n%s\n", result.Insights)
+	}
+	return exitOK
+}
+
+// simulate reflects on the fixture sessions in dir, by counting or by
+// asking the LLM
+func simulate(learner *learning.Learner, dir string, useLLM bool) (*learning.ReflectionResult, error) {
+	sessions, err := session.LoadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	if useLLM {
+		return learner.ReflectOn(sessions)
 	}
+	return learner.ReflectHeuristic(sessions)
+}
 
-	fmt.Printf("✓ Will always delete: %s\n", pattern)
+func runAlways(stdout, stderr io.Writer, pa patternArgs) int {
+	return addPreference(stdout, stderr, "always_delete", "Will always delete", pa)
 }
 
-func runNever(pattern string) {
+func runNever(stdout, stderr io.Writer, pa patternArgs) int {
+	return addPreference(stdout, stderr, "never_delete", "Will never delete", pa)
+}
+
+// addPreference previews what a preference would match, confirms if that's
+// surprisingly broad, then saves it
+func addPreference(stdout, stderr io.Writer, prefType, done string, pa patternArgs) int {
 	rs, _ := rules.Load()
-	client := llm.NewClient("kimi-k2-thinking:cloud")
+	client := configuredClient()
 	learner := learning.NewLearner(rs, client)
 
-	if err := learner.AddPreference("never_delete", pattern, "", "User specified"); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		return
+	root := pa.root()
+	fmt.Fprintf(stdout, "%sChecking what %s matches under %s...%s", Dim, pa.pattern, root, Reset)
+	listing, _, err := scan.Cached(root, pa.rescan)
+	fmt.Fprint(stdout, "\r\033[K")
+	if err != nil {
+		fmt.Fprintf(stderr, "%sCould not preview matches: %v%s\n", Dim, err, Reset)
+	} else {
+		preview := learner.PreviewPreference(prefType, pa.pattern, pa.location, listing)
+		fmt.Fprintf(stdout, "%s currently matches %d paths (%s).\n", pa.pattern, preview.Matches, formatBytes(preview.TotalSize))
+
+		if preview.NeedsConfirmation && !pa.yes {
+			fmt.Fprintf(stdout, "%sThat's a lot to delete automatically.%s Run 'forge rules test %q' to see them.\n", Yellow, Reset, pa.pattern)
+			fmt.Fprint(stdout, "Add this preference anyway? [y/N] ")
+			input, err := prompt.NewLineReader(os.Stdin).ReadLine()
+			if err != nil || !prompt.IsYes(input, false) {
+				fmt.Fprintln(stdout, "Left preferences unchanged.")
+				return exitAborted
+			}
+		}
+	}
+
+	if err := learner.AddPreference(prefType, pa.pattern, pa.location, "User specified"); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitError
+	}
+
+	target := pa.pattern
+	if pa.location != "" {
+		target += " in " + pa.location
+	}
+	fmt.Fprintf(stdout, "✓ %s: %s\n", done, target)
+
+	return exitOK
+}
+
+// runTeach asks about common kinds of clutter and saves the answers as
+// preferences, skipping any already answered
+func runTeach(stdout, stderr io.Writer) int {
+	rs, _ := rules.Load()
+	learner := learning.NewLearner(rs, nil)
+	in := prompt.NewLineReader(os.Stdin)
+
+	fmt.Fprintln(stdout, "Tell me how to treat some common clutter, so I don't have to learn it the slow way.")
+	fmt.Fprintf(stdout, "%sAnswer a (always delete), n (never delete), k (ask each time), or press Enter to skip.%s\n\n", Dim, Reset)
+
+	taught, skipped := 0, 0
+	for _, topic := range learning.TeachTopics {
+		if learner.Taught(topic) {
+			skipped++
+			continue
+		}
+		for {
+			fmt.Fprintf(stdout, "%s? [a/n/k/Enter] ", topic.Name)
+			input, err := in.ReadLine()
+			if err != nil {
+				fmt.Fprintln(stdout)
+				fmt.Fprintf(stdout, "Stopped; kept the %d answers so far.\n", taught)
+				return exitAborted
+			}
+			prefType, ok := learning.ParseTeachAnswer(input)
+			if !ok {
+				fmt.Fprintf(stdout, "%sPlease answer a, n, k, or press Enter to skip.%s\n", Dim, Reset)
+				continue
+			}
+			if prefType != "" {
+				if err := learner.Teach(topic, prefType); err != nil {
+					fmt.Fprintf(stderr, "Error: %v\n", err)
+					return exitError
+				}
+				taught++
+			}
+			break
+		}
 	}
 
-	fmt.Printf("✓ Will never delete: %s\n", pattern)
+	if skipped > 0 {
+		fmt.Fprintf(stdout, "%sSkipped %d you'd already answered.%s\n", Dim, skipped, Reset)
+	}
+	if taught == 0 {
+		fmt.Fprintln(stdout, "Nothing new learned.")
+		return exitNothingToDo
+	}
+	fmt.Fprintf(stdout, "✓ Saved %d answers to %s.\n", taught, filepath.Join(rules.ForgeDir(), "rules", "preferences.yaml"))
+	return exitOK
 }
 
-func runForget(pattern string) {
+func runForget(stdout io.Writer, pattern string) int {
 	rs, _ := rules.Load()
-	client := llm.NewClient("kimi-k2-thinking:cloud")
+	client := configuredClient()
 	learner := learning.NewLearner(rs, client)
 
 	if learner.ForgetCalibration(pattern) {
-		fmt.Printf("✓ Forgot learned behavior for: %s\n", pattern)
+		fmt.Fprintf(stdout, "✓ Forgot learned behavior for: %s\n", pattern)
+	} else {
+		fmt.Fprintf(stdout, "No learned behavior found for: %s\n", pattern)
+	}
+
+	return exitOK
+}
+
+// runHide hides a report category or shows it again
+func runHide(stdout, stderr io.Writer, action, category string) int {
+	rs, err := rules.Load()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitError
+	}
+
+	if action == "hide" {
+		rs.Hide(category)
+	} else if !rs.Unhide(category) {
+		fmt.Fprintf(stdout, "%s isn't hidden.\n", category)
+		return exitOK
+	}
+
+	if err := rs.Save(); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitError
+	}
+	if action == "hide" {
+		fmt.Fprintf(stdout, "✓ Hid %s; it's still scanned and counted, but left out of reports until 'forge unhide %s'.\n", category, category)
 	} else {
-		fmt.Printf("No learned behavior found for: %s\n", pattern)
+		fmt.Fprintf(stdout, "✓ %s is shown again.\n", category)
 	}
+	return exitOK
 }
 
-func runReset(includePrefs bool) {
+func runReset(stdout, stderr io.Writer, includePrefs bool) int {
 	rs, _ := rules.Load()
-	client := llm.NewClient("kimi-k2-thinking:cloud")
+	client := configuredClient()
 	learner := learning.NewLearner(rs, client)
 
 	if err := learner.Reset(includePrefs); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		return
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitError
 	}
 
 	if includePrefs {
-		fmt.Println("✓ Reset all calibrations and preferences.")
+		fmt.Fprintln(stdout, "✓ Reset all calibrations and preferences.")
 	} else {
-		fmt.Println("✓ Reset calibrations (preferences kept).")
+		fmt.Fprintln(stdout, "✓ Reset calibrations (preferences kept).")
 	}
+
+	return exitOK
 }
 
-func runShowRules() {
+func runShowRules(stdout, stderr io.Writer) int {
 	rs, err := rules.Load()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		return
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitError
 	}
 
-	fmt.Println("Base rules:")
-	for name, rule := range rs.Base.Categories {
-		fmt.Printf("  %s: confidence=%s, risk=%s, action=%s\n",
-			name, rule.Confidence, rule.Risk, rule.DefaultAction)
+	fmt.Fprintln(stdout, "Base rules:")
+	for _, name := range slices.Sorted(maps.Keys(rs.Base.Categories)) {
+		rule := rs.Base.Categories[name]
+		fmt.Fprintf(stdout, "  %s: confidence=%s, risk=%s, action=%s",
+			name, rules.Level(rule.Confidence).Label(), rules.Level(rule.Risk).Label(), rule.DefaultAction)
+		if rs.IsDisabled(name) {
+			fmt.Fprintf(stdout, " %s(disabled)%s", Dim, Reset)
+		}
+		fmt.Fprintln(stdout)
 	}
 
 	if len(rs.Calibrations.Adjustments) > 0 {
-		fmt.Println("\nCalibrations:")
+		fmt.Fprintln(stdout, "\nCalibrations:")
 		for _, cal := range rs.Calibrations.Adjustments {
-			fmt.Printf("  %s: %s → %s (%s)\n",
+			fmt.Fprintf(stdout, "  %s: %s → %s (%s)\n",
 				cal.Pattern, cal.Original.Action, cal.Calibrated.Action, cal.Reason)
 		}
 	}
 
 	if len(rs.Preferences.AlwaysDelete) > 0 || len(rs.Preferences.NeverDelete) > 0 {
-		fmt.Println("\nPreferences:")
+		fmt.Fprintln(stdout, "\nPreferences:")
 		for _, p := range rs.Preferences.AlwaysDelete {
-			fmt.Printf("  always delete: %s\n", p.Pattern)
+			fmt.Fprintf(stdout, "  always delete: %s\n", p.Pattern)
 		}
 		for _, p := range rs.Preferences.NeverDelete {
-			fmt.Printf("  never delete: %s\n", p.Pattern)
+			fmt.Fprintf(stdout, "  never delete: %s\n", p.Pattern)
+		}
+	}
+
+	if len(rs.Preferences.Hidden) > 0 {
+		fmt.Fprintf(stdout, "\nHidden categories: %s\n", strings.Join(rs.Preferences.Hidden, ", "))
+	}
+
+	return exitOK
+}
+
+// runRulesToggle turns a base rule off or back on
+func runRulesToggle(stdout, stderr io.Writer, action, name string) int {
+	rs, err := rules.Load()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitError
+	}
+
+	if action == "disable" {
+		if err := rs.Disable(name); err != nil {
+			fmt.Fprintf(stderr, "Error: %v. Run 'forge rules' to list them.\n", err)
+			return exitError
+		}
+	} else if !rs.Enable(name) {
+		fmt.Fprintf(stdout, "%s isn't disabled.\n", name)
+		return exitOK
+	}
+
+	if err := rs.Save(); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitError
+	}
+	if action == "disable" {
+		fmt.Fprintf(stdout, "✓ Disabled %s; it won't match anything until 'forge rules enable %s'.\n", name, name)
+	} else {
+		fmt.Fprintf(stdout, "✓ Enabled %s.\n", name)
+	}
+	return exitOK
+}
+
+// runRulesCompact merges calibrations learned more than once for a pattern
+func runRulesCompact(stdout, stderr io.Writer) int {
+	rs, err := rules.Load()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitError
+	}
+
+	removed := rs.Calibrations.Compact()
+	if removed == 0 {
+		fmt.Fprintln(stdout, "No duplicate calibrations.")
+		return exitOK
+	}
+	if err := rs.Save(); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitError
+	}
+	fmt.Fprintf(stdout, "✓ Merged %d duplicate calibrations; %d left.\n", removed, len(rs.Calibrations.Adjustments))
+	return exitOK
+}
+
+// runRulesDiff shows how the effective rules differ from base
+func runRulesDiff(stdout, stderr io.Writer) int {
+	rs, err := rules.Load()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitError
+	}
+
+	changes := rs.Diff()
+	if len(changes) == 0 {
+		fmt.Fprintln(stdout, "All rules match their base settings.")
+		return exitOK
+	}
+
+	fmt.Fprintln(stdout, "Rules changed from base:")
+	printRuleDiff(stdout, changes)
+	return exitOK
+}
+
+// printRuleDiff shows each rule's base → effective settings and why
+func printRuleDiff(w io.Writer, changes []rules.RuleChange) {
+	for _, c := range changes {
+		fmt.Fprintf(w, "\n  %s%s%s\n", Bold, c.Rule, Reset)
+		if c.BaseConfidence != c.EffectiveConfidence {
+			fmt.Fprintf(w, "      confidence: %s → %s\n", rules.Level(c.BaseConfidence).Label(), rules.Level(c.EffectiveConfidence).Label())
+		}
+		if c.BaseAction != c.EffectiveAction {
+			fmt.Fprintf(w, "      action:     %s → %s\n", c.BaseAction, c.EffectiveAction)
+		}
+		if c.Pattern != "" {
+			learned := c.Pattern
+			if t, err := time.Parse(time.RFC3339, c.LearnedAt); err == nil {
+				learned += ", learned " + t.Format("2006-01-02")
+			}
+			fmt.Fprintf(w, "      %sfrom:       %s%s\n", Dim, learned, Reset)
+		}
+		if c.Reason != "" {
+			fmt.Fprintf(w, "      %swhy:        %s%s\n", Dim, c.Reason, Reset)
+		}
+	}
+}
+
+// newRuleChanges returns the entries of after that aren't in before
+func newRuleChanges(before, after []rules.RuleChange) []rules.RuleChange {
+	seen := make(map[rules.RuleChange]bool, len(before))
+	for _, c := range before {
+		seen[c] = true
+	}
+	var changed []rules.RuleChange
+	for _, c := range after {
+		if !seen[c] {
+			changed = append(changed, c)
+		}
+	}
+	return changed
+}
+
+// patternArgs is a pattern plus the flags the rule commands accept
+type patternArgs struct {
+	pattern  string
+	location string
+	rescan   bool // ignore any cached scan
+	yes      bool // skip the broad-pattern confirmation
+}
+
+func parsePatternArgs(args []string) patternArgs {
+	var pa patternArgs
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--location":
+			if i+1 < len(args) {
+				i++
+				pa.location = args[i]
+			}
+		case "--rescan":
+			pa.rescan = true
+		case "--yes", "-y":
+			pa.yes = true
+		default:
+			pa.pattern = args[i]
+		}
+	}
+	return pa
+}
+
+// root is the directory to scan: the location if given, otherwise home
+func (pa patternArgs) root() string {
+	if pa.location == "" {
+		return rules.ExpandHome("~")
+	}
+	return rules.ExpandHome(pa.location)
+}
+
+// runRulesTest lists what a pattern (and optional location) would match on disk
+func runRulesTest(stdout, stderr io.Writer, args []string) int {
+	pa := parsePatternArgs(args)
+	if pa.pattern == "" {
+		fmt.Fprintln(stdout, "Usage: forge rules test <pattern> [--location <dir>] [--rescan]")
+		return exitError
+	}
+	pattern, location, root := pa.pattern, pa.location, pa.root()
+
+	fmt.Fprintf(stdout, "%sScanning %s...%s", Dim, root, Reset)
+	listing, cached, err := scan.Cached(root, pa.rescan)
+	fmt.Fprint(stdout, "\r\033[K")
+	if err != nil {
+		fmt.Fprintf(stderr, "Error scanning %s: %v\n", root, err)
+		return exitError
+	}
+	if cached {
+		fmt.Fprintf(stdout, "%sUsing scan from %s (--rescan to refresh)%s\n", Dim, listing.ScannedAt.Format("15:04"), Reset)
+	}
+
+	sizes := make(map[string]int64, len(listing.Entries))
+	for _, e := range listing.Entries {
+		sizes[e.Path] = e.Size
+	}
+	matches := rules.MatchPaths(listing.Paths(), pattern, location)
+	if len(matches) == 0 {
+		fmt.Fprintf(stdout, "%q matches nothing under %s.\n", pattern, root)
+		return exitNothingToDo
+	}
+
+	sort.Slice(matches, func(i, j int) bool { return sizes[matches[i]] > sizes[matches[j]] })
+
+	var total int64
+	for _, m := range matches {
+		total += sizes[m]
+	}
+
+	const shown = 25
+	for i, m := range matches {
+		if i == shown {
+			fmt.Fprintf(stdout, "  %s... and %d more%s\n", Dim, len(matches)-shown, Reset)
+			break
 		}
+		fmt.Fprintf(stdout, "  %10s  %s\n", formatBytes(sizes[m]), m)
+	}
+	fmt.Fprintf(stdout, "\n%s%q matches %d paths, %s total%s\n", Bold, pattern, len(matches), formatBytes(total), Reset)
+	if listing.Errors > 0 {
+		fmt.Fprintf(stdout, "%s%d paths could not be read and were not checked.%s\n", Dim, listing.Errors, Reset)
 	}
+	return exitOK
 }
 
-func runShowSessions() {
+func runShowSessions(stdout, stderr io.Writer) int {
 	sessions, err := session.ListSessions(10)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		return
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitError
 	}
 
 	if len(sessions) == 0 {
-		fmt.Println("No sessions yet.")
-		return
+		fmt.Fprintln(stdout, "No sessions yet.")
+		return exitOK
 	}
 
-	fmt.Println("Recent sessions:")
+	fmt.Fprintln(stdout, "Recent sessions:")
 	for _, id := range sessions {
 		s, err := session.LoadSession(id)
 		if err != nil {
 			continue
 		}
-		fmt.Printf("  %s - %s (%d interactions)\n",
+		fmt.Fprintf(stdout, "  %s - %s (%d interactions)\n",
 			s.ID, s.Tool, len(s.Interactions))
 	}
+
+	return exitOK
+}
+
+// runShowSession replays one past session as a timeline
+func runShowSession(stdout, stderr io.Writer, id string, noPager bool) int {
+	s, code := loadSessionArg(stderr, id)
+	if s == nil {
+		return code
+	}
+	report := pager.Start(noPager || stdout != os.Stdout)
+	renderSession(report.Writer(stdout), s)
+	report.Stop()
+	return exitOK
+}
+
+// runExportSession prints a session's JSON, with --anonymize swapping paths
+// for placeholders so it can be attached to a bug report
+func runExportSession(stdout, stderr io.Writer, args []string) int {
+	var id string
+	anonymize := false
+	for _, arg := range args {
+		switch {
+		case arg == "--anonymize":
+			anonymize = true
+		case id == "" && !strings.HasPrefix(arg, "-"):
+			id = arg
+		default:
+			fmt.Fprintf(stderr, "Unknown argument %q\n", arg)
+			return exitError
+		}
+	}
+	if id == "" {
+		fmt.Fprintln(stdout, "Usage: forge sessions export <id> [--anonymize]")
+		return exitError
+	}
+
+	s, code := loadSessionArg(stderr, id)
+	if s == nil {
+		return code
+	}
+	if anonymize {
+		s = s.Anonymized()
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		fmt.Fprintf(stderr, "Error encoding session: %v\n", err)
+		return exitError
+	}
+	fmt.Fprintln(stdout, string(data))
+	return exitOK
+}
+
+// loadSessionArg loads the session named on the command line, or explains
+// why it couldn't and returns the exit code
+func loadSessionArg(stderr io.Writer, id string) (*session.Session, int) {
+	// Accept the file name too, as ls ~/.forge/sessions shows it
+	id = strings.TrimSuffix(id, ".json")
+	if id == "" || id != filepath.Base(id) {
+		fmt.Fprintf(stderr, "Error: %q is not a session id\n", id)
+		return nil, exitError
+	}
+
+	s, err := session.LoadSession(id)
+	if errors.Is(err, fs.ErrNotExist) {
+		fmt.Fprintf(stderr, "No session %q. Run 'forge sessions' to list recent ones.\n", id)
+		return nil, exitError
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "Error reading session %s: %v\n", id, err)
+		return nil, exitError
+	}
+	return s, exitOK
+}
+
+// renderSession writes a session's interactions in order, then its outcome
+func renderSession(w io.Writer, s *session.Session) {
+	fmt.Fprintf(w, "%s%s%s  %s", Bold, s.ID, Reset, s.Tool)
+	if !s.Timestamp.IsZero() {
+		fmt.Fprintf(w, "  %s%s%s", Dim, s.Timestamp.Format("Mon Jan 2 2006 15:04"), Reset)
+	}
+	if s.DurationMs > 0 {
+		fmt.Fprintf(w, "%s, %s%s", Dim, (time.Duration(s.DurationMs) * time.Millisecond).Round(time.Second), Reset)
+	}
+	fmt.Fprintln(w)
+	if sum := s.ScanSummary; sum.TotalScannedBytes > 0 || sum.CategoriesFound > 0 {
+		fmt.Fprintf(w, "%sScanned %s in %d files, %d categories found%s\n",
+			Dim, formatBytes(sum.TotalScannedBytes), sum.TotalFiles, sum.CategoriesFound, Reset)
+	}
+	if len(s.Context.FlagsUsed) > 0 {
+		fmt.Fprintf(w, "%sFlags: %s%s\n", Dim, strings.Join(s.Context.FlagsUsed, " "), Reset)
+	}
+	fmt.Fprintln(w)
+
+	if len(s.Interactions) == 0 {
+		fmt.Fprintf(w, "%sNo suggestions were made.%s\n", Dim, Reset)
+	}
+	for n, i := range s.Interactions {
+		icon, color := responseStyle(i.UserResponse)
+		fmt.Fprintf(w, "%2d. %s%s %-13s%s %s%s%s", n+1, color, icon, i.UserResponse, Reset, Bold, i.Category, Reset)
+		if i.Item != "" {
+			fmt.Fprintf(w, " %s", i.Item)
+		}
+		var details []string
+		if i.ItemsPresented > 0 {
+			details = append(details, fmt.Sprintf("%d items", i.ItemsPresented))
+		}
+		if i.TotalSize > 0 {
+			details = append(details, formatBytes(i.TotalSize))
+		}
+		if i.Confidence != "" {
+			details = append(details, i.Confidence+" confidence")
+		}
+		if len(details) > 0 {
+			fmt.Fprintf(w, " %s(%s)%s", Dim, strings.Join(details, ", "), Reset)
+		}
+		fmt.Fprintln(w)
+
+		if i.Suggestion != "" {
+			fmt.Fprintf(w, "      %s%s%s\n", Dim, i.Suggestion, Reset)
+		}
+		if i.UserComment != "" {
+			fmt.Fprintf(w, "      %s\"%s\"%s\n", Dim, i.UserComment, Reset)
+		}
+		if i.BytesFreed > 0 {
+			fmt.Fprintf(w, "      %sFreed %s%s\n", Green, formatBytes(i.BytesFreed), Reset)
+		}
+	}
+
+	out := s.Outcome
+	fmt.Fprintf(w, "\n%sOutcome:%s freed %s, %d deleted, %d kept",
+		Bold, Reset, formatBytes(out.TotalFreed), out.ItemsDeleted, out.ItemsKept)
+	if out.Regrets > 0 {
+		fmt.Fprintf(w, ", %s%d regretted%s", Red, out.Regrets, Reset)
+	}
+	fmt.Fprintln(w)
+	if rate, ok := s.AcceptanceRate(); ok {
+		fmt.Fprintf(w, "%sAcceptance rate:%s %.0f%%\n", Bold, Reset, rate*100)
+	}
+	if out.UserSatisfaction != nil {
+		fmt.Fprintf(w, "%sSatisfaction:%s %d/5\n", Bold, Reset, *out.UserSatisfaction)
+	}
+}
+
+// responseStyle is the icon and color for a recorded user response
+func responseStyle(response string) (string, string) {
+	switch response {
+	case "accept", "auto_accepted", "modify":
+		return "✓", Green
+	case "reject":
+		return "✗", Red
+	case "skip":
+		return "→", Yellow
+	default:
+		return "·", Dim
+	}
+}
+
+func formatBytes(b int64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%d B", b)
+	}
+	div, exp := int64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(b)/float64(div), "KMGTPE"[exp])
 }
 
 func getToolDescription(tool string) string {
 	switch tool {
 	case "forge-dust":
-		return "Firing up the furnace to smelt away disk clutter..."
+		return messages.Get("tool.dust")
 	case "forge-habits":
-		return "Examining your workflow to hammer out inefficiencies..."
+		return messages.Get("tool.habits")
+	default:
+		return messages.Getf("tool.other", tool)
+	}
+}
+
+// spinnerMode is how progress is shown while a tool runs
+type spinnerMode int
+
+const (
+	spinnerOff    spinnerMode = iota // Nothing: stdout is redirected, or for JSON
+	spinnerStatic                    // One plain line, for --quiet
+	spinnerThemed                    // The animated spinner with rotating status messages
+)
+
+// chooseSpinner picks how to show progress. Redirected output gets nothing,
+// since a spinner's redraws are only noise in a log.
+func chooseSpinner(jsonOut, quiet, tty bool) spinnerMode {
+	switch {
+	case jsonOut || !tty:
+		return spinnerOff
+	case quiet:
+		return spinnerStatic
 	default:
-		return fmt.Sprintf("Forging %s...", tool)
+		return spinnerThemed
 	}
 }
 
-func showSpinner(prefix string, done chan bool) {
+// startSpinner shows progress in mode until the returned stop is called,
+// which waits for the spinner to finish before clearing its line
+func startSpinner(w io.Writer, mode spinnerMode, prefix string) (stop func()) {
+	switch mode {
+	case spinnerStatic:
+		fmt.Fprintf(w, "%s%s...%s", Dim, prefix, Reset)
+		return func() { fmt.Fprint(w, "\r\033[K") }
+	case spinnerThemed:
+		done := make(chan struct{})
+		stopped := make(chan struct{})
+		go func() {
+			defer close(stopped)
+			showSpinner(w, done)
+		}()
+		return func() {
+			close(done)
+			<-stopped
+			fmt.Fprint(w, "\r\033[K")
+		}
+	default:
+		return func() {}
+	}
+}
+
+func showSpinner(w io.Writer, done <-chan struct{}) {
 	frames := []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
 
 	// Rotating status messages with forge personality
-	statusMessages := []string{
-		"Heating up the forge",
-		"Stoking the flames",
-		"Examining the ore",
-		"Working the bellows",
-		"Smelting the data",
-		"Hammering out details",
-		"Shaping raw findings",
-		"Tempering the results",
-		"Striking while hot",
-		"Forging insights",
-		"Refining the metal",
-		"Checking the crucible",
-		"Quenching the analysis",
-		"Polishing the output",
-		"Annealing the findings",
-		"Nearly forged",
-	}
+	statusMessages := messages.Lines("spinner.statuses")
 
 	i := 0
 	msgIndex := 0
 	lastMsgChange := time.Now()
 	msgInterval := 8 * time.Second // Change message every 8 seconds
 
+	tick := time.NewTicker(80 * time.Millisecond)
+	defer tick.Stop()
 	for {
+		// Change status message periodically
+		if time.Since(lastMsgChange) > msgInterval {
+			msgIndex = (msgIndex + 1) % len(statusMessages)
+			lastMsgChange = time.Now()
+		}
+
+		currentMsg := statusMessages[msgIndex]
+		fmt.Fprintf(w, "\r\033[K%s%s %s...%s", Cyan, frames[i%len(frames)], currentMsg, Reset)
+		i++
+
 		select {
 		case <-done:
 			return
-		default:
-			// Change status message periodically
-			if time.Since(lastMsgChange) > msgInterval {
-				msgIndex = (msgIndex + 1) % len(statusMessages)
-				lastMsgChange = time.Now()
+		case <-tick.C:
+		}
+	}
+}
+
+// configuredModels returns the fallback chain of models from config.yaml
+func configuredModels() []string {
+	cfg, _ := config.Load()
+	return cfg.Models()
+}
+
+// configuredClient returns a client for the models in config.yaml
+func configuredClient() *llm.OllamaClient {
+	cfg, _ := config.Load()
+	return cfg.Client(cfg.Models())
+}
+
+// runModel lists installed Ollama models or pulls one
+func runModel(stdout, stderr io.Writer, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(stdout, "Usage: forge model list | forge model ping | forge model pull [name]")
+		return exitError
+	}
+	chain := configuredModels()
+	model := chain[0]
+
+	switch args[0] {
+	case "list":
+		models, err := llm.NewClient(model).ListModels()
+		if err != nil {
+			fmt.Fprintf(stderr, "Could not reach Ollama: %v\n", err)
+			fmt.Fprintf(stderr, "Is it running? Start it with: ollama serve\n")
+			return exitLLMUnavailable
+		}
+		if len(models) == 0 {
+			fmt.Fprintf(stdout, "No models installed. Get forge's default with: forge model pull\n")
+			return exitNothingToDo
+		}
+
+		selected, found := llm.SelectModel(chain, models)
+		for _, m := range models {
+			marker := " "
+			if found && (m.Name == selected || m.Name == selected+":latest") {
+				marker = "*"
 			}
+			fmt.Fprintf(stdout, "  %s %-36s %10s  %s\n", marker, m.Name, formatBytes(m.Size), m.ModifiedAt.Format("2006-01-02"))
+		}
+		if found {
+			fmt.Fprintf(stdout, "\n%s* the model forge uses%s\n", Dim, Reset)
+		} else {
+			fmt.Fprintf(stdout, "\n%sNone of forge's models (%s) is installed: forge model pull%s\n", Yellow, strings.Join(chain, ", "), Reset)
+		}
+		return exitOK
 
-			currentMsg := statusMessages[msgIndex]
-			fmt.Printf("\r\033[K%s%s %s...%s", Cyan, frames[i%len(frames)], currentMsg, Reset)
-			i++
-			time.Sleep(80 * time.Millisecond)
+	case "ping":
+		client := configuredClient()
+		latency, err := client.Ping()
+		if err != nil {
+			fmt.Fprintf(stderr, "%s didn't answer: %v\n", client.Model, err)
+			return exitLLMUnavailable
+		}
+		printLatency(stdout, client.Model, latency)
+		return exitOK
+
+	case "pull":
+		if len(args) > 1 {
+			model = args[1]
 		}
+		cmd, err := llm.PullCommand(model)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return exitError
+		}
+		fmt.Fprintf(stdout, "Pulling %s...\n", model)
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, stdout, stderr
+		if err := cmd.Run(); err != nil {
+			fmt.Fprintf(stderr, "ollama pull %s failed: %v\n", model, err)
+			return exitError
+		}
+		return exitOK
 	}
+
+	fmt.Fprintln(stdout, "Usage: forge model list | forge model ping | forge model pull [name]")
+	return exitError
 }
 
-func printHelp() {
-	fmt.Printf(`forge v%s - Adaptive system optimization toolkit
+func printHelp(w io.Writer) {
+	fmt.Fprintf(w, `forge v%s - Adaptive system optimization toolkit
 
 Usage:
   forge <tool> [flags]     Run a tool with adaptive interaction
@@ -435,23 +1718,55 @@ Tools:
   habits                   Shell history analysis
 
 Commands:
-  review                   Show what forge has learned
-  learn                    Force learning reflection
-  always <pattern>         Always delete files matching pattern
-  never <pattern>          Never delete files matching pattern
+  clean                    Quick scan, then clean only caches that rebuild themselves, after one confirmation
+  assess --input <file>    Assess saved --json tool output without rescanning
+  review                   Show what forge has learned (--no-pager)
+  learn [--dry-run]        Force learning reflection (--dry-run previews changes)
+  teach                    Answer a few questions to seed preferences for common clutter
+  always <pattern>         Always delete files matching pattern (--location <dir>, --yes)
+  never <pattern>          Never delete files matching pattern (--location <dir>)
   forget <pattern>         Forget learned behavior for pattern
+  hide <category>          Leave a category out of reports, e.g. downloads (still counted)
+  unhide <category>        Show a hidden category again
   reset [--all]            Reset calibrations (--all includes preferences)
   rules                    Show current ruleset
+  rules --diff             Show which rules learning changed, when, and why
+  rules compact            Merge calibrations learned more than once for a pattern
+  rules test <pattern>     List what a pattern would match (--location <dir>, --rescan)
+  rules disable <category> Turn off a shipped rule, e.g. personal_media
+  rules enable <category>  Turn it back on
   sessions                 Show recent sessions
+  sessions show <id>       Replay a past session: suggestions, answers, outcome (--no-pager)
+  sessions export <id>     Print a session's JSON; --anonymize hides paths for bug reports
+  model list               Show the models installed in Ollama
+  model ping               Time a trivial answer from the model forge uses
+  model pull [name]        Download a model (default: the first configured one)
   help                     Show this help
 
 Examples:
   forge dust               Run disk cleanup with adaptive guidance
+  forge clean              Just clear the safe caches (same as dust --quick, low-risk caches only)
   forge dust --quick       Quick mode, bias toward auto-cleanup
+  forge dust --explain-mode  Show why each category got its interaction mode
+  forge dust --preview     Show the assessment without cleaning anything
+  forge dust --target 20GB Propose just enough safe cleanup to free 20GB
+  forge dust --safe        Only offer caches and other things that rebuild themselves
+  forge dust --plain       Plain language instead of the forge's metaphors (any command)
+  forge dust --model qwen3:8b,llama3.2  Use the first of these models that's installed
+  forge dust --verbose     Say how fast the model answers before starting
+  forge assess --input dust.json --preview
+  forge dust --json        Print the assessment as JSON, for other frontends
+  forge dust --yes         Clean what you accept without listing every path first
+  forge dust --quiet       A plain "Scanning..." instead of the forge's spinner
+  forge dust --compact     One line per category, expanded when you pick it
+  forge dust --show-kept   End by listing large items left alone, and why
+  forge dust --events run.ndjson  Stream what happens as JSON lines, for a GUI or log
   forge habits             Analyze shell history
   forge review             See what behaviors have been learned
   forge always "*.dmg"     Always auto-delete .dmg files
   forge never "*.mov"      Never suggest deleting .mov files
+  forge rules test "*.dmg" --location ~/Downloads
+  forge model pull         Get the model forge uses, before its first run
 
 The forge adapts to your preferences over time. Run 'forge review' to see
 what it has learned, or 'forge reset' to start fresh.
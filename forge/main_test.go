@@ -0,0 +1,570 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"forge/assessment"
+	"forge/conversation"
+	"forge/events"
+	"forge/learning"
+	"forge/messages"
+	"forge/rules"
+	"forge/session"
+)
+
+func TestOutcomeCode(t *testing.T) {
+	empty := &assessment.SessionAssessment{}
+	found := &assessment.SessionAssessment{
+		Categories: []assessment.CategoryAssessment{{Category: "node_modules"}},
+	}
+	aborted := fmt.Errorf("guided: %w", conversation.ErrAborted)
+
+	tests := []struct {
+		name           string
+		assess         *assessment.SessionAssessment
+		loopErr        error
+		partial        bool
+		llmUnavailable bool
+		want           int
+	}{
+		{"clean run", found, nil, false, false, exitOK},
+		{"no findings", empty, nil, false, false, exitNothingToDo},
+		{"scan error", found, nil, true, false, exitPartialScan},
+		{"scan error without findings", empty, nil, true, false, exitPartialScan},
+		{"llm unavailable", found, nil, false, true, exitLLMUnavailable},
+		{"no findings beats llm unavailable", empty, nil, false, true, exitNothingToDo},
+		{"abort", found, aborted, true, true, exitAborted},
+		{"loop error", found, errors.New("boom"), false, false, exitError},
+	}
+
+	for _, tt := range tests {
+		if got := outcomeCode(tt.assess, tt.loopErr, tt.partial, tt.llmUnavailable); got != tt.want {
+			t.Errorf("%s: outcomeCode() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAssessOutputFromFixture(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	data, err := os.ReadFile(filepath.Join("testdata", "dust.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs, err := rules.Load()
+	if err != nil {
+		t.Fatalf("rules.Load() error = %v", err)
+	}
+
+	tests := []struct {
+		args []string
+		want assessment.Mode
+	}{
+		{nil, assessment.ModeSuggest},
+		{[]string{"--quick"}, assessment.ModeAuto},
+		{[]string{"--careful"}, assessment.ModeGuided},
+	}
+
+	for _, tt := range tests {
+		toolOutput, assess, err := assessOutput(data, tt.args, rs, nil, runOptions{noLLM: true})
+		if err != nil {
+			t.Fatalf("assessOutput(%v) error = %v", tt.args, err)
+		}
+		if toolOutput.Tool != "forge-dust" {
+			t.Errorf("Tool = %q, want forge-dust", toolOutput.Tool)
+		}
+		if assess.OverallMode != tt.want {
+			t.Errorf("assessOutput(%v) mode = %s, want %s (%s)", tt.args, assess.OverallMode, tt.want, assess.ModeReason)
+		}
+	}
+}
+
+func TestCleanOnlyTargetsReversibleLowRisk(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	category := func(id string, risk string, reversible bool) string {
+		return fmt.Sprintf(`{"id": %q, "name": %q, "total_size": 1000,
+			"metadata": {"typical_risk": %q, "reversible": %v},
+			"items": [{"path": "/home/u/%s", "size": 1000, "type": %q}]}`, id, id, risk, reversible, id, id)
+	}
+	data := []byte(`{"tool": "forge-dust", "categories": [` + strings.Join([]string{
+		category("cache_directories", "low", true),
+		category("global_caches", "low", true),
+		category("system_caches", "high", true),
+		category("trash", "low", false),
+		category("downloads", "low", false),
+		category("large_files", "medium", false),
+		category("mystery", "", true), // Unknown risk counts as medium
+	}, ",") + `]}`)
+
+	_, assess, err := assessOutput(data, cleanFlags, &rules.RuleSet{}, nil, runOptions{noLLM: true, clean: true})
+	if err != nil {
+		t.Fatalf("assessOutput() error = %v", err)
+	}
+
+	var got []string
+	for _, cat := range assess.Categories {
+		got = append(got, cat.Category)
+		if !cat.Reversible || rules.ParseLevel(cat.Risk) != rules.LevelLow {
+			t.Errorf("clean offers %s: risk %q, reversible %v", cat.Category, cat.Risk, cat.Reversible)
+		}
+	}
+	if want := []string{"cache_directories", "global_caches"}; !slices.Equal(got, want) {
+		t.Errorf("clean offers %q, want %q", got, want)
+	}
+	if want := []string{"system_caches", "trash", "downloads", "large_files", "mystery"}; !slices.Equal(assess.Withheld, want) {
+		t.Errorf("Withheld = %q, want %q", assess.Withheld, want)
+	}
+}
+
+func TestCleanDeletesOnlyReversibleLowRisk(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	dir := t.TempDir()
+	paths := map[string]string{}
+	category := func(id string, risk string, reversible bool) string {
+		paths[id] = filepath.Join(dir, id)
+		if err := os.WriteFile(paths[id], make([]byte, 1000), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return fmt.Sprintf(`{"id": %q, "name": %q, "total_size": 1000,
+			"metadata": {"typical_risk": %q, "reversible": %v},
+			"items": [{"path": %q, "size": 1000, "type": %q}]}`, id, id, risk, reversible, paths[id], id)
+	}
+	data := []byte(`{"tool": "forge-dust", "categories": [` + strings.Join([]string{
+		category("cache_directories", "low", true),
+		category("system_caches", "high", true),
+		category("downloads", "low", false),
+	}, ",") + `]}`)
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.WriteString("y\n")
+	w.Close()
+	stdin := os.Stdin
+	os.Stdin = r
+	defer func() { os.Stdin = stdin }()
+
+	if code := converse(io.Discard, io.Discard, "forge-dust", data, cleanFlags, &rules.RuleSet{}, nil, runOptions{noLLM: true, clean: true}); code != exitOK {
+		t.Errorf("converse() = %d, want %d", code, exitOK)
+	}
+	for id, wantGone := range map[string]bool{"cache_directories": true, "system_caches": false, "downloads": false} {
+		if _, err := os.Lstat(paths[id]); os.IsNotExist(err) != wantGone {
+			t.Errorf("%s deleted = %v, want %v", id, os.IsNotExist(err), wantGone)
+		}
+	}
+}
+
+func TestAssessJSON(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"assess", "--input", filepath.Join("testdata", "dust.json"), "--json", "--no-llm"}, &stdout, &stderr); code != exitOK {
+		t.Errorf("forge assess --json = %d, want %d; stderr:\n%s", code, exitOK, &stderr)
+	}
+
+	var assess assessment.SessionAssessment
+	if err := json.Unmarshal(stdout.Bytes(), &assess); err != nil {
+		t.Fatalf("forge assess --json printed more than JSON: %v\n%s", err, &stdout)
+	}
+	if assess.Opening.Mode != assessment.ModeSuggest || assess.Opening.Categories != len(assess.Categories) ||
+		assess.Opening.Reclaimable != assess.TotalReclaimable || len(assess.Opening.TopCategories) == 0 {
+		t.Errorf("runAssess(--json) opening = %+v, want the assessment's mode, totals and top categories", assess.Opening)
+	}
+}
+
+func TestParseRunOptionsModel(t *testing.T) {
+	tests := []struct {
+		args     []string
+		models   []string
+		filtered []string
+		wantErr  bool
+	}{
+		{[]string{"--model", "qwen3:8b, llama3.2", "--quick"}, []string{"qwen3:8b", "llama3.2"}, []string{"--model", "qwen3:8b", "--quick"}, false},
+		{[]string{"--model=mistral"}, []string{"mistral"}, []string{"--model", "mistral"}, false},
+		{[]string{"--quick"}, nil, []string{"--quick"}, false},
+		{[]string{"--model"}, nil, nil, true},
+		{[]string{"--model", ","}, nil, nil, true},
+	}
+
+	for _, tt := range tests {
+		opts, filtered, err := parseRunOptions(tt.args)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseRunOptions(%v) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(opts.models, tt.models) {
+			t.Errorf("parseRunOptions(%v) models = %v, want %v", tt.args, opts.models, tt.models)
+		}
+		// The tool gets the first choice for its own LLM calls
+		if !tt.wantErr && !reflect.DeepEqual(filtered, tt.filtered) {
+			t.Errorf("parseRunOptions(%v) filtered = %v, want %v", tt.args, filtered, tt.filtered)
+		}
+	}
+}
+
+func TestParseRunOptionsTarget(t *testing.T) {
+	tests := []struct {
+		args     []string
+		target   int64
+		filtered int
+		wantErr  bool
+	}{
+		{[]string{"--target", "20GB", "--quick"}, 20 << 30, 1, false},
+		{[]string{"--target=500mb"}, 500 << 20, 0, false},
+		{[]string{"--target", "1.5"}, 3 << 19, 0, false}, // Bare numbers are MB, as in forge-dust
+		{[]string{"--target", "0"}, 0, 0, true},
+		{[]string{"--target"}, 0, 0, true},
+		{[]string{"--target", "lots"}, 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		opts, filtered, err := parseRunOptions(tt.args)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseRunOptions(%v) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && (opts.target != tt.target || len(filtered) != tt.filtered) {
+			t.Errorf("parseRunOptions(%v) = target %d, %v, want %d, %d passthrough", tt.args, opts.target, filtered, tt.target, tt.filtered)
+		}
+	}
+}
+
+func TestRenderSessionShowsEachInteraction(t *testing.T) {
+	s := &session.Session{
+		ID:   "sess_20260102_150405",
+		Tool: "forge-dust",
+		Interactions: []session.Interaction{
+			{Category: "node_modules", TotalSize: 3 << 30, UserResponse: "auto_accepted", BytesFreed: 3 << 30},
+			{Category: "large_files", Item: "~/Movies/trip.mov", UserResponse: "reject"},
+			{Category: "downloads", UserResponse: "viewed"},
+		},
+		Outcome: session.Outcome{TotalFreed: 3 << 30, ItemsDeleted: 1, ItemsKept: 1},
+	}
+
+	var buf bytes.Buffer
+	renderSession(&buf, s)
+	out := buf.String()
+
+	for _, i := range s.Interactions {
+		if !strings.Contains(out, i.Category) || !strings.Contains(out, i.UserResponse) {
+			t.Errorf("renderSession output is missing %s/%s:\n%s", i.Category, i.UserResponse, out)
+		}
+	}
+	if !strings.Contains(out, "50%") {
+		t.Errorf("renderSession output is missing the 50%% acceptance rate:\n%s", out)
+	}
+}
+
+func TestSimulateFromFixture(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	rs, err := rules.Load()
+	if err != nil {
+		t.Fatalf("rules.Load() error = %v", err)
+	}
+	learner := learning.NewLearner(rs, nil)
+
+	result, err := simulate(learner, filepath.Join("testdata", "simulate"), false)
+	if err != nil {
+		t.Fatalf("simulate() error = %v", err)
+	}
+	if result.AnalysisSummary.SessionsAnalyzed != 6 || result.AnalysisSummary.TotalInteractions != 13 {
+		t.Errorf("simulate() analyzed %d sessions, %d interactions, want 6, 13",
+			result.AnalysisSummary.SessionsAnalyzed, result.AnalysisSummary.TotalInteractions)
+	}
+
+	var got []string
+	for _, c := range learner.PreviewCalibrations(result) {
+		got = append(got, fmt.Sprintf("%s: %s/%s → %s/%s", c.Pattern,
+			c.BeforeConfidence, c.BeforeAction, c.AfterConfidence, c.AfterAction))
+	}
+	want := []string{
+		"*.dmg: medium/suggest_delete → low/suggest_delete",
+		"node_modules: high/suggest_delete → very_high/auto_delete",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("simulate() changes = %q, want %q", got, want)
+	}
+
+	if _, err := os.Stat(filepath.Join(home, ".forge")); !os.IsNotExist(err) {
+		t.Errorf("simulate() touched ~/.forge (stat error = %v)", err)
+	}
+}
+
+func TestShowRulesIsSorted(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	showRules := func() string {
+		var stdout bytes.Buffer
+		if code := run([]string{"rules"}, &stdout, io.Discard); code != exitOK {
+			t.Fatalf("forge rules = %d, want %d", code, exitOK)
+		}
+		return stdout.String()
+	}
+	first := showRules()
+	for range 5 {
+		if got := showRules(); got != first {
+			t.Fatalf("forge rules output changed between runs:\n%s\nthen\n%s", first, got)
+		}
+	}
+
+	var names []string
+	for _, line := range strings.Split(first, "\n") {
+		if name, _, ok := strings.Cut(strings.TrimPrefix(line, "  "), ": confidence="); ok {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 || !slices.IsSorted(names) {
+		t.Errorf("forge rules listed %q, want them sorted by name", names)
+	}
+}
+
+func TestPlainFlag(t *testing.T) {
+	t.Cleanup(func() { messages.Use(nil) })
+
+	args, plain := takeFlag([]string{"forge", "dust", "--plain", "--quick"}, "--plain")
+	if !plain || !reflect.DeepEqual(args, []string{"forge", "dust", "--quick"}) {
+		t.Errorf("takeFlag() = %q, %v, want the flag removed", args, plain)
+	}
+	if _, plain := takeFlag([]string{"forge", "dust"}, "--plain"); plain {
+		t.Error("takeFlag() found --plain where there was none")
+	}
+
+	useMessages(io.Discard, true)
+	if got := getToolDescription("forge-dust"); got != "Scanning for disk clutter..." {
+		t.Errorf("getToolDescription(forge-dust) with --plain = %q", got)
+	}
+}
+
+func TestUnwritableForgeDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	// A file where the directory should be can't be written into, even as root
+	forgeDir := filepath.Join(home, ".forge")
+	if err := os.WriteFile(forgeDir, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		os.Remove(forgeDir)
+		rules.CheckStorage()
+	})
+
+	var buf bytes.Buffer
+	useStorage(&buf)
+	if !strings.Contains(buf.String(), "won't be saved") {
+		t.Errorf("useStorage() warned %q, want it to say nothing will be saved", buf.String())
+	}
+	if !rules.Ephemeral() {
+		t.Fatal("Ephemeral() = false after an unwritable ~/.forge")
+	}
+
+	// Saves report they were skipped rather than failing halfway or pretending
+	rs, err := rules.Load()
+	if err != nil {
+		t.Fatalf("rules.Load() error = %v", err)
+	}
+	rs.Preferences.NeverDelete = append(rs.Preferences.NeverDelete, rules.Preference{Pattern: "*.psd"})
+	if err := rs.Save(); !errors.Is(err, rules.ErrEphemeral) {
+		t.Errorf("RuleSet.Save() error = %v, want ErrEphemeral", err)
+	}
+	if err := session.NewSession("forge-dust").Save(); !errors.Is(err, rules.ErrEphemeral) {
+		t.Errorf("Session.Save() error = %v, want ErrEphemeral", err)
+	}
+	if info, err := os.Stat(forgeDir); err != nil || info.IsDir() {
+		t.Errorf("~/.forge was replaced: %v", err)
+	}
+}
+
+func TestWrappedToolsCheckStorage(t *testing.T) {
+	t.Setenv("PATH", t.TempDir()) // No forge-dust or forge-habits to run
+	for _, tool := range []string{"forge-dust", "forge-habits"} {
+		home := t.TempDir()
+		t.Setenv("HOME", home)
+		rules.CheckStorage()
+		forgeDir := filepath.Join(home, ".forge")
+		os.RemoveAll(forgeDir)
+		if err := os.WriteFile(forgeDir, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		// The session is saved in ~/.forge, so it's checked before the tool runs
+		runTool(io.Discard, io.Discard, tool, []string{"--no-llm"})
+		if !rules.Ephemeral() {
+			t.Errorf("runTool(%s) didn't find ~/.forge unwritable", tool)
+		}
+		os.Remove(forgeDir)
+		rules.CheckStorage()
+	}
+}
+
+func TestChooseSpinner(t *testing.T) {
+	tests := []struct {
+		jsonOut, quiet, tty bool
+		want                spinnerMode
+	}{
+		{tty: true, want: spinnerThemed},
+		{quiet: true, tty: true, want: spinnerStatic},
+		{tty: false, want: spinnerOff},              // piped to a log
+		{quiet: true, tty: false, want: spinnerOff}, // quiet doesn't bring it back
+		{jsonOut: true, tty: true, want: spinnerOff},
+	}
+	for _, tt := range tests {
+		if got := chooseSpinner(tt.jsonOut, tt.quiet, tt.tty); got != tt.want {
+			t.Errorf("chooseSpinner(json=%v, quiet=%v, tty=%v) = %v, want %v", tt.jsonOut, tt.quiet, tt.tty, got, tt.want)
+		}
+	}
+}
+
+func TestParseRunOptionsQuiet(t *testing.T) {
+	opts, rest, err := parseRunOptions([]string{"--quiet", "--quick"})
+	if err != nil || !opts.quiet || !reflect.DeepEqual(rest, []string{"--quick"}) {
+		t.Errorf("parseRunOptions(--quiet --quick) = %+v, %q, %v", opts.quiet, rest, err)
+	}
+}
+
+func TestRunVersion(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"version"}, &stdout, &stderr); code != exitOK {
+		t.Errorf("run(version) = %d, want %d", code, exitOK)
+	}
+	if want := "forge v" + version + "\n"; stdout.String() != want {
+		t.Errorf("run(version) printed %q, want %q", stdout.String(), want)
+	}
+	if stderr.Len() > 0 {
+		t.Errorf("run(version) wrote %q to stderr, want nothing", stderr.String())
+	}
+
+	stdout.Reset()
+	if code := run([]string{"forget"}, &stdout, &stderr); code != exitError || !strings.Contains(stdout.String(), "Usage: forge forget") {
+		t.Errorf("run(forget) = %d, %q; want %d and its usage", code, stdout.String(), exitError)
+	}
+}
+
+func TestConversationPrintsToRunsWriter(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Cleanup(func() { messages.Use(nil) })
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.WriteString("n\n")
+	w.Close()
+	stdin := os.Stdin
+	os.Stdin = r
+	defer func() { os.Stdin = stdin }()
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"assess", "--plain", "--input", filepath.Join("testdata", "dust.json"), "--no-llm"}, &stdout, &stderr); code != exitOK {
+		t.Fatalf("forge assess = %d, want %d; stderr:\n%s", code, exitOK, &stderr)
+	}
+	for _, want := range []string{"FORGE", messages.Get("clean.declined")} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("forge assess printed %q, want it to include %q", stdout.String(), want)
+		}
+	}
+}
+
+func TestEventsForScriptedRun(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	// Real paths to delete, so only what's actually deleted is reported
+	dir := t.TempDir()
+	web, api := filepath.Join(dir, "web"), filepath.Join(dir, "api")
+	const size = 3000
+	input := filepath.Join(dir, "dust.json")
+	fixture := fmt.Sprintf(`{"tool": "forge-dust", "categories": [{
+		"id": "cache_directories", "name": "Cache Directories", "total_size": %d, "item_count": 2,
+		"metadata": {"typical_risk": "low", "reversible": true, "safe_action": "delete"},
+		"items": [{"path": %q, "size": 2000}, {"path": %q, "size": 1000}]}]}`, size, web, api)
+	if err := os.WriteFile(input, []byte(fixture), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(answer string) []events.Event {
+		for _, p := range []string{web, api} {
+			if err := os.WriteFile(p, []byte("cache"), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		r, w, err := os.Pipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.WriteString(answer)
+		w.Close()
+		stdin := os.Stdin
+		os.Stdin = r
+		defer func() { os.Stdin = stdin }()
+
+		path := filepath.Join(t.TempDir(), "run.ndjson")
+		if code := runAssess(io.Discard, io.Discard, []string{"--input", input, "--no-llm", "--events", path}); code != exitOK {
+			t.Fatalf("runAssess() = %d, want %d", code, exitOK)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []events.Event
+		for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+			var e events.Event
+			if err := json.Unmarshal([]byte(line), &e); err != nil {
+				t.Fatalf("line %q: %v", line, err)
+			}
+			got = append(got, e)
+		}
+		return got
+	}
+
+	tests := []struct {
+		answer string
+		want   []events.Event
+	}{
+		{"y\n", []events.Event{
+			{Type: events.CategoryFound, Category: "Cache Directories", Size: size, Items: 2, Mode: "suggest"},
+			{Type: events.SuggestionPresented, Category: "Cache Directories", Size: size, Suggestion: "suggest_delete"},
+			{Type: events.UserResponse, Category: "Cache Directories", Size: size, Suggestion: "suggest_delete", Response: "accept"},
+			{Type: events.Deleted, Category: "Cache Directories", Path: web, Size: 2000},
+			{Type: events.Deleted, Category: "Cache Directories", Path: api, Size: 1000},
+			{Type: events.Finished, Freed: size},
+		}},
+		{"n\n", []events.Event{
+			{Type: events.CategoryFound, Category: "Cache Directories", Size: size, Items: 2, Mode: "suggest"},
+			{Type: events.SuggestionPresented, Category: "Cache Directories", Size: size, Suggestion: "suggest_delete"},
+			{Type: events.UserResponse, Category: "Cache Directories", Size: size, Suggestion: "suggest_delete", Response: "reject"},
+			{Type: events.Finished},
+		}},
+	}
+	for _, tt := range tests {
+		got := run(tt.answer)
+		for i := range got {
+			if got[i].Time.IsZero() {
+				t.Errorf("answer %q: event %d has no time", tt.answer, i)
+			}
+			if got[i].Type == events.Finished && (got[i].ExitCode == nil || *got[i].ExitCode != exitOK) {
+				t.Errorf("answer %q: finished without exit code %d", tt.answer, exitOK)
+			}
+			got[i].Time, got[i].ExitCode = time.Time{}, nil
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("answer %q: events =\n%+v\nwant\n%+v", tt.answer, got, tt.want)
+		}
+	}
+}
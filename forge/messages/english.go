@@ -0,0 +1,69 @@
+package messages
+
+// English is forge's own voice, and the fallback for every other pack
+var English = Pack{
+	// Shown while a tool runs and while the LLM thinks
+	"tool.dust":   "Firing up the furnace to smelt away disk clutter...",
+	"tool.habits": "Examining your workflow to hammer out inefficiencies...",
+	"tool.other":  "Forging %s...",
+	"spinner.statuses": `Heating up the forge
+Stoking the flames
+Examining the ore
+Working the bellows
+Smelting the data
+Hammering out details
+Shaping raw findings
+Tempering the results
+Striking while hot
+Forging insights
+Refining the metal
+Checking the crucible
+Quenching the analysis
+Polishing the output
+Annealing the findings
+Nearly forged`,
+
+	// Opening lines, by assessment mode
+	"opening.auto":          "Found pure slag ready to burn off.",
+	"opening.suggest":       "Found %s of raw material that could be smelted down.",
+	"opening.guided":        "Found %d ore deposits to inspect. Let's examine each one.",
+	"opening.collaborative": "Found some unusual materials. Best we look at these together before firing up the furnace.",
+	"opening.informative":   "Laid out the findings on the anvil. The hammer's yours.",
+	"opening.done":          "Forge inspection complete. The workshop is clean.",
+
+	// The conversation
+	"quit":                  "Banking the fire. Until next time.",
+	"done":                  "Forged and finished.",
+	"auto.start":            "⚡ Burning off the slag...",
+	"suggest.found":         "Found %s of raw material to reclaim:",
+	"clean.start":           "✓ Firing up the crucible...",
+	"clean.declined":        "The metal cools. Nothing changed.",
+	"target.none":           "Nothing safe to melt down toward %s.",
+	"guided.found":          "Found %s ore deposits to inspect:",
+	"category.deleted":      "✓ Into the furnace",
+	"undo.done":             "↩ Pulled %s back out of the fire.",
+	"undo.partial":          "↩ Pulled what was left of %s back out of the fire.",
+	"undo.none":             "Nothing has gone into the fire yet.",
+	"undo.outright":         "%s went straight into the fire, off the forge's own ground; there's no pulling it back.",
+	"undo.failed":           "Couldn't pull %s back out: %v",
+	"category.skipped":      "Set aside for now.",
+	"file.deleted":          "✓ Into the crucible",
+	"file.kept":             "✓ Preserved",
+	"safe.start":            "Smelting the pure ore...",
+	"batch.active":          "Leaving %d caches still warm from the last day; they'd only be rebuilt.",
+	"safe.none":             "No ore here is pure enough to smelt unseen. Pick a category to look closer.",
+	"delete.protected":      "Not for the crucible: %s, which your never_delete %q guards.",
+	"delete.fix":            "%s is yours but locked against the hammer. Loosen its permissions and strike again?",
+	"delete.failed":         "Couldn't melt down %s: %v",
+	"delete.stopped":        "The crucible's cracked (%v); nothing more goes in this session.",
+	"delete.unjournaled":    "No cleanup journal this time (%v); melting down without one.",
+	"delete.unquarantined":  "No quarantine this time (%v); what melts down stays melted.",
+	"delete.unemptied":      "Couldn't clear out %s (%v); a later run will.",
+	"collaborative.intro":   "Found some unusual alloys that need your eye.",
+	"collaborative.deleted": "✓ Into the crucible",
+	"collaborative.kept":    "✓ Set aside",
+	"collaborative.skipped": "Passing over.",
+	"informative.intro":     "Laid out the materials for your inspection.",
+	"rating.ask":            "How did the forging go? Rate it 1-5, or press Enter to skip:",
+	"informative.outro":     "The forge stands ready. Return when you're prepared to work the metal.",
+}
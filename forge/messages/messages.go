@@ -0,0 +1,95 @@
+// Package messages is forge's catalog of user-facing phrases, keyed by id.
+// English in the forge's voice is the default; a pack replaces some or all
+// of it, for a plainer tone or another language.
+package messages
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"forge/rules"
+)
+
+// Pack maps message ids to their text, which may hold fmt verbs
+type Pack map[string]string
+
+// Packs are the built-in packs, by name
+var Packs = map[string]Pack{
+	"forge": English,
+	"plain": Plain,
+}
+
+// current is the pack Get reads before falling back to English
+var current Pack
+
+// Use makes p the pack Get reads; ids it lacks still come from English.
+// Call it before output starts: it isn't safe alongside Get.
+func Use(p Pack) {
+	current = p
+}
+
+// Get returns the text for id. An id missing from every pack comes back
+// as itself, so a typo shows up rather than printing nothing.
+func Get(id string) string {
+	if text, ok := current[id]; ok {
+		return text
+	}
+	if text, ok := English[id]; ok {
+		return text
+	}
+	return id
+}
+
+// Getf is Get with the text used as a format for args
+func Getf(id string, args ...any) string {
+	return fmt.Sprintf(Get(id), args...)
+}
+
+// Lines returns a message that's a list, one entry per line
+func Lines(id string) []string {
+	return strings.Split(strings.TrimSpace(Get(id)), "\n")
+}
+
+// Dir returns ~/.forge/messages, where packs beyond the built-in ones live
+func Dir() string {
+	return filepath.Join(rules.ForgeDir(), "messages")
+}
+
+// Load returns the built-in pack called name, or else the one in
+// Dir()/<name>.yaml, a map of ids to text
+func Load(name string) (Pack, error) {
+	if p, ok := Packs[name]; ok {
+		return p, nil
+	}
+	data, err := os.ReadFile(filepath.Join(Dir(), name+".yaml"))
+	if err != nil {
+		return nil, err
+	}
+	var p Pack
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("messages %s: %w", name, err)
+	}
+	return p, nil
+}
+
+// Locale returns the language the environment asks for, such as "fr" for
+// LANG=fr_FR.UTF-8, or "" for English and the C locale
+func Locale() string {
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		value := os.Getenv(env)
+		if value == "" {
+			continue
+		}
+		lang, _, _ := strings.Cut(value, "_")
+		lang, _, _ = strings.Cut(lang, ".")
+		if lang == "C" || lang == "POSIX" || lang == "en" {
+			return ""
+		}
+		return lang
+	}
+	return ""
+}
@@ -0,0 +1,85 @@
+package messages
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPackOverridesEnglish(t *testing.T) {
+	t.Cleanup(func() { Use(nil) })
+
+	if got := Get("done"); got != "Forged and finished." {
+		t.Errorf("Get(done) = %q, want the forge voice by default", got)
+	}
+
+	Use(Pack{"done": "Fertig."})
+	if got := Get("done"); got != "Fertig." {
+		t.Errorf("Get(done) = %q, want the pack's text", got)
+	}
+	if got := Get("quit"); got != English["quit"] {
+		t.Errorf("Get(quit) = %q, want English for ids the pack lacks", got)
+	}
+	if got := Get("no.such.id"); got != "no.such.id" {
+		t.Errorf("Get(no.such.id) = %q, want the id itself", got)
+	}
+}
+
+func TestLoadPackFromFile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Cleanup(func() { Use(nil) })
+
+	if err := os.MkdirAll(Dir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	yaml := "done: Terminé.\nopening.suggest: \"%s à récupérer.\"\n"
+	if err := os.WriteFile(filepath.Join(Dir(), "fr.yaml"), []byte(yaml), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := Load("fr")
+	if err != nil {
+		t.Fatalf("Load(fr) error = %v", err)
+	}
+	Use(p)
+	if got := Getf("opening.suggest", "3.0 GB"); got != "3.0 GB à récupérer." {
+		t.Errorf("Getf(opening.suggest) = %q, want the French text", got)
+	}
+
+	if p, err := Load("plain"); err != nil || p["done"] != Plain["done"] {
+		t.Errorf("Load(plain) = %v, %v, want the built-in plain pack", p, err)
+	}
+	if _, err := Load("de"); err == nil {
+		t.Error("Load(de) error = nil, want one for a missing pack")
+	}
+}
+
+func TestEveryPackKeyIsKnown(t *testing.T) {
+	for id := range Plain {
+		if _, ok := English[id]; !ok {
+			t.Errorf("Plain has %q, which English doesn't", id)
+		}
+	}
+}
+
+func TestLocale(t *testing.T) {
+	tests := []struct {
+		all, lang string
+		want      string
+	}{
+		{"", "fr_FR.UTF-8", "fr"},
+		{"de_DE", "fr_FR.UTF-8", "de"},
+		{"", "en_US.UTF-8", ""},
+		{"", "C", ""},
+		{"", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("LC_ALL", tt.all)
+		t.Setenv("LC_MESSAGES", "")
+		t.Setenv("LANG", tt.lang)
+		if got := Locale(); got != tt.want {
+			t.Errorf("Locale() with LC_ALL=%q LANG=%q = %q, want %q", tt.all, tt.lang, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package messages
+
+// Plain says the same as English without the forge metaphors
+var Plain = Pack{
+	"tool.dust":   "Scanning for disk clutter...",
+	"tool.habits": "Analyzing your shell history...",
+	"tool.other":  "Running %s...",
+	"spinner.statuses": `Working
+Still working
+Almost done`,
+
+	"opening.auto":          "Found items that are safe to clean automatically.",
+	"opening.suggest":       "Found %s that could be reclaimed.",
+	"opening.guided":        "Found %d categories to review, one at a time.",
+	"opening.collaborative": "Found some unusual items. Review them together before deleting anything.",
+	"opening.informative":   "Here are the findings. Nothing will be deleted without you.",
+	"opening.done":          "Nothing to clean up.",
+
+	"quit":                  "Exiting.",
+	"done":                  "Done.",
+	"auto.start":            "Cleaning...",
+	"suggest.found":         "Found %s to reclaim:",
+	"clean.start":           "✓ Cleaning...",
+	"clean.declined":        "Nothing changed.",
+	"target.none":           "Nothing safe to delete toward %s.",
+	"guided.found":          "Found %s categories to review:",
+	"category.deleted":      "✓ Deleted",
+	"undo.done":             "↩ Undid the deletion of %s.",
+	"undo.partial":          "↩ Restored what could be restored of %s.",
+	"undo.none":             "Nothing has been deleted yet.",
+	"undo.outright":         "%s was on another volume and deleted outright, so it can't be restored.",
+	"undo.failed":           "Couldn't restore %s: %v",
+	"category.skipped":      "Skipped.",
+	"file.deleted":          "✓ Deleted",
+	"file.kept":             "✓ Kept",
+	"delete.protected":      "Not deleted: %s is protected by your never_delete %q.",
+	"delete.fix":            "%s is yours but its permissions don't allow deleting it. Fix them and try again?",
+	"delete.failed":         "Couldn't delete %s: %v",
+	"delete.stopped":        "The cleanup journal failed (%v); nothing more is deleted this session.",
+	"delete.unjournaled":    "Can't keep a cleanup journal (%v); deleting without one.",
+	"delete.unquarantined":  "Can't hold deletions for undo (%v); deleting outright.",
+	"delete.unemptied":      "Couldn't empty %s (%v); a later run will.",
+	"safe.start":            "Cleaning the safe items...",
+	"batch.active":          "Skipping %d caches changed in the last day; they would only be rebuilt.",
+	"safe.none":             "Nothing here is safe to clean without a look. Pick a category instead.",
+	"collaborative.intro":   "Some unusual items need your review.",
+	"collaborative.deleted": "✓ Deleted",
+	"collaborative.kept":    "✓ Kept",
+	"collaborative.skipped": "Skipped.",
+	"informative.intro":     "Here are the findings for your review.",
+	"rating.ask":            "How was this session? Rate it 1-5, or press Enter to skip:",
+	"informative.outro":     "Run forge again when you're ready to clean up.",
+}
@@ -0,0 +1,105 @@
+package rules
+
+import "strings"
+
+// Level is the shared vocabulary for both risk and confidence, as written
+// in rules, calibrations and forge-dust's JSON
+type Level string
+
+const (
+	LevelLow      Level = "low"
+	LevelMedium   Level = "medium"
+	LevelHigh     Level = "high"
+	LevelVeryHigh Level = "very_high"
+)
+
+// Levels lists every level, lowest first
+var Levels = []Level{LevelLow, LevelMedium, LevelHigh, LevelVeryHigh}
+
+// levelInfo is the one place levels map to scores, labels and icons
+var levelInfo = map[Level]struct {
+	score      int
+	label      string
+	riskIcon   string
+	confidence string
+}{
+	LevelLow:      {1, "low", "🟢", "◔"},
+	LevelMedium:   {2, "medium", "🟡", "◑"},
+	LevelHigh:     {3, "high", "🔴", "◕"},
+	LevelVeryHigh: {4, "very high", "🔴", "●"},
+}
+
+// ParseLevel normalizes spellings like "Very High" or "very-high"; it
+// returns "" for anything that isn't a level
+func ParseLevel(s string) Level {
+	l := Level(strings.NewReplacer(" ", "_", "-", "_").Replace(strings.ToLower(strings.TrimSpace(s))))
+	if _, ok := levelInfo[l]; !ok {
+		return ""
+	}
+	return l
+}
+
+// Score orders levels from 1 (low) to 4 (very high). Unknown levels score
+// as medium, so a typo is treated with neither trust nor alarm.
+func (l Level) Score() int {
+	if info, ok := levelInfo[l]; ok {
+		return info.score
+	}
+	return levelInfo[LevelMedium].score
+}
+
+// AtLeast reports whether l scores at least as high as other
+func (l Level) AtLeast(other Level) bool {
+	return l.Score() >= other.Score()
+}
+
+// Lower returns the next level down; low stays low
+func (l Level) Lower() Level {
+	for i := len(Levels) - 1; i > 0; i-- {
+		if Levels[i].Score() < l.Score() {
+			return Levels[i]
+		}
+	}
+	return LevelLow
+}
+
+// Higher returns the next level up; very high stays very high
+func (l Level) Higher() Level {
+	for _, level := range Levels {
+		if level.Score() > l.Score() {
+			return level
+		}
+	}
+	return LevelVeryHigh
+}
+
+// Label is the level for people to read, e.g. "very high"
+func (l Level) Label() string {
+	if info, ok := levelInfo[l]; ok {
+		return info.label
+	}
+	return string(l)
+}
+
+// RiskIcon shows the level as a traffic light; unknown risk is medium
+func (l Level) RiskIcon() string {
+	if info, ok := levelInfo[l]; ok {
+		return info.riskIcon
+	}
+	return levelInfo[LevelMedium].riskIcon
+}
+
+// ConfidenceIcon shows the level as a filling circle; unknown confidence is medium
+func (l Level) ConfidenceIcon() string {
+	if info, ok := levelInfo[l]; ok {
+		return info.confidence
+	}
+	return levelInfo[LevelMedium].confidence
+}
+
+// Legend is a one-line key to the risk and confidence icons
+func Legend() string {
+	return "Risk: " + LevelLow.RiskIcon() + " low " + LevelMedium.RiskIcon() + " medium " + LevelHigh.RiskIcon() + " high" +
+		"   Confidence: " + LevelLow.ConfidenceIcon() + " low " + LevelMedium.ConfidenceIcon() + " medium " +
+		LevelHigh.ConfidenceIcon() + " high " + LevelVeryHigh.ConfidenceIcon() + " very high"
+}
@@ -0,0 +1,83 @@
+package rules
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEveryLevelHasIconsAndLabel(t *testing.T) {
+	seen := map[int]Level{}
+	for _, l := range Levels {
+		if l.RiskIcon() == "" || l.ConfidenceIcon() == "" || l.Label() == "" {
+			t.Errorf("Level %q: RiskIcon() = %q, ConfidenceIcon() = %q, Label() = %q; want all set",
+				l, l.RiskIcon(), l.ConfidenceIcon(), l.Label())
+		}
+		if other, ok := seen[l.Score()]; ok {
+			t.Errorf("Levels %q and %q both score %d", other, l, l.Score())
+		}
+		seen[l.Score()] = l
+		if !strings.Contains(Legend(), l.ConfidenceIcon()) {
+			t.Errorf("Legend() = %q, missing the confidence icon for %q", Legend(), l)
+		}
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Level
+	}{
+		{"low", LevelLow},
+		{" Medium ", LevelMedium},
+		{"HIGH", LevelHigh},
+		{"very_high", LevelVeryHigh},
+		{"Very High", LevelVeryHigh},
+		{"very-high", LevelVeryHigh},
+		{"extreme", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := ParseLevel(tt.input); got != tt.want {
+			t.Errorf("ParseLevel(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestLevelLower(t *testing.T) {
+	tests := []struct {
+		input Level
+		want  Level
+	}{
+		{LevelVeryHigh, LevelHigh},
+		{LevelHigh, LevelMedium},
+		{LevelMedium, LevelLow},
+		{LevelLow, LevelLow},
+		{"", LevelLow}, // unknown is medium
+	}
+
+	for _, tt := range tests {
+		if got := tt.input.Lower(); got != tt.want {
+			t.Errorf("Level(%q).Lower() = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestLevelHigher(t *testing.T) {
+	tests := []struct {
+		input Level
+		want  Level
+	}{
+		{LevelLow, LevelMedium},
+		{LevelMedium, LevelHigh},
+		{LevelHigh, LevelVeryHigh},
+		{LevelVeryHigh, LevelVeryHigh},
+		{"", LevelHigh}, // unknown is medium
+	}
+
+	for _, tt := range tests {
+		if got := tt.input.Higher(); got != tt.want {
+			t.Errorf("Level(%q).Higher() = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
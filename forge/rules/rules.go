@@ -1,8 +1,16 @@
 package rules
 
 import (
+	_ "embed"
+	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"forge-shared/datadir"
 
 	"gopkg.in/yaml.v3"
 )
@@ -50,6 +58,11 @@ type Preference struct {
 	Reason   string `yaml:"reason,omitempty"`
 }
 
+// embeddedBase holds the default rules shipped in the binary
+//
+//go:embed base.yaml
+var embeddedBase []byte
+
 // BaseRules contains the shipped default rules
 type BaseRules struct {
 	Version    int             `yaml:"version"`
@@ -70,6 +83,8 @@ type Preferences struct {
 	AlwaysDelete     []Preference `yaml:"always_delete"`
 	NeverDelete      []Preference `yaml:"never_delete"`
 	AlwaysAsk        []Preference `yaml:"always_ask"`
+	Disabled         []string     `yaml:"disabled,omitempty"` // base rule categories turned off
+	Hidden           []string     `yaml:"hidden,omitempty"`   // report categories, by id or name, left out of reports
 	InteractionStyle string       `yaml:"interaction_style"` // efficient, thorough, minimal
 }
 
@@ -92,10 +107,10 @@ type RuleSet struct {
 	Merged       map[string]MergedRule
 }
 
-// ForgeDir returns the forge configuration directory
+// ForgeDir returns the forge configuration directory, the one datadir.Dir
+// picks for forge-dust and forge-habits too
 func ForgeDir() string {
-	home, _ := os.UserHomeDir()
-	return filepath.Join(home, ".forge")
+	return datadir.Dir()
 }
 
 // Load reads all rule files and merges them
@@ -106,13 +121,14 @@ func Load() (*RuleSet, error) {
 
 	forgeDir := ForgeDir()
 
-	// Load base rules (shipped with binary, or from file)
+	// Load base rules: the embedded defaults, with the user's base.yaml on top
+	rs.Base = defaultBaseRules()
 	baseFile := filepath.Join(forgeDir, "rules", "base.yaml")
 	if data, err := os.ReadFile(baseFile); err == nil {
-		yaml.Unmarshal(data, &rs.Base)
-	} else {
-		// Use embedded defaults
-		rs.Base = defaultBaseRules()
+		var user BaseRules
+		if yaml.Unmarshal(data, &user) == nil {
+			rs.Base = overlay(rs.Base, user)
+		}
 	}
 
 	// Load calibrations
@@ -135,6 +151,12 @@ func Load() (*RuleSet, error) {
 
 // Save writes the calibrations and preferences to disk
 func (rs *RuleSet) Save() error {
+	// Keep Merged in step with whatever was changed in memory
+	rs.merge()
+	if Ephemeral() {
+		return ErrEphemeral
+	}
+
 	forgeDir := ForgeDir()
 	rulesDir := filepath.Join(forgeDir, "rules")
 
@@ -144,20 +166,32 @@ func (rs *RuleSet) Save() error {
 
 	// Save calibrations
 	if len(rs.Calibrations.Adjustments) > 0 || rs.Calibrations.TotalSessions > 0 {
-		calData, _ := yaml.Marshal(&rs.Calibrations)
-		os.WriteFile(filepath.Join(rulesDir, "calibrations.yaml"), calData, 0644)
+		calData, err := yaml.Marshal(&rs.Calibrations)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(rulesDir, "calibrations.yaml"), calData, 0644); err != nil {
+			return err
+		}
 	}
 
 	// Save preferences
-	prefData, _ := yaml.Marshal(&rs.Preferences)
-	os.WriteFile(filepath.Join(rulesDir, "preferences.yaml"), prefData, 0644)
-
-	return nil
+	prefData, err := yaml.Marshal(&rs.Preferences)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(rulesDir, "preferences.yaml"), prefData, 0644)
 }
 
 func (rs *RuleSet) merge() {
-	// Start with base rules
+	// Rebuilt from scratch, so a rule disabled since the last merge drops out
+	rs.Merged = make(map[string]MergedRule)
+
+	// Start with base rules, leaving out the ones the user disabled
 	for name, rule := range rs.Base.Categories {
+		if rs.IsDisabled(name) {
+			continue
+		}
 		merged := MergedRule{
 			Rule:            rule,
 			Source:          "base",
@@ -189,6 +223,162 @@ func (rs *RuleSet) merge() {
 	// TODO: Apply always_delete, never_delete, always_ask preferences
 }
 
+// IsDisabled reports whether the user turned off the named base rule
+func (rs *RuleSet) IsDisabled(name string) bool {
+	return slices.Contains(rs.Preferences.Disabled, name)
+}
+
+// Disable turns off a base rule, so nothing matches it until it's enabled
+// again. It's kept by name in preferences, so it outlasts changes to the
+// rule itself.
+func (rs *RuleSet) Disable(name string) error {
+	if _, ok := rs.Base.Categories[name]; !ok {
+		return fmt.Errorf("no rule named %q", name)
+	}
+	if !rs.IsDisabled(name) {
+		rs.Preferences.Disabled = append(rs.Preferences.Disabled, name)
+	}
+	rs.merge()
+	return nil
+}
+
+// Enable turns a disabled rule back on, reporting whether it was disabled
+func (rs *RuleSet) Enable(name string) bool {
+	i := slices.Index(rs.Preferences.Disabled, name)
+	if i == -1 {
+		return false
+	}
+	rs.Preferences.Disabled = slices.Delete(rs.Preferences.Disabled, i, i+1)
+	rs.merge()
+	return true
+}
+
+// IsHidden reports whether the user hid a report category, given by its id
+// or name in any case
+func (rs *RuleSet) IsHidden(category string) bool {
+	return slices.ContainsFunc(rs.Preferences.Hidden, func(h string) bool {
+		return strings.EqualFold(h, category)
+	})
+}
+
+// Hide leaves a report category out of reports and the conversation. Unlike
+// disabling a rule it changes nothing about how files are judged: the
+// category is still scanned and counted, just not shown.
+func (rs *RuleSet) Hide(category string) {
+	if !rs.IsHidden(category) {
+		rs.Preferences.Hidden = append(rs.Preferences.Hidden, category)
+	}
+}
+
+// Unhide shows a hidden category again, reporting whether it was hidden
+func (rs *RuleSet) Unhide(category string) bool {
+	n := len(rs.Preferences.Hidden)
+	rs.Preferences.Hidden = slices.DeleteFunc(rs.Preferences.Hidden, func(h string) bool {
+		return strings.EqualFold(h, category)
+	})
+	return len(rs.Preferences.Hidden) < n
+}
+
+// Add records a calibration. One already kept for the same pattern and
+// location is merged into it: the new settings win, the first original is
+// kept, and the evidence adds up. The result goes last, so it has the final
+// say, as a fresh calibration would.
+func (c *Calibrations) Add(cal Calibration) {
+	i := slices.IndexFunc(c.Adjustments, func(old Calibration) bool {
+		return old.Pattern == cal.Pattern && old.Location == cal.Location
+	})
+	if i == -1 {
+		c.Adjustments = append(c.Adjustments, cal)
+		return
+	}
+
+	old := c.Adjustments[i]
+	cal.Original = old.Original
+	observations := old.Evidence.Observations + cal.Evidence.Observations
+	if observations > 0 {
+		cal.Evidence.AcceptRate = (old.Evidence.AcceptRate*float64(old.Evidence.Observations) +
+			cal.Evidence.AcceptRate*float64(cal.Evidence.Observations)) / float64(observations)
+	}
+	cal.Evidence.Observations = observations
+	cal.Evidence.Sessions = append(slices.Clone(old.Evidence.Sessions), cal.Evidence.Sessions...)
+
+	c.Adjustments = append(slices.Delete(c.Adjustments, i, i+1), cal)
+}
+
+// Compact merges calibrations kept more than once for the same pattern and
+// location, as Add does, and returns how many entries it removed
+func (c *Calibrations) Compact() int {
+	before := len(c.Adjustments)
+	adjustments := c.Adjustments
+	c.Adjustments = nil
+	for _, cal := range adjustments {
+		c.Add(cal)
+	}
+	return before - len(c.Adjustments)
+}
+
+// RuleChange is how one rule's effective settings differ from its base
+type RuleChange struct {
+	Rule                string
+	BaseConfidence      string
+	EffectiveConfidence string
+	BaseAction          string
+	EffectiveAction     string
+	Pattern             string // calibration that made the change
+	LearnedAt           string
+	Reason              string
+}
+
+// Diff returns the rules whose effective confidence or action differs from
+// base, sorted by name, with the calibration responsible
+func (rs *RuleSet) Diff() []RuleChange {
+	names := make([]string, 0, len(rs.Merged))
+	for name := range rs.Merged {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var changes []RuleChange
+	for _, name := range names {
+		merged := rs.Merged[name]
+		base := rs.Base.Categories[name]
+		if merged.EffectiveConf == base.Confidence && merged.EffectiveAction == base.DefaultAction {
+			continue
+		}
+
+		change := RuleChange{
+			Rule:                name,
+			BaseConfidence:      base.Confidence,
+			EffectiveConfidence: merged.EffectiveConf,
+			BaseAction:          base.DefaultAction,
+			EffectiveAction:     merged.EffectiveAction,
+		}
+		// Calibrations apply in order, so the last matching one has the final say
+		for _, cal := range rs.Calibrations.Adjustments {
+			if matchesPattern(merged.Patterns, cal.Pattern) {
+				change.Pattern = cal.Pattern
+				change.LearnedAt = cal.LearnedAt
+				change.Reason = cal.Reason
+			}
+		}
+		changes = append(changes, change)
+	}
+
+	return changes
+}
+
+// RulesMatching returns the sorted names of merged rules that include pattern
+func (rs *RuleSet) RulesMatching(pattern string) []string {
+	var names []string
+	for name, merged := range rs.Merged {
+		if matchesPattern(merged.Patterns, pattern) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
 func matchesPattern(patterns []string, pattern string) bool {
 	for _, p := range patterns {
 		if p == pattern {
@@ -198,21 +388,42 @@ func matchesPattern(patterns []string, pattern string) bool {
 	return false
 }
 
+// NeverDeleteFor returns the first never_delete preference matching path
+func (rs *RuleSet) NeverDeleteFor(path string) (Preference, bool) {
+	for _, pref := range rs.Preferences.NeverDelete {
+		if MatchPath(path, pref.Pattern, pref.Location) {
+			return pref, true
+		}
+	}
+	return Preference{}, false
+}
+
+// Protected returns the never_delete preference covering path: one matching
+// path itself or any directory it's in
+func (rs *RuleSet) Protected(path string) (Preference, bool) {
+	for p := filepath.Clean(path); ; p = filepath.Dir(p) {
+		if pref, ok := rs.NeverDeleteFor(p); ok {
+			return pref, true
+		}
+		if filepath.Dir(p) == p {
+			return Preference{}, false
+		}
+	}
+}
+
 // GetRuleFor returns the most applicable rule for a given path
 func (rs *RuleSet) GetRuleFor(path string) *MergedRule {
 	// Check preferences first
-	for _, pref := range rs.Preferences.NeverDelete {
-		if matchPath(path, pref.Pattern, pref.Location) {
-			return &MergedRule{
-				EffectiveAction: "never_delete",
-				IsOverridden:    true,
-				Source:          "preference",
-			}
+	if _, ok := rs.NeverDeleteFor(path); ok {
+		return &MergedRule{
+			EffectiveAction: "never_delete",
+			IsOverridden:    true,
+			Source:          "preference",
 		}
 	}
 
 	for _, pref := range rs.Preferences.AlwaysDelete {
-		if matchPath(path, pref.Pattern, pref.Location) {
+		if MatchPath(path, pref.Pattern, pref.Location) {
 			return &MergedRule{
 				EffectiveAction: "auto_delete",
 				IsOverridden:    true,
@@ -221,10 +432,12 @@ func (rs *RuleSet) GetRuleFor(path string) *MergedRule {
 		}
 	}
 
-	// Check merged rules
-	for _, rule := range rs.Merged {
+	// Check merged rules, in name order so overlapping patterns always
+	// resolve to the same rule
+	for _, name := range slices.Sorted(maps.Keys(rs.Merged)) {
+		rule := rs.Merged[name]
 		for _, pattern := range rule.Patterns {
-			if matchPath(path, pattern, "") {
+			if MatchPath(path, pattern, "") {
 				return &rule
 			}
 		}
@@ -233,77 +446,79 @@ func (rs *RuleSet) GetRuleFor(path string) *MergedRule {
 	return nil
 }
 
-func matchPath(path, pattern, location string) bool {
+// MatchPath reports whether path's name matches pattern and, when location
+// is set, path lies strictly inside location
+func MatchPath(path, pattern, location string) bool {
 	// Simple pattern matching - could be enhanced
 	matched, _ := filepath.Match(pattern, filepath.Base(path))
-	return matched
+	if !matched || location == "" {
+		return matched
+	}
+
+	dir, err := filepath.Abs(ExpandHome(location))
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// MatchPaths returns the paths MatchPath accepts, leaving out anything inside
+// an already-matched directory so sizes aren't counted twice
+func MatchPaths(paths []string, pattern, location string) []string {
+	matched := map[string]bool{}
+	var result []string
+	for _, path := range paths {
+		if !MatchPath(path, pattern, location) || insideAny(path, matched) {
+			continue
+		}
+		matched[path] = true
+		result = append(result, path)
+	}
+	return result
 }
 
+// insideAny reports whether any parent directory of path is in dirs
+func insideAny(path string, dirs map[string]bool) bool {
+	for dir := filepath.Dir(path); dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+		if dirs[dir] {
+			return true
+		}
+	}
+	return false
+}
+
+// ExpandHome replaces a leading ~ with the user's home directory
+func ExpandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		home, _ := os.UserHomeDir()
+		return filepath.Join(home, path[1:])
+	}
+	return path
+}
+
+// defaultBaseRules parses the embedded base.yaml. It is checked by the
+// tests, so failing to parse is a build mistake rather than a user error.
 func defaultBaseRules() BaseRules {
-	return BaseRules{
-		Version: 1,
-		Categories: map[string]Rule{
-			"node_modules": {
-				Type:           "cache",
-				Patterns:       []string{"node_modules"},
-				Confidence:     "high",
-				Risk:           "low",
-				Reversible:     true,
-				RebuildCommand: "npm install",
-				DefaultAction:  "suggest_delete",
-			},
-			"rust_target": {
-				Type:           "cache",
-				Patterns:       []string{"target"},
-				Confidence:     "high",
-				Risk:           "low",
-				Reversible:     true,
-				RebuildCommand: "cargo build",
-				DefaultAction:  "suggest_delete",
-			},
-			"xcode_derived": {
-				Type:           "cache",
-				Patterns:       []string{"DerivedData"},
-				Confidence:     "high",
-				Risk:           "low",
-				Reversible:     true,
-				DefaultAction:  "suggest_delete",
-			},
-			"homebrew_cache": {
-				Type:           "cache",
-				Patterns:       []string{"Homebrew/downloads"},
-				Confidence:     "high",
-				Risk:           "low",
-				Reversible:     true,
-				RebuildCommand: "brew fetch",
-				DefaultAction:  "suggest_delete",
-			},
-			"python_cache": {
-				Type:           "cache",
-				Patterns:       []string{"__pycache__", ".pytest_cache", ".mypy_cache"},
-				Confidence:     "high",
-				Risk:           "low",
-				Reversible:     true,
-				DefaultAction:  "suggest_delete",
-			},
-			"installers": {
-				Type:          "temporary",
-				Patterns:      []string{"*.dmg", "*.pkg"},
-				Locations:     []string{"~/Downloads"},
-				Confidence:    "medium",
-				Risk:          "low",
-				Reversible:    false,
-				DefaultAction: "suggest_delete",
-			},
-			"personal_media": {
-				Type:          "personal",
-				Patterns:      []string{"*.mov", "*.mp4", "*.wav", "*.jpg", "*.png"},
-				Locations:     []string{"~/Documents", "~/Pictures", "~/Movies"},
-				Confidence:    "low",
-				Risk:          "high",
-				Reversible:    false,
-				DefaultAction: "inform_only",
-			},
-		},
+	var base BaseRules
+	if err := yaml.Unmarshal(embeddedBase, &base); err != nil {
+		panic("rules: embedded base.yaml: " + err.Error())
+	}
+	return base
+}
+
+// overlay lays user rules over base: categories with the same name are
+// replaced whole, new ones are added
+func overlay(base, user BaseRules) BaseRules {
+	merged := BaseRules{Version: base.Version, Categories: make(map[string]Rule, len(base.Categories)+len(user.Categories))}
+	if user.Version != 0 {
+		merged.Version = user.Version
+	}
+	for name, rule := range base.Categories {
+		merged.Categories[name] = rule
+	}
+	for name, rule := range user.Categories {
+		merged.Categories[name] = rule
 	}
+	return merged
 }
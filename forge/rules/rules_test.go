@@ -0,0 +1,307 @@
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMatchPaths(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	p := func(rel string) string { return filepath.Join(home, rel) }
+	paths := []string{
+		p("Downloads"),
+		p("Downloads/installer.dmg"),
+		p("Downloads/old"),
+		p("Downloads/old/setup.dmg"),
+		p("Documents"),
+		p("Documents/backup.dmg"),
+		p("Projects"),
+		p("Projects/app"),
+		p("Projects/app/node_modules"),
+		p("Projects/app/node_modules/dep"),
+		p("Projects/app/node_modules/dep/node_modules"),
+		p("Projects/app/node_modules/dep/index.js"),
+		p("Projects/app/main.go"),
+	}
+
+	tests := []struct {
+		pattern  string
+		location string
+		want     []string
+	}{
+		{"*.dmg", "", []string{p("Downloads/installer.dmg"), p("Downloads/old/setup.dmg"), p("Documents/backup.dmg")}},
+		{"*.dmg", "~/Downloads", []string{p("Downloads/installer.dmg"), p("Downloads/old/setup.dmg")}},
+		{"*.dmg", p("Downloads/old"), []string{p("Downloads/old/setup.dmg")}},
+		{"*.dmg", "~/Down", nil},
+		{"node_modules", "", []string{p("Projects/app/node_modules")}},
+		{"*.mov", "", nil},
+		{"*", "~/Projects/app", []string{p("Projects/app/node_modules"), p("Projects/app/main.go")}},
+	}
+
+	for _, tt := range tests {
+		got := MatchPaths(paths, tt.pattern, tt.location)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("MatchPaths(%q, %q) = %v, want %v", tt.pattern, tt.location, got, tt.want)
+		}
+	}
+}
+
+// A preference's location used to be recorded but ignored, so the
+// preference applied everywhere; GetRuleFor now holds it to that location
+func TestPreferenceLocationLimitsGetRuleFor(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	rs, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs.Preferences.NeverDelete = append(rs.Preferences.NeverDelete, Preference{Pattern: "*.mov", Location: "~/Documents/Family"})
+	rs.Preferences.AlwaysDelete = append(rs.Preferences.AlwaysDelete, Preference{Pattern: "*.dmg", Location: "~/Downloads"})
+
+	tests := []struct {
+		path string
+		want string // EffectiveAction of an overriding preference, "" for none
+	}{
+		{filepath.Join(home, "Documents/Family/birthday.mov"), "never_delete"},
+		{filepath.Join(home, "Documents/birthday.mov"), ""},
+		{filepath.Join(home, "Downloads/setup.dmg"), "auto_delete"},
+		{filepath.Join(home, "Downloads/old/setup.dmg"), "auto_delete"},
+		{filepath.Join(home, "Desktop/setup.dmg"), ""},
+	}
+
+	for _, tt := range tests {
+		got := ""
+		if rule := rs.GetRuleFor(tt.path); rule != nil && rule.IsOverridden {
+			got = rule.EffectiveAction
+		}
+		if got != tt.want {
+			t.Errorf("GetRuleFor(%q) preference = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestEmbeddedBaseRules(t *testing.T) {
+	base := defaultBaseRules()
+	if base.Version != 1 || len(base.Categories) != 7 {
+		t.Fatalf("embedded base: version %d with %d categories, want version 1 with 7", base.Version, len(base.Categories))
+	}
+	want := Rule{
+		Type:           "cache",
+		Patterns:       []string{"node_modules"},
+		Confidence:     "high",
+		Risk:           "low",
+		Reversible:     true,
+		RebuildCommand: "npm install",
+		DefaultAction:  "suggest_delete",
+	}
+	if got := base.Categories["node_modules"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("node_modules = %+v, want %+v", got, want)
+	}
+	for name, rule := range base.Categories {
+		if ParseLevel(rule.Confidence) == "" || ParseLevel(rule.Risk) == "" || rule.DefaultAction == "" {
+			t.Errorf("%s: confidence %q, risk %q, action %q; want all valid", name, rule.Confidence, rule.Risk, rule.DefaultAction)
+		}
+	}
+}
+
+func TestUserBaseRulesOverlayEmbedded(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	userBase := `version: 1
+categories:
+  gradle_cache:
+    type: cache
+    patterns: ['.gradle/caches']
+    confidence: high
+    risk: low
+    reversible: true
+    default_action: suggest_delete
+  installers:
+    type: temporary
+    patterns: ['*.dmg', '*.pkg', '*.msi']
+    locations: ['~/Downloads']
+    confidence: high
+    risk: low
+    default_action: suggest_delete
+`
+	dir := filepath.Join(home, ".forge", "rules")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "base.yaml"), []byte(userBase), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	rs, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	embedded := defaultBaseRules()
+	if got, want := len(rs.Base.Categories), len(embedded.Categories)+1; got != want {
+		t.Errorf("Load() has %d categories, want %d (the embedded ones plus gradle_cache)", got, want)
+	}
+	if _, ok := rs.Merged["gradle_cache"]; !ok {
+		t.Error("user category gradle_cache is missing")
+	}
+	if got := rs.Base.Categories["installers"].Confidence; got != "high" {
+		t.Errorf("installers confidence = %q, want the user's override \"high\"", got)
+	}
+	if got := rs.Base.Categories["node_modules"]; !reflect.DeepEqual(got, embedded.Categories["node_modules"]) {
+		t.Errorf("node_modules = %+v, want it inherited from the embedded rules", got)
+	}
+}
+
+func TestDisabledRuleMatchesNothing(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	rs, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rule := rs.GetRuleFor("/Users/me/Movies/holiday.mov"); rule == nil || !matchesPattern(rule.Patterns, "*.mov") {
+		t.Fatalf("GetRuleFor(holiday.mov) = %+v before disabling, want personal_media", rule)
+	}
+
+	if err := rs.Disable("personal_media"); err != nil {
+		t.Fatalf("Disable() error = %v", err)
+	}
+	if err := rs.Disable("no_such_rule"); err == nil {
+		t.Error("Disable(no_such_rule) succeeded, want an error")
+	}
+	if err := rs.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The choice survives a reload
+	rs, err = Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rs.IsDisabled("personal_media") {
+		t.Fatal("personal_media not disabled after reload")
+	}
+	if _, ok := rs.Merged["personal_media"]; ok {
+		t.Error("disabled rule still merged")
+	}
+	for _, path := range []string{"/Users/me/Movies/holiday.mov", "/Users/me/Pictures/cat.jpg"} {
+		if rule := rs.GetRuleFor(path); rule != nil {
+			t.Errorf("GetRuleFor(%q) = %+v, want no rule once personal_media is disabled", path, rule)
+		}
+	}
+	if rule := rs.GetRuleFor("/Users/me/Downloads/setup.dmg"); rule == nil {
+		t.Error("GetRuleFor(setup.dmg) = nil, want other rules unaffected")
+	}
+
+	if !rs.Enable("personal_media") || rs.GetRuleFor("/Users/me/Movies/holiday.mov") == nil {
+		t.Error("Enable(personal_media) didn't bring the rule back")
+	}
+}
+
+func TestCompactCalibrations(t *testing.T) {
+	cal := func(pattern, location, conf string, observations int, rate float64) Calibration {
+		c := Calibration{Pattern: pattern, Location: location}
+		c.Original.Confidence = "high"
+		c.Calibrated.Confidence = conf
+		c.Evidence.Observations = observations
+		c.Evidence.AcceptRate = rate
+		return c
+	}
+	c := Calibrations{Adjustments: []Calibration{
+		cal("node_modules", "", "very_high", 10, 1.0),
+		cal("*.dmg", "", "low", 5, 0.2),
+		cal("*.dmg", "~/Downloads", "low", 5, 0.2),
+		cal("node_modules", "", "medium", 30, 0.6),
+	}}
+	c.Adjustments[3].Original.Confidence = "very_high"
+
+	if removed := c.Compact(); removed != 1 {
+		t.Errorf("Compact() = %d, want 1", removed)
+	}
+	var got []string
+	for _, a := range c.Adjustments {
+		got = append(got, a.Pattern+"@"+a.Location)
+	}
+	if want := []string{"*.dmg@", "*.dmg@~/Downloads", "node_modules@"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Compact() left %q, want %q", got, want)
+	}
+
+	merged := c.Adjustments[2]
+	if merged.Original.Confidence != "high" || merged.Calibrated.Confidence != "medium" {
+		t.Errorf("merged = %s → %s, want the first original and the latest setting, high → medium",
+			merged.Original.Confidence, merged.Calibrated.Confidence)
+	}
+	if merged.Evidence.Observations != 40 || merged.Evidence.AcceptRate != 0.7 {
+		t.Errorf("merged evidence = %d at %v, want 40 at 0.7",
+			merged.Evidence.Observations, merged.Evidence.AcceptRate)
+	}
+
+	if removed := c.Compact(); removed != 0 {
+		t.Errorf("Compact() again = %d, want 0", removed)
+	}
+}
+
+func TestForgeDir(t *testing.T) {
+	home, xdg := t.TempDir(), t.TempDir()
+	forgeHome := filepath.Join(t.TempDir(), "forge")
+	tests := []struct {
+		name      string
+		forgeHome string
+		xdg       string
+		legacy    bool // ~/.forge already exists
+		want      string
+	}{
+		{"nothing set", "", "", false, filepath.Join(home, ".forge")},
+		{"FORGE_HOME", forgeHome, xdg, true, forgeHome},
+		{"XDG_DATA_HOME", "", xdg, false, filepath.Join(xdg, "forge")},
+		{"XDG_DATA_HOME with ~/.forge", "", xdg, true, filepath.Join(xdg, "forge")},
+		{"relative XDG_DATA_HOME", "", "data", false, filepath.Join(home, ".forge")},
+	}
+	for _, tt := range tests {
+		t.Setenv("HOME", home)
+		t.Setenv("FORGE_HOME", tt.forgeHome)
+		t.Setenv("XDG_DATA_HOME", tt.xdg)
+		os.RemoveAll(filepath.Join(home, ".forge"))
+		if tt.legacy {
+			if err := os.Mkdir(filepath.Join(home, ".forge"), 0755); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if got := ForgeDir(); got != tt.want {
+			t.Errorf("%s: ForgeDir() = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRulesKeptInForgeHome(t *testing.T) {
+	home, forgeHome := t.TempDir(), t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("FORGE_HOME", forgeHome)
+
+	rs, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	rs.Hide("Downloads")
+	if err := rs.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(forgeHome, "rules", "preferences.yaml")); err != nil {
+		t.Errorf("preferences not saved in FORGE_HOME: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(home, ".forge")); !os.IsNotExist(err) {
+		t.Errorf("Save() wrote to ~/.forge despite FORGE_HOME (stat error = %v)", err)
+	}
+
+	if rs, err = Load(); err != nil {
+		t.Fatalf("Load() again error = %v", err)
+	}
+	if !rs.IsHidden("Downloads") {
+		t.Error("IsHidden(Downloads) = false after reloading from FORGE_HOME, want true")
+	}
+}
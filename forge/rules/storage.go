@@ -0,0 +1,40 @@
+package rules
+
+import (
+	"errors"
+	"os"
+)
+
+// ErrEphemeral is returned by saves skipped because ForgeDir can't be
+// written this run
+var ErrEphemeral = errors.New("~/.forge isn't writable, so nothing is saved this run")
+
+// ephemeral is set by CheckStorage when ForgeDir can't be written
+var ephemeral bool
+
+// CheckStorage makes sure ForgeDir exists and files can be written in it.
+// If not, the run carries on in memory: Ephemeral reports true, and saves
+// return ErrEphemeral rather than writing half of what they meant to.
+func CheckStorage() error {
+	err := writable(ForgeDir())
+	ephemeral = err != nil
+	return err
+}
+
+// Ephemeral reports whether this run keeps sessions and learning in memory only
+func Ephemeral() bool {
+	return ephemeral
+}
+
+// writable creates dir if need be and checks a file can be written in it
+func writable(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, ".writable-*")
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
@@ -0,0 +1,229 @@
+package scan
+
+import (
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"forge/rules"
+)
+
+// MaxAge is how long a cached scan is reused before rescanning
+const MaxAge = time.Hour
+
+// maxCachedEntries bounds the cached scan. A bigger listing, a whole disk
+// say, isn't kept: it would take up disk of its own, and reading it back
+// would take about as long as walking again.
+var maxCachedEntries = 200_000
+
+// Entry is a scanned file or directory; directory sizes include their contents
+type Entry struct {
+	Path  string `json:"path"`
+	Size  int64  `json:"size"`
+	IsDir bool   `json:"is_dir,omitempty"`
+}
+
+// Result is a listing of everything under Root
+type Result struct {
+	Root      string    `json:"root"`
+	ScannedAt time.Time `json:"scanned_at"`
+	Entries   []Entry   `json:"entries"`
+	Errors    int       `json:"errors"`
+}
+
+// CachePath returns where the last scan is kept
+func CachePath() string {
+	return filepath.Join(rules.ForgeDir(), "cache", "scan.json")
+}
+
+// Walk lists every file and directory under root, sorted by path
+func Walk(root string) (*Result, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{Root: root, ScannedAt: time.Now()}
+	dirs := map[string]int{} // path -> index into Entries
+
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			result.Errors++
+			if path == root {
+				return err
+			}
+			return nil // Keep walking past unreadable entries
+		}
+
+		if d.IsDir() {
+			dirs[path] = len(result.Entries)
+			result.Entries = append(result.Entries, Entry{Path: path, IsDir: true})
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			result.Errors++
+			return nil
+		}
+		result.Entries = append(result.Entries, Entry{Path: path, Size: info.Size()})
+
+		// Roll the size up into every enclosing directory
+		for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+			if i, ok := dirs[dir]; ok {
+				result.Entries[i].Size += info.Size()
+			}
+			if dir == root || dir == filepath.Dir(dir) {
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(result.Entries, func(i, j int) bool {
+		return result.Entries[i].Path < result.Entries[j].Path
+	})
+	return result, nil
+}
+
+// Cached returns a listing of root, reusing the cached scan when it is fresh
+// and covers root, otherwise walking and caching a new one if it isn't too
+// big to keep
+func Cached(root string, rescan bool) (*Result, bool, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if !rescan {
+		if cached, err := loadCache(); err == nil && time.Since(cached.ScannedAt) < MaxAge && within(root, cached.Root) {
+			return cached.Under(root), true, nil
+		}
+	}
+
+	result, err := Walk(root)
+	if err != nil {
+		return nil, false, err
+	}
+	saveCache(result) // Best effort; a failed cache write only costs a rescan
+	return result, false, nil
+}
+
+// Lookup returns the part of the cached scan under root if the cache is
+// fresh and covers it. Unlike Cached, it never walks or replaces the cache.
+func Lookup(root string) (*Result, bool) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, false
+	}
+	cached, err := loadCache()
+	if err != nil || time.Since(cached.ScannedAt) >= MaxAge || !within(root, cached.Root) {
+		return nil, false
+	}
+	return cached.Under(root), true
+}
+
+// Under returns the part of the listing inside root
+func (r *Result) Under(root string) *Result {
+	if root == r.Root {
+		return r
+	}
+	sub := &Result{Root: root, ScannedAt: r.ScannedAt, Errors: r.Errors}
+	for _, e := range r.Entries {
+		if within(e.Path, root) {
+			sub.Entries = append(sub.Entries, e)
+		}
+	}
+	return sub
+}
+
+// Breakdown is what a directory's size is made of
+type Breakdown struct {
+	Dir       Entry
+	Top       []Entry // Largest direct children, largest first
+	Rest      int64   // Combined size of the children not in Top
+	RestCount int
+}
+
+// Breakdown lists the n largest files and directories directly inside dir.
+// It returns false if dir isn't a directory in the listing.
+func (r *Result) Breakdown(dir string, n int) (Breakdown, bool) {
+	dir = filepath.Clean(dir)
+	var b Breakdown
+	found := false
+	var children []Entry
+	for _, e := range r.Entries {
+		switch {
+		case e.Path == dir:
+			b.Dir, found = e, e.IsDir
+		case filepath.Dir(e.Path) == dir:
+			children = append(children, e)
+		}
+	}
+	if !found {
+		return Breakdown{}, false
+	}
+
+	sort.SliceStable(children, func(i, j int) bool { return children[i].Size > children[j].Size })
+	if len(children) > n {
+		for _, e := range children[n:] {
+			b.Rest += e.Size
+		}
+		b.RestCount = len(children) - n
+		children = children[:n]
+	}
+	b.Top = children
+	return b, true
+}
+
+// Paths returns the path of every entry, in order
+func (r *Result) Paths() []string {
+	paths := make([]string, len(r.Entries))
+	for i, e := range r.Entries {
+		paths[i] = e.Path
+	}
+	return paths
+}
+
+// within reports whether path is dir or inside it
+func within(path, dir string) bool {
+	return path == dir || strings.HasPrefix(path, strings.TrimSuffix(dir, string(filepath.Separator))+string(filepath.Separator))
+}
+
+func loadCache() (*Result, error) {
+	data, err := os.ReadFile(CachePath())
+	if err != nil {
+		return nil, err
+	}
+	var r Result
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// saveCache keeps r as the cached scan, or drops the cache if r is too big
+// to keep, so an older scan isn't reused in its place
+func saveCache(r *Result) error {
+	if len(r.Entries) > maxCachedEntries {
+		if err := os.Remove(CachePath()); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(CachePath()), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(CachePath(), data, 0644)
+}
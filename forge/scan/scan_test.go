@@ -0,0 +1,98 @@
+package scan
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBreakdownTopChildren(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]int{
+		"app/node_modules/react/index.js": 5000,
+		"app/node_modules/lodash/a.js":    3000,
+		"app/src/main.go":                 100,
+		"app/video.mov":                   7000,
+		"app/notes.txt":                   10,
+		"app/.cache/x":                    200,
+	}
+	for rel, size := range files {
+		path := filepath.Join(root, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, err := Walk(root)
+	if err != nil {
+		t.Fatalf("Walk() error = %v", err)
+	}
+
+	app := filepath.Join(root, "app")
+	b, ok := result.Breakdown(app, 2)
+	if !ok {
+		t.Fatalf("Breakdown(%s) found no directory", app)
+	}
+	if b.Dir.Size != 15310 {
+		t.Errorf("Dir.Size = %d, want 15310", b.Dir.Size)
+	}
+	want := []Entry{
+		{Path: filepath.Join(app, "node_modules"), Size: 8000, IsDir: true},
+		{Path: filepath.Join(app, "video.mov"), Size: 7000},
+	}
+	if len(b.Top) != len(want) {
+		t.Fatalf("Top = %+v, want %+v", b.Top, want)
+	}
+	for i, e := range b.Top {
+		if e != want[i] {
+			t.Errorf("Top[%d] = %+v, want %+v", i, e, want[i])
+		}
+	}
+	if b.Rest != 310 || b.RestCount != 3 {
+		t.Errorf("Rest = %d in %d entries, want 310 in 3", b.Rest, b.RestCount)
+	}
+
+	// Fewer children than asked for
+	if b, _ := result.Breakdown(filepath.Join(app, "node_modules"), 10); len(b.Top) != 2 || b.RestCount != 0 {
+		t.Errorf("Breakdown(node_modules, 10) = %+v, want both children and no rest", b)
+	}
+
+	// Files have nothing to break down
+	if _, ok := result.Breakdown(filepath.Join(app, "video.mov"), 5); ok {
+		t.Error("Breakdown(file) ok = true, want false")
+	}
+}
+
+func TestLargeScansAreNotCached(t *testing.T) {
+	t.Setenv("FORGE_HOME", t.TempDir())
+	defer func(n int) { maxCachedEntries = n }(maxCachedEntries)
+	maxCachedEntries = 3
+
+	small, large := t.TempDir(), t.TempDir()
+	for _, name := range []string{"a", "b"} {
+		os.WriteFile(filepath.Join(small, name), nil, 0644)
+	}
+	for _, name := range []string{"a", "b", "c", "d"} {
+		os.WriteFile(filepath.Join(large, name), nil, 0644)
+	}
+
+	if _, _, err := Cached(small, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := Lookup(small); !ok {
+		t.Fatal("small scan not cached")
+	}
+
+	if _, _, err := Cached(large, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(CachePath()); !os.IsNotExist(err) {
+		t.Errorf("cache still there after a scan too large to keep: %v", err)
+	}
+	if _, ok := Lookup(small); ok {
+		t.Error("Lookup() reused the older scan once a larger one replaced it")
+	}
+}
@@ -0,0 +1,96 @@
+package session
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+	"strings"
+)
+
+// Anonymized returns a copy of s that's safe to attach to a bug report:
+// paths become placeholders and free-text comments are dropped. Sizes,
+// categories, suggestions and responses are kept, which is all the learner
+// reads, so the copy reproduces the same behavior. Each copy has its own
+// Anonymizer, so placeholders can't be matched up across exports.
+func (s *Session) Anonymized() *Session {
+	a := NewAnonymizer()
+	out := *s
+	out.Interactions = make([]Interaction, len(s.Interactions))
+	for i, in := range s.Interactions {
+		if isPath(in.Item) {
+			in.Item = a.Path(in.Item)
+		}
+		if in.UserComment != "" {
+			in.UserComment = "[redacted]"
+		}
+		out.Interactions[i] = in
+	}
+
+	out.Context.FlagsUsed = make([]string, len(s.Context.FlagsUsed))
+	for i, flag := range s.Context.FlagsUsed {
+		if name, value, ok := strings.Cut(flag, "="); ok && isPath(value) {
+			flag = name + "=" + a.Path(value)
+		} else if isPath(flag) {
+			flag = a.Path(flag)
+		}
+		out.Context.FlagsUsed[i] = flag
+	}
+	if s.Context.FlagsUsed == nil {
+		out.Context.FlagsUsed = nil
+	}
+	if s.Outcome.UserSatisfaction != nil {
+		sat := *s.Outcome.UserSatisfaction
+		out.Outcome.UserSatisfaction = &sat
+	}
+	return &out
+}
+
+// Anonymizer turns names into placeholders with a random salt of its own.
+// An unsalted hash of a common name like "Users" or "Downloads" could be
+// looked up; a salted one can't, and means nothing outside its export.
+type Anonymizer struct {
+	salt []byte
+}
+
+// NewAnonymizer returns an Anonymizer with a fresh random salt
+func NewAnonymizer() *Anonymizer {
+	salt := make([]byte, 16)
+	rand.Read(salt) // Never fails, per crypto/rand
+	return &Anonymizer{salt: salt}
+}
+
+// Path replaces each element of path with a short hash of it, keeping the
+// depth, a leading / or ~, and the extension: /Users/me/Downloads/a.dmg
+// becomes something like /1b2c3d4e/5e6f7a8b/9b0c1d2e/3e4a5b6c.dmg. The
+// same name always gets the same placeholder from a, so paths that share
+// directories still do.
+func (a *Anonymizer) Path(path string) string {
+	slash := filepath.ToSlash(path)
+	parts := strings.Split(slash, "/")
+	for i, part := range parts {
+		if part == "" || part == "~" || part == "." || part == ".." {
+			continue
+		}
+		ext := filepath.Ext(part)
+		if ext == part {
+			ext = "" // A dotfile's name is all name
+		}
+		parts[i] = a.shortHash(strings.TrimSuffix(part, ext)) + ext
+	}
+	return filepath.FromSlash(strings.Join(parts, "/"))
+}
+
+// shortHash is an unreadable stand-in for name, the same for as long as a
+// is used
+func (a *Anonymizer) shortHash(name string) string {
+	mac := hmac.New(sha256.New, a.salt)
+	mac.Write([]byte(name))
+	return hex.EncodeToString(mac.Sum(nil)[:4])
+}
+
+// isPath reports whether s looks like a file path rather than, say, a command
+func isPath(s string) bool {
+	return strings.HasPrefix(s, "~") || strings.ContainsRune(s, '/') || strings.ContainsRune(s, filepath.Separator)
+}
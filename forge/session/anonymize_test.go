@@ -0,0 +1,82 @@
+package session
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAnonymizedRedactsPathsOnly(t *testing.T) {
+	satisfied := 4
+	s := &Session{
+		ID:          "sess_20260301_101500",
+		Tool:        "forge-dust",
+		Timestamp:   time.Date(2026, 3, 1, 10, 15, 0, 0, time.UTC),
+		DurationMs:  42000,
+		ScanSummary: ScanSummary{TotalScannedBytes: 1 << 40, TotalFiles: 90000, CategoriesFound: 4},
+		Interactions: []Interaction{
+			{Category: "cache_directories", ItemsPresented: 12, TotalSize: 3 << 30, Suggestion: "auto_delete", Confidence: "high", UserResponse: "auto_accepted"},
+			{Category: "downloads", Item: "/Users/jo/Downloads/tax-return-2025.pdf", TotalSize: 80 << 20, Suggestion: "delete", Confidence: "low", UserResponse: "reject", UserComment: "that's my tax return"},
+			{Category: "downloads", Item: "/Users/jo/Downloads/App-1.2.dmg", TotalSize: 90 << 20, Suggestion: "delete", Confidence: "medium", UserResponse: "accept", BytesFreed: 90 << 20},
+		},
+		Outcome: Outcome{TotalFreed: 90 << 20, ItemsDeleted: 1, ItemsKept: 1, UserSatisfaction: &satisfied},
+		Context: Context{FlagsUsed: []string{"--path=/Users/jo/Projects", "--quick"}, TimeOfDay: "morning", SessionDuration: "short"},
+	}
+
+	got := s.Anonymized()
+
+	tax, app := got.Interactions[1].Item, got.Interactions[2].Item
+	for _, p := range []string{tax, app, got.Context.FlagsUsed[0]} {
+		if strings.Contains(p, "jo") || strings.Contains(p, "Users") || strings.Contains(p, "Downloads") || strings.Contains(p, "tax") {
+			t.Errorf("path %q still shows a real name", p)
+		}
+	}
+	if filepath.Ext(tax) != ".pdf" || filepath.Ext(app) != ".dmg" {
+		t.Errorf("extensions not kept: %q, %q", tax, app)
+	}
+	if strings.Count(tax, "/") != 4 || !strings.HasPrefix(tax, "/") {
+		t.Errorf("depth not kept: %q", tax)
+	}
+	if filepath.Dir(tax) != filepath.Dir(app) {
+		t.Errorf("files in one folder got different folders: %q, %q", tax, app)
+	}
+	if other := s.Anonymized().Interactions[2].Item; other == app {
+		t.Errorf("two exports both anonymized App-1.2.dmg as %q, want each its own placeholders", app)
+	}
+	if got.Interactions[1].UserComment != "[redacted]" {
+		t.Errorf("UserComment = %q, want it redacted", got.Interactions[1].UserComment)
+	}
+	if !strings.HasPrefix(got.Context.FlagsUsed[0], "--path=/") || got.Context.FlagsUsed[1] != "--quick" {
+		t.Errorf("FlagsUsed = %q, want the path redacted and --quick kept", got.Context.FlagsUsed)
+	}
+
+	// Put the redacted fields back and everything else must match
+	restored := *got
+	restored.Interactions = append([]Interaction(nil), got.Interactions...)
+	for i := range restored.Interactions {
+		restored.Interactions[i].Item = s.Interactions[i].Item
+		restored.Interactions[i].UserComment = s.Interactions[i].UserComment
+	}
+	restored.Context.FlagsUsed = s.Context.FlagsUsed
+	if !reflect.DeepEqual(&restored, s) {
+		t.Errorf("Anonymized() changed more than paths:\n got %+v\nwant %+v", restored, *s)
+	}
+
+	// The original is untouched
+	if s.Interactions[1].Item != "/Users/jo/Downloads/tax-return-2025.pdf" || s.Context.FlagsUsed[0] != "--path=/Users/jo/Projects" {
+		t.Error("Anonymized() modified the original session")
+	}
+}
+
+func TestAnonymizerIsStableWithinOneExport(t *testing.T) {
+	a := NewAnonymizer()
+	path := "/Users/jo/Downloads/App-1.2.dmg"
+	if first, again := a.Path(path), a.Path(path); first != again {
+		t.Errorf("Path() = %q then %q, want the same placeholder", first, again)
+	}
+	if mine, theirs := a.Path(path), NewAnonymizer().Path(path); mine == theirs {
+		t.Errorf("two anonymizers both gave %q, want different salts", mine)
+	}
+}
@@ -0,0 +1,133 @@
+package session
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"forge/rules"
+)
+
+// Summary is a session cut down to what the learner reads. Save appends one
+// per session to the index, so recent sessions can be read from the tail of
+// one file instead of a file each.
+type Summary struct {
+	ID           string          `json:"id"`
+	Tool         string          `json:"tool"`
+	Timestamp    time.Time       `json:"ts"`
+	Interactions []SummaryAnswer `json:"i,omitempty"`
+	Satisfaction *int            `json:"sat,omitempty"` // 1-5 if asked
+}
+
+// SummaryAnswer is an interaction without its item or comment
+type SummaryAnswer struct {
+	Category     string `json:"c"`
+	Suggestion   string `json:"s"`
+	Confidence   string `json:"conf,omitempty"`
+	UserResponse string `json:"r"`
+	TotalSize    int64  `json:"b,omitempty"`
+}
+
+// IndexPath is the session index, one Summary per line, oldest first
+func IndexPath() string {
+	return filepath.Join(rules.ForgeDir(), "sessions.jsonl")
+}
+
+// Summarize cuts s down to its Summary
+func (s *Session) Summarize() Summary {
+	sum := Summary{ID: s.ID, Tool: s.Tool, Timestamp: s.Timestamp, Satisfaction: s.Outcome.UserSatisfaction}
+	for _, i := range s.Interactions {
+		sum.Interactions = append(sum.Interactions, SummaryAnswer{
+			Category:     i.Category,
+			Suggestion:   i.Suggestion,
+			Confidence:   i.Confidence,
+			UserResponse: i.UserResponse,
+			TotalSize:    i.TotalSize,
+		})
+	}
+	return sum
+}
+
+// Session expands the summary back into a Session with only the summarized
+// fields set
+func (sum Summary) Session() *Session {
+	s := &Session{ID: sum.ID, Tool: sum.Tool, Timestamp: sum.Timestamp}
+	s.Outcome.UserSatisfaction = sum.Satisfaction
+	for _, a := range sum.Interactions {
+		s.Interactions = append(s.Interactions, Interaction{
+			Category:     a.Category,
+			Suggestion:   a.Suggestion,
+			Confidence:   a.Confidence,
+			UserResponse: a.UserResponse,
+			TotalSize:    a.TotalSize,
+		})
+	}
+	return s
+}
+
+// appendIndex adds s's summary to the index at path
+func appendIndex(path string, s *Session) error {
+	line, err := json.Marshal(s.Summarize())
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+// tailChunk is how much of the index is read at a time, from the end
+const tailChunk = 16 * 1024
+
+// ReadIndexTail returns the last n summaries in the index at path, newest
+// first. Only as much of the file as those lines need is read. A session
+// indexed twice counts once, as its latest line; unreadable lines are skipped.
+func ReadIndexTail(path string, n int) ([]Summary, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	var summaries []Summary
+	seen := make(map[string]bool)
+	var rest []byte // Start of a line whose beginning is in an earlier chunk
+	for end := info.Size(); end > 0 && len(summaries) < n; {
+		start := max(end-tailChunk, 0)
+		chunk := make([]byte, end-start, end-start+int64(len(rest)))
+		if _, err := f.ReadAt(chunk, start); err != nil && err != io.EOF {
+			return nil, err
+		}
+		chunk = append(chunk, rest...)
+		end = start
+
+		// Every line but the first is complete; the first may continue back
+		lines := bytes.Split(chunk, []byte("\n"))
+		rest = lines[0]
+		if start == 0 {
+			rest = nil
+		} else {
+			lines = lines[1:]
+		}
+		for i := len(lines) - 1; i >= 0 && len(summaries) < n; i-- {
+			var sum Summary
+			if len(bytes.TrimSpace(lines[i])) == 0 || json.Unmarshal(lines[i], &sum) != nil || seen[sum.ID] {
+				continue
+			}
+			seen[sum.ID] = true
+			summaries = append(summaries, sum)
+		}
+	}
+	return summaries, nil
+}
@@ -0,0 +1,96 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestReadIndexTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sessions.jsonl")
+	start := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+
+	// Enough sessions that the tail spans several chunks
+	for n := 0; n < 300; n++ {
+		s := &Session{ID: fmt.Sprintf("sess_%03d", n), Tool: "forge-dust", Timestamp: start.Add(time.Duration(n) * time.Hour)}
+		for i := 0; i < 5; i++ {
+			s.AddInteraction(Interaction{Category: "cache_directories", Item: "/not/indexed", Suggestion: "auto_delete", UserResponse: "accept", TotalSize: int64(n)})
+		}
+		if err := appendIndex(path, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A torn write and a session saved twice are both survivable
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("{\"id\": \"sess_broken\n")
+	f.Close()
+	resaved := &Session{ID: "sess_298", Tool: "forge-dust", Timestamp: start}
+	resaved.AddInteraction(Interaction{Category: "downloads", Suggestion: "delete", UserResponse: "reject"})
+	if err := appendIndex(path, resaved); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ReadIndexTail(path, 4)
+	if err != nil {
+		t.Fatalf("ReadIndexTail() error = %v", err)
+	}
+	var ids []string
+	for _, s := range got {
+		ids = append(ids, s.ID)
+	}
+	if want := []string{"sess_298", "sess_299", "sess_297", "sess_296"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("ReadIndexTail() ids = %v, want %v", ids, want)
+	}
+	if got[0].Interactions[0].Category != "downloads" {
+		t.Errorf("sess_298 = %+v, want its latest save", got[0])
+	}
+
+	s := got[1].Session()
+	want := Interaction{Category: "cache_directories", Suggestion: "auto_delete", UserResponse: "accept", TotalSize: 299}
+	if s.Tool != "forge-dust" || !s.Timestamp.Equal(start.Add(299*time.Hour)) || len(s.Interactions) != 5 || s.Interactions[0] != want {
+		t.Errorf("sess_299 expands to %+v, want 5 of %+v", s, want)
+	}
+
+	if all, _ := ReadIndexTail(path, 1000); len(all) != 300 {
+		t.Errorf("ReadIndexTail(1000) = %d summaries, want all 300", len(all))
+	}
+}
+
+func TestLoadRecentSessionsUsesIndex(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	for n := 0; n < 3; n++ {
+		s := &Session{ID: fmt.Sprintf("sess_%d", n), Tool: "forge-dust"}
+		s.AddInteraction(Interaction{Category: "downloads", Item: "/Users/me/Downloads/a.dmg", Suggestion: "delete", UserResponse: "accept"})
+		rating := n + 1
+		s.Outcome.UserSatisfaction = &rating
+		if err := s.Save(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	recent, err := LoadRecentSessions(2)
+	if err != nil || len(recent) != 2 || recent[0].ID != "sess_2" || recent[1].ID != "sess_1" {
+		t.Fatalf("LoadRecentSessions(2) = %v, %v, want sess_2 and sess_1", recent, err)
+	}
+	if recent[0].Interactions[0].Item != "" {
+		t.Error("LoadRecentSessions() read the full session file, want the index summary")
+	}
+	if sat := recent[0].Outcome.UserSatisfaction; sat == nil || *sat != 3 {
+		t.Errorf("LoadRecentSessions() satisfaction = %v, want 3 from the index", sat)
+	}
+
+	// Without the index, the session files still answer
+	if err := os.Remove(IndexPath()); err != nil {
+		t.Fatal(err)
+	}
+	recent, err = LoadRecentSessions(2)
+	if err != nil || len(recent) != 2 || recent[0].Interactions[0].Item == "" {
+		t.Errorf("LoadRecentSessions(2) without an index = %v, %v, want the full sessions", recent, err)
+	}
+}
@@ -1,10 +1,13 @@
 package session
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"time"
 
 	"forge/rules"
@@ -31,16 +34,17 @@ type ScanSummary struct {
 
 // Interaction records a single suggestion and user response
 type Interaction struct {
-	Category       string `json:"category"`
-	Item           string `json:"item,omitempty"`
-	ItemsPresented int    `json:"items_presented,omitempty"`
-	TotalSize      int64  `json:"total_size"`
-	Suggestion     string `json:"suggestion"`
-	Confidence     string `json:"confidence"`
-	UserResponse   string `json:"user_response"` // accept, reject, modify, skip
-	UserComment    string `json:"user_comment,omitempty"`
-	ItemsAffected  int    `json:"items_affected,omitempty"`
-	BytesFreed     int64  `json:"bytes_freed,omitempty"`
+	Category         string `json:"category"`
+	Item             string `json:"item,omitempty"`
+	ItemsPresented   int    `json:"items_presented,omitempty"`
+	TotalSize        int64  `json:"total_size"`
+	Suggestion       string `json:"suggestion"`
+	Confidence       string `json:"confidence"`
+	UserResponse     string `json:"user_response"` // accept, reject, modify, skip
+	UserComment      string `json:"user_comment,omitempty"`
+	ItemsAffected    int    `json:"items_affected,omitempty"`
+	BytesFreed       int64  `json:"bytes_freed,omitempty"`
+	PermissionsFixed bool   `json:"permissions_fixed,omitempty"` // permissions were opened up, with the user's say-so, to delete it
 }
 
 // Outcome summarizes the session results
@@ -59,11 +63,13 @@ type Context struct {
 	SessionDuration string   `json:"session_duration"` // short, medium, long
 }
 
-// NewSession creates a new session with a unique ID
+// NewSession creates a new session with a unique ID. The time alone only
+// goes to the second, so runs in the same second, as from a script, would
+// share it and overwrite each other's file; a random suffix tells them apart.
 func NewSession(tool string) *Session {
 	now := time.Now()
 	return &Session{
-		ID:        fmt.Sprintf("sess_%s", now.Format("20060102_150405")),
+		ID:        fmt.Sprintf("sess_%s_%s", now.Format("20060102_150405"), idSuffix()),
 		Tool:      tool,
 		Timestamp: now,
 		Context: Context{
@@ -72,9 +78,82 @@ func NewSession(tool string) *Session {
 	}
 }
 
-// AddInteraction records a user interaction
+// idSuffix is six random hex digits for a session ID
+func idSuffix() string {
+	b := make([]byte, 3)
+	rand.Read(b) // never fails
+	return hex.EncodeToString(b)
+}
+
+// AddInteraction records a user interaction, keeping the outcome's totals
+// up to date
 func (s *Session) AddInteraction(i Interaction) {
 	s.Interactions = append(s.Interactions, i)
+	switch {
+	case i.deletes():
+		s.Outcome.ItemsDeleted += i.items()
+		s.Outcome.TotalFreed += i.BytesFreed
+	case i.UserResponse == "reject" || i.UserResponse == "skip":
+		s.Outcome.ItemsKept += i.items()
+	}
+}
+
+// Undo takes back the deletion recorded as interaction idx: it's recorded
+// as rejected, noted as undone, and counted as a regret. It returns the
+// interaction as it was, and false if that one deleted nothing.
+func (s *Session) Undo(idx int) (Interaction, bool) {
+	if idx < 0 || idx >= len(s.Interactions) || !s.Interactions[idx].deletes() {
+		return Interaction{}, false
+	}
+	i := &s.Interactions[idx]
+	undone := *i
+	s.Outcome.ItemsDeleted -= i.items()
+	s.Outcome.TotalFreed -= i.BytesFreed
+	s.Outcome.ItemsKept += i.items()
+	s.Outcome.Regrets++
+	i.UserResponse, i.BytesFreed, i.UserComment = "reject", 0, "undone"
+	return undone, true
+}
+
+// UndoPart takes back part of the deletion recorded as interaction idx:
+// freed bytes of it were restored, but the rest is gone for good, so the
+// answer stands
+func (s *Session) UndoPart(idx int, freed int64) {
+	if idx < 0 || idx >= len(s.Interactions) || !s.Interactions[idx].deletes() {
+		return
+	}
+	s.Interactions[idx].BytesFreed -= freed
+	s.Outcome.TotalFreed -= freed
+}
+
+// deletes reports whether the interaction deleted something
+func (i Interaction) deletes() bool {
+	return i.UserResponse == "accept" || i.UserResponse == "auto_accepted"
+}
+
+// items is how many items the interaction covered, one unless it says
+func (i Interaction) items() int {
+	return max(i.ItemsAffected, 1)
+}
+
+// AcceptanceRate returns the share of decided suggestions that were accepted,
+// counting auto-accepted ones. Views and explanations aren't decisions, so
+// ok is false when there were none.
+func (s *Session) AcceptanceRate() (rate float64, ok bool) {
+	accepted, decided := 0, 0
+	for _, i := range s.Interactions {
+		switch i.UserResponse {
+		case "accept", "auto_accepted", "modify":
+			accepted++
+			decided++
+		case "reject", "skip":
+			decided++
+		}
+	}
+	if decided == 0 {
+		return 0, false
+	}
+	return float64(accepted) / float64(decided), true
 }
 
 // Finish completes the session and calculates duration
@@ -85,6 +164,9 @@ func (s *Session) Finish() {
 
 // Save writes the session to disk
 func (s *Session) Save() error {
+	if rules.Ephemeral() {
+		return rules.ErrEphemeral
+	}
 	sessionsDir := filepath.Join(rules.ForgeDir(), "sessions")
 	if err := os.MkdirAll(sessionsDir, 0755); err != nil {
 		return err
@@ -96,12 +178,40 @@ func (s *Session) Save() error {
 		return err
 	}
 
-	return os.WriteFile(filename, data, 0644)
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		return err
+	}
+	return appendIndex(IndexPath(), s)
 }
 
 // LoadSession reads a session from disk
 func LoadSession(id string) (*Session, error) {
-	filename := filepath.Join(rules.ForgeDir(), "sessions", id+".json")
+	return readSession(filepath.Join(rules.ForgeDir(), "sessions", id+".json"))
+}
+
+// LoadDir reads every session file in dir, newest first. It's for sessions
+// kept outside ~/.forge, such as test fixtures.
+func LoadDir(dir string) ([]*Session, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var sessions []*Session
+	for _, path := range paths {
+		s, err := readSession(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+		}
+		sessions = append(sessions, s)
+	}
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].Timestamp.After(sessions[j].Timestamp)
+	})
+	return sessions, nil
+}
+
+func readSession(filename string) (*Session, error) {
 	data, err := os.ReadFile(filename)
 	if err != nil {
 		return nil, err
@@ -137,8 +247,19 @@ func ListSessions(limit int) ([]string, error) {
 	return sessions, nil
 }
 
-// LoadRecentSessions loads the N most recent sessions
+// LoadRecentSessions loads the N most recent sessions, newest first. When
+// the index covers them, they come from its tail and carry only what a
+// Summary keeps; use LoadSession for the full record.
 func LoadRecentSessions(n int) ([]*Session, error) {
+	if summaries, err := ReadIndexTail(IndexPath(), n); err == nil && len(summaries) >= min(n, CountSessions()) {
+		sessions := make([]*Session, len(summaries))
+		for i, sum := range summaries {
+			sessions[i] = sum.Session()
+		}
+		return sessions, nil
+	}
+
+	// Sessions saved before the index existed need their own files
 	ids, err := ListSessions(n)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,57 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewSessionIDsDifferWithinASecond(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	a, b := NewSession("forge-dust"), NewSession("forge-dust")
+	if stamp := a.Timestamp.Format("20060102_150405"); b.Timestamp.Format("20060102_150405") != stamp {
+		t.Skip("the clock ticked over a second between sessions")
+	}
+	if a.ID == b.ID {
+		t.Fatalf("NewSession() gave both sessions ID %s", a.ID)
+	}
+	if want := "sess_" + a.Timestamp.Format("20060102_150405") + "_"; !strings.HasPrefix(a.ID, want) {
+		t.Errorf("ID = %s, want prefix %s", a.ID, want)
+	}
+
+	for _, s := range []*Session{a, b} {
+		if err := s.Save(); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+	files, err := filepath.Glob(filepath.Join(os.Getenv("HOME"), ".forge", "sessions", "sess_*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Errorf("saved %d session files, want 2: %v", len(files), files)
+	}
+}
+
+func TestSessionsKeptInForgeHome(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	forgeHome := t.TempDir()
+	t.Setenv("FORGE_HOME", forgeHome)
+
+	s := NewSession("forge-dust")
+	if err := s.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(forgeHome, "sessions", s.ID+".json")); err != nil {
+		t.Errorf("session not saved in FORGE_HOME: %v", err)
+	}
+	loaded, err := LoadSession(s.ID)
+	if err != nil {
+		t.Fatalf("LoadSession(%s) error = %v", s.ID, err)
+	}
+	if loaded.ID != s.ID {
+		t.Errorf("LoadSession(%s).ID = %s", s.ID, loaded.ID)
+	}
+}